@@ -243,6 +243,8 @@ const (
 	ColumnOptionOnUpdate // For Timestamp and Datetime only.
 	ColumnOptionFulltext
 	ColumnOptionComment
+	ColumnOptionCheck
+	ColumnOptionGenerated
 )
 
 // ColumnOption is used for parsing column constraint info from SQL.
@@ -250,8 +252,10 @@ type ColumnOption struct {
 	node
 
 	Tp ColumnOptionType
-	// The value For Default or On Update.
+	// The value For Default or On Update, or the expression for Check or Generated.
 	Expr ExprNode
+	// Stored is only for generated column, true for STORED and false for VIRTUAL.
+	Stored bool
 }
 
 // Accept implements Node Accept interface.
@@ -309,6 +313,7 @@ const (
 	ConstraintUniqIndex
 	ConstraintForeignKey
 	ConstraintFulltext
+	ConstraintCheck
 )
 
 // Constraint is constraint for table definition.
@@ -326,6 +331,9 @@ type Constraint struct {
 
 	// Index Options
 	Option *IndexOption
+
+	// Used for check constraint.
+	Expr ExprNode
 }
 
 // Accept implements Node Accept interface.
@@ -356,6 +364,13 @@ func (n *Constraint) Accept(v Visitor) (Node, bool) {
 		}
 		n.Option = node.(*IndexOption)
 	}
+	if n.Expr != nil {
+		node, ok := n.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expr = node.(ExprNode)
+	}
 	return v.Leave(n)
 }
 
@@ -397,9 +412,11 @@ type CreateTableStmt struct {
 
 	IfNotExists bool
 	Table       *TableName
+	ReferTable  *TableName
 	Cols        []*ColumnDef
 	Constraints []*Constraint
 	Options     []*TableOption
+	Select      ResultSetNode
 }
 
 // Accept implements Node Accept interface.
@@ -414,6 +431,13 @@ func (n *CreateTableStmt) Accept(v Visitor) (Node, bool) {
 		return n, false
 	}
 	n.Table = node.(*TableName)
+	if n.ReferTable != nil {
+		node, ok = n.ReferTable.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.ReferTable = node.(*TableName)
+	}
 	for i, val := range n.Cols {
 		node, ok = val.Accept(v)
 		if !ok {
@@ -428,6 +452,13 @@ func (n *CreateTableStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Constraints[i] = node.(*Constraint)
 	}
+	if n.Select != nil {
+		node, ok := n.Select.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Select = node.(ResultSetNode)
+	}
 	return v.Leave(n)
 }
 
@@ -636,6 +667,8 @@ const (
 	AlterTableModifyColumn
 	AlterTableChangeColumn
 	AlterTableRenameTable
+	AlterTableDropCheck
+	AlterTableRenameIndex
 
 // TODO: Add more actions
 )
@@ -652,6 +685,8 @@ type AlterTableSpec struct {
 	NewColumn     *ColumnDef
 	OldColumnName *ColumnName
 	Position      *ColumnPosition
+	FromKey       model.CIStr
+	ToKey         model.CIStr
 }
 
 // Accept implements Node Accept interface.
@@ -461,6 +461,17 @@ type SelectStmt struct {
 	Limit *Limit
 	// Lock is the lock type
 	LockTp SelectLockType
+	// TableHints are the optimizer hints in the /*+ ... */ comment after the SELECT keyword.
+	TableHints []*TableOptimizerHint
+}
+
+// TableOptimizerHint is an optimizer hint of the select statement.
+// See https://dev.mysql.com/doc/refman/5.7/en/optimizer-hints.html
+type TableOptimizerHint struct {
+	// HintName is the name of the hint, like max_execution_time.
+	HintName model.CIStr
+	// MaxExecutionTime is the argument of MAX_EXECUTION_TIME(n) in milliseconds.
+	MaxExecutionTime uint64
 }
 
 // Accept implements Node Accept interface.
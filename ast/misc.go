@@ -37,6 +37,7 @@ var (
 	_ StmtNode = &RollbackStmt{}
 	_ StmtNode = &SetPwdStmt{}
 	_ StmtNode = &SetStmt{}
+	_ StmtNode = &TraceStmt{}
 	_ StmtNode = &UseStmt{}
 	_ StmtNode = &AnalyzeTableStmt{}
 	_ StmtNode = &FlushTableStmt{}
@@ -64,7 +65,8 @@ type AuthOption struct {
 	ByAuthString bool
 	AuthString   string
 	HashString   string
-	// TODO: support auth_plugin
+	// AuthPlugin is the authentication plugin of the account, it's empty if not specified.
+	AuthPlugin string
 }
 
 // ExplainStmt is a statement to provide information about how is SQL statement executed
@@ -91,6 +93,29 @@ func (n *ExplainStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// TraceStmt is a statement to execute a SQL statement and return the spans of its execution,
+// the rows of the statement are discarded.
+type TraceStmt struct {
+	stmtNode
+
+	Stmt StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *TraceStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*TraceStmt)
+	node, ok := n.Stmt.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Stmt = node.(DMLNode)
+	return v.Leave(n)
+}
+
 // PrepareStmt is a statement to prepares a SQL statement which contains placeholders,
 // and it is executed with ExecuteStmt and released with DeallocateStmt.
 // See https://dev.mysql.com/doc/refman/5.7/en/prepare.html
@@ -372,13 +397,49 @@ type UserSpec struct {
 	AuthOpt *AuthOption
 }
 
+// TLSOptionType is the type of the REQUIRE clause of an account.
+type TLSOptionType int
+
+// TLSOption types.
+const (
+	// TLSOptionNotSpecified means the REQUIRE clause is omitted.
+	TLSOptionNotSpecified TLSOptionType = iota
+	// TLSOptionNone means the account can connect with or without TLS.
+	TLSOptionNone
+	// TLSOptionSSL means the account must connect with TLS.
+	TLSOptionSSL
+	// TLSOptionX509 means the account must connect with TLS and present a valid client certificate.
+	TLSOptionX509
+)
+
+// ResourceOptionType is the type of the resource limit of an account.
+type ResourceOptionType int
+
+// ResourceOption types.
+const (
+	// MaxQueriesPerHour limits the number of statements the account can issue per hour.
+	MaxQueriesPerHour ResourceOptionType = iota + 1
+	// MaxUpdatesPerHour limits the number of statements that modify tables or databases per hour.
+	MaxUpdatesPerHour
+	// MaxUserConnections limits the number of simultaneous connections of the account.
+	MaxUserConnections
+)
+
+// ResourceOption is a resource limit in the WITH clause of an account, 0 means no limit.
+type ResourceOption struct {
+	Type  ResourceOptionType
+	Count uint64
+}
+
 // CreateUserStmt creates user account.
 // See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
 type CreateUserStmt struct {
 	stmtNode
 
-	IfNotExists bool
-	Specs       []*UserSpec
+	IfNotExists     bool
+	Specs           []*UserSpec
+	TLSOption       TLSOptionType
+	ResourceOptions []*ResourceOption
 }
 
 // Accept implements Node Accept interface.
@@ -396,9 +457,11 @@ func (n *CreateUserStmt) Accept(v Visitor) (Node, bool) {
 type AlterUserStmt struct {
 	stmtNode
 
-	IfExists    bool
-	CurrentAuth *AuthOption
-	Specs       []*UserSpec
+	IfExists        bool
+	CurrentAuth     *AuthOption
+	Specs           []*UserSpec
+	TLSOption       TLSOptionType
+	ResourceOptions []*ResourceOption
 }
 
 // Accept implements Node Accept interface.
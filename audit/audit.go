@@ -0,0 +1,228 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records who connected, from where and what statements they ran.
+//
+// The events are passed to the registered plugins whose filters match them. A plugin returning an error
+// aborts the audited operation, the connection is refused or the statement fails without returning its
+// result, so nothing is done without being audited.
+package audit
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+)
+
+// The types of the audit events.
+const (
+	// EventConnect is the event of a connection handshake or a COM_CHANGE_USER command.
+	EventConnect = "Connect"
+	// EventDisconnect is the event of a COM_QUIT command.
+	EventDisconnect = "Disconnect"
+	// EventQuery is the event of an executed statement.
+	EventQuery = "Query"
+	// EventQueryStart is the event of a DDL statement before it's executed. The DDL statements are committed
+	// by the DDL jobs and can't be rolled back, so they're refused if they can't be audited before they're
+	// executed. They're audited with their results by the EventQuery events as well.
+	EventQueryStart = "QueryStart"
+)
+
+// Event is an audit event.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	ConnID uint64    `json:"conn_id"`
+	User   string    `json:"user"`
+	Host   string    `json:"host"`
+	DB     string    `json:"db"`
+	// StmtClass is the statement class of executor.StatementClass.
+	StmtClass string `json:"stmt_class,omitempty"`
+	// Tables are the tables accessed by the statement, in the form of "db.table".
+	Tables []string `json:"tables,omitempty"`
+	SQL    string   `json:"sql,omitempty"`
+	// Succeeded is false if the event fails, the error is recorded in ErrCode and ErrMsg.
+	// The statement in autocommit mode is audited before its transaction is committed, so the commit error isn't recorded.
+	Succeeded bool   `json:"succeeded"`
+	ErrCode   uint16 `json:"err_code,omitempty"`
+	ErrMsg    string `json:"err_msg,omitempty"`
+}
+
+// SetError records the result of the audited operation.
+func (e *Event) SetError(err error) {
+	e.Succeeded = err == nil
+	if err == nil {
+		return
+	}
+	e.ErrMsg = err.Error()
+	if te, ok := errors.Cause(err).(*terror.Error); ok {
+		e.ErrCode = te.ToSQLError().Code
+	} else if me, ok := errors.Cause(err).(*mysql.SQLError); ok {
+		e.ErrCode = me.Code
+	} else {
+		e.ErrCode = mysql.ErrUnknown
+	}
+}
+
+// Plugin audits the events.
+type Plugin interface {
+	// Name returns the name of the plugin, it's unique among the registered plugins.
+	Name() string
+	// Audit records the event, the audited operation is aborted if it returns an error.
+	Audit(e *Event) error
+	// Close releases the resources of the plugin when it's unregistered.
+	Close() error
+}
+
+// Filter selects the events that are audited, the empty fields match all the events.
+// The names are case-insensitive.
+type Filter struct {
+	Users []string
+	// Classes are the statement classes of executor.StatementClass, e.g. "Insert", "CreateTable" and "Grant".
+	Classes []string
+	// DBs match the current database of the statement or the database of any accessed table.
+	DBs []string
+	// Tables are in the form of "db.table", they match any accessed table.
+	Tables []string
+}
+
+func matchName(names []string, name string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match checks if the event is selected by the filter, the connection events are only filtered by the users.
+func (f *Filter) Match(e *Event) bool {
+	if f == nil {
+		return true
+	}
+	if !matchName(f.Users, e.User) {
+		return false
+	}
+	if e.Type != EventQuery && e.Type != EventQueryStart {
+		return true
+	}
+	if !matchName(f.Classes, e.StmtClass) {
+		return false
+	}
+	if len(f.DBs) > 0 && !matchName(f.DBs, e.DB) && !matchTables(e.Tables, func(tbl string) bool {
+		return matchName(f.DBs, tbl[:strings.Index(tbl, ".")])
+	}) {
+		return false
+	}
+	return len(f.Tables) == 0 || matchTables(e.Tables, func(tbl string) bool {
+		return matchName(f.Tables, tbl)
+	})
+}
+
+// matchTables checks if any of the tables matches.
+func matchTables(tables []string, match func(tbl string) bool) bool {
+	for _, tbl := range tables {
+		if match(tbl) {
+			return true
+		}
+	}
+	return false
+}
+
+type registeredPlugin struct {
+	plugin Plugin
+	filter *Filter
+}
+
+var registry struct {
+	sync.RWMutex
+	plugins []registeredPlugin
+}
+
+var (
+	errPluginExists = terror.ClassAudit.New(codePluginExists, "audit plugin %s already exists")
+	// ErrAuditFailed is returned when an event can't be audited.
+	ErrAuditFailed = terror.ClassAudit.New(codeAuditFailed, "Aborted by audit plugin '%-.48s': %s")
+)
+
+// Error codes.
+const (
+	codePluginExists terror.ErrCode = 1
+	codeAuditFailed  terror.ErrCode = 3164
+)
+
+func init() {
+	auditMySQLErrCodes := map[terror.ErrCode]uint16{
+		codeAuditFailed: mysql.ErrAuditAPIAbort,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassAudit] = auditMySQLErrCodes
+}
+
+// Register registers an audit plugin, the events matching the filter are passed to it. A nil filter matches all the events.
+func Register(p Plugin, f *Filter) error {
+	registry.Lock()
+	defer registry.Unlock()
+	for _, rp := range registry.plugins {
+		if rp.plugin.Name() == p.Name() {
+			return errPluginExists.GenByArgs(p.Name())
+		}
+	}
+	registry.plugins = append(registry.plugins, registeredPlugin{plugin: p, filter: f})
+	return nil
+}
+
+// Unregister unregisters the audit plugin and closes it, it does nothing if the plugin isn't registered.
+func Unregister(name string) error {
+	registry.Lock()
+	defer registry.Unlock()
+	for i, rp := range registry.plugins {
+		if rp.plugin.Name() == name {
+			registry.plugins = append(registry.plugins[:i], registry.plugins[i+1:]...)
+			return errors.Trace(rp.plugin.Close())
+		}
+	}
+	return nil
+}
+
+// Enabled returns true if there is any registered plugin, the callers can skip building the events if it's false.
+func Enabled() bool {
+	registry.RLock()
+	defer registry.RUnlock()
+	return len(registry.plugins) > 0
+}
+
+// Log passes the event to the plugins whose filters match it. It returns ErrAuditFailed if any plugin fails,
+// and the audited operation should be aborted.
+func Log(e *Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, rp := range registry.plugins {
+		if !rp.filter.Match(e) {
+			continue
+		}
+		if err := rp.plugin.Audit(e); err != nil {
+			return ErrAuditFailed.GenByArgs(rp.plugin.Name(), err.Error())
+		}
+	}
+	return nil
+}
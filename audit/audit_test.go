@@ -0,0 +1,208 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testAuditSuite{})
+
+type testAuditSuite struct{}
+
+type mockPlugin struct {
+	name   string
+	err    error
+	events []*Event
+	closed bool
+}
+
+func (p *mockPlugin) Name() string {
+	return p.name
+}
+
+func (p *mockPlugin) Audit(e *Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *mockPlugin) Close() error {
+	p.closed = true
+	return nil
+}
+
+func (s *testAuditSuite) TestFilter(c *C) {
+	defer testleak.AfterTest(c)()
+	query := &Event{
+		Type:      EventQuery,
+		User:      "root",
+		DB:        "test",
+		StmtClass: "Insert",
+		Tables:    []string{"test.t1", "other.t2"},
+	}
+	connect := &Event{Type: EventConnect, User: "root"}
+	cases := []struct {
+		filter  *Filter
+		query   bool
+		connect bool
+	}{
+		{nil, true, true},
+		{&Filter{}, true, true},
+		{&Filter{Users: []string{"ROOT"}}, true, true},
+		{&Filter{Users: []string{"u1"}}, false, false},
+		{&Filter{Classes: []string{"insert", "Grant"}}, true, true},
+		{&Filter{Classes: []string{"Select"}}, false, true},
+		{&Filter{DBs: []string{"test"}}, true, true},
+		{&Filter{DBs: []string{"other"}}, true, true},
+		{&Filter{DBs: []string{"mysql"}}, false, true},
+		{&Filter{Tables: []string{"other.T2"}}, true, true},
+		{&Filter{Tables: []string{"test.t2"}}, false, true},
+		{&Filter{Users: []string{"root"}, Classes: []string{"Insert"}, Tables: []string{"test.t3"}}, false, true},
+	}
+	queryStart := *query
+	queryStart.Type = EventQueryStart
+	for _, ca := range cases {
+		c.Check(ca.filter.Match(query), Equals, ca.query, Commentf("%v", ca.filter))
+		c.Check(ca.filter.Match(&queryStart), Equals, ca.query, Commentf("%v", ca.filter))
+		c.Check(ca.filter.Match(connect), Equals, ca.connect, Commentf("%v", ca.filter))
+	}
+}
+
+func (s *testAuditSuite) TestLog(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(Enabled(), IsFalse)
+	// Nothing is audited without plugins.
+	c.Assert(Log(&Event{Type: EventQuery}), IsNil)
+
+	p1 := &mockPlugin{name: "p1"}
+	p2 := &mockPlugin{name: "p2"}
+	c.Assert(Register(p1, nil), IsNil)
+	c.Assert(Register(p2, &Filter{Users: []string{"u2"}}), IsNil)
+	c.Assert(terror.ErrorEqual(Register(&mockPlugin{name: "p1"}, nil), errPluginExists), IsTrue)
+	c.Assert(Enabled(), IsTrue)
+
+	e := &Event{Type: EventQuery, User: "u1", StmtClass: "Insert"}
+	e.SetError(nil)
+	c.Assert(Log(e), IsNil)
+	c.Assert(e.Time.IsZero(), IsFalse)
+	c.Assert(e.Succeeded, IsTrue)
+	c.Assert(p1.events, HasLen, 1)
+	c.Assert(p2.events, HasLen, 0)
+
+	e = &Event{Type: EventQuery, User: "u2", StmtClass: "Insert"}
+	e.SetError(errors.Trace(mysql.NewErr(mysql.ErrNoSuchTable, "test", "t")))
+	c.Assert(e.Succeeded, IsFalse)
+	c.Assert(e.ErrCode, Equals, uint16(mysql.ErrNoSuchTable))
+	c.Assert(Log(e), IsNil)
+	c.Assert(p1.events, HasLen, 2)
+	c.Assert(p2.events, HasLen, 1)
+
+	// The event fails if any plugin fails.
+	p2.err = errors.New("disk full")
+	err := Log(&Event{Type: EventConnect, User: "u2"})
+	c.Assert(terror.ErrorEqual(err, ErrAuditFailed), IsTrue)
+	c.Assert(err.(*terror.Error).ToSQLError().Code, Equals, uint16(mysql.ErrAuditAPIAbort))
+	c.Assert(err, ErrorMatches, ".*p2.*disk full.*")
+
+	c.Assert(Unregister("p2"), IsNil)
+	c.Assert(p2.closed, IsTrue)
+	c.Assert(Log(&Event{Type: EventConnect, User: "u2"}), IsNil)
+	c.Assert(Unregister("p1"), IsNil)
+	c.Assert(Unregister("p1"), IsNil)
+	c.Assert(Enabled(), IsFalse)
+}
+
+func readEvents(c *C, path string) []*Event {
+	f, err := os.Open(path)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	var events []*Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e := new(Event)
+		c.Assert(json.Unmarshal(scanner.Bytes(), e), IsNil)
+		events = append(events, e)
+	}
+	c.Assert(scanner.Err(), IsNil)
+	return events
+}
+
+func (s *testAuditSuite) TestFileWriter(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "audit")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	e := &Event{Type: EventQuery, ConnID: 1, User: "root", SQL: "insert into t values (1)", Succeeded: true}
+	js, err := json.Marshal(e)
+	c.Assert(err, IsNil)
+	lineSize := int64(len(js) + 1)
+
+	// Each file holds two events.
+	w, err := NewFileWriter(path, lineSize*2, 2)
+	c.Assert(err, IsNil)
+	c.Assert(w.Name(), Equals, FilePluginName)
+	for i := 0; i < 7; i++ {
+		e.ConnID = uint64(i)
+		c.Assert(w.Audit(e), IsNil)
+	}
+	c.Assert(w.Close(), IsNil)
+
+	// The events 0 and 1 are dropped as only two rotated files are kept.
+	for name, ids := range map[string][]uint64{
+		path:        {6},
+		path + ".1": {4, 5},
+		path + ".2": {2, 3},
+	} {
+		events := readEvents(c, name)
+		c.Assert(events, HasLen, len(ids), Commentf("%s", name))
+		for i, id := range ids {
+			c.Assert(events[i].ConnID, Equals, id)
+			c.Assert(events[i].SQL, Equals, e.SQL)
+		}
+	}
+	_, err = os.Stat(path + ".3")
+	c.Assert(os.IsNotExist(err), IsTrue)
+
+	// The size of the existing file is counted after reopening.
+	w, err = NewFileWriter(path, lineSize*2, 2)
+	c.Assert(err, IsNil)
+	e.ConnID = 7
+	c.Assert(w.Audit(e), IsNil)
+	e.ConnID = 8
+	c.Assert(w.Audit(e), IsNil)
+	c.Assert(w.Close(), IsNil)
+	c.Assert(readEvents(c, path), HasLen, 1)
+	c.Assert(readEvents(c, path+".1"), HasLen, 2)
+}
@@ -0,0 +1,139 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/juju/errors"
+)
+
+// FilePluginName is the name of the built-in audit plugin that writes the events to a file.
+const FilePluginName = "file"
+
+// FileWriter is the built-in audit plugin, it appends the events to a file as JSON lines.
+// The file is rotated when its size exceeds maxSize, the rotated files are named as path.1, path.2 and so on,
+// path.1 is the newest one, and at most maxBackups rotated files are kept.
+type FileWriter struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileWriter opens the audit log file, maxSize is in bytes and 0 disables the rotation.
+func NewFileWriter(path string, maxSize int64, maxBackups int) (*FileWriter, error) {
+	w := &FileWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := w.open(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return w, nil
+}
+
+func (w *FileWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Trace(err)
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+func (w *FileWriter) backupName(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+// rotate renames the current file to path.1 after shifting the older backups, and opens a new file.
+func (w *FileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	w.file = nil
+	if w.maxBackups <= 0 {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return errors.Trace(err)
+		}
+		return errors.Trace(w.open())
+	}
+	if err := os.Remove(w.backupName(w.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(w.backupName(i), w.backupName(i+1)); err != nil && !os.IsNotExist(err) {
+			return errors.Trace(err)
+		}
+	}
+	if err := os.Rename(w.path, w.backupName(1)); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(w.open())
+}
+
+// Name implements the Plugin Name interface.
+func (w *FileWriter) Name() string {
+	return FilePluginName
+}
+
+// Audit implements the Plugin Audit interface.
+func (w *FileWriter) Audit(e *Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	data = append(data, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		// The file is closed or the last rotation failed, try to open it again.
+		if err = w.open(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(data)) > w.maxSize {
+		if err = w.rotate(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	n, err := w.file.Write(data)
+	w.size += int64(n)
+	return errors.Trace(err)
+}
+
+// Close implements the Plugin Close interface.
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return errors.Trace(err)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -50,6 +51,13 @@ const (
 		Execute_priv		ENUM('N','Y') NOT NULL  DEFAULT 'N',
 		Index_priv		ENUM('N','Y') NOT NULL  DEFAULT 'N',
 		Create_user_priv	ENUM('N','Y') NOT NULL  DEFAULT 'N',
+		Ssl_type		ENUM('','ANY','X509','SPECIFIED') NOT NULL  DEFAULT '',
+		plugin			CHAR(64) NOT NULL  DEFAULT 'mysql_native_password',
+		pg_md5_password		CHAR(35) NOT NULL  DEFAULT '',
+		pg_scram_password	VARCHAR(256) NOT NULL  DEFAULT '',
+		max_questions		INT UNSIGNED NOT NULL  DEFAULT 0,
+		max_updates		INT UNSIGNED NOT NULL  DEFAULT 0,
+		max_user_connections	INT UNSIGNED NOT NULL  DEFAULT 0,
 		PRIMARY KEY (Host, User));`
 	// CreateDBPrivTable is the SQL statement creates DB scope privilege table in system db.
 	CreateDBPrivTable = `CREATE TABLE if not exists mysql.db (
@@ -143,6 +151,11 @@ const (
 	// Const for TiDB server version 2.
 	version2 = 2
 	version3 = 3
+	version4 = 4
+	version5 = 5
+	version6 = 6
+	version7 = 7
+	version8 = 8
 )
 
 func checkBootstrapped(s Session) (bool, error) {
@@ -212,6 +225,21 @@ func upgrade(s Session) {
 	if ver < version3 {
 		upgradeToVer3(s)
 	}
+	if ver < version4 {
+		upgradeToVer4(s)
+	}
+	if ver < version5 {
+		upgradeToVer5(s)
+	}
+	if ver < version6 {
+		upgradeToVer6(s)
+	}
+	if ver < version7 {
+		upgradeToVer7(s)
+	}
+	if ver < version8 {
+		upgradeToVer8(s)
+	}
 
 	updateBootstrapVer(s)
 	_, err = s.Execute("COMMIT")
@@ -260,6 +288,65 @@ func upgradeToVer3(s Session) {
 	mustExecute(s, sql)
 }
 
+// Update to version 4.
+func upgradeToVer4(s Session) {
+	// Version 4 adds the Ssl_type column to mysql.user for the REQUIRE clause of accounts.
+	sql := fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN Ssl_type ENUM('','ANY','X509','SPECIFIED') NOT NULL DEFAULT '';",
+		mysql.SystemDB, mysql.UserTable)
+	doReentrantDDL(s, sql, infoschema.ErrColumnExists)
+}
+
+// Update to version 5.
+func upgradeToVer5(s Session) {
+	// Version 5 adds the plugin column to mysql.user for the authentication plugin of accounts.
+	sql := fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN plugin CHAR(64) NOT NULL DEFAULT '%s';",
+		mysql.SystemDB, mysql.UserTable, mysql.AuthNativePassword)
+	doReentrantDDL(s, sql, infoschema.ErrColumnExists)
+}
+
+// Update to version 6.
+func upgradeToVer6(s Session) {
+	// Version 6 adds the password verifiers of the PostgreSQL protocol to mysql.user.
+	sql := fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN pg_md5_password CHAR(35) NOT NULL DEFAULT '';",
+		mysql.SystemDB, mysql.UserTable)
+	doReentrantDDL(s, sql, infoschema.ErrColumnExists)
+	sql = fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN pg_scram_password VARCHAR(256) NOT NULL DEFAULT '';",
+		mysql.SystemDB, mysql.UserTable)
+	doReentrantDDL(s, sql, infoschema.ErrColumnExists)
+}
+
+// Update to version 7.
+func upgradeToVer7(s Session) {
+	// Version 7 adds the resource limits of accounts to mysql.user.
+	for _, column := range []string{"max_questions", "max_updates", "max_user_connections"} {
+		sql := fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN %s INT UNSIGNED NOT NULL DEFAULT 0;",
+			mysql.SystemDB, mysql.UserTable, column)
+		doReentrantDDL(s, sql, infoschema.ErrColumnExists)
+	}
+}
+
+// Update to version 8.
+func upgradeToVer8(s Session) {
+	// Version 8 adds the max_execution_time system variable.
+	sql := fmt.Sprintf(`INSERT IGNORE INTO %s.%s VALUES ("%s", "%s");`, mysql.SystemDB, mysql.GlobalVariablesTable,
+		variable.MaxExecutionTime, variable.SysVars[variable.MaxExecutionTime].Value)
+	mustExecute(s, sql)
+}
+
+// doReentrantDDL executes a DDL statement of the upgrade, the statement may have been done by
+// another TiDB server or by a newer bootstrap, so the errors in ignorableErrs are ignored.
+func doReentrantDDL(s Session, sql string, ignorableErrs ...error) {
+	_, err := s.Execute(sql)
+	for _, ignorableErr := range ignorableErrs {
+		if terror.ErrorEqual(err, ignorableErr) {
+			return
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
 // Update boostrap version variable in mysql.TiDB table.
 func updateBootstrapVer(s Session) {
 	// Update bootstrap version.
@@ -307,7 +394,7 @@ func doDMLWorks(s Session) {
 
 	// Insert a default user with empty password.
 	mustExecute(s, `INSERT INTO mysql.user VALUES
-		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
+		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "", "mysql_native_password", "", "", 0, 0, 0)`)
 
 	// Init global system variables table.
 	values := make([]string, 0, len(variable.SysVars))
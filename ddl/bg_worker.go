@@ -37,6 +37,12 @@ func (d *ddl) handleBgJobQueue() error {
 			return errors.Trace(filterError(err, errNotOwner))
 		}
 
+		queueLen, err := t.BgJobQueueLen()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		jobQueueGauge.WithLabelValues(JobType(bgJobFlag).String()).Set(float64(queueLen))
+
 		// Get the first background job and run it.
 		job, err = d.getFirstBgJob(t)
 		if err != nil {
@@ -77,6 +83,15 @@ func (d *ddl) runBgJob(t *meta.Meta, job *model.Job) {
 	job.State = model.JobRunning
 
 	var err error
+	startTime := time.Now()
+	defer func() {
+		retLabel := handleJobSucc
+		if err != nil {
+			retLabel = handleJobFailed
+		}
+		runJobHistogram.WithLabelValues(JobType(bgJobFlag).String(), job.Type.String(),
+			retLabel).Observe(time.Since(startTime).Seconds())
+	}()
 	switch job.Type {
 	case model.ActionDropSchema:
 		err = d.delReorgSchema(t, job)
@@ -0,0 +1,281 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+)
+
+// buildCheckInfo builds a check constraint on tblInfo, and validates its expression.
+func buildCheckInfo(ctx context.Context, tblInfo *model.TableInfo, name model.CIStr, exprStr string) (*model.CheckInfo, error) {
+	if len(name.L) == 0 {
+		name = getAnonymousCheckName(tblInfo)
+	}
+	// A non-public check may be left in a stale schema by a rolled back job,
+	// the ddl worker will check the duplicated name again.
+	if ck := findCheckByName(name.L, tblInfo.Checks); ck != nil && ck.State == model.StatePublic {
+		return nil, errCheckConstraintDupName.GenByArgs(name.O)
+	}
+	if _, err := plan.RewriteTableExpr(ctx, exprStr, tblInfo); err != nil {
+		if terror.ErrorEqual(err, plan.ErrDisallowedTableExpr) {
+			return nil, errCheckConstraintFuncNotAllowed.GenByArgs(name.O)
+		}
+		return nil, errors.Trace(err)
+	}
+	ckInfo := &model.CheckInfo{
+		Name:       name,
+		ExprString: exprStr,
+	}
+	return ckInfo, nil
+}
+
+// getAnonymousCheckName generates a check constraint name like MySQL does, e.g. t_chk_1.
+func getAnonymousCheckName(tblInfo *model.TableInfo) model.CIStr {
+	for i := 1; ; i++ {
+		name := model.NewCIStr(fmt.Sprintf("%s_chk_%d", tblInfo.Name.O, i))
+		if findCheckByName(name.L, tblInfo.Checks) == nil {
+			return name
+		}
+	}
+}
+
+func findCheckByName(name string, checks []*model.CheckInfo) *model.CheckInfo {
+	for _, ck := range checks {
+		if ck.Name.L == name {
+			return ck
+		}
+	}
+	return nil
+}
+
+// checkColumnWithCheck returns an error if the column is used by a check constraint of the table.
+func checkColumnWithCheck(tblInfo *model.TableInfo, colName model.CIStr) error {
+	for _, ck := range tblInfo.Checks {
+		expr, err := plan.ParseTableExpr(ck.ExprString)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, name := range plan.ExtractColumnNames(expr) {
+			if name.L == colName.L {
+				return errDependentByCheckConstraint.GenByArgs(ck.Name.O, colName.O)
+			}
+		}
+	}
+	return nil
+}
+
+func removeCheck(tblInfo *model.TableInfo, name string) {
+	checks := make([]*model.CheckInfo, 0, len(tblInfo.Checks))
+	for _, ck := range tblInfo.Checks {
+		if ck.Name.L != name {
+			checks = append(checks, ck)
+		}
+	}
+	tblInfo.Checks = checks
+}
+
+func (d *ddl) onCreateCheck(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var ckInfo model.CheckInfo
+	err = job.DecodeArgs(&ckInfo)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	checkInfo := findCheckByName(ckInfo.Name.L, tblInfo.Checks)
+	if checkInfo != nil && (checkInfo.State == model.StatePublic || job.SchemaState == model.StateNone) {
+		job.State = model.JobCancelled
+		return errCheckConstraintDupName.GenByArgs(ckInfo.Name.O)
+	}
+	if checkInfo == nil {
+		checkInfo = &ckInfo
+		checkInfo.State = model.StateNone
+		checkInfo.ID = allocateIndexID(tblInfo)
+		tblInfo.Checks = append(tblInfo.Checks, checkInfo)
+	}
+
+	ver, err := updateSchemaVersion(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	switch checkInfo.State {
+	case model.StateNone:
+		// none -> write only
+		// In the write only state, all the new written rows are checked.
+		job.SchemaState = model.StateWriteOnly
+		checkInfo.State = model.StateWriteOnly
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		checkInfo.State = model.StateWriteReorganization
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateWriteReorganization:
+		// reorganization -> public
+		reorgInfo, err := d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// If we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return errors.Trace(err)
+		}
+
+		var tbl table.Table
+		tbl, err = d.getTable(schemaID, tblInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		err = d.runReorgJob(func() error {
+			return d.checkTableRows(tbl, checkInfo, reorgInfo)
+		})
+		if err != nil {
+			if terror.ErrorEqual(err, errWaitReorgTimeout) {
+				// if timeout, we should return, check for the owner and re-wait job done.
+				return nil
+			}
+			if terror.ErrorEqual(err, table.ErrCheckConstraintViolated) {
+				// Removing a check constraint only relaxes the restriction of writing,
+				// so we can remove it directly.
+				log.Warnf("[ddl] run DDL job %v err %v, rollback the job", job, err)
+				removeCheck(tblInfo, checkInfo.Name.L)
+				if err1 := t.UpdateTable(schemaID, tblInfo); err1 != nil {
+					return errors.Trace(err1)
+				}
+				job.SchemaState = model.StateNone
+				job.State = model.JobRollbackDone
+				job.BinlogInfo.AddTableInfo(ver, tblInfo)
+			}
+			return errors.Trace(err)
+		}
+
+		checkInfo.State = model.StatePublic
+		if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+			return errors.Trace(err)
+		}
+
+		// Finish this job.
+		job.SchemaState = model.StatePublic
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		return nil
+	default:
+		return ErrInvalidCheckState.Gen("invalid check constraint state %v", checkInfo.State)
+	}
+}
+
+func (d *ddl) onDropCheck(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var ckName model.CIStr
+	if err = job.DecodeArgs(&ckName); err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	checkInfo := findCheckByName(ckName.L, tblInfo.Checks)
+	if checkInfo == nil {
+		job.State = model.JobCancelled
+		return errCheckConstraintNotFound.GenByArgs(ckName.O)
+	}
+
+	ver, err := updateSchemaVersion(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	switch checkInfo.State {
+	case model.StatePublic:
+		// Dropping a check constraint only relaxes the restriction of writing, so it is safe
+		// that some servers still check the rows with it.
+		// public -> none
+		removeCheck(tblInfo, ckName.L)
+		job.SchemaState = model.StateNone
+		checkInfo.State = model.StateNone
+		if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+			return errors.Trace(err)
+		}
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		return nil
+	default:
+		return ErrInvalidCheckState.Gen("invalid check constraint state %v", checkInfo.State)
+	}
+}
+
+// checkTableRows checks whether all the rows in the snapshot satisfy the check constraint.
+func (d *ddl) checkTableRows(t table.Table, checkInfo *model.CheckInfo, reorgInfo *reorgInfo) error {
+	ctx := d.newContext()
+	tblInfo := t.Meta()
+	expr, err := plan.RewriteTableExpr(ctx, checkInfo.ExprString, tblInfo)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	decoder, err := newTableRowDecoder(ctx, tblInfo)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	sc := ctx.GetSessionVars().StmtCtx
+	count := reorgInfo.GetRowCount()
+	err = d.iterateSnapshotRows(t, reorgInfo.SnapshotVer, reorgInfo.Handle,
+		func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+			row, err := decoder.decode(ctx, h, rawRecord)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			val, err := expr.Eval(row)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if !val.IsNull() {
+				b, err := val.ToBool(sc)
+				if err != nil {
+					return false, errors.Trace(err)
+				}
+				if b == 0 {
+					return false, table.ErrCheckConstraintViolated.GenByArgs(checkInfo.Name.O)
+				}
+			}
+			count++
+			return true, nil
+		})
+	reorgInfo.SetRowCount(count)
+	return errors.Trace(err)
+}
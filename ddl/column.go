@@ -165,7 +165,9 @@ func (d *ddl) onAddColumn(t *meta.Meta, job *model.Job) error {
 		if err != nil {
 			return errors.Trace(err)
 		}
-		if columnInfo.DefaultValue != nil || mysql.HasNotNullFlag(columnInfo.Flag) {
+		// The virtual generated column is not stored, so it doesn't need to be backfilled.
+		if columnInfo.GeneratedStored ||
+			(!columnInfo.IsGenerated() && (columnInfo.DefaultValue != nil || mysql.HasNotNullFlag(columnInfo.Flag))) {
 			err = d.runReorgJob(func() error {
 				return d.addTableColumn(tbl, columnInfo, reorgInfo, job)
 			})
@@ -300,7 +302,14 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 	handles := make([]int64, 0, defaultBatchCnt)
 	// Get column default value.
 	var err error
-	if columnInfo.DefaultValue != nil {
+	if columnInfo.IsGenerated() {
+		colMeta.genDecoder, err = newTableRowDecoder(ctx, t.Meta(), columnInfo)
+		if err != nil {
+			job.State = model.JobCancelled
+			return errors.Trace(err)
+		}
+		colMeta.offset = columnInfo.Offset
+	} else if columnInfo.DefaultValue != nil {
 		colMeta.defaultVal, _, err = table.GetColDefaultValue(ctx, columnInfo)
 		if err != nil {
 			job.State = model.JobCancelled
@@ -348,7 +357,7 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 
 // backfillColumnInTxn deals with a part of backfilling column data in a Transaction.
 // This part of the column data rows is defaultSmallBatchCnt.
-func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []int64, txn kv.Transaction) (int64, error) {
+func (d *ddl) backfillColumnInTxn(ctx context.Context, t table.Table, colMeta *columnMeta, handles []int64, txn kv.Transaction) (int64, error) {
 	nextHandle := handles[0]
 	for _, handle := range handles {
 		log.Debug("[ddl] backfill column...", handle)
@@ -377,8 +386,16 @@ func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []
 			newColumnIDs = append(newColumnIDs, colID)
 			newRow = append(newRow, val)
 		}
+		newVal := colMeta.defaultVal
+		if colMeta.genDecoder != nil {
+			row, err := colMeta.genDecoder.decode(ctx, handle, rowVal)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			newVal = row[colMeta.offset]
+		}
 		newColumnIDs = append(newColumnIDs, colMeta.colID)
-		newRow = append(newRow, colMeta.defaultVal)
+		newRow = append(newRow, newVal)
 		newRowVal, err := tablecodec.EncodeRow(newRow, newColumnIDs)
 		if err != nil {
 			return 0, errors.Trace(err)
@@ -396,6 +413,9 @@ type columnMeta struct {
 	colID      int64
 	defaultVal types.Datum
 	oldColMap  map[int64]*types.FieldType
+	// genDecoder is used to evaluate the value of the stored generated column at offset.
+	genDecoder *tableRowDecoder
+	offset     int
 }
 
 func (d *ddl) backfillColumn(ctx context.Context, t table.Table, colMeta *columnMeta, handles []int64, reorgInfo *reorgInfo) error {
@@ -412,7 +432,7 @@ func (d *ddl) backfillColumn(ctx context.Context, t table.Table, colMeta *column
 				return errors.Trace(err)
 			}
 
-			nextHandle, err1 := d.backfillColumnInTxn(t, colMeta, handles[:endIdx], txn)
+			nextHandle, err1 := d.backfillColumnInTxn(ctx, t, colMeta, handles[:endIdx], txn)
 			if err1 != nil {
 				return errors.Trace(err1)
 			}
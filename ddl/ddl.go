@@ -56,6 +56,11 @@ var (
 		"unsupported drop integer primary key")
 
 	errBlobKeyWithoutLength = terror.ClassDDL.New(codeBlobKeyWithoutLength, "index for BLOB/TEXT column must specificate a key length")
+	errInvalidUseOfNull     = terror.ClassDDL.New(codeInvalidUseOfNull, "Invalid use of NULL value")
+	errKeyDoesNotExist      = terror.ClassDDL.New(codeKeyDoesNotExist, "Key '%s' doesn't exist in table '%s'")
+	errWrongNameForIndex    = terror.ClassDDL.New(codeWrongNameForIndex, "Incorrect index name '%s'")
+	errCollationMismatch    = terror.ClassDDL.New(codeCollationMismatch, "COLLATION '%s' is not valid for CHARACTER SET '%s'")
+	errUnknownCollation     = terror.ClassDDL.New(codeUnknownCollation, "Unknown collation: '%s'")
 	errIncorrectPrefixKey   = terror.ClassDDL.New(codeIncorrectPrefixKey, "Incorrect prefix key; the used key part isn't a string, the used length is longer than the key part, or the storage engine doesn't support unique prefix keys")
 	errTooLongKey           = terror.ClassDDL.New(codeTooLongKey,
 		fmt.Sprintf("Specified key was too long; max key length is %d bytes", maxPrefixLength))
@@ -68,6 +73,16 @@ var (
 	errFileNotFound          = terror.ClassDDL.New(codeFileNotFound, "Can't find file: './%s/%s.frm'")
 	errErrorOnRename         = terror.ClassDDL.New(codeErrorOnRename, "Error on rename of './%s/%s' to './%s/%s'")
 
+	errGeneratedColumnFuncNotAllowed = terror.ClassDDL.New(codeGeneratedColumnFuncNotAllowed, "Expression of generated column '%s' contains a disallowed function.")
+	errUnsupportedOnGeneratedColumn  = terror.ClassDDL.New(codeUnsupportedOnGeneratedColumn, "'%s' is not supported for generated columns.")
+	errGeneratedColumnNonPrior       = terror.ClassDDL.New(codeGeneratedColumnNonPrior, "Generated column can refer only to generated columns defined prior to it.")
+	errDependentByGeneratedColumn    = terror.ClassDDL.New(codeDependentByGeneratedColumn, "Column '%s' has a generated column dependency.")
+	errGeneratedColumnRefAutoInc     = terror.ClassDDL.New(codeGeneratedColumnRefAutoInc, "Generated column '%s' cannot refer to auto-increment column.")
+	errCheckConstraintFuncNotAllowed = terror.ClassDDL.New(codeCheckConstraintFuncNotAllowed, "An expression of a check constraint '%s' contains disallowed function.")
+	errCheckConstraintNotFound       = terror.ClassDDL.New(codeCheckConstraintNotFound, "Check constraint '%s' is not found in the table.")
+	errCheckConstraintDupName        = terror.ClassDDL.New(codeCheckConstraintDupName, "Duplicate check constraint name '%s'.")
+	errDependentByCheckConstraint    = terror.ClassDDL.New(codeDependentByCheckConstraint, "Check constraint '%s' uses column '%s', hence column cannot be dropped or renamed.")
+
 	// ErrInvalidDBState returns for invalid database state.
 	ErrInvalidDBState = terror.ClassDDL.New(codeInvalidDBState, "invalid database state")
 	// ErrInvalidTableState returns for invalid Table state.
@@ -78,6 +93,8 @@ var (
 	ErrInvalidIndexState = terror.ClassDDL.New(codeInvalidIndexState, "invalid index state")
 	// ErrInvalidForeignKeyState returns for invalid foreign key state.
 	ErrInvalidForeignKeyState = terror.ClassDDL.New(codeInvalidForeignKeyState, "invalid foreign key state")
+	// ErrInvalidCheckState returns for invalid check constraint state.
+	ErrInvalidCheckState = terror.ClassDDL.New(codeInvalidCheckState, "invalid check constraint state")
 
 	// ErrColumnBadNull returns for a bad null value.
 	ErrColumnBadNull = terror.ClassDDL.New(codeBadNull, "column cann't be null")
@@ -97,6 +114,12 @@ type DDL interface {
 	DropSchema(ctx context.Context, schema model.CIStr) error
 	CreateTable(ctx context.Context, ident ast.Ident, cols []*ast.ColumnDef,
 		constrs []*ast.Constraint, options []*ast.TableOption) error
+	CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ident) error
+	// CreateTableSelect creates a table like CreateTable, the rows of the table are written and committed by fill
+	// before the table is created, so the table is public with all the rows, or it isn't created.
+	// fill returns the last auto ID it allocated.
+	CreateTableSelect(ctx context.Context, ident ast.Ident, cols []*ast.ColumnDef,
+		constrs []*ast.Constraint, options []*ast.TableOption, fill func(tbInfo *model.TableInfo) (int64, error)) error
 	DropTable(ctx context.Context, tableIdent ast.Ident) (err error)
 	CreateIndex(ctx context.Context, tableIdent ast.Ident, unique bool, indexName model.CIStr,
 		columnNames []*ast.IndexColName) error
@@ -111,7 +134,7 @@ type DDL interface {
 	// GetLease returns current schema lease time.
 	GetLease() time.Duration
 	// Stats returns the DDL statistics.
-	Stats() (map[string]interface{}, error)
+	Stats(vars *variable.SessionVars) (map[string]interface{}, error)
 	// GetScope gets the status variables scope.
 	GetScope(status string) variable.ScopeFlag
 	// Stop stops DDL worker.
@@ -400,6 +423,7 @@ const (
 	codeInvalidColumnState     = 102
 	codeInvalidIndexState      = 103
 	codeInvalidForeignKeyState = 104
+	codeInvalidCheckState      = 105
 
 	codeCantDropColWithIndex    = 201
 	codeUnsupportedAddColumn    = 202
@@ -418,8 +442,23 @@ const (
 	codeCantDropFieldOrKey    = 1091
 	codeWrongDBName           = 1102
 	codeWrongTableName        = 1103
+	codeInvalidUseOfNull      = 1138
 	codeBlobKeyWithoutLength  = 1170
+	codeKeyDoesNotExist       = 1176
+	codeCollationMismatch     = 1253
+	codeUnknownCollation      = 1273
+	codeWrongNameForIndex     = 1280
 	codeInvalidOnUpdate       = 1294
+
+	codeGeneratedColumnFuncNotAllowed = 3102
+	codeUnsupportedOnGeneratedColumn  = 3106
+	codeGeneratedColumnNonPrior       = 3107
+	codeDependentByGeneratedColumn    = 3108
+	codeGeneratedColumnRefAutoInc     = 3109
+	codeCheckConstraintFuncNotAllowed = 3814
+	codeCheckConstraintNotFound       = 3821
+	codeCheckConstraintDupName        = 3822
+	codeDependentByCheckConstraint    = 3959
 )
 
 func init() {
@@ -428,7 +467,12 @@ func init() {
 		codeCantRemoveAllFields:   mysql.ErrCantRemoveAllFields,
 		codeCantDropFieldOrKey:    mysql.ErrCantDropFieldOrKey,
 		codeInvalidOnUpdate:       mysql.ErrInvalidOnUpdate,
+		codeInvalidUseOfNull:      mysql.ErrInvalidUseOfNull,
 		codeBlobKeyWithoutLength:  mysql.ErrBlobKeyWithoutLength,
+		codeKeyDoesNotExist:       mysql.ErrKeyDoesNotExits,
+		codeCollationMismatch:     mysql.ErrCollationCharsetMismatch,
+		codeUnknownCollation:      mysql.ErrUnknownCollation,
+		codeWrongNameForIndex:     mysql.ErrWrongNameForIndex,
 		codeIncorrectPrefixKey:    mysql.ErrWrongSubKey,
 		codeTooLongIdent:          mysql.ErrTooLongIdent,
 		codeTooLongKey:            mysql.ErrTooLongKey,
@@ -438,6 +482,16 @@ func init() {
 		codeWrongTableName:        mysql.ErrWrongTableName,
 		codeFileNotFound:          mysql.ErrFileNotFound,
 		codeErrorOnRename:         mysql.ErrErrorOnRename,
+
+		codeGeneratedColumnFuncNotAllowed: mysql.ErrGeneratedColumnFunctionIsNotAllowed,
+		codeUnsupportedOnGeneratedColumn:  mysql.ErrUnsupportedOnGeneratedColumn,
+		codeGeneratedColumnNonPrior:       mysql.ErrGeneratedColumnNonPrior,
+		codeDependentByGeneratedColumn:    mysql.ErrDependentByGeneratedColumn,
+		codeGeneratedColumnRefAutoInc:     mysql.ErrGeneratedColumnRefAutoInc,
+		codeCheckConstraintFuncNotAllowed: mysql.ErrCheckConstraintFunctionIsNotAllowed,
+		codeCheckConstraintNotFound:       mysql.ErrCheckConstraintNotFound,
+		codeCheckConstraintDupName:        mysql.ErrCheckConstraintDupName,
+		codeDependentByCheckConstraint:    mysql.ErrDependentByCheckConstraint,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassDDL] = ddlMySQLErrCodes
 }
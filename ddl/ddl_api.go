@@ -293,10 +293,26 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 				}
 			case ast.ColumnOptionFulltext:
 				// Do nothing.
+			case ast.ColumnOptionCheck:
+				constraint := &ast.Constraint{Tp: ast.ConstraintCheck, Expr: v.Expr}
+				constraints = append(constraints, constraint)
+			case ast.ColumnOptionGenerated:
+				col.GeneratedExprString = v.Expr.Text()
+				col.GeneratedStored = v.Stored
 			}
 		}
 	}
 
+	if col.IsGenerated() {
+		if err := checkGeneratedColumnOptions(col, hasDefaultValue, setOnUpdateNow); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if col.Charset == charset.CharsetBin {
+			col.Flag |= mysql.BinaryFlag
+		}
+		return col, constraints, nil
+	}
+
 	setTimestampDefaultValue(col, hasDefaultValue, setOnUpdateNow)
 
 	// Set `NoDefaultValueFlag` if this field doesn't have a default value and
@@ -313,6 +329,26 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 	return col, constraints, nil
 }
 
+// checkGeneratedColumnOptions checks the options which can't be specified for a generated column.
+// The value of a generated column is always evaluated from its expression, so it has no default value.
+func checkGeneratedColumnOptions(col *table.Column, hasDefaultValue bool, setOnUpdateNow bool) error {
+	if hasDefaultValue {
+		return errUnsupportedOnGeneratedColumn.GenByArgs("Specified a default value")
+	}
+	if setOnUpdateNow {
+		return errUnsupportedOnGeneratedColumn.GenByArgs("ON UPDATE")
+	}
+	if mysql.HasAutoIncrementFlag(col.Flag) {
+		return errUnsupportedOnGeneratedColumn.GenByArgs("AUTO_INCREMENT")
+	}
+	if col.IsVirtualGenerated() && mysql.HasPriKeyFlag(col.Flag) {
+		return errUnsupportedOnGeneratedColumn.GenByArgs("Defining a virtual generated column as primary key")
+	}
+	// The flags set for the timestamp column are only for its default value.
+	col.Flag &= ^uint(mysql.TimestampFlag | mysql.OnUpdateNowFlag)
+	return nil
+}
+
 func getDefaultValue(ctx context.Context, c *ast.ColumnOption, tp byte, fsp int) (interface{}, error) {
 	if tp == mysql.TypeTimestamp || tp == mysql.TypeDatetime {
 		vd, err := expression.GetTimeValue(ctx, c.Expr, tp, fsp)
@@ -456,6 +492,7 @@ func setEmptyConstraintName(namesMap map[string]bool, constr *ast.Constraint, fo
 func checkConstraintNames(constraints []*ast.Constraint) error {
 	constrNames := map[string]bool{}
 	fkNames := map[string]bool{}
+	checkNames := map[string]bool{}
 
 	// Check not empty constraint name whether is duplicated.
 	for _, constr := range constraints {
@@ -464,6 +501,14 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+		} else if constr.Tp == ast.ConstraintCheck {
+			nameLower := strings.ToLower(constr.Name)
+			if checkNames[nameLower] {
+				return errCheckConstraintDupName.GenByArgs(constr.Name)
+			}
+			if nameLower != "" {
+				checkNames[nameLower] = true
+			}
 		} else {
 			err := checkDuplicateConstraint(constrNames, constr.Name, false)
 			if err != nil {
@@ -473,7 +518,11 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 	}
 
 	// Set empty constraint names.
+	// Empty check constraint names are set when building the table info.
 	for _, constr := range constraints {
+		if constr.Tp == ast.ConstraintCheck {
+			continue
+		}
 		if constr.Tp == ast.ConstraintForeignKey {
 			setEmptyConstraintName(fkNames, constr, true)
 		} else {
@@ -484,7 +533,7 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 	return nil
 }
 
-func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constraints []*ast.Constraint) (tbInfo *model.TableInfo, err error) {
+func (d *ddl) buildTableInfo(ctx context.Context, tableName model.CIStr, cols []*table.Column, constraints []*ast.Constraint) (tbInfo *model.TableInfo, err error) {
 	tbInfo = &model.TableInfo{
 		Name: tableName,
 	}
@@ -496,7 +545,15 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 		v.ID = allocateColumnID(tbInfo)
 		tbInfo.Columns = append(tbInfo.Columns, v.ToInfo())
 	}
+	if err = checkGeneratedColumns(ctx, tbInfo); err != nil {
+		return nil, errors.Trace(err)
+	}
 	for _, constr := range constraints {
+		if constr.Tp == ast.ConstraintCheck {
+			// Build check constraints after all the other constraints, so the names of them
+			// are generated in the order they are defined.
+			continue
+		}
 		if constr.Tp == ast.ConstraintForeignKey {
 			for _, fk := range tbInfo.ForeignKeys {
 				if fk.Name.L == strings.ToLower(constr.Name) {
@@ -532,6 +589,9 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 				if col == nil {
 					return nil, errKeyColumnDoesNotExits.Gen("key column %s doesn't exist in table", key.Column.Name)
 				}
+				if col.IsVirtualGenerated() {
+					return nil, errUnsupportedOnGeneratedColumn.GenByArgs("Defining a virtual generated column as primary key")
+				}
 				switch col.Tp {
 				case mysql.TypeLong, mysql.TypeLonglong,
 					mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24:
@@ -566,11 +626,36 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 		idxInfo.ID = allocateIndexID(tbInfo)
 		tbInfo.Indices = append(tbInfo.Indices, idxInfo)
 	}
+	for _, constr := range constraints {
+		if constr.Tp != ast.ConstraintCheck {
+			continue
+		}
+		ckInfo, err := buildCheckInfo(ctx, tbInfo, model.NewCIStr(constr.Name), constr.Expr.Text())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ckInfo.ID = allocateIndexID(tbInfo)
+		ckInfo.State = model.StatePublic
+		tbInfo.Checks = append(tbInfo.Checks, ckInfo)
+	}
 	return
 }
 
 func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
 	constraints []*ast.Constraint, options []*ast.TableOption) (err error) {
+	return d.createTable(ctx, ident, colDefs, constraints, options, nil)
+}
+
+func (d *ddl) CreateTableSelect(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
+	constraints []*ast.Constraint, options []*ast.TableOption, fill func(tbInfo *model.TableInfo) (int64, error)) error {
+	return d.createTable(ctx, ident, colDefs, constraints, options, fill)
+}
+
+// createTable creates the table, if fill isn't nil, it's called to write the rows of the table before the job is
+// added, and the rows are deleted in background if the table isn't created. fill returns the last ID it allocated,
+// the allocator of the created table is rebased to it.
+func (d *ddl) createTable(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
+	constraints []*ast.Constraint, options []*ast.TableOption, fill func(tbInfo *model.TableInfo) (int64, error)) (err error) {
 	is := d.GetInformationSchema()
 	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
@@ -599,7 +684,7 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 		return errors.Trace(err)
 	}
 
-	tbInfo, err := d.buildTableInfo(ident.Name, cols, newConstraints)
+	tbInfo, err := d.buildTableInfo(ctx, ident.Name, cols, newConstraints)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -613,30 +698,119 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	}
 
 	handleTableOptions(options, tbInfo, schema.ID)
+	var lastID int64
+	if fill != nil {
+		if lastID, err = fill(tbInfo); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	err = d.doDDLJob(ctx, job)
+	if err != nil && fill != nil {
+		d.delTableDataInBackground(job)
+	}
 	if err == nil {
-		if tbInfo.AutoIncID > 1 {
+		if tbInfo.AutoIncID-1 > lastID {
 			// Default tableAutoIncID base is 0.
 			// If the first id is expected to greater than 1, we need to do rebase.
-			d.handleAutoIncID(tbInfo, schema.ID)
+			lastID = tbInfo.AutoIncID - 1
+		}
+		if lastID > 0 {
+			d.handleAutoIncID(tbInfo, schema.ID, lastID)
 		}
 	}
 	err = d.callHookOnChanged(err)
 	return errors.Trace(err)
 }
 
+func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ident) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	referTbl, err := is.TableByName(referIdent.Schema, referIdent.Name)
+	if err != nil {
+		return infoschema.ErrTableNotExists.GenByArgs(referIdent.Schema, referIdent.Name)
+	}
+	if is.TableExists(ident.Schema, ident.Name) {
+		return infoschema.ErrTableExists.GenByArgs(ident)
+	}
+	if err = checkTooLongTable(ident.Name); err != nil {
+		return errors.Trace(err)
+	}
+
+	tbInfo := buildTableInfoWithLike(referTbl.Meta())
+	tbInfo.Name = ident.Name
+	tbInfo.ID, err = d.genGlobalID()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tbInfo.ID,
+		Type:       model.ActionCreateTable,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{tbInfo},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// buildTableInfoWithLike copies the public columns, indices and check constraints of the refer table.
+// Like MySQL, the foreign keys and the auto increment base are not copied.
+func buildTableInfoWithLike(referTblInfo *model.TableInfo) *model.TableInfo {
+	tbInfo := referTblInfo.Clone()
+	tbInfo.AutoIncID = 0
+	tbInfo.ForeignKeys = nil
+
+	cols := make([]*model.ColumnInfo, 0, len(tbInfo.Columns))
+	offsets := make(map[string]int, len(tbInfo.Columns))
+	for _, col := range tbInfo.Columns {
+		if col.State != model.StatePublic {
+			continue
+		}
+		col.Offset = len(cols)
+		offsets[col.Name.L] = col.Offset
+		cols = append(cols, col)
+	}
+	tbInfo.Columns = cols
+
+	indices := make([]*model.IndexInfo, 0, len(tbInfo.Indices))
+	for _, idx := range tbInfo.Indices {
+		if idx.State != model.StatePublic {
+			continue
+		}
+		for _, idxCol := range idx.Columns {
+			idxCol.Offset = offsets[idxCol.Name.L]
+		}
+		indices = append(indices, idx)
+	}
+	tbInfo.Indices = indices
+
+	checks := make([]*model.CheckInfo, 0, len(tbInfo.Checks))
+	for _, ck := range tbInfo.Checks {
+		if ck.State == model.StatePublic {
+			checks = append(checks, ck)
+		}
+	}
+	tbInfo.Checks = checks
+	return tbInfo
+}
+
 // If create table with auto_increment option, we should rebase tableAutoIncID value.
-func (d *ddl) handleAutoIncID(tbInfo *model.TableInfo, schemaID int64) error {
+func (d *ddl) handleAutoIncID(tbInfo *model.TableInfo, schemaID int64, newBase int64) error {
 	alloc := autoid.NewAllocator(d.store, schemaID)
 	tbInfo.State = model.StatePublic
 	tb, err := table.TableFromMeta(alloc, tbInfo)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	// The operation of the minus 1 to make sure that the current value doesn't be used,
+	// The base is the AUTO_INCREMENT option minus 1 to make sure that the current value doesn't be used,
 	// the next Alloc operation will get this value.
 	// Its behavior is consistent with MySQL.
-	if err = tb.RebaseAutoID(tbInfo.AutoIncID-1, false); err != nil {
+	if err = tb.RebaseAutoID(newBase, false); err != nil {
 		return errors.Trace(err)
 	}
 	return nil
@@ -653,7 +827,7 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo, sch
 		case ast.TableOptionCharset:
 			tbInfo.Charset = op.StrValue
 		case ast.TableOptionCollate:
-			tbInfo.Charset = op.StrValue
+			tbInfo.Collate = op.StrValue
 		}
 	}
 }
@@ -666,12 +840,16 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 
 	for _, spec := range specs {
 		switch spec.Tp {
+		case ast.AlterTableOption:
+			err = d.AlterTableOptions(ctx, ident, spec.Options)
 		case ast.AlterTableAddColumn:
 			err = d.AddColumn(ctx, ident, spec)
 		case ast.AlterTableDropColumn:
 			err = d.DropColumn(ctx, ident, spec.OldColumnName.Name)
 		case ast.AlterTableDropIndex:
 			err = d.DropIndex(ctx, ident, model.NewCIStr(spec.Name))
+		case ast.AlterTableDropPrimaryKey:
+			err = d.DropPrimaryKey(ctx, ident)
 		case ast.AlterTableAddConstraint:
 			constr := spec.Constraint
 			switch spec.Constraint.Tp {
@@ -679,13 +857,19 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 				err = d.CreateIndex(ctx, ident, false, model.NewCIStr(constr.Name), spec.Constraint.Keys)
 			case ast.ConstraintUniq, ast.ConstraintUniqIndex, ast.ConstraintUniqKey:
 				err = d.CreateIndex(ctx, ident, true, model.NewCIStr(constr.Name), spec.Constraint.Keys)
+			case ast.ConstraintPrimaryKey:
+				err = d.CreatePrimaryKey(ctx, ident, spec.Constraint.Keys)
 			case ast.ConstraintForeignKey:
 				err = d.CreateForeignKey(ctx, ident, model.NewCIStr(constr.Name), spec.Constraint.Keys, spec.Constraint.Refer)
+			case ast.ConstraintCheck:
+				err = d.CreateCheck(ctx, ident, model.NewCIStr(constr.Name), spec.Constraint.Expr)
 			default:
 				// Nothing to do now.
 			}
 		case ast.AlterTableDropForeignKey:
 			err = d.DropForeignKey(ctx, ident, model.NewCIStr(spec.Name))
+		case ast.AlterTableDropCheck:
+			err = d.DropCheck(ctx, ident, model.NewCIStr(spec.Name))
 		case ast.AlterTableModifyColumn:
 			err = d.ModifyColumn(ctx, ident, spec)
 		case ast.AlterTableChangeColumn:
@@ -693,6 +877,8 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 		case ast.AlterTableRenameTable:
 			newIdent := ast.Ident{Schema: spec.NewTable.Schema, Name: spec.NewTable.Name}
 			err = d.RenameTable(ctx, ident, newIdent)
+		case ast.AlterTableRenameIndex:
+			err = d.RenameIndex(ctx, ident, spec.FromKey, spec.ToKey)
 		default:
 			// Nothing to do now.
 		}
@@ -705,6 +891,91 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 	return nil
 }
 
+// AlterTableOptions changes the table options of ALTER TABLE. The auto_increment base, the comment
+// and the default charset and collation of the table are changed by metadata-only jobs,
+// other options are ignored.
+func (d *ddl) AlterTableOptions(ctx context.Context, ident ast.Ident, options []*ast.TableOption) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+
+	newJob := func(tp model.ActionType, args ...interface{}) *model.Job {
+		return &model.Job{
+			SchemaID:   schema.ID,
+			TableID:    t.Meta().ID,
+			Type:       tp,
+			BinlogInfo: &model.HistoryInfo{},
+			Args:       args,
+		}
+	}
+	var (
+		jobs                 []*model.Job
+		toCharset, toCollate string
+	)
+	for _, op := range options {
+		switch op.Tp {
+		case ast.TableOptionAutoIncrement:
+			jobs = append(jobs, newJob(model.ActionRebaseAutoID, int64(op.UintValue)))
+		case ast.TableOptionComment:
+			jobs = append(jobs, newJob(model.ActionSetTableComment, op.StrValue))
+		case ast.TableOptionCharset:
+			toCharset = op.StrValue
+		case ast.TableOptionCollate:
+			toCollate = op.StrValue
+		}
+	}
+	if len(toCharset) != 0 || len(toCollate) != 0 {
+		toCharset, toCollate, err = resolveCharsetAndCollate(toCharset, toCollate)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		jobs = append(jobs, newJob(model.ActionModifyTableCharsetAndCollate, toCharset, toCollate))
+	}
+
+	for _, job := range jobs {
+		err = d.doDDLJob(ctx, job)
+		err = d.callHookOnChanged(err)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// resolveCharsetAndCollate fills the missing one of the charset and the collation.
+// Like MySQL, the charset is derived from the collation if only the collation is specified.
+func resolveCharsetAndCollate(cs, co string) (string, string, error) {
+	co = strings.ToLower(co)
+	if len(cs) == 0 {
+		for _, c := range charset.GetCollations() {
+			if c.Name == co {
+				cs = c.CharsetName
+				break
+			}
+		}
+		if len(cs) == 0 {
+			return "", "", errUnknownCollation.GenByArgs(co)
+		}
+	}
+	if len(co) == 0 {
+		var err error
+		co, err = charset.GetDefaultCollation(cs)
+		if err != nil {
+			return "", "", errors.Trace(err)
+		}
+	}
+	if !charset.ValidCharsetAndCollation(cs, co) {
+		return "", "", errCollationMismatch.GenByArgs(co, cs)
+	}
+	return cs, co, nil
+}
+
 func checkColumnConstraint(constraints []*ast.ColumnOption) error {
 	for _, constraint := range constraints {
 		switch constraint.Tp {
@@ -761,6 +1032,11 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 			return errors.Trace(err)
 		}
 	}
+	if colInfo.IsGenerated() {
+		if err = checkAddGeneratedColumn(ctx, t.Meta(), colInfo, spec.Position); err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -803,6 +1079,12 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 	if col.IsPKHandleColumn(tblInfo) {
 		return errUnsupportedPKHandle
 	}
+	if err = checkColumnWithCheck(tblInfo, colName); err != nil {
+		return errors.Trace(err)
+	}
+	if err = checkColumnWithGenerated(tblInfo, colName); err != nil {
+		return errors.Trace(err)
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -873,10 +1155,16 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 		return nil, infoschema.ErrColumnNotExists.GenByArgs(originalColName, ident.Name)
 	}
 	if spec.Constraint != nil || (spec.Position != nil && spec.Position.Tp != ast.ColumnPositionNone) ||
-		len(spec.NewColumn.Options) != 0 || spec.NewColumn.Tp == nil {
+		spec.NewColumn.Tp == nil {
 		// Make sure the column definition is simple field type.
 		return nil, errUnsupportedModifyColumn
 	}
+	// The comment is the only column option which can be modified.
+	for _, opt := range spec.NewColumn.Options {
+		if opt.Tp != ast.ColumnOptionComment {
+			return nil, errUnsupportedModifyColumn
+		}
+	}
 	setCharsetCollationFlenDecimal(spec.NewColumn.Tp)
 	if !modifiable(&col.FieldType, spec.NewColumn.Tp) {
 		return nil, errUnsupportedModifyColumn
@@ -885,6 +1173,23 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	newCol := *col
 	newCol.FieldType = *spec.NewColumn.Tp
 	newCol.Name = spec.NewColumn.Name.Name
+	for _, opt := range spec.NewColumn.Options {
+		value, err := expression.EvalAstExpr(opt.Expr, ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if newCol.Comment, err = value.ToString(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if newCol.Name.L != originalColName.L {
+		if err = checkColumnWithCheck(t.Meta(), originalColName); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err = checkColumnWithGenerated(t.Meta(), originalColName); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	job := &model.Job{
 		SchemaID:   schema.ID,
 		TableID:    t.Meta().ID,
@@ -1072,6 +1377,48 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 	return errors.Trace(err)
 }
 
+// CreatePrimaryKey adds a primary key to a table which has none.
+// The primary key is built as a unique index named PRIMARY, the existing rows are
+// validated while the index is being filled. Like MySQL, the nullable key columns
+// are changed to NOT NULL, the job fails if any existing row has a NULL key value.
+func (d *ddl) CreatePrimaryKey(ctx context.Context, ti ast.Ident, idxColNames []*ast.IndexColName) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+
+	tblInfo := t.Meta()
+	if tblInfo.PKIsHandle || findPrimaryIndex(tblInfo) != nil {
+		return infoschema.ErrMultiplePriKey
+	}
+	for _, key := range idxColNames {
+		col := findCol(tblInfo.Columns, key.Column.Name.O)
+		if col == nil {
+			return errKeyColumnDoesNotExits.Gen("key column %s doesn't exist in table", key.Column.Name)
+		}
+		if col.IsVirtualGenerated() {
+			return errUnsupportedOnGeneratedColumn.GenByArgs("Defining a virtual generated column as primary key")
+		}
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionAddPrimaryKey,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{true, model.NewCIStr(table.PrimaryKeyName), idxColNames},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func buildFKInfo(fkName model.CIStr, keys []*ast.IndexColName, refer *ast.ReferenceDef) (*model.FKInfo, error) {
 	var fkInfo model.FKInfo
 	fkInfo.Name = fkName
@@ -1150,6 +1497,65 @@ func (d *ddl) DropForeignKey(ctx context.Context, ti ast.Ident, fkName model.CIS
 	return errors.Trace(err)
 }
 
+func (d *ddl) CreateCheck(ctx context.Context, ti ast.Ident, ckName model.CIStr, expr ast.ExprNode) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+
+	ckInfo, err := buildCheckInfo(ctx, t.Meta(), ckName, expr.Text())
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionAddCheck,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{ckInfo},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) DropCheck(ctx context.Context, ti ast.Ident, ckName model.CIStr) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+
+	if ckInfo := findCheckByName(ckName.L, t.Meta().Checks); ckInfo == nil {
+		return errCheckConstraintNotFound.GenByArgs(ckName.O)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionDropCheck,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{ckName},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr) error {
 	is := d.infoHandle.Get()
 	schema, ok := is.SchemaByName(ti.Schema)
@@ -1178,6 +1584,79 @@ func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr
 	return errors.Trace(err)
 }
 
+// DropPrimaryKey drops the primary key index of a table.
+// The integer primary key which is used as the row handle can't be dropped.
+func (d *ddl) DropPrimaryKey(ctx context.Context, ti ast.Ident) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+
+	tblInfo := t.Meta()
+	if tblInfo.PKIsHandle {
+		return errUnsupportedPKHandle
+	}
+	indexInfo := findPrimaryIndex(tblInfo)
+	if indexInfo == nil {
+		return ErrCantDropFieldOrKey.Gen("primary key doesn't exist")
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionDropPrimaryKey,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{indexInfo.Name},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// RenameIndex renames an index of the table, only the table metadata is changed.
+func (d *ddl) RenameIndex(ctx context.Context, ti ast.Ident, from, to model.CIStr) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+
+	// The primary key is always named PRIMARY.
+	for _, name := range []model.CIStr{from, to} {
+		if name.L == strings.ToLower(table.PrimaryKeyName) {
+			return errWrongNameForIndex.GenByArgs(name.O)
+		}
+	}
+	if findIndexByName(from.L, t.Meta().Indices) == nil {
+		return errKeyDoesNotExist.GenByArgs(from.O, ti.Name.O)
+	}
+	if from.L != to.L && findIndexByName(to.L, t.Meta().Indices) != nil {
+		return errDupKeyName.Gen("index already exist %s", to)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionRenameIndex,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{from, to},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 // findCol finds column in cols by name.
 func findCol(cols []*model.ColumnInfo, name string) *model.ColumnInfo {
 	name = strings.ToLower(name)
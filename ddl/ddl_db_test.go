@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	tmysql "github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
@@ -513,6 +514,200 @@ func (s *testDBSuite) TestIssue2293(c *C) {
 	s.tk.MustQuery("select * from t_issue_2293").Check(testkit.Rows("1"))
 }
 
+func (s *testDBSuite) TestCheckConstraint(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_check")
+	s.tk.MustExec("create table t_check (a int check (a > 0), b int, constraint chk_ab check (a < b))")
+
+	s.tk.MustExec("insert into t_check values (1, 2), (null, 1), (2, null)")
+	s.testErrorCode(c, "insert into t_check values (0, 2)", tmysql.ErrCheckConstraintViolated)
+	s.testErrorCode(c, "insert into t_check values (3, 2)", tmysql.ErrCheckConstraintViolated)
+	s.testErrorCode(c, "replace into t_check values (3, 1)", tmysql.ErrCheckConstraintViolated)
+	s.testErrorCode(c, "update t_check set b = 0 where a = 1", tmysql.ErrCheckConstraintViolated)
+	s.tk.MustExec("update t_check set b = 3 where a = 1")
+	s.tk.MustQuery("select * from t_check order by a").Check(testkit.Rows("<nil> 1", "1 3", "2 <nil>"))
+
+	s.tk.MustQuery("select constraint_name, check_clause from information_schema.check_constraints where table_name = 't_check' order by constraint_name").
+		Check(testkit.Rows("chk_ab a < b", "t_check_chk_1 a > 0"))
+
+	// Adding a check constraint validates the existing rows.
+	s.testErrorCode(c, "alter table t_check add constraint chk_b check (b > 1)", tmysql.ErrCheckConstraintViolated)
+	s.tk.MustQuery("select count(*) from information_schema.check_constraints where constraint_name = 'chk_b'").Check(testkit.Rows("0"))
+	s.tk.MustExec("delete from t_check where b = 1")
+	s.tk.MustExec("alter table t_check add constraint chk_b check (b > 1)")
+	s.testErrorCode(c, "insert into t_check values (5, 1)", tmysql.ErrCheckConstraintViolated)
+
+	s.testErrorCode(c, "alter table t_check add constraint chk_b check (b > 2)", tmysql.ErrCheckConstraintDupName)
+	s.testErrorCode(c, "alter table t_check add check (a > rand())", tmysql.ErrCheckConstraintFunctionIsNotAllowed)
+	s.testErrorCode(c, "alter table t_check drop column b", tmysql.ErrDependentByCheckConstraint)
+	s.testErrorCode(c, "alter table t_check drop check chk_c", tmysql.ErrCheckConstraintNotFound)
+	s.testErrorCode(c, "create table t_check_dup (a int, constraint c1 check (a > 0), constraint c1 check (a < 10))", tmysql.ErrCheckConstraintDupName)
+
+	s.tk.MustExec("alter table t_check drop check chk_b")
+	s.tk.MustExec("alter table t_check drop check chk_ab")
+	s.tk.MustExec("insert into t_check values (5, 1)")
+	s.tk.MustExec("alter table t_check drop column b")
+	s.tk.MustQuery("show create table t_check").Check(testkit.Rows("t_check CREATE TABLE `t_check` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  CONSTRAINT `t_check_chk_1` CHECK (a > 0)\n" +
+		") ENGINE=InnoDB"))
+	s.tk.MustExec("drop table t_check")
+}
+
+func (s *testDBSuite) TestGeneratedColumn(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_gen")
+	s.tk.MustExec("create table t_gen (a int, b int as (a + 1), c int generated always as (b * 2) stored, index idx_c (c))")
+
+	s.tk.MustExec("insert into t_gen (a) values (1), (2)")
+	s.tk.MustExec("insert into t_gen values (3, default, default)")
+	s.tk.MustExec("insert into t_gen set a = 4")
+	s.tk.MustQuery("select * from t_gen order by a").Check(testkit.Rows("1 2 4", "2 3 6", "3 4 8", "4 5 10"))
+	s.tk.MustQuery("select a from t_gen where b = 3").Check(testkit.Rows("2"))
+	s.tk.MustQuery("select a from t_gen where (a + 1) * 2 = 8").Check(testkit.Rows("3"))
+	s.tk.MustQuery("select a from t_gen where c between 5 and 9 order by a").Check(testkit.Rows("2", "3"))
+	s.tk.MustExec("update t_gen set a = 10 where a = 1")
+	s.tk.MustQuery("select * from t_gen where c = 22").Check(testkit.Rows("10 11 22"))
+	s.tk.MustExec("delete from t_gen where b = 11")
+	s.tk.MustQuery("select count(*) from t_gen").Check(testkit.Rows("3"))
+
+	s.testErrorCode(c, "insert into t_gen values (1, 2, 3)", tmysql.ErrBadGeneratedColumn)
+	s.testErrorCode(c, "insert into t_gen (a, c) values (1, 3)", tmysql.ErrBadGeneratedColumn)
+	s.testErrorCode(c, "update t_gen set b = 1", tmysql.ErrBadGeneratedColumn)
+	s.testErrorCode(c, "create table t_gen_err (a int as (b + 1), b int as (a + 1))", tmysql.ErrGeneratedColumnNonPrior)
+	s.testErrorCode(c, "create table t_gen_err (a int auto_increment primary key, b int as (a + 1))", tmysql.ErrGeneratedColumnRefAutoInc)
+	s.testErrorCode(c, "create table t_gen_err (a int, b int as (a + rand()))", tmysql.ErrGeneratedColumnFunctionIsNotAllowed)
+	s.testErrorCode(c, "create table t_gen_err (a int, b int as (a + 1) default 1)", tmysql.ErrUnsupportedOnGeneratedColumn)
+	s.testErrorCode(c, "create table t_gen_err (a int, b int as (a + 1), index idx_b (b))", tmysql.ErrUnsupportedOnGeneratedColumn)
+	s.testErrorCode(c, "alter table t_gen drop column a", tmysql.ErrDependentByGeneratedColumn)
+
+	// Adding a stored generated column fills the existing rows.
+	s.tk.MustExec("alter table t_gen add column d int as (a * 10) stored after a")
+	s.tk.MustExec("alter table t_gen add column e int as (d + c)")
+	s.tk.MustExec("insert into t_gen (a) values (5)")
+	s.tk.MustQuery("select * from t_gen order by a").Check(testkit.Rows(
+		"2 20 3 6 26", "3 30 4 8 38", "4 40 5 10 50", "5 50 6 12 62"))
+	s.tk.MustQuery("select column_name, extra, generation_expression from information_schema.columns where table_name = 't_gen' and column_name = 'e'").
+		Check(testkit.Rows("e VIRTUAL GENERATED d + c"))
+	s.tk.MustExec("alter table t_gen drop column e")
+	s.tk.MustExec("alter table t_gen drop column d")
+	s.tk.MustQuery("show create table t_gen").Check(testkit.Rows("t_gen CREATE TABLE `t_gen` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` int(11) GENERATED ALWAYS AS (a + 1) VIRTUAL,\n" +
+		"  `c` int(11) GENERATED ALWAYS AS (b * 2) STORED,\n" +
+		"  KEY `idx_c` (`c`)\n" +
+		") ENGINE=InnoDB"))
+	s.tk.MustExec("drop table t_gen")
+}
+
+func (s *testDBSuite) TestPrimaryKey(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_pk")
+	s.tk.MustExec("create table t_pk (a varchar(10) not null, b int not null, c int)")
+	s.tk.MustExec("insert into t_pk values ('a', 1, 1), ('b', 2, 2), ('b', 3, 3)")
+
+	s.testErrorCode(c, "alter table t_pk add primary key (d)", tmysql.ErrKeyColumnDoesNotExits)
+	s.testErrorCode(c, "alter table t_pk drop primary key", tmysql.ErrCantDropFieldOrKey)
+
+	// Duplicate rows roll the job back.
+	_, err := s.tk.Exec("alter table t_pk add primary key (a)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "[kv:1062]Duplicate for key PRIMARY")
+	t := s.testGetTable(c, "t_pk")
+	c.Assert(t.Indices(), HasLen, 0)
+
+	s.tk.MustExec("alter table t_pk add primary key (a, b)")
+	s.testErrorCode(c, "alter table t_pk add primary key (b)", tmysql.ErrMultiplePriKey)
+	s.testErrorCode(c, "insert into t_pk values ('a', 1, 4)", tmysql.ErrDupEntry)
+	s.tk.MustQuery("select column_name, column_key from information_schema.columns where table_name = 't_pk'").
+		Check(testkit.Rows("a PRI", "b PRI", "c "))
+	s.tk.MustQuery("show create table t_pk").Check(testkit.Rows("t_pk CREATE TABLE `t_pk` (\n" +
+		"  `a` varchar(10) NOT NULL,\n" +
+		"  `b` int(11) NOT NULL,\n" +
+		"  `c` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`a`,`b`)\n" +
+		") ENGINE=InnoDB"))
+
+	s.tk.MustExec("alter table t_pk drop primary key")
+	t = s.testGetTable(c, "t_pk")
+	c.Assert(t.Indices(), HasLen, 0)
+	s.tk.MustExec("insert into t_pk values ('a', 1, 4)")
+	s.tk.MustQuery("select column_name, column_key from information_schema.columns where table_name = 't_pk'").
+		Check(testkit.Rows("a ", "b ", "c "))
+
+	// The nullable columns are changed to NOT NULL if they have no NULL values.
+	s.tk.MustExec("insert into t_pk values ('c', 4, null)")
+	s.testErrorCode(c, "alter table t_pk add primary key (c)", tmysql.ErrInvalidUseOfNull)
+	t = s.testGetTable(c, "t_pk")
+	c.Assert(t.Indices(), HasLen, 0)
+	s.tk.MustQuery("select is_nullable from information_schema.columns where table_name = 't_pk' and column_name = 'c'").
+		Check(testkit.Rows("YES"))
+	s.tk.MustExec("delete from t_pk where c is null")
+	s.tk.MustExec("alter table t_pk add primary key (c)")
+	s.tk.MustQuery("select is_nullable, column_key from information_schema.columns where table_name = 't_pk' and column_name = 'c'").
+		Check(testkit.Rows("NO PRI"))
+	s.testErrorCode(c, "insert into t_pk values ('d', 5, null)", tmysql.ErrBadNull)
+
+	// The integer primary key used as the row handle can't be dropped.
+	s.tk.MustExec("create table t_pk_handle (a int primary key)")
+	s.testErrorCode(c, "alter table t_pk_handle drop primary key", int(tmysql.ErrUnknown))
+	s.tk.MustExec("drop table t_pk, t_pk_handle")
+}
+
+func (s *testDBSuite) TestAlterTableOptions(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_opt")
+	s.tk.MustExec("create table t_opt (a int auto_increment, b int, c int, primary key (a), index idx_b (b), index idx_c (c))")
+	s.tk.MustExec("insert into t_opt (b) values (1)")
+
+	// The first insert has allocated a batch of IDs, so the base must be greater than the batch.
+	base := autoid.GetStep() * 2
+	s.tk.MustExec(fmt.Sprintf("alter table t_opt auto_increment = %d", base))
+	s.tk.MustExec("insert into t_opt (b) values (2)")
+	s.tk.MustQuery("select a from t_opt order by a").Check(testkit.Rows("1", fmt.Sprint(base)))
+	// The auto_increment base never goes backwards, the rebased allocator starts after the allocated IDs.
+	s.tk.MustExec("alter table t_opt auto_increment = 10")
+	t := s.testGetTable(c, "t_opt")
+	c.Assert(t.Meta().AutoIncID, Equals, base+autoid.GetStep())
+	s.tk.MustExec("insert into t_opt (b) values (3)")
+	s.tk.MustQuery("select a from t_opt where b = 3").Check(testkit.Rows(fmt.Sprint(base + autoid.GetStep())))
+
+	s.tk.MustExec("alter table t_opt comment = 'table comment'")
+	s.tk.MustExec("alter table t_opt modify column b int comment 'column comment'")
+	s.tk.MustExec("alter table t_opt default charset = utf8mb4")
+	s.tk.MustExec("alter table t_opt rename index idx_b to idx_b2")
+	s.tk.MustQuery("show create table t_opt").Check(testkit.Rows("t_opt CREATE TABLE `t_opt` (\n" +
+		"  `a` int(11) NOT NULL AUTO_INCREMENT,\n" +
+		"  `b` int(11) DEFAULT NULL COMMENT 'column comment',\n" +
+		"  `c` int(11) DEFAULT NULL,\n" +
+		" PRIMARY KEY (`a`),\n" +
+		"  KEY `idx_b2` (`b`),\n" +
+		"  KEY `idx_c` (`c`)\n" +
+		fmt.Sprintf(") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci AUTO_INCREMENT=%d COMMENT='table comment'", base+autoid.GetStep())))
+	s.tk.MustQuery("select count(*) from t_opt use index (idx_b2) where b > 0").Check(testkit.Rows("3"))
+
+	s.tk.MustExec("alter table t_opt collate latin1_bin")
+	t = s.testGetTable(c, "t_opt")
+	c.Assert(t.Meta().Charset, Equals, "latin1")
+	c.Assert(t.Meta().Collate, Equals, "latin1_bin")
+	s.testErrorCode(c, "alter table t_opt charset utf8 collate latin1_bin", tmysql.ErrCollationCharsetMismatch)
+	s.testErrorCode(c, "alter table t_opt collate xxx_bin", tmysql.ErrUnknownCollation)
+
+	s.testErrorCode(c, "alter table t_opt rename index idx_x to idx_y", tmysql.ErrKeyDoesNotExits)
+	s.testErrorCode(c, "alter table t_opt rename index idx_b2 to idx_c", tmysql.ErrDupKeyName)
+	s.testErrorCode(c, "alter table t_opt rename index idx_b2 to `primary`", tmysql.ErrWrongNameForIndex)
+	s.testErrorCode(c, "alter table t_opt modify column c int not null comment 'x'", int(tmysql.ErrUnknown))
+	s.tk.MustExec("drop table t_opt")
+}
+
 func (s *testDBSuite) TestCreateIndexType(c *C) {
 	defer testleak.AfterTest(c)()
 	s.tk = testkit.NewTestKit(c, s.store)
@@ -249,6 +249,12 @@ func (d *ddl) handleDDLJobQueue() error {
 				return errors.Trace(err)
 			}
 
+			queueLen, err := t.DDLJobQueueLen()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			jobQueueGauge.WithLabelValues(JobType(ddlJobFlag).String()).Set(float64(queueLen))
+
 			// We become the owner. Get the first job and run it.
 			job, err = d.getFirstDDLJob(t)
 			if job == nil || err != nil {
@@ -343,6 +349,15 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) {
 	}
 
 	var err error
+	startTime := time.Now()
+	defer func() {
+		retLabel := handleJobSucc
+		if err != nil {
+			retLabel = handleJobFailed
+		}
+		runJobHistogram.WithLabelValues(JobType(ddlJobFlag).String(), job.Type.String(),
+			retLabel).Observe(time.Since(startTime).Seconds())
+	}()
 	switch job.Type {
 	case model.ActionCreateSchema:
 		err = d.onCreateSchema(t, job)
@@ -358,9 +373,9 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) {
 		err = d.onDropColumn(t, job)
 	case model.ActionModifyColumn:
 		err = d.onModifyColumn(t, job)
-	case model.ActionAddIndex:
+	case model.ActionAddIndex, model.ActionAddPrimaryKey:
 		err = d.onCreateIndex(t, job)
-	case model.ActionDropIndex:
+	case model.ActionDropIndex, model.ActionDropPrimaryKey:
 		err = d.onDropIndex(t, job)
 	case model.ActionAddForeignKey:
 		err = d.onCreateForeignKey(t, job)
@@ -370,6 +385,18 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) {
 		err = d.onTruncateTable(t, job)
 	case model.ActionRenameTable:
 		err = d.onRenameTable(t, job)
+	case model.ActionAddCheck:
+		err = d.onCreateCheck(t, job)
+	case model.ActionDropCheck:
+		err = d.onDropCheck(t, job)
+	case model.ActionRebaseAutoID:
+		err = d.onRebaseAutoID(t, job)
+	case model.ActionSetTableComment:
+		err = d.onSetTableComment(t, job)
+	case model.ActionModifyTableCharsetAndCollate:
+		err = d.onModifyTableCharsetAndCollate(t, job)
+	case model.ActionRenameIndex:
+		err = d.onRenameIndex(t, job)
 	default:
 		// Invalid job, cancel it.
 		job.State = model.JobCancelled
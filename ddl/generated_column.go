@@ -0,0 +1,212 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// checkGeneratedColumns checks the expressions of all the generated columns in tblInfo.
+// The columns of tblInfo must be ordered by their offsets.
+func checkGeneratedColumns(ctx context.Context, tblInfo *model.TableInfo) error {
+	for i, col := range tblInfo.Columns {
+		if !col.IsGenerated() {
+			continue
+		}
+		expr, err := plan.ParseTableExpr(col.GeneratedExprString)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, name := range plan.ExtractColumnNames(expr) {
+			refCol := findCol(tblInfo.Columns, name.L)
+			if refCol == nil {
+				// The unknown column is reported when rewriting the expression.
+				continue
+			}
+			if refCol.IsGenerated() && refCol.Offset >= i {
+				return errGeneratedColumnNonPrior
+			}
+			if mysql.HasAutoIncrementFlag(refCol.Flag) {
+				return errGeneratedColumnRefAutoInc.GenByArgs(col.Name.O)
+			}
+		}
+		if _, err = plan.RewriteTableExpr(ctx, col.GeneratedExprString, tblInfo); err != nil {
+			if terror.ErrorEqual(err, plan.ErrDisallowedTableExpr) {
+				return errGeneratedColumnFuncNotAllowed.GenByArgs(col.Name.O)
+			}
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// checkColumnWithGenerated returns an error if the column is used by a generated column of the table.
+func checkColumnWithGenerated(tblInfo *model.TableInfo, colName model.CIStr) error {
+	for _, col := range tblInfo.Columns {
+		if !col.IsGenerated() || col.Name.L == colName.L {
+			continue
+		}
+		expr, err := plan.ParseTableExpr(col.GeneratedExprString)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, name := range plan.ExtractColumnNames(expr) {
+			if name.L == colName.L {
+				return errDependentByGeneratedColumn.GenByArgs(colName.O)
+			}
+		}
+	}
+	return nil
+}
+
+// checkAddGeneratedColumn checks the generated column to be added to the table at the position.
+func checkAddGeneratedColumn(ctx context.Context, tblInfo *model.TableInfo, colInfo *model.ColumnInfo, pos *ast.ColumnPosition) error {
+	cols := make([]*model.ColumnInfo, 0, len(tblInfo.Columns)+1)
+	position := -1
+	for _, col := range tblInfo.Columns {
+		if col.State != model.StatePublic {
+			continue
+		}
+		cols = append(cols, col.Clone())
+		if pos != nil && pos.Tp == ast.ColumnPositionAfter && col.Name.L == pos.RelativeColumn.Name.L {
+			position = len(cols)
+		}
+	}
+	if pos != nil && pos.Tp == ast.ColumnPositionFirst {
+		position = 0
+	} else if pos != nil && pos.Tp == ast.ColumnPositionAfter {
+		if position == -1 {
+			return infoschema.ErrColumnNotExists.GenByArgs(pos.RelativeColumn, tblInfo.Name)
+		}
+	} else {
+		position = len(cols)
+	}
+	newCols := make([]*model.ColumnInfo, 0, len(cols)+1)
+	newCols = append(newCols, cols[:position]...)
+	newCols = append(newCols, colInfo.Clone())
+	newCols = append(newCols, cols[position:]...)
+	for i, col := range newCols {
+		col.Offset = i
+		col.State = model.StatePublic
+	}
+
+	newTblInfo := tblInfo.Clone()
+	newTblInfo.Columns = newCols
+	return errors.Trace(checkGeneratedColumns(ctx, newTblInfo))
+}
+
+// tableRowDecoder decodes the raw data of a row to the datums of the writable columns, ordered by their offsets.
+// The values of the generated columns chosen by the decoder are evaluated from the other columns.
+type tableRowDecoder struct {
+	tblInfo     *model.TableInfo
+	cols        []*model.ColumnInfo
+	colTps      map[int64]*types.FieldType
+	defaultVals []types.Datum
+	genCols     []*model.ColumnInfo
+	genExprs    []expression.Expression
+}
+
+// newTableRowDecoder creates a tableRowDecoder, the virtual generated columns and the generated columns
+// in evalCols are evaluated when decoding.
+func newTableRowDecoder(ctx context.Context, tblInfo *model.TableInfo, evalCols ...*model.ColumnInfo) (*tableRowDecoder, error) {
+	d := &tableRowDecoder{
+		tblInfo: tblInfo,
+		colTps:  make(map[int64]*types.FieldType, len(tblInfo.Columns)),
+	}
+	for _, col := range tblInfo.Columns {
+		if col.State == model.StateDeleteOnly || col.State == model.StateDeleteReorganization {
+			continue
+		}
+		d.cols = append(d.cols, col)
+		d.colTps[col.ID] = &col.FieldType
+	}
+	d.defaultVals = make([]types.Datum, len(d.cols))
+	for _, col := range d.cols {
+		if col.IsGenerated() || mysql.HasNoDefaultValueFlag(col.Flag) {
+			// The value of the column is always evaluated or stored in the row.
+			continue
+		}
+		val, _, err := table.GetColDefaultValue(ctx, col)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		d.defaultVals[col.Offset] = val
+	}
+	// Generated columns can only refer to the columns before them, so they are evaluated in the order of offsets.
+	for i := range d.cols {
+		col := d.colByOffset(i)
+		if !col.IsVirtualGenerated() && findCol(evalCols, col.Name.L) == nil {
+			continue
+		}
+		expr, err := plan.RewriteTableExpr(ctx, col.GeneratedExprString, tblInfo)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		d.genCols = append(d.genCols, col)
+		d.genExprs = append(d.genExprs, expr)
+	}
+	return d, nil
+}
+
+func (d *tableRowDecoder) colByOffset(offset int) *model.ColumnInfo {
+	for _, col := range d.cols {
+		if col.Offset == offset {
+			return col
+		}
+	}
+	return nil
+}
+
+func (d *tableRowDecoder) decode(ctx context.Context, h int64, rawRecord []byte) ([]types.Datum, error) {
+	rowMap, err := tablecodec.DecodeRow(rawRecord, d.colTps)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make([]types.Datum, len(d.cols))
+	for _, col := range d.cols {
+		if mysql.HasPriKeyFlag(col.Flag) && d.tblInfo.PKIsHandle {
+			if mysql.HasUnsignedFlag(col.Flag) {
+				row[col.Offset].SetUint64(uint64(h))
+			} else {
+				row[col.Offset].SetInt64(h)
+			}
+		} else if val, ok := rowMap[col.ID]; ok {
+			row[col.Offset] = val
+		} else {
+			row[col.Offset] = d.defaultVals[col.Offset]
+		}
+	}
+	for i, col := range d.genCols {
+		val, err := d.genExprs[i].Eval(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row[col.Offset], err = table.CastValue(ctx, val, col)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return row, nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
@@ -49,6 +50,13 @@ func buildIndexColumns(columns []*model.ColumnInfo, idxColNames []*ast.IndexColN
 			return nil, errKeyColumnDoesNotExits.Gen("column does not exist: %s", ic.Column.Name)
 		}
 
+		// The value of virtual generated column is not stored, so it can't be indexed.
+		// The indices on the virtual generated columns aren't supported yet, a stored generated
+		// column can be indexed instead, the predicates on its expression are matched to the index.
+		if col.IsVirtualGenerated() {
+			return nil, errUnsupportedOnGeneratedColumn.GenByArgs("Index on virtual generated column")
+		}
+
 		// Length must be specified for BLOB and TEXT column indexes.
 		if types.IsTypeBlob(col.FieldType.Tp) && ic.Length == types.UnspecifiedLength {
 			return nil, errors.Trace(errBlobKeyWithoutLength)
@@ -132,6 +140,13 @@ func buildIndexInfo(tblInfo *model.TableInfo, indexName model.CIStr, idxColNames
 }
 
 func addIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
+	if indexInfo.Primary {
+		for _, col := range indexInfo.Columns {
+			tblInfo.Columns[col.Offset].Flag |= mysql.PriKeyFlag
+		}
+		return
+	}
+
 	col := indexInfo.Columns[0]
 
 	if indexInfo.Unique && len(indexInfo.Columns) == 1 {
@@ -141,7 +156,27 @@ func addIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
 	}
 }
 
+// setIndexColumnsNotNull sets the NOT NULL flag of the index columns, it returns the names of the changed columns.
+func setIndexColumnsNotNull(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) []model.CIStr {
+	var nullCols []model.CIStr
+	for _, idxCol := range indexInfo.Columns {
+		col := tblInfo.Columns[idxCol.Offset]
+		if !mysql.HasNotNullFlag(col.Flag) {
+			col.Flag |= mysql.NotNullFlag
+			nullCols = append(nullCols, col.Name)
+		}
+	}
+	return nullCols
+}
+
 func dropIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
+	if indexInfo.Primary {
+		for _, col := range indexInfo.Columns {
+			tblInfo.Columns[col.Offset].Flag &= ^uint(mysql.PriKeyFlag)
+		}
+		return
+	}
+
 	col := indexInfo.Columns[0]
 
 	if indexInfo.Unique && len(indexInfo.Columns) == 1 {
@@ -185,8 +220,10 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) error {
 		unique      bool
 		indexName   model.CIStr
 		idxColNames []*ast.IndexColName
+		// nullCols are the primary key columns changed to NOT NULL by the job.
+		nullCols []model.CIStr
 	)
-	err = job.DecodeArgs(&unique, &indexName, &idxColNames)
+	err = job.DecodeArgs(&unique, &indexName, &idxColNames, &nullCols)
 	if err != nil {
 		job.State = model.JobCancelled
 		return errors.Trace(err)
@@ -198,16 +235,27 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) error {
 		return errDupKeyName.Gen("index already exist %s", indexName)
 	}
 
+	isPK := job.Type == model.ActionAddPrimaryKey
 	if indexInfo == nil {
+		if isPK && (tblInfo.PKIsHandle || findPrimaryIndex(tblInfo) != nil) {
+			job.State = model.JobCancelled
+			return infoschema.ErrMultiplePriKey
+		}
 		indexInfo, err = buildIndexInfo(tblInfo, indexName, idxColNames, model.StateNone)
 		if err != nil {
 			job.State = model.JobCancelled
 			return errors.Trace(err)
 		}
-		indexInfo.Primary = false
+		indexInfo.Primary = isPK
 		indexInfo.Unique = unique
 		indexInfo.ID = allocateIndexID(tblInfo)
 		tblInfo.Indices = append(tblInfo.Indices, indexInfo)
+		if isPK {
+			// The columns are NOT NULL before the index is filled, so no NULL values can be written
+			// after the existing rows are validated.
+			nullCols = setIndexColumnsNotNull(tblInfo, indexInfo)
+			job.Args = []interface{}{unique, indexName, idxColNames, nullCols}
+		}
 	}
 
 	ver, err := updateSchemaVersion(t, job)
@@ -259,9 +307,9 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) error {
 				// if timeout, we should return, check for the owner and re-wait job done.
 				return nil
 			}
-			if terror.ErrorEqual(err, kv.ErrKeyExists) {
+			if terror.ErrorEqual(err, kv.ErrKeyExists) || terror.ErrorEqual(err, errInvalidUseOfNull) {
 				log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
-				err = d.convert2RollbackJob(t, job, tblInfo, indexInfo)
+				err = d.convert2RollbackJob(t, job, tblInfo, indexInfo, nullCols, err)
 			}
 			return errors.Trace(err)
 		}
@@ -283,9 +331,11 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) error {
 	}
 }
 
-func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, indexInfo *model.IndexInfo) error {
+func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, indexInfo *model.IndexInfo,
+	nullCols []model.CIStr, reorgErr error) error {
 	job.State = model.JobRollback
-	job.Args = []interface{}{indexInfo.Name}
+	// The columns changed to NOT NULL are restored when the rollback is done.
+	job.Args = []interface{}{indexInfo.Name, nullCols}
 	// If add index job rollbacks in write reorganization state, its need to delete all keys which has been added.
 	// Its work is the same as drop index job do.
 	// The write reorganization state in add index job that likes write only state in drop index job.
@@ -296,6 +346,9 @@ func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.T
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if terror.ErrorEqual(reorgErr, errInvalidUseOfNull) {
+		return errors.Trace(reorgErr)
+	}
 	return kv.ErrKeyExists.Gen("Duplicate for key %s", indexInfo.Name.O)
 }
 
@@ -306,8 +359,11 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) error {
 		return errors.Trace(err)
 	}
 
-	var indexName model.CIStr
-	if err = job.DecodeArgs(&indexName); err != nil {
+	var (
+		indexName model.CIStr
+		nullCols  []model.CIStr
+	)
+	if err = job.DecodeArgs(&indexName, &nullCols); err != nil {
 		job.State = model.JobCancelled
 		return errors.Trace(err)
 	}
@@ -360,6 +416,11 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) error {
 		tblInfo.Indices = newIndices
 		// Set column index flag.
 		dropIndexColumnFlag(tblInfo, indexInfo)
+		for _, name := range nullCols {
+			if col := findCol(tblInfo.Columns, name.O); col != nil {
+				col.Flag &= ^uint(mysql.NotNullFlag)
+			}
+		}
 		if err = t.UpdateTable(schemaID, tblInfo); err != nil {
 			return errors.Trace(err)
 		}
@@ -378,6 +439,30 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) error {
 	return errors.Trace(err)
 }
 
+func (d *ddl) onRenameIndex(t *meta.Meta, job *model.Job) error {
+	var from, to model.CIStr
+	if err := job.DecodeArgs(&from, &to); err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	idx := findIndexByName(from.L, tblInfo.Indices)
+	if idx == nil {
+		job.State = model.JobCancelled
+		return errKeyDoesNotExist.GenByArgs(from.O, tblInfo.Name.O)
+	}
+	if from.L != to.L && findIndexByName(to.L, tblInfo.Indices) != nil {
+		job.State = model.JobCancelled
+		return errDupKeyName.Gen("index already exist %s", to)
+	}
+	idx.Name = to
+	return errors.Trace(updateTableInfoOnly(t, job, tblInfo))
+}
+
 func (d *ddl) fetchRowColVals(txn kv.Transaction, t table.Table, taskOpInfo *indexTaskOpInfo, handleInfo *handleInfo) (
 	[]*indexRecord, *taskResult) {
 	handleCnt := defaultTaskHandleCnt
@@ -428,7 +513,12 @@ func (d *ddl) fetchRowColVals(txn kv.Transaction, t table.Table, taskOpInfo *ind
 		idxVal := make([]types.Datum, 0, len(idxInfo.Columns))
 		for _, v := range idxInfo.Columns {
 			col := cols[v.Offset]
-			idxVal = append(idxVal, rowMap[col.ID])
+			val := rowMap[col.ID]
+			if idxInfo.Primary && val.IsNull() {
+				ret.err = errInvalidUseOfNull.GenByArgs()
+				return nil, ret
+			}
+			idxVal = append(idxVal, val)
 		}
 		idxRecord.vals = idxVal
 	}
@@ -666,6 +756,15 @@ func findIndexByName(idxName string, indices []*model.IndexInfo) *model.IndexInf
 	return nil
 }
 
+func findPrimaryIndex(tblInfo *model.TableInfo) *model.IndexInfo {
+	for _, idx := range tblInfo.Indices {
+		if idx.Primary {
+			return idx
+		}
+	}
+	return nil
+}
+
 func allocateIndexID(tblInfo *model.TableInfo) int64 {
 	tblInfo.MaxIndexID++
 	return tblInfo.MaxIndexID
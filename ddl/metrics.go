@@ -36,6 +36,24 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 20),
 		}, []string{"type", "action", "result_state"})
 
+	jobQueueGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "ddl",
+			Name:      "job_queue_length",
+			Help:      "Gauge of jobs in the queue seen by the owner.",
+		}, []string{"type"})
+
+	// runJobHistogram observes every step of a job run by the owner, a job runs a step for each schema state.
+	runJobHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "ddl",
+			Name:      "run_job_duration_seconds",
+			Help:      "Bucketed histogram of processing time (s) of running a job step by the owner",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 20),
+		}, []string{"type", "action", "result_state"})
+
 	// handle batch data type.
 	batchAddCol              = "batch_add_col"
 	batchAddIdx              = "batch_add_idx"
@@ -53,5 +71,7 @@ var (
 func init() {
 	prometheus.MustRegister(jobsGauge)
 	prometheus.MustRegister(handleJobHistogram)
+	prometheus.MustRegister(jobQueueGauge)
+	prometheus.MustRegister(runJobHistogram)
 	prometheus.MustRegister(batchHandleDataHistogram)
 }
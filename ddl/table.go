@@ -15,6 +15,7 @@ package ddl
 
 import (
 	"github.com/juju/errors"
+	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
@@ -169,6 +170,26 @@ func getTableInfo(t *meta.Meta, job *model.Job, schemaID int64) (*model.TableInf
 	return tblInfo, nil
 }
 
+// delTableDataInBackground adds a background job to delete the data of the table, which is written
+// before the job creating the table fails.
+func (d *ddl) delTableDataInBackground(job *model.Job) {
+	bgJob := &model.Job{
+		ID:       job.ID,
+		SchemaID: job.SchemaID,
+		TableID:  job.TableID,
+		Type:     model.ActionDropTable,
+		Args:     []interface{}{tablecodec.EncodeTablePrefix(job.TableID)},
+	}
+	err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+		return errors.Trace(d.prepareBgJob(meta.NewMeta(txn), bgJob))
+	})
+	if err != nil {
+		log.Errorf("[ddl] add background job to delete the data of table %d err %v", job.TableID, errors.ErrorStack(err))
+		return
+	}
+	d.startBgJob(bgJob.Type)
+}
+
 // dropTableData deletes data in a limited number. If limit < 0, deletes all data.
 func (d *ddl) dropTableData(startKey kv.Key, job *model.Job, limit int) (int, error) {
 	prefix := tablecodec.EncodeTablePrefix(job.TableID)
@@ -282,3 +303,84 @@ func checkTableNotExists(t *meta.Meta, job *model.Job, schemaID int64, tableName
 
 	return nil
 }
+
+func (d *ddl) onRebaseAutoID(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	var newBase int64
+	err := job.DecodeArgs(&newBase)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// The next allocated ID is newBase. The IDs which have been allocated are never reused,
+	// so if newBase isn't greater than them, the next ID follows the allocated ones.
+	end, err := t.GetAutoTableID(schemaID, tblInfo.ID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if newBase-1 > end {
+		if _, err = t.GenAutoTableID(schemaID, tblInfo.ID, newBase-1-end); err != nil {
+			return errors.Trace(err)
+		}
+	} else {
+		newBase = end + 1
+	}
+	tblInfo.AutoIncID = newBase
+	return errors.Trace(updateTableInfoOnly(t, job, tblInfo))
+}
+
+func (d *ddl) onSetTableComment(t *meta.Meta, job *model.Job) error {
+	var comment string
+	if err := job.DecodeArgs(&comment); err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	tblInfo.Comment = comment
+	return errors.Trace(updateTableInfoOnly(t, job, tblInfo))
+}
+
+func (d *ddl) onModifyTableCharsetAndCollate(t *meta.Meta, job *model.Job) error {
+	var toCharset, toCollate string
+	if err := job.DecodeArgs(&toCharset, &toCollate); err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// Only the default charset and collation of the table are changed,
+	// the existing columns keep their own ones.
+	tblInfo.Charset = toCharset
+	tblInfo.Collate = toCollate
+	return errors.Trace(updateTableInfoOnly(t, job, tblInfo))
+}
+
+// updateTableInfoOnly updates the table info of a metadata-only job and finishes the job.
+func updateTableInfoOnly(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo) error {
+	err := t.UpdateTable(job.SchemaID, tblInfo)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+	ver, err := updateSchemaVersion(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job.SchemaState = model.StatePublic
+	job.State = model.JobDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return nil
+}
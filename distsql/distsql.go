@@ -22,9 +22,11 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 )
@@ -71,6 +73,8 @@ type selectResult struct {
 	fields     []*types.FieldType
 	resp       kv.Response
 	ignoreData bool
+	sc         *variable.StatementContext
+	span       *tracing.Span
 
 	results chan resultWithErr
 	closed  chan struct{}
@@ -108,6 +112,7 @@ func (r *selectResult) fetch() {
 			return
 		}
 		pr := &partialResult{
+			sc:         r.sc,
 			index:      r.index,
 			fields:     r.fields,
 			reader:     reader,
@@ -128,7 +133,9 @@ func (r *selectResult) fetch() {
 
 // Next returns the next row.
 func (r *selectResult) Next() (PartialResult, error) {
+	startTime := time.Now()
 	re := <-r.results
+	r.sc.AddCopWaitTime(time.Since(startTime))
 	return re.result, errors.Trace(re.err)
 }
 
@@ -145,6 +152,7 @@ func (r *selectResult) IgnoreData() {
 func (r *selectResult) Close() error {
 	// close this channel tell fetch goroutine to exit
 	close(r.closed)
+	r.span.Finish()
 	return r.resp.Close()
 }
 
@@ -159,6 +167,7 @@ type partialResult struct {
 	cursor     int
 	dataOffset int64
 	ignoreData bool
+	sc         *variable.StatementContext
 
 	done    chan error
 	fetched bool
@@ -185,6 +194,11 @@ func (pr *partialResult) fetch() {
 		pr.done <- errInvalidResp.Gen("[%d %s]", pr.resp.Error.GetCode(), pr.resp.Error.GetMsg())
 		return
 	}
+	rowCount := len(pr.resp.Rows)
+	for _, chunk := range pr.resp.Chunks {
+		rowCount += len(chunk.RowsMeta)
+	}
+	pr.sc.AddProcessedKeys(int64(rowCount))
 
 	pr.done <- nil
 }
@@ -195,7 +209,9 @@ var dummyData = make([]types.Datum, 0)
 // If no more row to return, data would be nil.
 func (pr *partialResult) Next() (handle int64, data []types.Datum, err error) {
 	if !pr.fetched {
+		startTime := time.Now()
 		err = <-pr.done
+		pr.sc.AddCopWaitTime(time.Since(startTime))
 		pr.fetched = true
 		if err != nil {
 			return 0, nil, err
@@ -278,7 +294,9 @@ func (pr *partialResult) Close() error {
 // concurrency: The max concurrency for underlying coprocessor request.
 // keepOrder: If the result should returned in key order. For example if we need keep data in order by
 //            scan index, we should set keepOrder to true.
-func Select(client kv.Client, req *tipb.SelectRequest, keyRanges []kv.KeyRange, concurrency int, keepOrder bool) (SelectResult, error) {
+// sc: The statement context, the request is cancelled at its deadline, the time waiting for the responses and
+//     the number of the returned rows are recorded in it.
+func Select(client kv.Client, req *tipb.SelectRequest, keyRanges []kv.KeyRange, concurrency int, keepOrder bool, sc *variable.StatementContext) (SelectResult, error) {
 	var err error
 	defer func() {
 		// Add metrics
@@ -290,21 +308,27 @@ func Select(client kv.Client, req *tipb.SelectRequest, keyRanges []kv.KeyRange,
 	}()
 
 	// Convert tipb.*Request to kv.Request.
-	kvReq, err1 := composeRequest(req, keyRanges, concurrency, keepOrder)
+	kvReq, err1 := composeRequest(req, keyRanges, concurrency, keepOrder, sc.Deadline)
 	if err1 != nil {
 		err = errors.Trace(err1)
 		return nil, err
 	}
 
+	// The span is finished when the result is closed.
+	span := sc.Span.StartChild("distsql.Select")
+	kvReq.Span = span
 	resp := client.Send(kvReq)
 	if resp == nil {
+		span.Finish()
 		err = errors.New("client returns nil response")
 		return nil, err
 	}
 	result := &selectResult{
+		sc:      sc,
 		resp:    resp,
 		results: make(chan resultWithErr, 5),
 		closed:  make(chan struct{}),
+		span:    span,
 	}
 	// If Aggregates is not nil, we should set result fields latter.
 	if len(req.Aggregates) == 0 && len(req.GroupBy) == 0 {
@@ -326,11 +350,13 @@ func Select(client kv.Client, req *tipb.SelectRequest, keyRanges []kv.KeyRange,
 }
 
 // Convert tipb.Request to kv.Request.
-func composeRequest(req *tipb.SelectRequest, keyRanges []kv.KeyRange, concurrency int, keepOrder bool) (*kv.Request, error) {
+func composeRequest(req *tipb.SelectRequest, keyRanges []kv.KeyRange, concurrency int, keepOrder bool, deadline time.Time) (*kv.Request, error) {
 	kvReq := &kv.Request{
 		Concurrency: concurrency,
 		KeepOrder:   keepOrder,
 		KeyRanges:   keyRanges,
+		Deadline:    deadline,
+		StartTs:     req.StartTs,
 	}
 	if req.IndexInfo != nil {
 		kvReq.Tp = kv.ReqTypeIndex
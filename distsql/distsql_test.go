@@ -25,6 +25,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
@@ -58,6 +59,7 @@ func (s *testTableCodecSuite) TestGoroutineLeak(c *C) {
 	countBefore := runtime.NumGoroutine()
 
 	sr = &selectResult{
+		sc:      new(variable.StatementContext),
 		resp:    &mockResponse{},
 		results: make(chan resultWithErr, 5),
 		closed:  make(chan struct{}),
@@ -41,6 +41,11 @@ type Domain struct {
 	m               sync.Mutex
 	SchemaValidator SchemaValidator
 	exit            chan struct{}
+	// userResources caches the resource limits of the accounts in mysql.user by the accounts.
+	userResources struct {
+		sync.Mutex
+		m map[string]userResourcesEntry
+	}
 
 	MockReloadFailed MockFailure // It mocks reload failed.
 }
@@ -70,6 +75,7 @@ func (do *Domain) loadInfoSchema(handle *infoschema.Handle, usedSchemaVersion in
 	if ok {
 		log.Infof("[ddl] diff load InfoSchema from version %d to %d, in %v",
 			usedSchemaVersion, latestSchemaVersion, time.Since(startTime))
+		loadInfoSchemaDuration.WithLabelValues("diff").Observe(time.Since(startTime).Seconds())
 		return latestSchemaVersion, nil
 	}
 
@@ -85,6 +91,7 @@ func (do *Domain) loadInfoSchema(handle *infoschema.Handle, usedSchemaVersion in
 	log.Infof("[ddl] full load InfoSchema from version %d to %d, in %v",
 		usedSchemaVersion, latestSchemaVersion, time.Since(startTime))
 	newISBuilder.Build()
+	loadInfoSchemaDuration.WithLabelValues("full").Observe(time.Since(startTime).Seconds())
 	return latestSchemaVersion, nil
 }
 
@@ -351,6 +358,10 @@ func NewDomain(store kv.Storage, lease time.Duration) (d *Domain, err error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	// The lock waits of the transactions are recorded in the performance schema.
+	if o, ok := store.(kv.LockWaitObservable); ok {
+		o.SetLockWaitObserver(d.infoHandle.GetPerfHandle())
+	}
 	d.ddl = ddl.NewDDL(d.store, d.infoHandle, &ddlCallback{do: d}, lease)
 	if err = d.Reload(); err != nil {
 		return nil, errors.Trace(err)
@@ -398,6 +409,51 @@ func (do *Domain) Privilege() *privileges.MySQLPrivilege {
 	return do.privHandle.Get()
 }
 
+// userResourcesTTL is how long the resource limits of an account are cached. The cache is cleared when
+// the accounts are changed by this server, the changes made by the other servers take effect after the
+// cached limits expire.
+const userResourcesTTL = time.Minute
+
+type userResourcesEntry struct {
+	res    variable.UserResources
+	expire time.Time
+}
+
+// GetUserResources returns the cached resource limits of the account, it returns false if they aren't
+// cached or they're expired.
+func (do *Domain) GetUserResources(account string) (*variable.UserResources, bool) {
+	do.userResources.Lock()
+	defer do.userResources.Unlock()
+	entry, ok := do.userResources.m[account]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expire) {
+		delete(do.userResources.m, account)
+		return nil, false
+	}
+	res := entry.res
+	return &res, true
+}
+
+// SetUserResources caches the resource limits of an account.
+func (do *Domain) SetUserResources(res *variable.UserResources) {
+	do.userResources.Lock()
+	if do.userResources.m == nil {
+		do.userResources.m = make(map[string]userResourcesEntry)
+	}
+	do.userResources.m[res.Account] = userResourcesEntry{res: *res, expire: time.Now().Add(userResourcesTTL)}
+	do.userResources.Unlock()
+}
+
+// ClearUserResources clears the cached resource limits, it's called after the accounts are created,
+// altered or dropped.
+func (do *Domain) ClearUserResources() {
+	do.userResources.Lock()
+	do.userResources.m = nil
+	do.userResources.Unlock()
+}
+
 // Domain error codes.
 const (
 	codeInfoSchemaExpired terror.ErrCode = 1
@@ -20,6 +20,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
 	"github.com/pingcap/tidb/util/mock"
@@ -94,3 +95,25 @@ func (*testSuite) TestT(c *C) {
 	err = store.Close()
 	c.Assert(err, IsNil)
 }
+
+func (*testSuite) TestUserResources(c *C) {
+	defer testleak.AfterTest(c)()
+	do := &Domain{}
+	_, ok := do.GetUserResources("u@%")
+	c.Assert(ok, IsFalse)
+	do.SetUserResources(&variable.UserResources{Account: "u@%", MaxUserConnections: 1})
+	res, ok := do.GetUserResources("u@%")
+	c.Assert(ok, IsTrue)
+	c.Assert(*res, Equals, variable.UserResources{Account: "u@%", MaxUserConnections: 1})
+
+	// The expired limits are removed.
+	do.userResources.m["u@%"] = userResourcesEntry{res: *res, expire: time.Now().Add(-time.Second)}
+	_, ok = do.GetUserResources("u@%")
+	c.Assert(ok, IsFalse)
+	c.Assert(do.userResources.m, HasLen, 0)
+
+	do.SetUserResources(res)
+	do.ClearUserResources()
+	_, ok = do.GetUserResources("u@%")
+	c.Assert(ok, IsFalse)
+}
@@ -34,9 +34,20 @@ var (
 			Help:      "Bucketed histogram of processing time (s) in load schema.",
 			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
 		})
+
+	// loadInfoSchemaDuration observes the loads that change the schema version, by the diff or the full load.
+	loadInfoSchemaDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "domain",
+			Name:      "load_info_schema_duration_seconds",
+			Help:      "Bucketed histogram of processing time (s) in loading the info schema by its load type.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		}, []string{"type"})
 )
 
 func init() {
 	prometheus.MustRegister(loadSchemaDuration)
 	prometheus.MustRegister(loadSchemaCounter)
+	prometheus.MustRegister(loadInfoSchemaDuration)
 }
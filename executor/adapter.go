@@ -20,10 +20,16 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/audit"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/util/slowlog"
+	"github.com/pingcap/tidb/util/tracing"
 )
 
 // recordSet wraps an executor, implements ast.RecordSet interface
@@ -32,6 +38,7 @@ type recordSet struct {
 	executor Executor
 	stmt     *statement
 	err      error
+	rowsSent uint64
 }
 
 func (a *recordSet) Fields() ([]*ast.ResultField, error) {
@@ -55,14 +62,20 @@ func (a *recordSet) Fields() ([]*ast.ResultField, error) {
 func (a *recordSet) Next() (*ast.Row, error) {
 	row, err := a.executor.Next()
 	if err != nil || row == nil {
-		return nil, errors.Trace(err)
+		a.err = a.stmt.convertTimeoutErr(err)
+		return nil, errors.Trace(a.err)
 	}
+	a.rowsSent++
 	return &ast.Row{Data: row.Data}, nil
 }
 
 func (a *recordSet) Close() error {
 	err := a.executor.Close()
 	a.stmt.logSlowQuery()
+	a.stmt.recordStatement(a.rowsSent, a.err)
+	a.stmt.clearDeadline()
+	// The statement is finished when the result set is closed.
+	a.stmt.span.Finish()
 	return errors.Trace(err)
 }
 
@@ -72,8 +85,13 @@ type statement struct {
 	is        infoschema.InfoSchema
 	ctx       context.Context
 	text      string
+	stmtNode  ast.StmtNode
 	plan      plan.Plan
 	startTime time.Time
+	// span is the root span of the statement if it's traced.
+	span *tracing.Span
+	// restricted indicates the statement is an internal SQL executed by ExecRestrictedSQL.
+	restricted bool
 }
 
 func (a *statement) OriginText() string {
@@ -84,56 +102,79 @@ func (a *statement) OriginText() string {
 // This function builds an Executor from a plan. If the Executor doesn't return result,
 // like the INSERT, UPDATE statements, it executes in this function, if the Executor returns
 // result, execution is done after this function returns, in the returned ast.RecordSet Next method.
-func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
+func (a *statement) Exec(ctx context.Context) (_ ast.RecordSet, err error) {
 	a.startTime = time.Now()
 	a.ctx = ctx
+	a.restricted = ctx.GetSessionVars().InRestrictedSQL
 	if _, ok := a.plan.(*plan.Execute); !ok {
 		// Do not sync transaction for Execute statement, because the real optimization work is done in
 		// "ExecuteExec.Build".
-		err := ctx.ActivePendingTxn()
+		err = ctx.ActivePendingTxn()
 		if err != nil {
-			return nil, errors.Trace(err)
+			return nil, errors.Trace(a.audit(err))
 		}
 	}
 
 	b := newExecutorBuilder(ctx, a.is)
 	e := b.build(a.plan)
 	if b.err != nil {
-		return nil, errors.Trace(b.err)
+		return nil, errors.Trace(a.audit(b.err))
 	}
+	// The span may be created by the TRACE statement.
+	a.span = ctx.GetSessionVars().StmtCtx.Span
 
 	// ExecuteExec is not a real Executor, we only use it to build another Executor from a prepared statement.
 	if executorExec, ok := e.(*ExecuteExec); ok {
-		err := executorExec.Build()
+		err = executorExec.Build()
 		if err != nil {
-			return nil, errors.Trace(err)
+			return nil, errors.Trace(a.audit(err))
 		}
 		a.text = executorExec.Stmt.Text()
+		a.stmtNode = executorExec.Stmt
 		a.plan = executorExec.Plan
 		e = executorExec.StmtExec
 	}
 
+	// The traced statement is executed before the transaction is committed, its rows are the spans.
+	if traceExec, ok := e.(*TraceExec); ok {
+		err = traceExec.run()
+		if err != nil {
+			return nil, errors.Trace(a.audit(a.convertTimeoutErr(err)))
+		}
+	}
+
 	// Fields or Schema are only used for statements that return result set.
 	if e.Schema().Len() == 0 {
 		// Check if "tidb_snapshot" is set for the write executors.
 		// In history read mode, we can not do write operations.
-		switch e.(type) {
+		switch unwrapTracedExec(e).(type) {
 		case *DeleteExec, *InsertExec, *UpdateExec, *ReplaceExec, *LoadData, *DDLExec:
 			snapshotTS := ctx.GetSessionVars().SnapshotTS
 			if snapshotTS != 0 {
-				return nil, errors.New("can not execute write statement when 'tidb_snapshot' is set")
+				return nil, errors.Trace(a.audit(errors.New("can not execute write statement when 'tidb_snapshot' is set")))
 			}
 		}
 
 		defer func() {
 			e.Close()
+			// The statement fails if it can't be audited, and the session rolls back the transaction.
+			err = a.audit(err)
 			a.logSlowQuery()
+			a.recordStatement(0, err)
+			a.clearDeadline()
 		}()
-		for {
-			row, err := e.Next()
+		if _, ok := unwrapTracedExec(e).(*DDLExec); ok {
+			err = auditStmt(ctx, audit.EventQueryStart, a.stmtNode, a.text, nil)
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+		}
+		for {
+			var row *Row
+			row, err = e.Next()
+			if err != nil {
+				return nil, errors.Trace(a.convertTimeoutErr(err))
+			}
 			// Even though there isn't any result set, the row is still used to indicate if there is
 			// more work to do.
 			// For example, the UPDATE statement updates a single row on a Next call, we keep calling Next until
@@ -143,27 +184,140 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 			}
 		}
 	}
+	// The statement returning result set is audited before its rows are returned, so no row is returned
+	// if it can't be audited.
+	if err = a.audit(nil); err != nil {
+		e.Close()
+		a.recordStatement(0, err)
+		a.clearDeadline()
+		return nil, errors.Trace(err)
+	}
 	return &recordSet{
 		executor: e,
 		stmt:     a,
 	}, nil
 }
 
-const (
-	queryLogMaxLen = 2048
-	slowThreshold  = 300 * time.Millisecond
-)
+// audit audits the statement, err is the error of the statement. The error of the audit plugins is returned
+// if the statement succeeds, otherwise it's logged and err is returned.
+func (a *statement) audit(err error) error {
+	auditErr := auditStmt(a.ctx, audit.EventQuery, a.stmtNode, a.text, err)
+	if err == nil {
+		return errors.Trace(auditErr)
+	}
+	if auditErr != nil {
+		log.Errorf("[%d] audit statement error: %v", a.ctx.GetSessionVars().ConnectionID, auditErr)
+	}
+	return err
+}
 
+// convertTimeoutErr returns ErrQueryTimeout if err is caused by exceeding the statement deadline,
+// for example, the coprocessor requests are cancelled with context.DeadlineExceeded.
+func (a *statement) convertTimeoutErr(err error) error {
+	if err != nil && a.ctx.GetSessionVars().StmtCtx.DeadlineExceeded() {
+		return ErrQueryTimeout
+	}
+	return err
+}
+
+// clearDeadline clears the deadline when the statement finishes, the internal SQLs executed
+// between the user statements share the statement context and should not be aborted.
+func (a *statement) clearDeadline() {
+	if !a.restricted {
+		a.ctx.GetSessionVars().StmtCtx.Deadline = time.Time{}
+	}
+}
+
+const queryLogMaxLen = 2048
+
+// logSlowQuery logs the statement, it's logged as a warning and written to the slow query log
+// if its query time exceeds tidb_slow_log_threshold.
 func (a *statement) logSlowQuery() {
-	costTime := time.Since(a.startTime)
+	sessVars := a.ctx.GetSessionVars()
+	sc := sessVars.StmtCtx
+	execTime := time.Since(a.startTime)
+	queryTime := sc.ParseTime + sc.CompileTime + execTime
 	sql := a.text
 	if len(sql) > queryLogMaxLen {
 		sql = sql[:queryLogMaxLen] + fmt.Sprintf("(len:%d)", len(sql))
 	}
-	connID := a.ctx.GetSessionVars().ConnectionID
-	if costTime < slowThreshold {
-		log.Debugf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
-	} else {
-		log.Warnf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
+	connID := sessVars.ConnectionID
+	var memMax int64
+	if sc.MemTracker != nil {
+		memMax = sc.MemTracker.MaxConsumed()
+	}
+	if queryTime < time.Duration(sessVars.SlowLogThreshold)*time.Millisecond {
+		log.Debugf("[%d][TIME_QUERY] %v mem_max:%d %s", connID, queryTime, memMax, sql)
+		return
+	}
+	log.Warnf("[%d][TIME_QUERY] %v mem_max:%d %s", connID, queryTime, memMax, sql)
+	if a.restricted {
+		return
+	}
+	_, digest := parser.NormalizeDigest(a.text)
+	entry := &slowlog.Entry{
+		Time:          time.Now(),
+		ConnID:        connID,
+		User:          sessVars.User,
+		DB:            sessVars.CurrentDB,
+		Digest:        digest,
+		QueryTime:     queryTime,
+		ParseTime:     sc.ParseTime,
+		CompileTime:   sc.CompileTime,
+		ExecTime:      execTime,
+		CopWaitTime:   sc.CopWaitTime(),
+		ProcessedKeys: sc.ProcessedKeys(),
+		MemMax:        memMax,
+		Plan:          plan.ToString(a.plan),
+		Query:         a.text,
+	}
+	if err := slowlog.Write(entry); err != nil {
+		log.Errorf("[%d] write slow query log error: %v", connID, errors.ErrorStack(err))
+	}
+}
+
+// recordStatement records the finished statement to the performance schema, err is the error returned by the statement.
+func (a *statement) recordStatement(rowsSent uint64, err error) {
+	if a.restricted || !perfschema.Enabled() {
+		return
+	}
+	sessVars := a.ctx.GetSessionVars()
+	sc := sessVars.StmtCtx
+	stats := &perfschema.StatementStats{
+		ConnID:     sessVars.ConnectionID,
+		Stmt:       a.stmtNode,
+		SQLText:    a.text,
+		SchemaName: sessVars.CurrentDB,
+		// The parse time and the compile time are counted in the statement, same as the slow query log.
+		StartTime:    a.startTime.Add(-(sc.ParseTime + sc.CompileTime)),
+		EndTime:      time.Now(),
+		Err:          err,
+		Warnings:     uint64(len(sc.GetWarnings())),
+		RowsAffected: sc.AffectedRows(),
+		RowsSent:     rowsSent,
+		RowsExamined: uint64(sc.ProcessedKeys()),
+		Plan:         plan.ToString(a.plan),
+	}
+	sessionctx.GetDomain(a.ctx).PerfSchema().RecordStatement(stats)
+}
+
+// recordCompileErr records the statement that fails to compile to the performance schema, so its error
+// is counted in the statement summary.
+func recordCompileErr(ctx context.Context, node ast.StmtNode, startTime time.Time, err error) {
+	sessVars := ctx.GetSessionVars()
+	if sessVars.InRestrictedSQL || !perfschema.Enabled() {
+		return
+	}
+	sc := sessVars.StmtCtx
+	stats := &perfschema.StatementStats{
+		ConnID:     sessVars.ConnectionID,
+		Stmt:       node,
+		SQLText:    node.Text(),
+		SchemaName: sessVars.CurrentDB,
+		StartTime:  startTime.Add(-sc.ParseTime),
+		EndTime:    time.Now(),
+		Err:        err,
+		Warnings:   uint64(len(sc.GetWarnings())),
 	}
+	sessionctx.GetDomain(ctx).PerfSchema().RecordStatement(stats)
 }
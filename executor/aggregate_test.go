@@ -273,7 +273,7 @@ func (s *testSuite) TestAggregation(c *C) {
 	result.Check(testkit.Rows("<nil>", "<nil>"))
 
 	result = tk.MustQuery("select count(*) from information_schema.columns")
-	result.Check(testkit.Rows("529"))
+	result.Check(testkit.Rows("640"))
 }
 
 func (s *testSuite) TestStreamAgg(c *C) {
@@ -0,0 +1,96 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/audit"
+	"github.com/pingcap/tidb/context"
+)
+
+// auditStmt passes the event of the statement to the audit plugins, tp is audit.EventQuery or audit.EventQueryStart,
+// err is the error of the statement. It returns the error of the audit plugins, the statement should fail if it's not nil.
+func auditStmt(ctx context.Context, tp string, node ast.StmtNode, sql string, err error) error {
+	sessVars := ctx.GetSessionVars()
+	if sessVars.InRestrictedSQL || !audit.Enabled() {
+		return nil
+	}
+	class := StatementClass(node)
+	if class == IGNORE {
+		return nil
+	}
+	e := &audit.Event{
+		Type:      tp,
+		ConnID:    sessVars.ConnectionID,
+		DB:        sessVars.CurrentDB,
+		StmtClass: class,
+		Tables:    accessedTables(node, sessVars.CurrentDB),
+		SQL:       sql,
+	}
+	// The user of the session is in the form of "user@host".
+	if i := strings.LastIndex(sessVars.User, "@"); i >= 0 {
+		e.User, e.Host = sessVars.User[:i], sessVars.User[i+1:]
+	} else {
+		e.User = sessVars.User
+	}
+	e.SetError(err)
+	return errors.Trace(audit.Log(e))
+}
+
+// tableCollector collects the tables in a statement, the tables without the schema are in the current database.
+type tableCollector struct {
+	currentDB string
+	tables    []string
+	seen      map[string]struct{}
+}
+
+func (tc *tableCollector) add(db, table string) {
+	if db == "" {
+		db = tc.currentDB
+	}
+	name := strings.ToLower(db + "." + table)
+	if _, ok := tc.seen[name]; ok {
+		return
+	}
+	tc.seen[name] = struct{}{}
+	tc.tables = append(tc.tables, name)
+}
+
+// Enter implements the ast.Visitor Enter interface.
+func (tc *tableCollector) Enter(n ast.Node) (ast.Node, bool) {
+	switch x := n.(type) {
+	case *ast.TableName:
+		tc.add(x.Schema.O, x.Name.O)
+	case *ast.GrantStmt:
+		if x.Level != nil && x.Level.Level == ast.GrantLevelTable {
+			tc.add(x.Level.DBName, x.Level.TableName)
+		}
+	}
+	return n, false
+}
+
+// Leave implements the ast.Visitor Leave interface.
+func (tc *tableCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// accessedTables returns the tables accessed by the statement in the form of "db.table".
+func accessedTables(node ast.StmtNode, currentDB string) []string {
+	tc := &tableCollector{currentDB: currentDB, seen: make(map[string]struct{})}
+	node.Accept(tc)
+	return tc.tables
+}
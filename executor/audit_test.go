@@ -0,0 +1,142 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/audit"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+)
+
+type mockAuditPlugin struct {
+	sync.Mutex
+	err    error
+	events []*audit.Event
+}
+
+func (p *mockAuditPlugin) Name() string {
+	return "mock"
+}
+
+func (p *mockAuditPlugin) Audit(e *audit.Event) error {
+	p.Lock()
+	defer p.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *mockAuditPlugin) Close() error {
+	return nil
+}
+
+func (p *mockAuditPlugin) popEvents() []*audit.Event {
+	p.Lock()
+	defer p.Unlock()
+	events := p.events
+	p.events = nil
+	return events
+}
+
+func (s *testSuite) TestAudit(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table audit_t (a int primary key)")
+	tk.MustExec("create table audit_t2 (a int)")
+
+	p := &mockAuditPlugin{}
+	c.Assert(audit.Register(p, &audit.Filter{Classes: []string{"Insert", "Select", "Grant", "CreateTable"}}), IsNil)
+	defer audit.Unregister(p.Name())
+
+	tk.MustExec("insert into audit_t values (1)")
+	tk.MustQuery("select * from audit_t, mysql.user where audit_t.a = 2").Check(testkit.Rows())
+	tk.MustExec("create table audit_t3 (a int)")
+	tk.MustExec("grant select on test.audit_t2 to 'root'@'%'")
+	// The statements are not audited if their classes are not selected.
+	tk.MustExec("update audit_t set a = 3")
+	_, err := tk.Exec("insert into audit_t values (4), (4)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select * from audit_not_exists")
+	c.Assert(err, NotNil)
+
+	events := p.popEvents()
+	c.Assert(events, HasLen, 7)
+	cases := []struct {
+		tp        string
+		class     string
+		tables    []string
+		succeeded bool
+	}{
+		{audit.EventQuery, "Insert", []string{"test.audit_t"}, true},
+		{audit.EventQuery, "Select", []string{"test.audit_t", "mysql.user"}, true},
+		// The DDL statements are audited before they're executed as well.
+		{audit.EventQueryStart, "CreateTable", []string{"test.audit_t3"}, true},
+		{audit.EventQuery, "CreateTable", []string{"test.audit_t3"}, true},
+		{audit.EventQuery, "Grant", []string{"test.audit_t2"}, true},
+		{audit.EventQuery, "Insert", []string{"test.audit_t"}, false},
+		{audit.EventQuery, "Select", []string{"test.audit_not_exists"}, false},
+	}
+	for i, ca := range cases {
+		e := events[i]
+		c.Assert(e.Type, Equals, ca.tp)
+		c.Assert(e.StmtClass, Equals, ca.class)
+		c.Assert(e.Tables, DeepEquals, ca.tables)
+		c.Assert(e.Succeeded, Equals, ca.succeeded)
+		c.Assert(e.DB, Equals, "test")
+		if !ca.succeeded {
+			c.Assert(e.ErrCode, Not(Equals), uint16(0))
+			c.Assert(e.ErrMsg, Not(Equals), "")
+		}
+	}
+
+	// The statements fail without their results if they can't be audited.
+	p.err = errors.New("disk full")
+	_, err = tk.Exec("insert into audit_t values (5)")
+	c.Assert(terror.ErrorEqual(err, audit.ErrAuditFailed), IsTrue)
+	_, err = tk.Exec("select * from audit_t")
+	c.Assert(terror.ErrorEqual(err, audit.ErrAuditFailed), IsTrue)
+	p.err = nil
+	tk.MustQuery("select * from audit_t").Check(testkit.Rows("3"))
+
+	// The transaction is rolled back if a statement in it can't be audited.
+	tk.MustExec("begin")
+	tk.MustExec("insert into audit_t values (6)")
+	p.err = errors.New("disk full")
+	_, err = tk.Exec("insert into audit_t values (7)")
+	c.Assert(terror.ErrorEqual(err, audit.ErrAuditFailed), IsTrue)
+	p.err = nil
+	tk.MustExec("commit")
+	tk.MustQuery("select * from audit_t").Check(testkit.Rows("3"))
+
+	// The DDL statements are refused before they're executed if they can't be audited.
+	p.err = errors.New("disk full")
+	_, err = tk.Exec("create table audit_t4 (a int)")
+	c.Assert(terror.ErrorEqual(err, audit.ErrAuditFailed), IsTrue)
+	p.err = nil
+	_, err = tk.Exec("select * from audit_t4")
+	c.Assert(err, NotNil)
+	p.popEvents()
+	tk.MustExec("create table audit_t4 (a int)")
+	events = p.popEvents()
+	c.Assert(events, HasLen, 2)
+	c.Assert(events[0].Type, Equals, audit.EventQueryStart)
+	c.Assert(events[1].Type, Equals, audit.EventQuery)
+	c.Assert(events[1].Succeeded, IsTrue)
+}
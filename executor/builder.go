@@ -16,6 +16,7 @@ package executor
 import (
 	"math"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -26,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -36,6 +38,8 @@ type executorBuilder struct {
 	is  infoschema.InfoSchema
 	// If there is any error during Executor building process, err is set.
 	err error
+	// tracedExecs are the traced executors that are not adopted by their parents yet.
+	tracedExecs []*tracedExec
 }
 
 func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema) *executorBuilder {
@@ -46,6 +50,24 @@ func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema) *executor
 }
 
 func (b *executorBuilder) build(p plan.Plan) Executor {
+	// The executor is wrapped to trace its Next calls and to collect its metrics, the traced executors
+	// built in buildExec are its children. The span of the statement is nil if it isn't traced.
+	stmtSpan := b.ctx.GetSessionVars().StmtCtx.Span
+	mark := len(b.tracedExecs)
+	e := b.buildExec(p)
+	children := b.tracedExecs[mark:]
+	if e == nil || !isTraceable(e) {
+		return e
+	}
+	te := newTracedExec(e, stmtSpan)
+	for _, child := range children {
+		child.parent = te
+	}
+	b.tracedExecs = append(b.tracedExecs[:mark], te)
+	return te
+}
+
+func (b *executorBuilder) buildExec(p plan.Plan) Executor {
 	switch v := p.(type) {
 	case nil:
 		return nil
@@ -61,6 +83,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildExecute(v)
 	case *plan.Explain:
 		return b.buildExplain(v)
+	case *plan.Trace:
+		return b.buildTrace(v)
 	case *plan.Insert:
 		return b.buildInsert(v)
 	case *plan.LoadData:
@@ -308,7 +332,11 @@ func (b *executorBuilder) buildGrant(grant *ast.GrantStmt) Executor {
 }
 
 func (b *executorBuilder) buildDDL(v *plan.DDL) Executor {
-	return &DDLExec{Statement: v.Statement, ctx: b.ctx, is: b.is}
+	e := &DDLExec{Statement: v.Statement, ctx: b.ctx, is: b.is}
+	if v.SelectPlan != nil {
+		e.SelectExec = b.build(v.SelectPlan)
+	}
+	return e
 }
 
 func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
@@ -318,13 +346,31 @@ func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
 	}
 }
 
+func (b *executorBuilder) buildTrace(v *plan.Trace) Executor {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	if sc.Span == nil {
+		// The statement isn't traced by the session, for example, it's a prepared statement.
+		sc.Span = tracing.NewRootSpan("trace", time.Now())
+	}
+	e := &TraceExec{
+		ctx:    b.ctx,
+		schema: v.Schema(),
+		span:   sc.Span,
+	}
+	e.stmtExec = b.build(v.StmtPlan)
+	if b.err != nil {
+		return nil
+	}
+	return e
+}
+
 func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor {
 	src := b.build(v.Children()[0])
 	if b.err != nil {
 		return nil
 	}
 	us := &UnionScanExec{ctx: b.ctx, Src: src, schema: v.Schema()}
-	switch x := src.(type) {
+	switch x := unwrapTracedExec(src).(type) {
 	case *XSelectTableExec:
 		us.desc = x.desc
 		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
@@ -679,6 +725,7 @@ func (b *executorBuilder) buildCache(v *plan.Cache) Executor {
 	return &CacheExec{
 		schema: v.Schema(),
 		Src:    src,
+		ctx:    b.ctx,
 	}
 }
 
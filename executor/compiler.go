@@ -14,9 +14,12 @@
 package executor
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/audit"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/plan"
@@ -30,23 +33,25 @@ type Compiler struct {
 // After preprocessed and validated, it will be optimized to a plan,
 // then wrappped to an adapter *statement as stmt.Statement.
 func (c *Compiler) Compile(ctx context.Context, node ast.StmtNode) (ast.Statement, error) {
+	startTime := time.Now()
 	is := GetInfoSchema(ctx)
 	if err := plan.Preprocess(node, is, ctx); err != nil {
-		return nil, errors.Trace(err)
+		return nil, errors.Trace(compileErr(ctx, node, startTime, err))
 	}
 	// Validate should be after NameResolve.
 	if err := plan.Validate(node, false); err != nil {
-		return nil, errors.Trace(err)
+		return nil, errors.Trace(compileErr(ctx, node, startTime, err))
 	}
 	p, err := plan.Optimize(ctx, node, is)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return nil, errors.Trace(compileErr(ctx, node, startTime, err))
 	}
 	stmtCount(node, p)
 	sa := &statement{
-		is:   is,
-		plan: p,
-		text: node.Text(),
+		is:       is,
+		plan:     p,
+		text:     node.Text(),
+		stmtNode: node,
 	}
 	return sa, nil
 }
@@ -64,3 +69,13 @@ func GetInfoSchema(ctx context.Context) infoschema.InfoSchema {
 	}
 	return is
 }
+
+// compileErr audits the statement that fails to compile, for example, the user doesn't have the privileges,
+// and records it to the performance schema. startTime is the time when the compilation starts.
+func compileErr(ctx context.Context, node ast.StmtNode, startTime time.Time, err error) error {
+	recordCompileErr(ctx, node, startTime, err)
+	if auditErr := auditStmt(ctx, audit.EventQuery, node, node.Text(), err); auditErr != nil {
+		log.Errorf("[%d] audit statement error: %v", ctx.GetSessionVars().ConnectionID, auditErr)
+	}
+	return err
+}
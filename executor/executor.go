@@ -15,9 +15,12 @@ package executor
 
 import (
 	"container/heap"
+	"io/ioutil"
+	"os"
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -29,9 +32,13 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/filesort"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -58,14 +65,18 @@ var (
 
 // Error instances.
 var (
-	ErrUnknownPlan     = terror.ClassExecutor.New(codeUnknownPlan, "Unknown plan")
-	ErrPrepareMulti    = terror.ClassExecutor.New(codePrepareMulti, "Can not prepare multiple statements")
-	ErrStmtNotFound    = terror.ClassExecutor.New(codeStmtNotFound, "Prepared statement not found")
-	ErrSchemaChanged   = terror.ClassExecutor.New(codeSchemaChanged, "Schema has changed")
-	ErrWrongParamCount = terror.ClassExecutor.New(codeWrongParamCount, "Wrong parameter count")
-	ErrRowKeyCount     = terror.ClassExecutor.New(codeRowKeyCount, "Wrong row key entry count")
-	ErrPrepareDDL      = terror.ClassExecutor.New(codePrepareDDL, "Can not prepare DDL statements")
-	ErrPasswordNoMatch = terror.ClassExecutor.New(CodePasswordNoMatch, "Can't find any matching row in the user table")
+	ErrUnknownPlan        = terror.ClassExecutor.New(codeUnknownPlan, "Unknown plan")
+	ErrPrepareMulti       = terror.ClassExecutor.New(codePrepareMulti, "Can not prepare multiple statements")
+	ErrStmtNotFound       = terror.ClassExecutor.New(codeStmtNotFound, "Prepared statement not found")
+	ErrSchemaChanged      = terror.ClassExecutor.New(codeSchemaChanged, "Schema has changed")
+	ErrWrongParamCount    = terror.ClassExecutor.New(codeWrongParamCount, "Wrong parameter count")
+	ErrRowKeyCount        = terror.ClassExecutor.New(codeRowKeyCount, "Wrong row key entry count")
+	ErrPrepareDDL         = terror.ClassExecutor.New(codePrepareDDL, "Can not prepare DDL statements")
+	ErrPasswordNoMatch    = terror.ClassExecutor.New(CodePasswordNoMatch, "Can't find any matching row in the user table")
+	ErrPluginNotLoaded    = terror.ClassExecutor.New(CodePluginNotLoaded, mysql.MySQLErrName[mysql.ErrPluginIsNotLoaded])
+	ErrQueryTimeout       = terror.ClassExecutor.New(CodeQueryTimeout, mysql.MySQLErrName[mysql.ErrQueryTimeout])
+	ErrQueryInterrupted   = terror.ClassExecutor.New(CodeQueryInterrupted, mysql.MySQLErrName[mysql.ErrQueryInterrupted])
+	ErrMemExceedThreshold = terror.ClassExecutor.New(CodeMemExceedThreshold, mysql.MySQLErrName[mysql.ErrCapacityExceeded])
 )
 
 // Error codes.
@@ -78,8 +89,12 @@ const (
 	codeRowKeyCount     terror.ErrCode = 6
 	codePrepareDDL      terror.ErrCode = 7
 	// MySQL error code
-	CodePasswordNoMatch terror.ErrCode = 1133
-	CodeCannotUser      terror.ErrCode = 1396
+	CodePasswordNoMatch    terror.ErrCode = 1133
+	CodeQueryInterrupted   terror.ErrCode = 1317
+	CodeCannotUser         terror.ErrCode = 1396
+	CodePluginNotLoaded    terror.ErrCode = 1524
+	CodeQueryTimeout       terror.ErrCode = 3024
+	CodeMemExceedThreshold terror.ErrCode = 3170
 )
 
 // Row represents a result set row, it may be returned from a table, a join, or a projection.
@@ -90,6 +105,19 @@ type Row struct {
 	RowKeys []*RowKeyEntry
 }
 
+// checkDeadline returns ErrQueryTimeout if the statement runs out of its execution time and ErrQueryInterrupted
+// if the session is killed, the executors which read data or loop over rows check it to stop the statement in time.
+func checkDeadline(ctx context.Context) error {
+	sessVars := ctx.GetSessionVars()
+	if sessVars.Killed() {
+		return ErrQueryInterrupted
+	}
+	if sessVars.StmtCtx.DeadlineExceeded() {
+		return ErrQueryTimeout
+	}
+	return nil
+}
+
 // RowKeyEntry represents a row key read from a table.
 type RowKeyEntry struct {
 	// The table which this row come from.
@@ -372,8 +400,12 @@ func init() {
 		return row.Data, nil
 	}
 	tableMySQLErrCodes := map[terror.ErrCode]uint16{
-		CodeCannotUser:      mysql.ErrCannotUser,
-		CodePasswordNoMatch: mysql.ErrPasswordNoMatch,
+		CodeCannotUser:         mysql.ErrCannotUser,
+		CodePasswordNoMatch:    mysql.ErrPasswordNoMatch,
+		CodePluginNotLoaded:    mysql.ErrPluginIsNotLoaded,
+		CodeQueryTimeout:       mysql.ErrQueryTimeout,
+		CodeQueryInterrupted:   mysql.ErrQueryInterrupted,
+		CodeMemExceedThreshold: mysql.ErrCapacityExceeded,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = tableMySQLErrCodes
 }
@@ -513,6 +545,8 @@ type TableScanExec struct {
 	cursor     int
 	schema     *expression.Schema
 	columns    []*model.ColumnInfo
+	// rowsRead is the number of the rows read since the last Close, they are counted by the table in Close.
+	rowsRead uint64
 
 	isInfoSchema     bool
 	infoSchemaRows   [][]types.Datum
@@ -526,6 +560,9 @@ func (e *TableScanExec) Schema() *expression.Schema {
 
 // Next implements the Executor interface.
 func (e *TableScanExec) Next() (*Row, error) {
+	if err := checkDeadline(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if e.isInfoSchema {
 		return e.nextForInfoSchema()
 	}
@@ -563,6 +600,8 @@ func (e *TableScanExec) Next() (*Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		e.ctx.GetSessionVars().StmtCtx.AddProcessedKeys(1)
+		e.rowsRead++
 		e.seekHandle = handle + 1
 		return row, nil
 	}
@@ -634,6 +673,10 @@ func (e *TableScanExec) getRow(handle int64) (*Row, error) {
 
 // Close implements the Executor Close interface.
 func (e *TableScanExec) Close() error {
+	if e.rowsRead > 0 {
+		tables.CountRowsReadFullScan(e.t.Meta().ID, e.rowsRead)
+		e.rowsRead = 0
+	}
 	e.iter = nil
 	e.cursor = 0
 	return nil
@@ -649,12 +692,28 @@ type SortExec struct {
 	fetched bool
 	err     error
 	schema  *expression.Schema
+
+	memTracker *memory.Tracker
+	// fileSorter sorts the rows on disk after the rows are spilled for exceeding tidb_mem_quota_query.
+	fileSorter *filesort.FileSorter
 }
 
 // Close implements the Executor Close interface.
 func (e *SortExec) Close() error {
 	e.fetched = false
 	e.Rows = nil
+	if e.memTracker != nil {
+		e.memTracker.Consume(-e.memTracker.BytesConsumed())
+		e.memTracker = nil
+	}
+	if e.fileSorter != nil {
+		err := e.fileSorter.Close()
+		e.fileSorter = nil
+		if err != nil {
+			e.Src.Close()
+			return errors.Trace(err)
+		}
+	}
 	return e.Src.Close()
 }
 
@@ -703,6 +762,7 @@ func (e *SortExec) Less(i, j int) bool {
 // Next implements the Executor Next interface.
 func (e *SortExec) Next() (*Row, error) {
 	if !e.fetched {
+		e.memTracker = newMemTracker(e.ctx, "SortExec")
 		for {
 			srcRow, err := e.Src.Next()
 			if err != nil {
@@ -721,14 +781,35 @@ func (e *SortExec) Next() (*Row, error) {
 					return nil, errors.Trace(err)
 				}
 			}
+			if e.fileSorter != nil {
+				if err = e.inputFileSorter(orderRow); err != nil {
+					return nil, errors.Trace(err)
+				}
+				continue
+			}
 			e.Rows = append(e.Rows, orderRow)
+			e.memTracker.Consume(datumsMemUsage(srcRow.Data) + datumsMemUsage(orderRow.key))
+			spill, err := checkMemQuota(e.ctx, e.memTracker, e.canSpillRow(srcRow))
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if spill {
+				if err = e.spill(); err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+		}
+		if e.fileSorter == nil {
+			sort.Sort(e)
 		}
-		sort.Sort(e)
 		e.fetched = true
 	}
 	if e.err != nil {
 		return nil, errors.Trace(e.err)
 	}
+	if e.fileSorter != nil {
+		return e.nextSpilledRow()
+	}
 	if e.Idx >= len(e.Rows) {
 		return nil, nil
 	}
@@ -737,6 +818,113 @@ func (e *SortExec) Next() (*Row, error) {
 	return row, nil
 }
 
+// sortSpillBufSize is the number of rows the FileSorter of a spilled SortExec holds in memory.
+const sortSpillBufSize = 4096
+
+// canSpillRow checks whether the row can be spilled to disk and restored. The kinds of the datums are
+// restored by restoreSpilledDatum, the row keys are dropped, they are only used above the sort by
+// UPDATE and DELETE statements.
+func (e *SortExec) canSpillRow(row *Row) bool {
+	if len(row.Data) == 0 || (len(row.RowKeys) > 0 && e.ctx.GetSessionVars().StmtCtx.InUpdateOrDeleteStmt) {
+		return false
+	}
+	for _, d := range row.Data {
+		switch d.Kind() {
+		case types.KindMysqlHex, types.KindMysqlBit, types.KindMysqlEnum, types.KindMysqlSet:
+			return false
+		}
+	}
+	return true
+}
+
+// spill moves the buffered rows to a FileSorter, the rest of the rows are sorted on disk too.
+func (e *SortExec) spill() error {
+	dir, err := ioutil.TempDir("", "tidb-sort")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	byDesc := make([]bool, len(e.ByItems))
+	for i, by := range e.ByItems {
+		byDesc[i] = by.Desc
+	}
+	// The meta of the datums is saved in an extra value.
+	e.fileSorter, err = new(filesort.Builder).SetSC(e.ctx.GetSessionVars().StmtCtx).
+		SetSchema(len(e.ByItems), e.schema.Len()+1).SetBuf(sortSpillBufSize).
+		SetDesc(byDesc).SetDir(dir).Build()
+	if err != nil {
+		os.RemoveAll(dir)
+		return errors.Trace(err)
+	}
+	for _, row := range e.Rows {
+		if err = e.inputFileSorter(row); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	e.Rows = nil
+	e.memTracker.Consume(-e.memTracker.BytesConsumed())
+	return nil
+}
+
+// spilledDatumMetaLen is the length of the meta of a spilled datum, it's the kind of the datum,
+// the type and the fsp of the time and duration values, which are lost in the encoded rows.
+const spilledDatumMetaLen = 3
+
+func (e *SortExec) inputFileSorter(row *orderByRow) error {
+	if !e.canSpillRow(row.row) {
+		return ErrMemExceedThreshold.GenByArgs(e.ctx.GetSessionVars().MemQuotaQuery, variable.TiDBMemQuotaQuery,
+			"The rows can't be spilled to disk.")
+	}
+	meta := make([]byte, 0, len(row.row.Data)*spilledDatumMetaLen)
+	for _, d := range row.row.Data {
+		var tp, fsp byte
+		switch d.Kind() {
+		case types.KindMysqlTime:
+			t := d.GetMysqlTime()
+			tp, fsp = t.Type, byte(int8(t.Fsp))
+		case types.KindMysqlDuration:
+			fsp = byte(int8(d.GetMysqlDuration().Fsp))
+		}
+		meta = append(meta, d.Kind(), tp, fsp)
+	}
+	val := append(row.row.Data[:len(row.row.Data):len(row.row.Data)], types.NewBytesDatum(meta))
+	return errors.Trace(e.fileSorter.Input(row.key, val, 0))
+}
+
+func (e *SortExec) nextSpilledRow() (*Row, error) {
+	_, val, _, err := e.fileSorter.Output()
+	if err != nil || val == nil {
+		return nil, errors.Trace(err)
+	}
+	meta := val[len(val)-1].GetBytes()
+	data := val[:len(val)-1]
+	for i := range data {
+		data[i], err = restoreSpilledDatum(data[i], meta[i*spilledDatumMetaLen:(i+1)*spilledDatumMetaLen])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return &Row{Data: data}, nil
+}
+
+// restoreSpilledDatum restores a datum decoded from the spilled rows with its meta.
+func restoreSpilledDatum(d types.Datum, meta []byte) (types.Datum, error) {
+	switch meta[0] {
+	case types.KindString:
+		d.SetString(string(d.GetBytes()))
+	case types.KindFloat32:
+		d.SetFloat32(float32(d.GetFloat64()))
+	case types.KindMysqlTime:
+		t := types.Time{Type: meta[1], Fsp: int(int8(meta[2]))}
+		if err := t.FromPackedUint(d.GetUint64()); err != nil {
+			return d, errors.Trace(err)
+		}
+		d.SetMysqlTime(t)
+	case types.KindMysqlDuration:
+		d.SetMysqlDuration(types.Duration{Duration: time.Duration(d.GetInt64()), Fsp: int(int8(meta[2]))})
+	}
+	return d, nil
+}
+
 // TopnExec implements a Top-N algorithm and it is built from a SELECT statement with ORDER BY and LIMIT.
 // Instead of sorting all the rows fetched from the table, it keeps the Top-N elements only in a heap to reduce memory usage.
 type TopnExec struct {
@@ -1100,6 +1288,8 @@ type CacheExec struct {
 	storedRows  []*Row
 	cursor      int
 	srcFinished bool
+	ctx         context.Context
+	memTracker  *memory.Tracker
 }
 
 // Schema implements the Executor Schema interface.
@@ -1129,6 +1319,14 @@ func (e *CacheExec) Next() (*Row, error) {
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+		} else {
+			if e.memTracker == nil {
+				e.memTracker = newMemTracker(e.ctx, "CacheExec")
+			}
+			e.memTracker.Consume(datumsMemUsage(row.Data))
+			if _, err = checkMemQuota(e.ctx, e.memTracker, false); err != nil {
+				return nil, errors.Trace(err)
+			}
 		}
 		e.storedRows = append(e.storedRows, row)
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -37,6 +38,7 @@ type HashAggExec struct {
 	groups            [][]byte
 	currentGroupIndex int
 	GroupByItems      []expression.Expression
+	memTracker        *memory.Tracker
 }
 
 // Close implements the Executor Close interface.
@@ -47,6 +49,10 @@ func (e *HashAggExec) Close() error {
 	for _, agg := range e.AggFuncs {
 		agg.Clear()
 	}
+	if e.memTracker != nil {
+		e.memTracker.Consume(-e.memTracker.BytesConsumed())
+		e.memTracker = nil
+	}
 	return e.Src.Close()
 }
 
@@ -60,6 +66,7 @@ func (e *HashAggExec) Next() (*Row, error) {
 	// In this stage we consider all data from src as a single group.
 	if !e.executed {
 		e.groupMap = make(map[string]bool)
+		e.memTracker = newMemTracker(e.ctx, "HashAggExec")
 		for {
 			hasMore, err := e.innerNext()
 			if err != nil {
@@ -142,6 +149,11 @@ func (e *HashAggExec) innerNext() (ret bool, err error) {
 	if _, ok := e.groupMap[string(groupKey)]; !ok {
 		e.groupMap[string(groupKey)] = true
 		e.groups = append(e.groups, groupKey)
+		// The group key is saved in groupMap and groups, and every aggregate function keeps a context for the group.
+		e.memTracker.Consume(int64(2*len(groupKey)) + datumSize*int64(len(e.AggFuncs)))
+		if _, err = checkMemQuota(e.ctx, e.memTracker, false); err != nil {
+			return false, errors.Trace(err)
+		}
 	}
 	for _, af := range e.AggFuncs {
 		af.Update(srcRow.Data, groupKey, e.ctx)
@@ -24,11 +24,15 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/perfschema"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -36,9 +40,11 @@ import (
 // It grabs a DDL instance from Domain, calling the DDL methods to do the work.
 type DDLExec struct {
 	Statement ast.StmtNode
-	ctx       context.Context
-	is        infoschema.InfoSchema
-	done      bool
+	// SelectExec is the executor of the select statement in CREATE TABLE ... SELECT.
+	SelectExec Executor
+	ctx        context.Context
+	is         infoschema.InfoSchema
+	done       bool
 }
 
 // Schema implements the Executor Schema interface.
@@ -106,10 +112,18 @@ func (e *DDLExec) Next() (*Row, error) {
 
 // Close implements the Executor Close interface.
 func (e *DDLExec) Close() error {
+	if e.SelectExec != nil {
+		return e.SelectExec.Close()
+	}
 	return nil
 }
 
 func (e *DDLExec) executeTruncateTable(s *ast.TruncateTableStmt) error {
+	// The performance schema tables are in memory, they are truncated without DDL jobs.
+	if strings.EqualFold(s.Table.Schema.L, perfschema.Name) {
+		err := sessionctx.GetDomain(e.ctx).PerfSchema().TruncateTable(s.Table.Name.O)
+		return errors.Trace(err)
+	}
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := sessionctx.GetDomain(e.ctx).DDL().TruncateTable(e.ctx, ident)
 	return errors.Trace(err)
@@ -146,7 +160,15 @@ func (e *DDLExec) executeCreateDatabase(s *ast.CreateDatabaseStmt) error {
 
 func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
-	err := sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options)
+	var err error
+	if s.ReferTable != nil {
+		referIdent := ast.Ident{Schema: s.ReferTable.Schema, Name: s.ReferTable.Name}
+		err = sessionctx.GetDomain(e.ctx).DDL().CreateTableWithLike(e.ctx, ident, referIdent)
+	} else if e.SelectExec != nil {
+		err = e.executeCreateTableSelect(ident, s)
+	} else {
+		err = sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options)
+	}
 	if terror.ErrorEqual(err, infoschema.ErrTableExists) {
 		if s.IfNotExists {
 			return nil
@@ -156,6 +178,139 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	return errors.Trace(err)
 }
 
+// executeCreateTableSelect creates the table with the result of the select statement.
+// The columns defined in the statement go first, and the other columns of the select result are appended,
+// their types are inferred from the schema of the select plan.
+// The rows are inserted and committed before the table is created, so the table isn't created if the rows
+// can't be inserted, and the other sessions never see the table without the rows.
+func (e *DDLExec) executeCreateTableSelect(ident ast.Ident, s *ast.CreateTableStmt) error {
+	colDefs := make([]*ast.ColumnDef, 0, len(s.Cols)+e.SelectExec.Schema().Len())
+	colDefs = append(colDefs, s.Cols...)
+	for _, col := range e.SelectExec.Schema().Columns {
+		if findColumnDef(s.Cols, col.ColName.L) == nil {
+			colDefs = append(colDefs, buildColumnDefFromSelect(col))
+		}
+	}
+	d := sessionctx.GetDomain(e.ctx).DDL()
+	err := d.CreateTableSelect(e.ctx, ident, colDefs, s.Constraints, s.Options, e.fillCreatedTable)
+	return errors.Trace(err)
+}
+
+// fillCreatedTable inserts the rows of the select executor into the table being created, and commits them.
+// It returns the last auto ID allocated for the rows.
+func (e *DDLExec) fillCreatedTable(tbInfo *model.TableInfo) (int64, error) {
+	// Commit the current transaction like the other DDL statements, so the select statement reads the
+	// latest data, and the rows are committed apart from the user's transaction.
+	if err := e.ctx.NewTxn(); err != nil {
+		return 0, errors.Trace(err)
+	}
+	// The table isn't in the schema yet, its IDs are allocated from the AUTO_INCREMENT option, and
+	// the allocator of the table is rebased to the last allocated ID after the table is created.
+	alloc := &createTableAllocator{}
+	if tbInfo.AutoIncID > 1 {
+		alloc.base = tbInfo.AutoIncID - 1
+	}
+	info := *tbInfo
+	info.State = model.StatePublic
+	tbl, err := table.TableFromMeta(alloc, &info)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if err = e.insertSelectRows(tbl); err != nil {
+		// Discard the inserted rows, so they aren't committed with the later statements.
+		if err1 := e.ctx.Txn().Rollback(); err1 != nil {
+			return 0, errors.Trace(err1)
+		}
+		if err1 := e.ctx.NewTxn(); err1 != nil {
+			return 0, errors.Trace(err1)
+		}
+		return 0, errors.Trace(err)
+	}
+	return alloc.base, errors.Trace(e.ctx.NewTxn())
+}
+
+// createTableAllocator allocates the IDs of the table created by CREATE TABLE ... SELECT before the table is created.
+type createTableAllocator struct {
+	base int64
+}
+
+// Alloc implements the autoid.Allocator Alloc interface.
+func (a *createTableAllocator) Alloc(tableID int64) (int64, error) {
+	a.base++
+	return a.base, nil
+}
+
+// Rebase implements the autoid.Allocator Rebase interface.
+func (a *createTableAllocator) Rebase(tableID, newBase int64, allocIDs bool) error {
+	if newBase > a.base {
+		a.base = newBase
+	}
+	return nil
+}
+
+// insertSelectRows inserts the rows of the select executor into the table.
+func (e *DDLExec) insertSelectRows(tbl table.Table) error {
+	selectCols := e.SelectExec.Schema().Columns
+	cols := make([]*table.Column, 0, len(selectCols))
+	for _, selectCol := range selectCols {
+		col := table.FindCol(tbl.Cols(), selectCol.ColName.O)
+		if col == nil {
+			return plan.ErrUnknownColumn.GenByArgs(selectCol.ColName.O, "field list")
+		}
+		if col.IsGenerated() {
+			return table.ErrBadGeneratedColumn.GenByArgs(col.Name.O, tbl.Meta().Name.O)
+		}
+		cols = append(cols, col)
+	}
+
+	insertVals := &InsertValues{ctx: e.ctx, Table: tbl}
+	for {
+		row, err := e.SelectExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+		data, err := insertVals.fillRowData(cols, row.Data, false)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if _, err = tbl.AddRecord(e.ctx, data); err != nil {
+			return errors.Trace(err)
+		}
+		e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+		insertVals.currRow++
+	}
+}
+
+func findColumnDef(colDefs []*ast.ColumnDef, name string) *ast.ColumnDef {
+	for _, colDef := range colDefs {
+		if colDef.Name.Name.L == name {
+			return colDef
+		}
+	}
+	return nil
+}
+
+// buildColumnDefFromSelect builds the column definition for a column of the select result.
+// Only the flags about the type and nullability are kept, like MySQL does.
+func buildColumnDefFromSelect(col *expression.Column) *ast.ColumnDef {
+	tp := *col.RetType
+	tp.Flag &= mysql.NotNullFlag | mysql.UnsignedFlag | mysql.BinaryFlag | mysql.ZerofillFlag
+	if tp.Tp == mysql.TypeNull {
+		// The column of NULL constant is created as BINARY(0).
+		tp.Tp = mysql.TypeString
+		tp.Flen = 0
+		tp.Charset, tp.Collate = charset.CharsetBin, charset.CollationBin
+		tp.Flag |= mysql.BinaryFlag
+	}
+	return &ast.ColumnDef{
+		Name: &ast.ColumnName{Name: col.ColName},
+		Tp:   &tp,
+	}
+}
+
 func (e *DDLExec) executeCreateIndex(s *ast.CreateIndexStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := sessionctx.GetDomain(e.ctx).DDL().CreateIndex(e.ctx, ident, s.Unique, model.NewCIStr(s.IndexName), s.IndexColNames)
@@ -17,6 +17,9 @@ import (
 	"fmt"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -111,6 +114,88 @@ func (s *testSuite) TestCreateTable(c *C) {
 	r.Check(testkit.Rows(rowStr1))
 }
 
+func (s *testSuite) TestCreateTableLike(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table like_src (id int auto_increment primary key, a int not null default 1, b varchar(10), unique key idx_a (a), key idx_b (b)) auto_increment = 100 comment 'src'")
+	tk.MustExec("insert into like_src (a, b) values (1, 'x')")
+	tk.MustExec("create table like_dst like like_src")
+	tk.MustExec("create table if not exists like_dst (like like_src)")
+	tk.MustQuery("select count(*) from like_dst").Check(testkit.Rows("0"))
+	tk.MustQuery("show create table like_dst").Check(testkit.Rows("like_dst CREATE TABLE `like_dst` (\n" +
+		"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+		"  `a` int(11) NOT NULL DEFAULT '1',\n" +
+		"  `b` varchar(10) DEFAULT NULL,\n" +
+		" PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `idx_a` (`a`),\n" +
+		"  KEY `idx_b` (`b`)\n" +
+		") ENGINE=InnoDB COMMENT='src'"))
+	tk.MustExec("insert into like_dst (b) values ('y')")
+	tk.MustQuery("select * from like_dst").Check(testkit.Rows(fmt.Sprintf("1 1 %v", []byte("y"))))
+	_, err := tk.Exec("insert into like_dst (a) values (1)")
+	c.Assert(err, NotNil)
+
+	_, err = tk.Exec("create table like_dst like like_src")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table like_dst2 like like_not_exists")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestCreateTableSelect(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table select_src (a int not null, b varchar(10), c decimal(10, 2))")
+	tk.MustExec("insert into select_src values (1, 'x', 1.5), (2, 'y', 2.5), (3, null, null)")
+
+	tk.MustExec("create table select_dst as select a, b, c from select_src where a < 3")
+	tk.MustQuery("select * from select_dst order by a").Check(testkit.Rows(
+		fmt.Sprintf("1 %v 1.50", []byte("x")), fmt.Sprintf("2 %v 2.50", []byte("y"))))
+	tk.MustQuery("show create table select_dst").Check(testkit.Rows("select_dst CREATE TABLE `select_dst` (\n" +
+		"  `a` int(11) NOT NULL,\n" +
+		"  `b` varchar(10) DEFAULT NULL,\n" +
+		"  `c` decimal(10,2) DEFAULT NULL\n" +
+		") ENGINE=InnoDB"))
+	// The handles of the inserted rows aren't allocated again.
+	tk.MustExec("insert into select_dst values (3, 'z', 3.5)")
+	tk.MustQuery("select count(*) from select_dst").Check(testkit.Rows("3"))
+
+	// The defined columns go first, and the columns with the same names are filled by the select result.
+	tk.MustExec("create table select_dst2 (id int auto_increment primary key, a bigint) select a, a * 10 as d from select_src")
+	tk.MustQuery("select * from select_dst2 order by id").Check(testkit.Rows("1 1 10", "2 2 20", "3 3 30"))
+
+	// The table is not created if the rows can't be inserted.
+	_, err := tk.Exec("create table select_dst3 (b int unique) select 1 as b from select_src")
+	c.Assert(terror.ErrorEqual(err, kv.ErrKeyExists), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from select_dst3")
+	c.Assert(infoschema.ErrTableNotExists.Equal(err), IsTrue, Commentf("err %v", err))
+	tk.MustExec("create table select_dst3 (a int primary key) select a from select_src")
+	tk.MustQuery("select count(*) from select_dst3").Check(testkit.Rows("3"))
+	// The table is not created if the select statement fails.
+	_, err = tk.Exec("create table select_dst4 select a from select_src where a = (select a from select_src)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select * from select_dst4")
+	c.Assert(infoschema.ErrTableNotExists.Equal(err), IsTrue, Commentf("err %v", err))
+	// The rows are committed with the table, and the auto increment IDs go on after the inserted rows.
+	tk.MustExec("begin")
+	tk.MustExec("create table select_dst5 (id int auto_increment primary key) select a from select_src")
+	tk.MustExec("rollback")
+	tk.MustExec("insert into select_dst5 (a) values (4)")
+	tk.MustQuery("select * from select_dst5 order by id").Check(testkit.Rows("1 1", "2 2", "3 3", "4 4"))
+
+	tk.MustExec("create table if not exists select_dst select 1 as a")
+	tk.MustQuery("select count(*) from select_dst").Check(testkit.Rows("3"))
+	_, err = tk.Exec("create table select_dup select a, a from select_src")
+	c.Assert(err, NotNil)
+}
+
 func (s *testSuite) TestCreateDropDatabase(c *C) {
 	defer testleak.AfterTest(c)()
 	tk := testkit.NewTestKit(c, s.store)
@@ -33,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
@@ -346,6 +347,8 @@ type XSelectIndexExec struct {
 
 	returnedRows uint64 // returned row count
 	handleCount  uint64 // returned handle count when reading first
+	// rowsRead is the number of the rows read since the last Close, they are counted by the index in Close.
+	rowsRead uint64
 
 	mu sync.Mutex
 
@@ -374,6 +377,10 @@ func (e *XSelectIndexExec) Schema() *expression.Schema {
 
 // Close implements Exec Close interface.
 func (e *XSelectIndexExec) Close() error {
+	if e.rowsRead > 0 {
+		tables.CountRowsReadIndex(e.tableInfo.ID, e.indexPlan.Index.ID, e.rowsRead)
+		e.rowsRead = 0
+	}
 	err := closeAll(e.result, e.partialResult)
 	if err != nil {
 		return errors.Trace(err)
@@ -392,11 +399,23 @@ func (e *XSelectIndexExec) Next() (*Row, error) {
 	if e.indexPlan.LimitCount != nil && e.returnedRows >= uint64(*e.indexPlan.LimitCount) {
 		return nil, nil
 	}
+	if err := checkDeadline(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	e.returnedRows++
+	var (
+		row *Row
+		err error
+	)
 	if e.singleReadMode {
-		return e.nextForSingleRead()
+		row, err = e.nextForSingleRead()
+	} else {
+		row, err = e.nextForDoubleRead()
+	}
+	if row != nil && !e.aggregate {
+		e.rowsRead++
 	}
-	return e.nextForDoubleRead()
+	return row, errors.Trace(err)
 }
 
 func (e *XSelectIndexExec) nextForSingleRead() (*Row, error) {
@@ -604,7 +623,7 @@ func (e *XSelectIndexExec) doIndexRequest() (distsql.SelectResult, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	return distsql.Select(e.ctx.GetClient(), selIdxReq, keyRanges, e.scanConcurrency, !e.indexPlan.OutOfOrder)
+	return distsql.Select(e.ctx.GetClient(), selIdxReq, keyRanges, e.scanConcurrency, !e.indexPlan.OutOfOrder, sc)
 }
 
 func (e *XSelectIndexExec) buildTableTasks(handles []int64) []*lookupTableTask {
@@ -735,7 +754,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 	selTableReq.GroupBy = e.byItems
 	keyRanges := tableHandlesToKVRanges(e.table.Meta().ID, handles)
 
-	resp, err := distsql.Select(e.ctx.GetClient(), selTableReq, keyRanges, e.scanConcurrency, false)
+	resp, err := distsql.Select(e.ctx.GetClient(), selTableReq, keyRanges, e.scanConcurrency, false, e.ctx.GetSessionVars().StmtCtx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -771,6 +790,8 @@ type XSelectTableExec struct {
 	keepOrder    bool
 	startTS      uint64
 	orderByList  []*tipb.ByItem
+	// rowsRead is the number of the rows read since the last Close, they are counted by the table in Close.
+	rowsRead uint64
 
 	/*
 	   The following attributes are used for aggregation push down.
@@ -818,7 +839,7 @@ func (e *XSelectTableExec) doRequest() error {
 	selReq.GroupBy = e.byItems
 
 	kvRanges := tableRangesToKVRanges(e.table.Meta().ID, e.ranges)
-	e.result, err = distsql.Select(e.ctx.GetClient(), selReq, kvRanges, e.scanConcurrency, e.keepOrder)
+	e.result, err = distsql.Select(e.ctx.GetClient(), selReq, kvRanges, e.scanConcurrency, e.keepOrder, e.ctx.GetSessionVars().StmtCtx)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -833,6 +854,10 @@ func (e *XSelectTableExec) doRequest() error {
 
 // Close implements the Executor Close interface.
 func (e *XSelectTableExec) Close() error {
+	if e.rowsRead > 0 {
+		tables.CountRowsReadFullScan(e.tableInfo.ID, e.rowsRead)
+		e.rowsRead = 0
+	}
 	err := closeAll(e.result, e.partialResult)
 	if err != nil {
 		return errors.Trace(err)
@@ -849,6 +874,9 @@ func (e *XSelectTableExec) Next() (*Row, error) {
 	if e.limitCount != nil && e.returnedRows >= uint64(*e.limitCount) {
 		return nil, nil
 	}
+	if err := checkDeadline(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if e.result == nil {
 		e.execStart = time.Now()
 		err := e.doRequest()
@@ -890,6 +918,7 @@ func (e *XSelectTableExec) Next() (*Row, error) {
 			// compose aggreagte row
 			return &Row{Data: rowData}, nil
 		}
+		e.rowsRead++
 		return resultRowToRow(e.table, h, rowData, e.asName), nil
 	}
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -67,6 +68,9 @@ type HashJoinExec struct {
 	// Channels for output.
 	resultErr  chan error
 	resultRows chan *Row
+
+	// memTracker tracks the memory used by the hash table.
+	memTracker *memory.Tracker
 }
 
 // hashJoinCtx holds the variables needed to do a hash join in one of many concurrent goroutines.
@@ -88,6 +92,10 @@ func (e *HashJoinExec) Close() error {
 	}
 	e.prepared = false
 	e.cursor = 0
+	if e.memTracker != nil {
+		e.memTracker.Consume(-e.memTracker.BytesConsumed())
+		e.memTracker = nil
+	}
 	return e.smallExec.Close()
 }
 
@@ -197,7 +205,9 @@ func (e *HashJoinExec) prepare() error {
 
 	e.hashTable = make(map[string][]*Row)
 	e.cursor = 0
+	e.memTracker = newMemTracker(e.ctx, "HashJoinExec")
 	sc := e.ctx.GetSessionVars().StmtCtx
+	buildRows := 0
 	for {
 		row, err := e.smallExec.Next()
 		if err != nil {
@@ -227,13 +237,21 @@ func (e *HashJoinExec) prepare() error {
 		}
 		if rows, ok := e.hashTable[string(hashcode)]; !ok {
 			e.hashTable[string(hashcode)] = []*Row{row}
+			e.memTracker.Consume(int64(len(hashcode)))
 		} else {
 			e.hashTable[string(hashcode)] = append(rows, row)
 		}
+		buildRows++
+		e.memTracker.Consume(datumsMemUsage(row.Data))
+		if _, err = checkMemQuota(e.ctx, e.memTracker, false); err != nil {
+			return errors.Trace(err)
+		}
 	}
+	hashJoinBuildRowsHistogram.Observe(float64(buildRows))
 
 	e.resultRows = make(chan *Row, e.concurrency*1000)
-	e.resultErr = make(chan error, 1)
+	// Every join worker sends at most one error, so the workers are never blocked by sending errors.
+	e.resultErr = make(chan error, e.concurrency)
 
 	for i := 0; i < e.concurrency; i++ {
 		e.wg.Add(1)
@@ -271,6 +289,10 @@ func (e *HashJoinExec) runJoinWorker(idx int) {
 		if !ok || e.finished.Load().(bool) {
 			break
 		}
+		if err = checkDeadline(e.ctx); err != nil {
+			e.resultErr <- errors.Trace(err)
+			break
+		}
 		for _, bigRow := range bigRows {
 			succ := e.joinOneBigRow(e.hashJoinContexts[idx], bigRow)
 			if !succ {
@@ -497,6 +519,9 @@ func (e *NestedLoopJoinExec) prepare() error {
 }
 
 func (e *NestedLoopJoinExec) doJoin(bigRow *Row) ([]*Row, error) {
+	if err := checkDeadline(e.Ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	e.resultRows = e.resultRows[0:0]
 	for _, row := range e.innerRows {
 		mergedRow := makeJoinRow(bigRow, row)
@@ -15,8 +15,10 @@ package executor
 
 import (
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/tablecodec"
+	dto "github.com/prometheus/client_model/go"
 )
 
 var _ = Suite(&testExecSuite{})
@@ -61,3 +63,64 @@ func (s *testExecSuite) TestMergeHandles(c *C) {
 		c.Assert(kr.EndKey, DeepEquals, ekr.EndKey)
 	}
 }
+
+type mockExec struct {
+	rows   int
+	cursor int
+}
+
+func (e *mockExec) Schema() *expression.Schema {
+	return expression.NewSchema()
+}
+
+func (e *mockExec) Next() (*Row, error) {
+	if e.cursor >= e.rows {
+		return nil, nil
+	}
+	e.cursor++
+	return &Row{}, nil
+}
+
+func (e *mockExec) Close() error {
+	e.cursor = 0
+	return nil
+}
+
+func (s *testExecSuite) TestTracedExecMetrics(c *C) {
+	metrics := func() (float64, uint64) {
+		rows, duration := &dto.Metric{}, &dto.Metric{}
+		c.Assert(executorRowsCounter.WithLabelValues("mockExec").Write(rows), IsNil)
+		c.Assert(executorDurationHistogram.WithLabelValues("mockExec").Write(duration), IsNil)
+		return rows.GetCounter().GetValue(), duration.GetHistogram().GetSampleCount()
+	}
+	te := newTracedExec(&mockExec{rows: 3}, nil)
+	run := func() {
+		for {
+			row, err := te.Next()
+			c.Assert(err, IsNil)
+			if row == nil {
+				break
+			}
+		}
+	}
+
+	// The executor isn't observed if it's closed without running.
+	c.Assert(te.Close(), IsNil)
+	rows, count := metrics()
+	c.Assert(rows, Equals, float64(0))
+	c.Assert(count, Equals, uint64(0))
+
+	run()
+	c.Assert(te.Close(), IsNil)
+	c.Assert(te.Close(), IsNil)
+	rows, count = metrics()
+	c.Assert(rows, Equals, float64(3))
+	c.Assert(count, Equals, uint64(1))
+
+	// Every run is observed.
+	run()
+	c.Assert(te.Close(), IsNil)
+	rows, count = metrics()
+	c.Assert(rows, Equals, float64(6))
+	c.Assert(count, Equals, uint64(2))
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/generallog"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -110,10 +111,22 @@ func (e *SetExecutor) executeSet() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			var generalLogOn bool
+			if name == variable.GeneralLog {
+				generalLogOn, svalue, err = parseOnOff(name, svalue)
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
 			err = sessionVars.GlobalVarsAccessor.SetGlobalSysVar(name, svalue)
 			if err != nil {
 				return errors.Trace(err)
 			}
+			if name == variable.GeneralLog {
+				// The general query log of this server is toggled immediately.
+				generallog.SetEnabled(generalLogOn)
+				log.Infof("[%d] set general_log = %s", sessionVars.ConnectionID, svalue)
+			}
 		} else {
 			// Set session scope system variable.
 			if sysVar.Scope&variable.ScopeSession == 0 {
@@ -180,6 +193,17 @@ func (e *SetExecutor) getVarValue(v *expression.VarAssignment, sysVar *variable.
 	return value, errors.Trace(err)
 }
 
+// parseOnOff parses the value of a boolean variable, it returns the value normalized to "ON" or "OFF".
+func parseOnOff(name, value string) (bool, string, error) {
+	switch strings.ToUpper(value) {
+	case "ON", "1", "TRUE":
+		return true, "ON", nil
+	case "OFF", "0", "FALSE":
+		return false, "OFF", nil
+	}
+	return false, "", variable.ErrWrongValueForVar.GenByArgs(name, value)
+}
+
 func (e *SetExecutor) loadSnapshotInfoSchemaIfNeeded(name string) error {
 	if name != variable.TiDBSnapshot {
 		return nil
@@ -18,6 +18,8 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/generallog"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -123,6 +125,19 @@ func (s *testSuite) TestSetVar(c *C) {
 	c.Assert(vars.SkipConstraintCheck, IsTrue)
 	tk.MustExec("set @@tidb_skip_constraint_check = '0'")
 	c.Assert(vars.SkipConstraintCheck, IsFalse)
+
+	// general_log takes effect immediately and is stored as ON or OFF.
+	tk.MustExec("set global general_log = 1")
+	c.Assert(generallog.Enabled(), IsTrue)
+	tk.MustQuery("select @@global.general_log").Check(testkit.Rows("ON"))
+	tk.MustExec("set @@global.general_log = 'off'")
+	c.Assert(generallog.Enabled(), IsFalse)
+	tk.MustQuery("select @@global.general_log").Check(testkit.Rows("OFF"))
+	_, err = tk.Exec("set global general_log = 'abc'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	c.Assert(generallog.Enabled(), IsFalse)
+	_, err = tk.Exec("set general_log = 1")
+	c.Assert(err, NotNil)
 }
 
 func (s *testSuite) TestSetCharset(c *C) {
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
@@ -67,10 +68,13 @@ func (e *SimpleExec) Next() (*Row, error) {
 		err = e.executeRollback(x)
 	case *ast.CreateUserStmt:
 		err = e.executeCreateUser(x)
+		e.clearUserResources()
 	case *ast.AlterUserStmt:
 		err = e.executeAlterUser(x)
+		e.clearUserResources()
 	case *ast.DropUserStmt:
 		err = e.executeDropUser(x)
+		e.clearUserResources()
 	case *ast.SetPwdStmt:
 		err = e.executeSetPwd(x)
 	case *ast.BinlogStmt:
@@ -89,6 +93,13 @@ func (e *SimpleExec) Close() error {
 	return nil
 }
 
+// clearUserResources clears the cached resource limits of the accounts after they're changed.
+func (e *SimpleExec) clearUserResources() {
+	if do := sessionctx.GetDomain(e.ctx); do != nil {
+		do.ClearUserResources()
+	}
+}
+
 func (e *SimpleExec) executeUse(s *ast.UseStmt) error {
 	dbname := model.NewCIStr(s.DBName)
 	dbinfo, exists := e.is.SchemaByName(dbname)
@@ -119,6 +130,7 @@ func (e *SimpleExec) executeBegin(s *ast.BeginStmt) error {
 	// the transaction with COMMIT or ROLLBACK. The autocommit mode then
 	// reverts to its previous state.
 	e.ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusInTrans, true)
+	e.ctx.GetSessionVars().TxnCtx.Autocommit = false
 	return nil
 }
 
@@ -151,20 +163,34 @@ func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 			continue
 		}
 		pwd := ""
+		plugin := mysql.AuthNativePassword
+		pgMD5, pgScram, err := pgPasswords(userName, spec.AuthOpt)
+		if err != nil {
+			return errors.Trace(err)
+		}
 		if spec.AuthOpt != nil {
 			if spec.AuthOpt.ByAuthString {
 				pwd = util.EncodePassword(spec.AuthOpt.AuthString)
 			} else {
 				pwd = util.EncodePassword(spec.AuthOpt.HashString)
 			}
+			if spec.AuthOpt.AuthPlugin != "" {
+				plugin = spec.AuthOpt.AuthPlugin
+			}
 		}
-		user := fmt.Sprintf(`("%s", "%s", "%s")`, host, userName, pwd)
+		if err := checkAuthPlugin(plugin); err != nil {
+			return errors.Trace(err)
+		}
+		user := fmt.Sprintf(`("%s", "%s", "%s", "%s", "%s", "%s", "%s", %d, %d, %d)`, host, userName, pwd, sslType(s.TLSOption), plugin, pgMD5, pgScram,
+			resourceLimit(s.ResourceOptions, ast.MaxQueriesPerHour), resourceLimit(s.ResourceOptions, ast.MaxUpdatesPerHour),
+			resourceLimit(s.ResourceOptions, ast.MaxUserConnections))
 		users = append(users, user)
 	}
 	if len(users) == 0 {
 		return nil
 	}
-	sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password) VALUES %s;`, mysql.SystemDB, mysql.UserTable, strings.Join(users, ", "))
+	sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password, Ssl_type, plugin, pg_md5_password, pg_scram_password, max_questions, max_updates, max_user_connections) VALUES %s;`,
+		mysql.SystemDB, mysql.UserTable, strings.Join(users, ", "))
 	_, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 	if err != nil {
 		return errors.Trace(err)
@@ -207,8 +233,29 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 				pwd = util.EncodePassword(spec.AuthOpt.HashString)
 			}
 		}
-		sql := fmt.Sprintf(`UPDATE %s.%s SET Password = "%s" WHERE Host = "%s" and User = "%s";`,
-			mysql.SystemDB, mysql.UserTable, pwd, host, userName)
+		assignments := make([]string, 0, 8)
+		if spec.AuthOpt != nil || (s.TLSOption == ast.TLSOptionNotSpecified && len(s.ResourceOptions) == 0) {
+			pgMD5, pgScram, err1 := pgPasswords(userName, spec.AuthOpt)
+			if err1 != nil {
+				return errors.Trace(err1)
+			}
+			assignments = append(assignments, fmt.Sprintf(`Password = "%s"`, pwd),
+				fmt.Sprintf(`pg_md5_password = "%s"`, pgMD5), fmt.Sprintf(`pg_scram_password = "%s"`, pgScram))
+		}
+		if spec.AuthOpt != nil && spec.AuthOpt.AuthPlugin != "" {
+			if err = checkAuthPlugin(spec.AuthOpt.AuthPlugin); err != nil {
+				return errors.Trace(err)
+			}
+			assignments = append(assignments, fmt.Sprintf(`plugin = "%s"`, spec.AuthOpt.AuthPlugin))
+		}
+		if s.TLSOption != ast.TLSOptionNotSpecified {
+			assignments = append(assignments, fmt.Sprintf(`Ssl_type = "%s"`, sslType(s.TLSOption)))
+		}
+		for _, opt := range s.ResourceOptions {
+			assignments = append(assignments, fmt.Sprintf(`%s = %d`, resourceLimitColumn(opt.Type), opt.Count))
+		}
+		sql := fmt.Sprintf(`UPDATE %s.%s SET %s WHERE Host = "%s" and User = "%s";`,
+			mysql.SystemDB, mysql.UserTable, strings.Join(assignments, ", "), host, userName)
 		_, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 		if err != nil {
 			failedUsers = append(failedUsers, spec.User)
@@ -226,6 +273,65 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 	return nil
 }
 
+// pgPasswords returns the password verifiers of the PostgreSQL protocol for the account. The verifiers
+// can only be computed from the clear text password, they are empty if the password is given by its hash.
+func pgPasswords(userName string, authOpt *ast.AuthOption) (md5, scram string, err error) {
+	if authOpt == nil || !authOpt.ByAuthString {
+		return "", "", nil
+	}
+	scram, err = util.PGScramPassword(authOpt.AuthString)
+	if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	return util.PGMD5Password(userName, authOpt.AuthString), scram, nil
+}
+
+// checkAuthPlugin checks whether the authentication plugin is supported by the server.
+func checkAuthPlugin(plugin string) error {
+	switch plugin {
+	case mysql.AuthNativePassword, mysql.AuthCachingSha2Password, mysql.AuthSocket:
+		return nil
+	default:
+		return ErrPluginNotLoaded.GenByArgs(plugin)
+	}
+}
+
+// resourceLimit returns the limit of the resource in the WITH clause of an account, the last one wins
+// if the resource is given more than once, 0 means no limit.
+func resourceLimit(opts []*ast.ResourceOption, tp ast.ResourceOptionType) uint64 {
+	var count uint64
+	for _, opt := range opts {
+		if opt.Type == tp {
+			count = opt.Count
+		}
+	}
+	return count
+}
+
+// resourceLimitColumn returns the mysql.user column of the resource limit.
+func resourceLimitColumn(tp ast.ResourceOptionType) string {
+	switch tp {
+	case ast.MaxQueriesPerHour:
+		return "max_questions"
+	case ast.MaxUpdatesPerHour:
+		return "max_updates"
+	default:
+		return "max_user_connections"
+	}
+}
+
+// sslType returns the value of mysql.user Ssl_type column for the REQUIRE clause of an account.
+func sslType(opt ast.TLSOptionType) string {
+	switch opt {
+	case ast.TLSOptionSSL:
+		return "ANY"
+	case ast.TLSOptionX509:
+		return "X509"
+	default:
+		return ""
+	}
+}
+
 func (e *SimpleExec) executeDropUser(s *ast.DropUserStmt) error {
 	failedUsers := make([]string, 0, len(s.UserList))
 	for _, user := range s.UserList {
@@ -296,8 +402,14 @@ func (e *SimpleExec) executeSetPwd(s *ast.SetPwdStmt) error {
 		return errors.Trace(ErrPasswordNoMatch)
 	}
 
+	pgScram, err := util.PGScramPassword(s.Password)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	// update mysql.user
-	sql := fmt.Sprintf(`UPDATE %s.%s SET password="%s" WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, util.EncodePassword(s.Password), userName, host)
+	sql := fmt.Sprintf(`UPDATE %s.%s SET password="%s", pg_md5_password="%s", pg_scram_password="%s" WHERE User="%s" AND Host="%s";`,
+		mysql.SystemDB, mysql.UserTable, util.EncodePassword(s.Password), util.PGMD5Password(userName, s.Password),
+		pgScram, userName, host)
 	_, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 	return errors.Trace(err)
 }
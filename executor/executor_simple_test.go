@@ -153,7 +153,45 @@ func (s *testSuite) TestUser(c *C) {
 	result = tk.MustQuery(`SELECT Password FROM mysql.User WHERE User="test1" and Host="localhost"`)
 	rowStr = fmt.Sprintf("%v", []byte(util.EncodePassword("1")))
 	result.Check(testkit.Rows(rowStr))
-	dropUserSQL = `DROP USER 'test1'@'localhost', 'test2'@'localhost', 'test3'@'localhost';`
+	// Test the REQUIRE clause, alter user without IDENTIFIED BY keeps the password.
+	alterUserSQL = `ALTER USER 'test2'@'localhost' REQUIRE X509;`
+	tk.MustExec(alterUserSQL)
+	result = tk.MustQuery(`SELECT Password, Ssl_type FROM mysql.User WHERE User="test2" and Host="localhost"`)
+	rowStr = fmt.Sprintf("%v", []byte(util.EncodePassword("222")))
+	result.Check(testkit.Rows(rowStr + " X509"))
+	alterUserSQL = `ALTER USER 'test2'@'localhost' REQUIRE NONE;`
+	tk.MustExec(alterUserSQL)
+	result = tk.MustQuery(`SELECT Ssl_type FROM mysql.User WHERE User="test2" and Host="localhost"`)
+	result.Check(testkit.Rows(""))
+	createUserSQL = `CREATE USER 'test4'@'localhost' REQUIRE SSL;`
+	tk.MustExec(createUserSQL)
+	result = tk.MustQuery(`SELECT Ssl_type FROM mysql.User WHERE User="test4" and Host="localhost"`)
+	result.Check(testkit.Rows("ANY"))
+	// Test the authentication plugin.
+	result = tk.MustQuery(`SELECT plugin FROM mysql.User WHERE User="test4" and Host="localhost"`)
+	result.Check(testkit.Rows(fmt.Sprintf("%v", []byte("mysql_native_password"))))
+	alterUserSQL = `ALTER USER 'test4'@'localhost' IDENTIFIED WITH caching_sha2_password BY '444';`
+	tk.MustExec(alterUserSQL)
+	result = tk.MustQuery(`SELECT Password, plugin FROM mysql.User WHERE User="test4" and Host="localhost"`)
+	rowStr = fmt.Sprintf("%v %v", []byte(util.EncodePassword("444")), []byte("caching_sha2_password"))
+	result.Check(testkit.Rows(rowStr))
+	createUserSQL = `CREATE USER 'test5'@'localhost' IDENTIFIED WITH auth_socket;`
+	tk.MustExec(createUserSQL)
+	result = tk.MustQuery(`SELECT plugin FROM mysql.User WHERE User="test5" and Host="localhost"`)
+	result.Check(testkit.Rows(fmt.Sprintf("%v", []byte("auth_socket"))))
+	_, err = tk.Exec(`CREATE USER 'test6'@'localhost' IDENTIFIED WITH unknown_plugin;`)
+	c.Check(terror.ErrorEqual(err, executor.ErrPluginNotLoaded), IsTrue)
+	// Test the resource limits, alter user without IDENTIFIED BY keeps the password.
+	createUserSQL = `CREATE USER 'test6'@'localhost' IDENTIFIED BY '666' WITH MAX_QUERIES_PER_HOUR 10 MAX_USER_CONNECTIONS 2;`
+	tk.MustExec(createUserSQL)
+	result = tk.MustQuery(`SELECT max_questions, max_updates, max_user_connections FROM mysql.User WHERE User="test6" and Host="localhost"`)
+	result.Check(testkit.Rows("10 0 2"))
+	alterUserSQL = `ALTER USER 'test6'@'localhost' WITH MAX_UPDATES_PER_HOUR 5 MAX_QUERIES_PER_HOUR 0;`
+	tk.MustExec(alterUserSQL)
+	result = tk.MustQuery(`SELECT Password, max_questions, max_updates, max_user_connections FROM mysql.User WHERE User="test6" and Host="localhost"`)
+	rowStr = fmt.Sprintf("%v", []byte(util.EncodePassword("666")))
+	result.Check(testkit.Rows(rowStr + " 0 5 2"))
+	dropUserSQL = `DROP USER 'test1'@'localhost', 'test2'@'localhost', 'test3'@'localhost', 'test4'@'localhost', 'test5'@'localhost', 'test6'@'localhost';`
 	tk.MustExec(dropUserSQL)
 
 	// Test drop user if exists.
@@ -16,7 +16,9 @@ package executor_test
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -32,7 +34,10 @@ import (
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -1405,3 +1410,221 @@ func (s *testSuite) TestAggPrune(c *C) {
 	tk.MustExec("insert into t values(1, 1, 3, NULL), (2, 1, NULL, 6), (3, NULL, 1, 2), (4, NULL, NULL, 1), (5, NULL, 2, NULL), (6, 3, NULL, NULL), (7, NULL, NULL, NULL), (8, 1, 2 ,3)")
 	tk.MustQuery("select count(distinct b, c, d) from t group by id").Check(testkit.Rows("0", "0", "0", "0", "0", "0", "0", "1"))
 }
+
+func (s *testSuite) TestMaxExecutionTime(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("begin")
+	for i := 0; i < 40; i++ {
+		tk.MustExec("insert t values (?)", i)
+	}
+	tk.MustExec("commit")
+	// drain reads all the rows of sql and returns the first error.
+	drain := func(sql string) error {
+		rs, err := tk.Exec(sql)
+		if err != nil {
+			return err
+		}
+		defer rs.Close()
+		for {
+			row, err := rs.Next()
+			if err != nil || row == nil {
+				return err
+			}
+		}
+	}
+	const crossJoin = "select count(*) from t a, t b, t c where a.a + b.a + c.a > 0"
+
+	tk.MustQuery("select @@max_execution_time").Check(testkit.Rows("0"))
+	err := drain("select /*+ MAX_EXECUTION_TIME(1) */ count(*) from t a, t b, t c where a.a + b.a + c.a > 0")
+	c.Assert(executor.ErrQueryTimeout.Equal(err), IsTrue, Commentf("err %v", err))
+	tk.MustQuery("select /*+ MAX_EXECUTION_TIME(100000) */ count(*) from t").Check(testkit.Rows("40"))
+
+	tk.MustExec("set @@max_execution_time = 1")
+	err = drain(crossJoin)
+	c.Assert(executor.ErrQueryTimeout.Equal(err), IsTrue, Commentf("err %v", err))
+	// The hint overrides the variable.
+	tk.MustQuery("select /*+ MAX_EXECUTION_TIME(0) */ count(*) from t a, t b where a.a = b.a").Check(testkit.Rows("40"))
+	// Only the SELECT statements have timeouts.
+	tk.MustExec("update t set a = a + 1 where a < 0")
+	tk.MustExec("set @@max_execution_time = 0")
+	tk.MustQuery(crossJoin).Check(testkit.Rows("63999"))
+}
+
+func (s *testSuite) TestSlowQuery(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	dir, err := ioutil.TempDir("", "slowlog")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	c.Assert(slowlog.SetFile(filepath.Join(dir, "slow.log")), IsNil)
+	defer slowlog.SetFile("")
+
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("insert t values (1), (2), (3)")
+	tk.MustQuery("select @@tidb_slow_log_threshold").Check(testkit.Rows("300"))
+	tk.MustQuery("select count(*) from information_schema.slow_query").Check(testkit.Rows("0"))
+
+	tk.MustExec("set @@tidb_slow_log_threshold = 0")
+	tk.MustQuery("select a from t where a > 1 order by a").Check(testkit.Rows("2", "3"))
+	_, digest := parser.NormalizeDigest("select a from t where a > 2 order by a")
+	result := tk.MustQuery("select conn_id, db, digest, process_keys, query_time >= exec_time, plan, query " +
+		"from information_schema.slow_query where query like 'select a from t%'")
+	connID := tk.Se.GetSessionVars().ConnectionID
+	result.Check(testkit.Rows(fmt.Sprintf("%d test %s 2 1 Table(t)->Sort select a from t where a > 1 order by a",
+		connID, digest)))
+	// The statements reading the SLOW_QUERY table are logged too, after they finish.
+	tk.MustQuery("select count(*) from information_schema.slow_query where query like '%information_schema.slow_query%'").Check(testkit.Rows("1"))
+}
+
+func (s *testSuite) TestTiDBInfoSchemaTables(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_regions")
+	tk.MustExec("create table t_regions (a int primary key, b int, c varchar(10), unique index idx_b(b), index idx_c(c(5)))")
+	tk.MustQuery(`select key_name, non_unique, seq_in_index, column_name, sub_part, index_id, state
+		from information_schema.tidb_indexes where table_schema = 'test' and table_name = 't_regions' order by index_id`).Check(testkit.Rows(
+		"PRIMARY 0 1 a <nil> 0 public",
+		"idx_b 0 1 b <nil> 1 public",
+		"idx_c 1 1 c 5 2 public",
+	))
+	if !*mockTikv {
+		return
+	}
+
+	tk.MustExec("insert t_regions values (1, 1, 'a'), (2, 2, 'b')")
+	tk.MustQuery("select b from t_regions where a = 1").Check(testkit.Rows("1"))
+	// The records and the indices are in the same region in the mock TiKV.
+	tk.MustQuery(`select is_index, index_name from information_schema.tikv_region_status
+		where db_name = 'test' and table_name = 't_regions' order by index_id`).Check(testkit.Rows(
+		"0 <nil>", "1 idx_b", "1 idx_c",
+	))
+	tk.MustQuery(`select i.key_name, count(distinct r.region_id) from information_schema.tidb_indexes i
+		join information_schema.tikv_region_status r on i.table_name = r.table_name and i.index_id = r.index_id
+		where i.table_schema = 'test' and i.table_name = 't_regions' group by i.key_name order by i.key_name`).Check(testkit.Rows(
+		"idx_b 1", "idx_c 1",
+	))
+	tk.MustQuery(`select type, request_count > 0 from information_schema.tidb_hot_regions
+		where db_name = 'test' and table_name = 't_regions' and index_id is null order by type`).Check(testkit.Rows(
+		"read 1", "write 1",
+	))
+}
+
+func (s *testSuite) TestTableStatistics(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_stats")
+	tk.MustExec("create table t_stats (a int primary key, b int, c int, index idx_b(b), index idx_c(c))")
+	tk.MustQuery(`select rows_read, rows_changed, lock_waits from information_schema.table_statistics
+		where table_schema = 'test' and table_name = 't_stats'`).Check(testkit.Rows("0 0 0"))
+
+	tk.MustExec("insert t_stats values (1, 1, 1), (2, 2, 2), (3, 3, 3)")
+	tk.MustExec("update t_stats set c = 10 where a = 1")
+	tk.MustExec("delete from t_stats where a = 3")
+	tk.MustQuery("select a from t_stats").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select b from t_stats use index(idx_b) where b > 1").Check(testkit.Rows("2"))
+	tk.MustQuery(`select rows_read, rows_read_full_scan, rows_read_index, rows_inserted, rows_updated, rows_deleted,
+		rows_changed, rows_changed_x_indexes from information_schema.table_statistics
+		where table_schema = 'test' and table_name = 't_stats'`).Check(testkit.Rows("5 4 1 3 1 1 5 10"))
+	// The rows are counted when the transaction is committed.
+	tk.MustExec("begin")
+	tk.MustExec("insert t_stats values (4, 4, 4)")
+	tk.MustQuery(`select rows_inserted from information_schema.table_statistics
+		where table_schema = 'test' and table_name = 't_stats'`).Check(testkit.Rows("3"))
+	tk.MustExec("rollback")
+	tk.MustExec("begin")
+	tk.MustExec("insert t_stats values (4, 4, 4)")
+	tk.MustExec("commit")
+	tk.MustQuery(`select rows_inserted from information_schema.table_statistics
+		where table_schema = 'test' and table_name = 't_stats'`).Check(testkit.Rows("4"))
+	tk.MustQuery(`select index_name, rows_read from information_schema.index_statistics
+		where table_schema = 'test' and table_name = 't_stats' order by index_name`).Check(testkit.Rows(
+		"idx_b 1", "idx_c 0",
+	))
+}
+
+func (s *testSuite) TestMemQuota(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(20), c datetime)")
+	tk.MustExec("begin")
+	for i := 0; i < 40; i++ {
+		tk.MustExec("insert t values (?, ?, ?)", (i*7)%40, fmt.Sprintf("name%d", i), fmt.Sprintf("2017-01-01 00:00:%02d", i))
+	}
+	tk.MustExec("commit")
+	drain := func(sql string) error {
+		rs, err := tk.Exec(sql)
+		if err != nil {
+			return err
+		}
+		defer rs.Close()
+		for {
+			row, err := rs.Next()
+			if err != nil || row == nil {
+				return err
+			}
+		}
+	}
+	queries := []string{
+		"select * from t order by a",
+		"select b, count(*) from t group by b",
+		"select count(*) from t a join t b on a.a = b.a",
+	}
+
+	tk.MustQuery("select @@tidb_mem_quota_query, @@tidb_mem_oom_action").Check(testkit.Rows("0 log"))
+	_, err := tk.Exec("set @@tidb_mem_oom_action = 'abort'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("err %v", err))
+
+	tk.MustExec("set @@tidb_mem_quota_query = 1000")
+	tk.MustExec("set @@tidb_mem_oom_action = 'CANCEL'")
+	for _, sql := range queries {
+		err = drain(sql)
+		c.Assert(executor.ErrMemExceedThreshold.Equal(err), IsTrue, Commentf("sql %s, err %v", sql, err))
+	}
+	// Small statements are not affected.
+	tk.MustQuery("select a from t where a < 2 order by a").Check(testkit.Rows("0", "1"))
+
+	tk.MustExec("set @@tidb_mem_oom_action = 'log'")
+	for _, sql := range queries {
+		c.Assert(drain(sql), IsNil)
+	}
+
+	// Sort spills its rows to disk, the others are cancelled.
+	tk.MustExec("set @@tidb_mem_oom_action = 'spill'")
+	var expected []string
+	for a := 39; a >= 0; a-- {
+		// The row of a is inserted at i = a * 23 % 40, since 7 * 23 % 40 = 1.
+		expected = append(expected, fmt.Sprintf("%d 2017-01-01 00:00:%02d", a, a*23%40))
+	}
+	tk.MustQuery("select a, c from t order by a desc").Check(testkit.Rows(expected...))
+	tk.MustQuery("select count(*) from (select * from t order by b) t1").Check(testkit.Rows("40"))
+	err = drain(queries[1])
+	c.Assert(executor.ErrMemExceedThreshold.Equal(err), IsTrue, Commentf("err %v", err))
+
+	tk.MustExec("set @@tidb_mem_quota_query = 0")
+	tk.MustQuery("select count(*) from t a join t b on a.a = b.a").Check(testkit.Rows("40"))
+}
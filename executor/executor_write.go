@@ -23,7 +23,9 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
@@ -37,7 +39,140 @@ var (
 	_ Executor = &LoadData{}
 )
 
-func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, assignFlag []bool, t table.Table, offset int, onDuplicateUpdate bool) error {
+// constraintChecker checks the written rows with the check constraints of the tables,
+// and fills the values of the generated columns.
+// The expressions of a table are built once and cached for the statement.
+type constraintChecker struct {
+	ctx       context.Context
+	checks    map[int64][]*tableCheck
+	generated map[int64]*tableGenerated
+}
+
+type tableCheck struct {
+	name string
+	expr expression.Expression
+}
+
+// tableGenerated is the writable generated columns of a table and their expressions.
+type tableGenerated struct {
+	cols  []*table.Column
+	exprs []expression.Expression
+}
+
+func newConstraintChecker(ctx context.Context) *constraintChecker {
+	return &constraintChecker{
+		ctx:       ctx,
+		checks:    make(map[int64][]*tableCheck),
+		generated: make(map[int64]*tableGenerated),
+	}
+}
+
+func (c *constraintChecker) getGenerated(t table.Table) (*tableGenerated, error) {
+	tblInfo := t.Meta()
+	if gen, ok := c.generated[tblInfo.ID]; ok {
+		return gen, nil
+	}
+	gen := &tableGenerated{}
+	// A generated column can only refer to the columns before it, so the writable columns
+	// can be evaluated in order.
+	for _, col := range t.WritableCols() {
+		if !col.IsGenerated() {
+			continue
+		}
+		expr, err := plan.RewriteTableExpr(c.ctx, col.GeneratedExprString, tblInfo)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		gen.cols = append(gen.cols, col)
+		gen.exprs = append(gen.exprs, expr)
+	}
+	c.generated[tblInfo.ID] = gen
+	return gen, nil
+}
+
+// fillGenerated evaluates the generated columns of the table on the row.
+// The row is extended to contain all the writable columns if the table has non-public generated columns,
+// so the stored generated column being added is also written.
+func (c *constraintChecker) fillGenerated(t table.Table, row []types.Datum) ([]types.Datum, error) {
+	gen, err := c.getGenerated(t)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(gen.cols) == 0 {
+		return row, nil
+	}
+	if writableLen := len(t.WritableCols()); len(row) < writableLen {
+		newRow := make([]types.Datum, writableLen)
+		copy(newRow, row)
+		row = newRow
+	}
+	for i, col := range gen.cols {
+		val, err := gen.exprs[i].Eval(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row[col.Offset], err = table.CastValue(c.ctx, val, col.ToInfo())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return row, nil
+}
+
+func (c *constraintChecker) getChecks(t table.Table) ([]*tableCheck, error) {
+	tblInfo := t.Meta()
+	if checks, ok := c.checks[tblInfo.ID]; ok {
+		return checks, nil
+	}
+	var checks []*tableCheck
+	for _, ck := range tblInfo.Checks {
+		// The check constraint in write only state must be checked, because it is being
+		// validated on the existing rows.
+		if ck.State == model.StateNone {
+			continue
+		}
+		expr, err := plan.RewriteTableExpr(c.ctx, ck.ExprString, tblInfo)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		checks = append(checks, &tableCheck{name: ck.Name.O, expr: expr})
+	}
+	c.checks[tblInfo.ID] = checks
+	return checks, nil
+}
+
+// checkRow returns an error if the row violates any check constraint of the table.
+// A constraint is violated only if the expression is evaluated to false, NULL is not a violation.
+func (c *constraintChecker) checkRow(t table.Table, row []types.Datum) error {
+	if len(t.Meta().Checks) == 0 {
+		return nil
+	}
+	checks, err := c.getChecks(t)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sc := c.ctx.GetSessionVars().StmtCtx
+	for _, ck := range checks {
+		val, err := ck.expr.Eval(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if val.IsNull() {
+			continue
+		}
+		b, err := val.ToBool(sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if b == 0 {
+			return table.ErrCheckConstraintViolated.GenByArgs(ck.name)
+		}
+	}
+	return nil
+}
+
+func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, assignFlag []bool, t table.Table, offset int,
+	onDuplicateUpdate bool, checker *constraintChecker) error {
 	cols := t.Cols()
 	touched := make(map[int]bool, len(cols))
 	assignExists := false
@@ -57,6 +192,9 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 
 		colIndex := i - offset
 		col := cols[colIndex]
+		if col.IsGenerated() {
+			return table.ErrBadGeneratedColumn.GenByArgs(col.Name.O, t.Meta().Name.O)
+		}
 		if col.IsPKHandleColumn(t.Meta()) {
 			newHandle = newData[i]
 		}
@@ -85,7 +223,22 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 		return errors.Trace(err)
 	}
 
-	if err := table.CheckNotNull(cols, newData); err != nil {
+	newData, err := checker.fillGenerated(t, newData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, col := range t.WritableCols() {
+		if col.IsGenerated() && !col.IsVirtualGenerated() {
+			// The stored generated columns are compared and updated with the assigned ones.
+			touched[col.Offset] = true
+		}
+	}
+
+	if err = table.CheckNotNull(cols, newData); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err = checker.checkRow(t, newData); err != nil {
 		return errors.Trace(err)
 	}
 
@@ -113,7 +266,6 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 		return nil
 	}
 
-	var err error
 	if !newHandle.IsNull() {
 		err = t.RemoveRecord(ctx, h, oldData)
 		if err != nil {
@@ -586,6 +738,8 @@ type InsertValues struct {
 	Lists     [][]expression.Expression
 	Setlist   []*expression.Assignment
 	IsPrepare bool
+
+	checker *constraintChecker
 }
 
 // InsertExec represents an insert executor.
@@ -834,9 +988,18 @@ func (e *InsertValues) fillRowData(cols []*table.Column, vals []types.Datum, ign
 	if err = table.CastValues(e.ctx, row, cols, ignoreErr); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if e.checker == nil {
+		e.checker = newConstraintChecker(e.ctx)
+	}
+	if row, err = e.checker.fillGenerated(e.Table, row); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if err = table.CheckNotNull(e.Table.Cols(), row); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if err = e.checker.checkRow(e.Table, row); err != nil {
+		return nil, errors.Trace(err)
+	}
 	return row, nil
 }
 
@@ -855,6 +1018,10 @@ func (e *InsertValues) initDefaultValues(row []types.Datum, marked map[int]struc
 	var defaultValueCols []*table.Column
 	sc := e.ctx.GetSessionVars().StmtCtx
 	for i, c := range e.Table.Cols() {
+		if c.IsGenerated() {
+			// The value of the generated column is evaluated after the other columns are filled.
+			continue
+		}
 		// It's used for retry.
 		if mysql.HasAutoIncrementFlag(c.Flag) && row[i].IsNull() &&
 			e.ctx.GetSessionVars().RetryInfo.Retrying {
@@ -928,7 +1095,7 @@ func (e *InsertExec) onDuplicateUpdate(row []types.Datum, h int64, cols map[int]
 	e.ctx.GetSessionVars().CurrInsertValues = row
 	// evaluate assignment
 	newData := make([]types.Datum, len(data))
-	for i, c := range row {
+	for i, c := range row[:len(data)] {
 		asgn, ok := cols[i]
 		if !ok {
 			newData[i] = c
@@ -949,7 +1116,10 @@ func (e *InsertExec) onDuplicateUpdate(row []types.Datum, h int64, cols map[int]
 			assignFlag[i] = false
 		}
 	}
-	if err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, 0, true); err != nil {
+	if e.checker == nil {
+		e.checker = newConstraintChecker(e.ctx)
+	}
+	if err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, 0, true, e.checker); err != nil {
 		return errors.Trace(err)
 	}
 	return nil
@@ -1093,6 +1263,7 @@ type UpdateExec struct {
 	newRowsData [][]types.Datum // The new values to be set.
 	fetched     bool
 	cursor      int
+	checker     *constraintChecker
 }
 
 // Schema implements the Executor Schema interface.
@@ -1120,6 +1291,9 @@ func (e *UpdateExec) Next() (*Row, error) {
 	if e.updatedRowKeys == nil {
 		e.updatedRowKeys = make(map[table.Table]map[int64]struct{})
 	}
+	if e.checker == nil {
+		e.checker = newConstraintChecker(e.ctx)
+	}
 	row := e.rows[e.cursor]
 	newData := e.newRowsData[e.cursor]
 	for _, entry := range row.RowKeys {
@@ -1137,7 +1311,7 @@ func (e *UpdateExec) Next() (*Row, error) {
 			continue
 		}
 		// Update row
-		err1 := updateRecord(e.ctx, handle, oldData, newTableData, assignFlag, tbl, offset, false)
+		err1 := updateRecord(e.ctx, handle, oldData, newTableData, assignFlag, tbl, offset, false, e.checker)
 		if err1 != nil {
 			return nil, errors.Trace(err1)
 		}
@@ -0,0 +1,71 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"unsafe"
+
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var datumSize = int64(unsafe.Sizeof(types.Datum{}))
+
+// newMemTracker creates a memory tracker for an executor which buffers rows,
+// it's attached to the memory tracker of the statement.
+func newMemTracker(ctx context.Context, label string) *memory.Tracker {
+	tracker := memory.NewTracker(label, -1)
+	tracker.AttachTo(ctx.GetSessionVars().StmtCtx.MemTracker)
+	return tracker
+}
+
+// checkMemQuota does the action of tidb_mem_oom_action if the statement exceeds tidb_mem_quota_query.
+// It returns true if the executor should spill its rows to disk, the executors which can't spill pass
+// false for canSpill, they are cancelled instead.
+func checkMemQuota(ctx context.Context, tracker *memory.Tracker, canSpill bool) (spill bool, err error) {
+	exceeded := tracker.Exceeded()
+	if exceeded == nil {
+		return false, nil
+	}
+	sessVars := ctx.GetSessionVars()
+	switch sessVars.MemOOMAction {
+	case variable.OOMActionLog:
+		if exceeded.MarkExceeded() {
+			log.Warnf("[%d] memory exceeds quota, %s", sessVars.ConnectionID, exceeded)
+		}
+		return false, nil
+	case variable.OOMActionSpill:
+		if canSpill {
+			log.Infof("[%d] memory exceeds quota, spill to disk, %s", sessVars.ConnectionID, exceeded)
+			return true, nil
+		}
+	}
+	log.Warnf("[%d] memory exceeds quota, cancel the statement, %s", sessVars.ConnectionID, exceeded)
+	return false, ErrMemExceedThreshold.GenByArgs(exceeded.BytesLimit(), variable.TiDBMemQuotaQuery, "The statement is cancelled.")
+}
+
+// datumsMemUsage estimates the memory used by datums.
+func datumsMemUsage(datums []types.Datum) int64 {
+	usage := datumSize * int64(len(datums))
+	for i := range datums {
+		switch datums[i].Kind() {
+		case types.KindString, types.KindBytes:
+			usage += int64(len(datums[i].GetBytes()))
+		}
+	}
+	return usage
+}
@@ -31,10 +31,44 @@ var (
 			Name:      "statement_node_total",
 			Help:      "Counter of StmtNode.",
 		}, []string{"type"})
+	executorRowsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "rows_total",
+			Help:      "Counter of rows returned by executors.",
+		}, []string{"type"})
+	executorDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "duration_seconds",
+			Help:      "Bucketed histogram of time spent in an executor from its first Next call to its Close call.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 20),
+		}, []string{"type"})
+	hashJoinBuildRowsHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "hash_join_build_rows",
+			Help:      "Bucketed histogram of rows in the hash table of HashJoinExec.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 12),
+		})
+	preparedStmtCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "plan_cache_total",
+			Help:      "Counter of prepared statements reused (hit) or prepared again for a schema change (miss) when executed.",
+		}, []string{"type"})
 )
 
 func init() {
 	prometheus.MustRegister(stmtNodeCounter)
+	prometheus.MustRegister(executorRowsCounter)
+	prometheus.MustRegister(executorDurationHistogram)
+	prometheus.MustRegister(hashJoinBuildRowsHistogram)
+	prometheus.MustRegister(preparedStmtCacheCounter)
 }
 
 func stmtCount(node ast.StmtNode, p plan.Plan) {
@@ -64,6 +98,10 @@ const (
 	CreateTable = "CreateTable"
 	// CreateUser represents create user statements.
 	CreateUser = "CreateUser"
+	// AlterUser represents alter user statements.
+	AlterUser = "AlterUser"
+	// DropUser represents drop user statements.
+	DropUser = "DropUser"
 	// Delete represents delete statements.
 	Delete = "Delete"
 	// DropDatabase represents drop database statements.
@@ -74,6 +112,8 @@ const (
 	DropTable = "DropTable"
 	// Explain represents explain statements.
 	Explain = "Explain"
+	// Grant represents grant statements.
+	Grant = "Grant"
 	// Replace represents replace statements.
 	Replace = "Replace"
 	// Insert represents insert statements.
@@ -86,17 +126,35 @@ const (
 	Set = "Set"
 	// Show represents show statements.
 	Show = "Show"
+	// Trace represents trace statements.
+	Trace = "Trace"
 	// TruncateTable represents truncate table statements.
 	TruncateTable = "TruncateTable"
 	// Update represents update statements.
 	Update = "Update"
 )
 
-// StatementLabel generates a label for a statement.
+// StatementLabel generates a label for a statement, the labels of the select, delete and update statements
+// have the attributes of their plans.
 func StatementLabel(node ast.StmtNode, p plan.Plan) string {
+	switch x := node.(type) {
+	case *ast.DeleteStmt:
+		return getDeleteStmtLabel(x, p)
+	case *ast.SelectStmt:
+		return getSelectStmtLabel(x, p)
+	case *ast.UpdateStmt:
+		return getUpdateStmtLabel(x, p)
+	}
+	return StatementClass(node)
+}
+
+// StatementClass returns the class of a statement, it's the statement label without the plan attributes.
+func StatementClass(node ast.StmtNode) string {
 	switch x := node.(type) {
 	case *ast.AlterTableStmt:
 		return AlterTable
+	case *ast.AlterUserStmt:
+		return AlterUser
 	case *ast.AnalyzeTableStmt:
 		return AnalyzeTable
 	case *ast.BeginStmt:
@@ -112,15 +170,19 @@ func StatementLabel(node ast.StmtNode, p plan.Plan) string {
 	case *ast.CreateUserStmt:
 		return CreateUser
 	case *ast.DeleteStmt:
-		return getDeleteStmtLabel(x, p)
+		return Delete
 	case *ast.DropDatabaseStmt:
 		return DropDatabase
 	case *ast.DropIndexStmt:
 		return DropIndex
 	case *ast.DropTableStmt:
 		return DropTable
+	case *ast.DropUserStmt:
+		return DropUser
 	case *ast.ExplainStmt:
 		return Explain
+	case *ast.GrantStmt:
+		return Grant
 	case *ast.InsertStmt:
 		if x.IsReplace {
 			return Replace
@@ -131,15 +193,17 @@ func StatementLabel(node ast.StmtNode, p plan.Plan) string {
 	case *ast.RollbackStmt:
 		return RollBack
 	case *ast.SelectStmt:
-		return getSelectStmtLabel(x, p)
+		return Select
 	case *ast.SetStmt, *ast.SetPwdStmt:
 		return Set
 	case *ast.ShowStmt:
 		return Show
+	case *ast.TraceStmt:
+		return Trace
 	case *ast.TruncateTableStmt:
 		return TruncateTable
 	case *ast.UpdateStmt:
-		return getUpdateStmtLabel(x, p)
+		return Update
 	case *ast.DeallocateStmt, *ast.ExecuteStmt, *ast.PrepareStmt, *ast.UseStmt:
 		return IGNORE
 	}
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/util/sqlexec"
@@ -79,6 +80,7 @@ type PrepareExec struct {
 
 	ID         uint32
 	ParamCount int
+	Fields     []*ast.ResultField
 	Err        error
 }
 
@@ -155,6 +157,8 @@ func (e *PrepareExec) DoPrepare() {
 		return
 	}
 
+	e.Fields = prepareResultFields(e.Ctx, stmt)
+
 	if e.ID == 0 {
 		e.ID = vars.GetNextPreparedStmtID()
 	}
@@ -164,6 +168,29 @@ func (e *PrepareExec) DoPrepare() {
 	vars.PreparedStmts[e.ID] = prepared
 }
 
+// prepareResultFields returns the result fields of a prepared query, the types of the
+// parameter markers are unknown until execution, so the result types may be less accurate.
+func prepareResultFields(ctx context.Context, stmt ast.StmtNode) []*ast.ResultField {
+	rs, ok := stmt.(ast.ResultSetNode)
+	if !ok {
+		return nil
+	}
+	// The fields are only a hint for the client, a failure of the type inference doesn't fail the prepare.
+	if err := plan.InferType(ctx.GetSessionVars().StmtCtx, stmt); err != nil {
+		return nil
+	}
+	var fields []*ast.ResultField
+	for _, rf := range rs.GetResultFields() {
+		field := *rf
+		field.Column = &model.ColumnInfo{
+			FieldType: *rf.Expr.GetType(),
+			Name:      rf.ColumnAsName,
+		}
+		fields = append(fields, &field)
+	}
+	return fields
+}
+
 // ExecuteExec represents an EXECUTE executor.
 // It cannot be executed by itself, all it needs to do is to build
 // another Executor from a prepared statement.
@@ -221,7 +248,10 @@ func (e *ExecuteExec) Build() error {
 		prepared.Params[i].SetDatum(val)
 	}
 
-	if prepared.SchemaVersion != e.IS.SchemaMetaVersion() {
+	if prepared.SchemaVersion == e.IS.SchemaMetaVersion() {
+		preparedStmtCacheCounter.WithLabelValues("hit").Inc()
+	} else {
+		preparedStmtCacheCounter.WithLabelValues("miss").Inc()
 		// If the schema version has changed we need to prepare it again,
 		// if this time it failed, the real reason for the error is schema changed.
 		err := plan.PrepareStmt(e.IS, e.Ctx, prepared.Stmt)
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -27,9 +28,11 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -99,6 +102,8 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowIndex()
 	case ast.ShowProcedureStatus:
 		return e.fetchShowProcedureStatus()
+	case ast.ShowProcessList:
+		return e.fetchShowProcessList()
 	case ast.ShowStatus:
 		return e.fetchShowStatus()
 	case ast.ShowTables:
@@ -109,7 +114,7 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowTriggers()
 	case ast.ShowVariables:
 		return e.fetchShowVariables()
-	case ast.ShowWarnings, ast.ShowProcessList, ast.ShowEvents:
+	case ast.ShowWarnings, ast.ShowEvents:
 		// empty result
 	}
 	return nil
@@ -322,7 +327,7 @@ func (e *ShowExec) fetchShowVariables() error {
 }
 
 func (e *ShowExec) fetchShowStatus() error {
-	statusVars, err := variable.GetStatusVars()
+	statusVars, err := variable.GetStatusVars(e.ctx.GetSessionVars())
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -356,7 +361,16 @@ func (e *ShowExec) fetchShowCreateTable() error {
 	var pkCol *table.Column
 	for i, col := range tb.Cols() {
 		buf.WriteString(fmt.Sprintf("  `%s` %s", col.Name.O, col.GetTypeDesc()))
-		if mysql.HasAutoIncrementFlag(col.Flag) {
+		if col.IsGenerated() {
+			genKind := "VIRTUAL"
+			if col.GeneratedStored {
+				genKind = "STORED"
+			}
+			buf.WriteString(fmt.Sprintf(" GENERATED ALWAYS AS (%s) %s", col.GeneratedExprString, genKind))
+			if mysql.HasNotNullFlag(col.Flag) {
+				buf.WriteString(" NOT NULL")
+			}
+		} else if mysql.HasAutoIncrementFlag(col.Flag) {
 			buf.WriteString(" NOT NULL AUTO_INCREMENT")
 		} else {
 			if mysql.HasNotNullFlag(col.Flag) {
@@ -452,12 +466,23 @@ func (e *ShowExec) fetchShowCreateTable() error {
 			buf.WriteString(fmt.Sprintf(" ON UPDATE %s", ast.ReferOptionType(fk.OnUpdate)))
 		}
 	}
+
+	for _, ck := range tb.Meta().Checks {
+		if ck.State != model.StatePublic {
+			continue
+		}
+		buf.WriteString(",\n")
+		buf.WriteString(fmt.Sprintf("  CONSTRAINT `%s` CHECK (%s)", ck.Name.O, ck.ExprString))
+	}
 	buf.WriteString("\n")
 
 	buf.WriteString(") ENGINE=InnoDB")
 	if s := tb.Meta().Charset; len(s) > 0 {
 		buf.WriteString(fmt.Sprintf(" DEFAULT CHARSET=%s", s))
 	}
+	if s := tb.Meta().Collate; len(s) > 0 {
+		buf.WriteString(fmt.Sprintf(" COLLATE=%s", s))
+	}
 
 	if tb.Meta().AutoIncID > 0 {
 		buf.WriteString(fmt.Sprintf(" AUTO_INCREMENT=%d", tb.Meta().AutoIncID))
@@ -535,6 +560,44 @@ func (e *ShowExec) fetchShowProcedureStatus() error {
 	return nil
 }
 
+type processInfoSlice []util.ProcessInfo
+
+func (s processInfoSlice) Len() int           { return len(s) }
+func (s processInfoSlice) Less(i, j int) bool { return s[i].ID < s[j].ID }
+func (s processInfoSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// showProcessListInfoLen is the max length of Info shown by SHOW PROCESSLIST without FULL.
+const showProcessListInfoLen = 100
+
+func (e *ShowExec) fetchShowProcessList() error {
+	sm := sessionctx.GetSessionManager(e.ctx)
+	if sm == nil {
+		return nil
+	}
+	pl := sm.ShowProcessList()
+	sort.Sort(processInfoSlice(pl))
+	for _, pi := range pl {
+		var db, info interface{}
+		if pi.DB != "" {
+			db = pi.DB
+		}
+		if pi.Info != "" {
+			if !e.Full && len(pi.Info) > showProcessListInfoLen {
+				info = pi.Info[:showProcessListInfoLen]
+			} else {
+				info = pi.Info
+			}
+		}
+		var t int64
+		if !pi.Time.IsZero() {
+			t = int64(time.Since(pi.Time) / time.Second)
+		}
+		data := types.MakeDatums(pi.ID, pi.User, pi.Host, db, pi.Command, t, pi.State, info, pi.Mem)
+		e.rows = append(e.rows, &Row{Data: data})
+	}
+	return nil
+}
+
 func (e *ShowExec) getTable() (table.Table, error) {
 	if e.Table == nil {
 		return nil, errors.New("table not found")
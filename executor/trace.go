@@ -0,0 +1,179 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/tracing"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// TraceExec represents a trace executor, it executes the statement and returns the spans of the
+// statement as rows, the rows of the statement are discarded.
+type TraceExec struct {
+	ctx      context.Context
+	schema   *expression.Schema
+	stmtExec Executor
+	// span is the root span of the trace.
+	span   *tracing.Span
+	rows   []*Row
+	cursor int
+}
+
+// Schema implements the Executor Schema interface.
+func (e *TraceExec) Schema() *expression.Schema {
+	return e.schema
+}
+
+// run executes the traced statement, it's called before the transaction is committed, so the rows are
+// returned after the spans of the transaction commit are recorded.
+func (e *TraceExec) run() error {
+	defer e.stmtExec.Close()
+	for {
+		row, err := e.stmtExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+	}
+}
+
+// Next implements the Executor Next interface.
+func (e *TraceExec) Next() (*Row, error) {
+	if e.rows == nil {
+		e.prepareTraceRows()
+	}
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+// prepareTraceRows formats the span tree, the operations are indented by their depth in the tree.
+func (e *TraceExec) prepareTraceRows() {
+	e.rows = make([]*Row, 0, 16)
+	e.span.Walk(func(span *tracing.Span, depth int) {
+		op := span.Operation()
+		if depth > 0 {
+			op = strings.Repeat("  ", depth-1) + "└─" + op
+		}
+		e.rows = append(e.rows, &Row{Data: types.MakeDatums(
+			op,
+			span.StartTime().Format("15:04:05.000000"),
+			span.Duration().String(),
+		)})
+	})
+}
+
+// Close implements the Executor Close interface.
+func (e *TraceExec) Close() error {
+	e.rows = nil
+	return nil
+}
+
+// tracedExec wraps an executor to trace its Next calls and to collect its metrics. The span is started at the
+// first Next call and its duration is the time spent in the Next calls, it's nil if the statement isn't traced.
+// The Next calls are timed only if the statement is traced, the metrics observe the time of a whole run.
+type tracedExec struct {
+	Executor
+	operation string
+	// stmtSpan is the root span of the statement, it's the parent span if the executor doesn't have a parent.
+	stmtSpan *tracing.Span
+	parent   *tracedExec
+	span     *tracing.Span
+	elapsed  time.Duration
+
+	// The rows and the start time of the current run, they are observed and reset when the executor is closed,
+	// an executor may run again after it's closed, e.g. the inner executor of ApplyJoinExec.
+	running  bool
+	runRows  int
+	runStart time.Time
+}
+
+func newTracedExec(e Executor, stmtSpan *tracing.Span) *tracedExec {
+	return &tracedExec{
+		Executor:  e,
+		operation: strings.TrimPrefix(fmt.Sprintf("%T", e), "*"),
+		stmtSpan:  stmtSpan,
+	}
+}
+
+// Next implements the Executor Next interface.
+func (e *tracedExec) Next() (*Row, error) {
+	if e.span == nil {
+		parentSpan := e.stmtSpan
+		if e.parent != nil && e.parent.span != nil {
+			parentSpan = e.parent.span
+		}
+		e.span = parentSpan.StartChild(e.operation)
+	}
+	if !e.running {
+		e.running = true
+		e.runStart = time.Now()
+	}
+	var row *Row
+	var err error
+	if e.span != nil {
+		startTime := time.Now()
+		row, err = e.Executor.Next()
+		e.elapsed += time.Since(startTime)
+	} else {
+		row, err = e.Executor.Next()
+	}
+	if row != nil {
+		e.runRows++
+	}
+	return row, errors.Trace(err)
+}
+
+// Close implements the Executor Close interface.
+func (e *tracedExec) Close() error {
+	e.span.FinishWithDuration(e.elapsed)
+	if e.running {
+		label := strings.TrimPrefix(e.operation, "executor.")
+		executorRowsCounter.WithLabelValues(label).Add(float64(e.runRows))
+		executorDurationHistogram.WithLabelValues(label).Observe(time.Since(e.runStart).Seconds())
+		e.running = false
+		e.runRows = 0
+	}
+	return errors.Trace(e.Executor.Close())
+}
+
+// isTraceable checks if the executor can be wrapped by tracedExec, the executors that are type asserted
+// after they are built can't be wrapped.
+func isTraceable(e Executor) bool {
+	switch e.(type) {
+	case *ExecuteExec, *AnalyzeExec, *TraceExec:
+		return false
+	}
+	return true
+}
+
+// unwrapTracedExec returns the executor wrapped by tracedExec.
+func unwrapTracedExec(e Executor) Executor {
+	if te, ok := e.(*tracedExec); ok {
+		return te.Executor
+	}
+	return e
+}
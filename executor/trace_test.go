@@ -0,0 +1,133 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/tracing"
+)
+
+// traceOperations returns the operation column of the TRACE statement.
+func traceOperations(c *C, tk *testkit.TestKit, sql string) []string {
+	var ops []string
+	for _, row := range tk.MustQuery(sql).Rows() {
+		c.Assert(row, HasLen, 3)
+		ops = append(ops, row[0].(string))
+	}
+	return ops
+}
+
+func (s *testSuite) TestTrace(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("insert t values (1, 1), (2, 2)")
+
+	ops := traceOperations(c, tk, "trace select b from t where b > 1")
+	c.Assert(ops, DeepEquals, []string{
+		"session.Execute",
+		"└─session.ParseSQL",
+		"└─plan.Optimize",
+		"└─plan.Optimize",
+		"└─executor.XSelectTableExec",
+		"└─distsql.Select",
+		"  └─tikv.copTask",
+	})
+	// The rows of the traced statement are discarded.
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("2"))
+
+	// The transaction commit is traced.
+	ops = traceOperations(c, tk, "trace update t set b = 3 where a = 2")
+	c.Assert(ops, DeepEquals, []string{
+		"session.Execute",
+		"└─session.ParseSQL",
+		"└─plan.Optimize",
+		"└─plan.Optimize",
+		"└─executor.UpdateExec",
+		"  └─executor.XSelectTableExec",
+		"└─distsql.Select",
+		"  └─tikv.copTask",
+		"└─tikv.2pc",
+		"  └─tikv.prewrite",
+		"  └─tikv.commit",
+	})
+	tk.MustQuery("select b from t where a = 2").Check(testkit.Rows("3"))
+
+	// The statement isn't traced by the session if it's prepared.
+	tk.MustExec("prepare stmt from 'trace insert t values (3, 3)'")
+	ops = traceOperations(c, tk, "execute stmt")
+	c.Assert(ops, DeepEquals, []string{
+		"trace",
+		"└─executor.InsertExec",
+		"└─tikv.2pc",
+		"  └─tikv.prewrite",
+		"  └─tikv.commit",
+	})
+	tk.MustQuery("select b from t where a = 3").Check(testkit.Rows("3"))
+
+	_, err := tk.Exec("trace insert t values (3, 3)")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestTraceExport(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	dir, err := ioutil.TempDir("", "trace")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trace.log")
+	c.Assert(tracing.SetExportFile(path), IsNil)
+	defer tracing.SetExportFile("")
+
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("insert t values (1, 1)")
+	tk.MustQuery("select b from t").Check(testkit.Rows("1"))
+	c.Assert(tracing.SetExportFile(""), IsNil)
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	c.Assert(len(lines), GreaterEqual, 5)
+	type span struct {
+		Operation string  `json:"operation"`
+		Children  []*span `json:"children"`
+	}
+	// The last trace is the select statement, its span is finished when the result set is closed.
+	var root span
+	c.Assert(json.Unmarshal([]byte(lines[len(lines)-1]), &root), IsNil)
+	c.Assert(root.Operation, Equals, "session.Execute")
+	var ops []string
+	for _, child := range root.Children {
+		ops = append(ops, child.Operation)
+	}
+	c.Assert(ops, DeepEquals, []string{"session.ParseSQL", "plan.Optimize", "executor.XSelectTableExec", "distsql.Select"})
+}
@@ -240,10 +240,11 @@ func (us *UnionScanExec) buildAndSortAddedRows(t table.Table, asName *model.CISt
 		} else {
 			newData = make([]types.Datum, 0, us.Src.Schema().Len())
 			var columns []*model.ColumnInfo
-			if t, ok := us.Src.(*XSelectTableExec); ok {
+			src := unwrapTracedExec(us.Src)
+			if t, ok := src.(*XSelectTableExec); ok {
 				columns = t.Columns
 			} else {
-				columns = us.Src.(*XSelectIndexExec).indexPlan.Columns
+				columns = src.(*XSelectIndexExec).indexPlan.Columns
 			}
 			for _, col := range columns {
 				newData = append(newData, data[col.Offset])
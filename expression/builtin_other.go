@@ -19,7 +19,6 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
-	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -197,18 +196,13 @@ func (b *builtinCastSig) eval(row []types.Datum) (d types.Datum, err error) {
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
-	switch b.tp.Tp {
-	// Parser has restricted this.
-	// TypeDouble is used during plan optimization.
-	case mysql.TypeString, mysql.TypeDuration, mysql.TypeDatetime,
-		mysql.TypeDate, mysql.TypeLonglong, mysql.TypeNewDecimal, mysql.TypeDouble:
-		d = args[0]
-		if d.IsNull() {
-			return
-		}
-		return d.ConvertTo(b.ctx.GetSessionVars().StmtCtx, b.tp)
+	// Parser has restricted the types of the cast functions in the statements.
+	// Other types are used during plan optimization, e.g. TypeDouble, and the column types of the generated columns.
+	d = args[0]
+	if d.IsNull() {
+		return
 	}
-	return d, errors.Errorf("unknown cast type - %v", b.tp)
+	return d.ConvertTo(b.ctx.GetSessionVars().StmtCtx, b.tp)
 }
 
 type setVarFunctionClass struct {
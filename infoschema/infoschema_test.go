@@ -253,6 +253,7 @@ func (*testSuite) TestInfoTables(c *C) {
 		"REFERENTIAL_CONSTRAINTS",
 		"SESSION_VARIABLES",
 		"PLUGINS",
+		"CHECK_CONSTRAINTS",
 	}
 	for _, t := range info_tables {
 		tb, err1 := is.TableByName(model.NewCIStr(infoschema.Name), model.NewCIStr(t))
@@ -14,8 +14,10 @@
 package infoschema
 
 import (
+	"encoding/hex"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -26,25 +28,35 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/types"
 )
 
 const (
-	tableSchemata      = "SCHEMATA"
-	tableTables        = "TABLES"
-	tableColumns       = "COLUMNS"
-	tableStatistics    = "STATISTICS"
-	tableCharacterSets = "CHARACTER_SETS"
-	tableCollations    = "COLLATIONS"
-	tableFiles         = "FILES"
-	catalogVal         = "def"
-	tableProfiling     = "PROFILING"
-	tablePartitions    = "PARTITIONS"
-	tableKeyColumm     = "KEY_COLUMN_USAGE"
-	tableReferConst    = "REFERENTIAL_CONSTRAINTS"
-	tableSessionVar    = "SESSION_VARIABLES"
-	tablePlugins       = "PLUGINS"
+	tableSchemata         = "SCHEMATA"
+	tableTables           = "TABLES"
+	tableColumns          = "COLUMNS"
+	tableStatistics       = "STATISTICS"
+	tableCharacterSets    = "CHARACTER_SETS"
+	tableCollations       = "COLLATIONS"
+	tableFiles            = "FILES"
+	catalogVal            = "def"
+	tableProfiling        = "PROFILING"
+	tablePartitions       = "PARTITIONS"
+	tableKeyColumm        = "KEY_COLUMN_USAGE"
+	tableReferConst       = "REFERENTIAL_CONSTRAINTS"
+	tableSessionVar       = "SESSION_VARIABLES"
+	tablePlugins          = "PLUGINS"
+	tableCheckConsts      = "CHECK_CONSTRAINTS"
+	tableSlowQuery        = "SLOW_QUERY"
+	tableTiDBIndexes      = "TIDB_INDEXES"
+	tableTiKVRegionStatus = "TIKV_REGION_STATUS"
+	tableTiDBHotRegions   = "TIDB_HOT_REGIONS"
+	tableTableStatistics  = "TABLE_STATISTICS"
+	tableIndexStatistics  = "INDEX_STATISTICS"
 )
 
 type columnInfo struct {
@@ -148,6 +160,7 @@ var columnsCols = []columnInfo{
 	{"EXTRA", mysql.TypeVarchar, 30, 0, nil, nil},
 	{"PRIVILEGES", mysql.TypeVarchar, 80, 0, nil, nil},
 	{"COLUMN_COMMENT", mysql.TypeVarchar, 1024, 0, nil, nil},
+	{"GENERATION_EXPRESSION", mysql.TypeBlob, 589779, 0, nil, nil},
 }
 
 var statisticsCols = []columnInfo{
@@ -236,6 +249,16 @@ var referConstCols = []columnInfo{
 	{"REFERENCED_TABLE_NAME", mysql.TypeVarchar, 64, mysql.NotNullFlag, nil, nil},
 }
 
+// See https://dev.mysql.com/doc/refman/8.0/en/check-constraints-table.html
+// TABLE_NAME is added because the names of check constraints are unique per table.
+var checkConstsCols = []columnInfo{
+	{"CONSTRAINT_CATALOG", mysql.TypeVarchar, 512, mysql.NotNullFlag, nil, nil},
+	{"CONSTRAINT_SCHEMA", mysql.TypeVarchar, 64, mysql.NotNullFlag, nil, nil},
+	{"CONSTRAINT_NAME", mysql.TypeVarchar, 64, mysql.NotNullFlag, nil, nil},
+	{"CHECK_CLAUSE", mysql.TypeLongBlob, types.UnspecifiedLength, mysql.NotNullFlag, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, mysql.NotNullFlag, nil, nil},
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/variables-table.html
 var sessionVarCols = []columnInfo{
 	{"VARIABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
@@ -286,6 +309,86 @@ var partitionsCols = []columnInfo{
 	{"TABLESPACE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
 }
 
+// slowQueryCols is the columns of the SLOW_QUERY table, the rows are parsed from the slow query log.
+var slowQueryCols = []columnInfo{
+	{"TIME", mysql.TypeDatetime, 26, 0, nil, nil},
+	{"CONN_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"USER", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"DIGEST", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"QUERY_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"PARSE_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"COMPILE_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"EXEC_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"COP_WAIT_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"PROCESS_KEYS", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"MEM_MAX", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"PLAN", mysql.TypeLongBlob, types.UnspecifiedLength, 0, nil, nil},
+	{"QUERY", mysql.TypeLongBlob, types.UnspecifiedLength, 0, nil, nil},
+}
+
+// tidbIndexesCols is the columns of the TIDB_INDEXES table, the indices which are not public are included.
+var tidbIndexesCols = []columnInfo{
+	{"TABLE_SCHEMA", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"NON_UNIQUE", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"KEY_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"SEQ_IN_INDEX", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"COLUMN_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"SUB_PART", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"INDEX_COMMENT", mysql.TypeVarchar, 2048, 0, nil, nil},
+	{"INDEX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"STATE", mysql.TypeVarchar, 64, 0, nil, nil},
+}
+
+// tikvRegionStatusCols is the columns of the TIKV_REGION_STATUS table, a region has a row for each table
+// or index whose keys are in the region, the keys are in hex.
+var tikvRegionStatusCols = []columnInfo{
+	{"REGION_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"START_KEY", mysql.TypeBlob, types.UnspecifiedLength, 0, nil, nil},
+	{"END_KEY", mysql.TypeBlob, types.UnspecifiedLength, 0, nil, nil},
+	{"TABLE_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"DB_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"IS_INDEX", mysql.TypeTiny, 1, 0, nil, nil},
+	{"INDEX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"INDEX_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"LEADER_STORE_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+}
+
+// tidbHotRegionsCols is the columns of the TIDB_HOT_REGIONS table, the requests are counted by this TiDB server.
+var tidbHotRegionsCols = []columnInfo{
+	{"TABLE_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"INDEX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"DB_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INDEX_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"REGION_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"TYPE", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"REQUEST_COUNT", mysql.TypeLonglong, 21, 0, nil, nil},
+}
+
+var tableStatisticsCols = []columnInfo{
+	{"TABLE_SCHEMA", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"ROWS_READ", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_READ_FULL_SCAN", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_READ_INDEX", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_INSERTED", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_UPDATED", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_DELETED", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_CHANGED", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_CHANGED_X_INDEXES", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"LOCK_WAITS", mysql.TypeLonglong, 21, 0, nil, nil},
+}
+
+var indexStatisticsCols = []columnInfo{
+	{"TABLE_SCHEMA", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"TABLE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INDEX_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"ROWS_READ", mysql.TypeLonglong, 21, 0, nil, nil},
+}
+
 func dataForCharacterSets() (records [][]types.Datum) {
 	records = append(records,
 		types.MakeDatums("ascii", "ascii_general_ci", "US ASCII", 1),
@@ -322,6 +425,47 @@ func dataForSessionVar(ctx context.Context) (records [][]types.Datum, err error)
 	return
 }
 
+func dataForSlowQuery(ctx context.Context) (records [][]types.Datum, err error) {
+	path := slowlog.File()
+	if path == "" {
+		return nil, nil
+	}
+	entries, err := slowlog.ParseFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The times are shown in the time zone of the session.
+	tz := ctx.GetSessionVars().TimeZone
+	if tz == nil {
+		tz = time.Local
+	}
+	for _, e := range entries {
+		t := types.Time{
+			Time: types.FromGoTime(e.Time.In(tz)),
+			Type: mysql.TypeDatetime,
+			Fsp:  types.MaxFsp,
+		}
+		record := types.MakeDatums(
+			t,                       // TIME
+			e.ConnID,                // CONN_ID
+			e.User,                  // USER
+			e.DB,                    // DB
+			e.Digest,                // DIGEST
+			e.QueryTime.Seconds(),   // QUERY_TIME
+			e.ParseTime.Seconds(),   // PARSE_TIME
+			e.CompileTime.Seconds(), // COMPILE_TIME
+			e.ExecTime.Seconds(),    // EXEC_TIME
+			e.CopWaitTime.Seconds(), // COP_WAIT_TIME
+			e.ProcessedKeys,         // PROCESS_KEYS
+			e.MemMax,                // MEM_MAX
+			e.Plan,                  // PLAN
+			e.Query,                 // QUERY
+		)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
 var filesCols = []columnInfo{
 	{"FILE_ID", mysql.TypeLonglong, 4, 0, nil, nil},
 	{"FILE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
@@ -458,7 +602,8 @@ func dataForColumnsInTable(schema *model.DBInfo, tbl *model.TableInfo) [][]types
 			columnDesc.Key,                    // COLUMN_KEY
 			columnDesc.Extra,                  // EXTRA
 			"select,insert,update,references", // PRIVILEGES
-			"", // COLUMN_COMMENT
+			"",                      // COLUMN_COMMENT
+			col.GeneratedExprString, // GENERATION_EXPRESSION
 		)
 		rows = append(rows, record)
 	}
@@ -544,20 +689,260 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 	return rows
 }
 
+func dataForTiDBIndexes(schemas []*model.DBInfo) [][]types.Datum {
+	rows := [][]types.Datum{}
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			if table.PKIsHandle {
+				for _, col := range table.Columns {
+					if mysql.HasPriKeyFlag(col.Flag) {
+						record := types.MakeDatums(
+							schema.Name.O,              // TABLE_SCHEMA
+							table.Name.O,               // TABLE_NAME
+							0,                          // NON_UNIQUE
+							"PRIMARY",                  // KEY_NAME
+							1,                          // SEQ_IN_INDEX
+							col.Name.O,                 // COLUMN_NAME
+							nil,                        // SUB_PART
+							"",                         // INDEX_COMMENT
+							0,                          // INDEX_ID
+							model.StatePublic.String(), // STATE
+						)
+						rows = append(rows, record)
+					}
+				}
+			}
+			for _, index := range table.Indices {
+				nonUnique := 1
+				if index.Unique {
+					nonUnique = 0
+				}
+				for i, key := range index.Columns {
+					subPart := interface{}(nil)
+					if key.Length != types.UnspecifiedLength {
+						subPart = key.Length
+					}
+					record := types.MakeDatums(
+						schema.Name.O,        // TABLE_SCHEMA
+						table.Name.O,         // TABLE_NAME
+						nonUnique,            // NON_UNIQUE
+						index.Name.O,         // KEY_NAME
+						i+1,                  // SEQ_IN_INDEX
+						key.Name.O,           // COLUMN_NAME
+						subPart,              // SUB_PART
+						index.Comment,        // INDEX_COMMENT
+						index.ID,             // INDEX_ID
+						index.State.String(), // STATE
+					)
+					rows = append(rows, record)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// tableKeyRange is the key range of the records or an index of a table.
+type tableKeyRange struct {
+	schema   *model.DBInfo
+	table    *model.TableInfo
+	index    *model.IndexInfo // nil for the records
+	startKey kv.Key
+	endKey   kv.Key
+}
+
+func buildTableKeyRanges(schemas []*model.DBInfo) []*tableKeyRange {
+	var ranges []*tableKeyRange
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			prefix := tablecodec.GenTableRecordPrefix(table.ID)
+			ranges = append(ranges, &tableKeyRange{schema: schema, table: table, startKey: prefix, endKey: prefix.PrefixNext()})
+			for _, index := range table.Indices {
+				prefix = tablecodec.EncodeTableIndexPrefix(table.ID, index.ID)
+				ranges = append(ranges, &tableKeyRange{schema: schema, table: table, index: index, startKey: prefix, endKey: prefix.PrefixNext()})
+			}
+		}
+	}
+	// The ranges don't overlap, they are sorted by the start keys to be searched by the regions.
+	sort.Sort(tableKeyRangesByStartKey(ranges))
+	return ranges
+}
+
+type tableKeyRangesByStartKey []*tableKeyRange
+
+func (s tableKeyRangesByStartKey) Len() int           { return len(s) }
+func (s tableKeyRangesByStartKey) Less(i, j int) bool { return s[i].startKey.Cmp(s[j].startKey) < 0 }
+func (s tableKeyRangesByStartKey) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// rangesInRegion returns the key ranges of the tables and the indices which overlap the region,
+// the ranges are sorted by buildTableKeyRanges.
+func rangesInRegion(ranges []*tableKeyRange, region *kv.RegionStatus) []*tableKeyRange {
+	i := sort.Search(len(ranges), func(i int) bool {
+		return region.StartKey.Cmp(ranges[i].endKey) < 0
+	})
+	j := i
+	for j < len(ranges) && (len(region.EndKey) == 0 || ranges[j].startKey.Cmp(region.EndKey) < 0) {
+		j++
+	}
+	return ranges[i:j]
+}
+
+// regionsOfStore returns all the regions of the store, it returns nil if the store doesn't consist of regions.
+func regionsOfStore(store kv.Storage) ([]*kv.RegionStatus, error) {
+	inspector, ok := store.(kv.RegionInspector)
+	if !ok {
+		return nil, nil
+	}
+	regions, err := inspector.Regions(nil, nil)
+	return regions, errors.Trace(err)
+}
+
+func dataForTiKVRegionStatus(store kv.Storage, schemas []*model.DBInfo) ([][]types.Datum, error) {
+	regions, err := regionsOfStore(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ranges := buildTableKeyRanges(schemas)
+	rows := [][]types.Datum{}
+	for _, region := range regions {
+		startKey, endKey := hex.EncodeToString(region.StartKey), hex.EncodeToString(region.EndKey)
+		inRegion := rangesInRegion(ranges, region)
+		if len(inRegion) == 0 {
+			record := types.MakeDatums(region.ID, startKey, endKey, nil, nil, nil, nil, nil, nil, region.LeaderStoreID)
+			rows = append(rows, record)
+			continue
+		}
+		for _, r := range inRegion {
+			isIndex, indexID, indexName := 0, interface{}(nil), interface{}(nil)
+			if r.index != nil {
+				isIndex, indexID, indexName = 1, r.index.ID, r.index.Name.O
+			}
+			record := types.MakeDatums(
+				region.ID,            // REGION_ID
+				startKey,             // START_KEY
+				endKey,               // END_KEY
+				r.table.ID,           // TABLE_ID
+				r.schema.Name.O,      // DB_NAME
+				r.table.Name.O,       // TABLE_NAME
+				isIndex,              // IS_INDEX
+				indexID,              // INDEX_ID
+				indexName,            // INDEX_NAME
+				region.LeaderStoreID, // LEADER_STORE_ID
+			)
+			rows = append(rows, record)
+		}
+	}
+	return rows, nil
+}
+
+func dataForTiDBHotRegions(store kv.Storage, schemas []*model.DBInfo) ([][]types.Datum, error) {
+	regions, err := regionsOfStore(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ranges := buildTableKeyRanges(schemas)
+	rows := [][]types.Datum{}
+	for _, region := range regions {
+		if region.ReadCount == 0 && region.WriteCount == 0 {
+			continue
+		}
+		for _, r := range rangesInRegion(ranges, region) {
+			indexID, indexName := interface{}(nil), interface{}(nil)
+			if r.index != nil {
+				indexID, indexName = r.index.ID, r.index.Name.O
+			}
+			for _, flow := range []struct {
+				tp    string
+				count uint64
+			}{{"read", region.ReadCount}, {"write", region.WriteCount}} {
+				if flow.count == 0 {
+					continue
+				}
+				record := types.MakeDatums(
+					r.table.ID,      // TABLE_ID
+					indexID,         // INDEX_ID
+					r.schema.Name.O, // DB_NAME
+					r.table.Name.O,  // TABLE_NAME
+					indexName,       // INDEX_NAME
+					region.ID,       // REGION_ID
+					flow.tp,         // TYPE
+					flow.count,      // REQUEST_COUNT
+				)
+				rows = append(rows, record)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// dataForTableStatistics returns the read and write counters of the tables since the server starts.
+func dataForTableStatistics(schemas []*model.DBInfo) [][]types.Datum {
+	rows := [][]types.Datum{}
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			stats := tables.GetTableStats(table.ID)
+			rowsRead := stats.RowsReadFullScan + stats.RowsReadIndex
+			rowsChanged := stats.RowsInserted + stats.RowsUpdated + stats.RowsDeleted
+			record := types.MakeDatums(
+				schema.Name.O,                                // TABLE_SCHEMA
+				table.Name.O,                                 // TABLE_NAME
+				rowsRead,                                     // ROWS_READ
+				stats.RowsReadFullScan,                       // ROWS_READ_FULL_SCAN
+				stats.RowsReadIndex,                          // ROWS_READ_INDEX
+				stats.RowsInserted,                           // ROWS_INSERTED
+				stats.RowsUpdated,                            // ROWS_UPDATED
+				stats.RowsDeleted,                            // ROWS_DELETED
+				rowsChanged,                                  // ROWS_CHANGED
+				stats.RowsChangedXIndexes,                    // ROWS_CHANGED_X_INDEXES
+				stats.LockWaits,                              // LOCK_WAITS
+			)
+			rows = append(rows, record)
+		}
+	}
+	return rows
+}
+
+// dataForIndexStatistics returns the number of rows read via the indices since the server starts,
+// the indices never read have 0 ROWS_READ.
+func dataForIndexStatistics(schemas []*model.DBInfo) [][]types.Datum {
+	rows := [][]types.Datum{}
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			for _, index := range table.Indices {
+				record := types.MakeDatums(
+					schema.Name.O, // TABLE_SCHEMA
+					table.Name.O,  // TABLE_NAME
+					index.Name.O,  // INDEX_NAME
+					tables.GetIndexRowsRead(table.ID, index.ID), // ROWS_READ
+				)
+				rows = append(rows, record)
+			}
+		}
+	}
+	return rows
+}
+
 var tableNameToColumns = map[string]([]columnInfo){
-	tableSchemata:      schemataCols,
-	tableTables:        tablesCols,
-	tableColumns:       columnsCols,
-	tableStatistics:    statisticsCols,
-	tableCharacterSets: charsetCols,
-	tableCollations:    collationsCols,
-	tableFiles:         filesCols,
-	tableProfiling:     profilingCols,
-	tablePartitions:    partitionsCols,
-	tableKeyColumm:     keyColumnUsageCols,
-	tableReferConst:    referConstCols,
-	tableSessionVar:    sessionVarCols,
-	tablePlugins:       pluginsCols,
+	tableSchemata:         schemataCols,
+	tableTables:           tablesCols,
+	tableColumns:          columnsCols,
+	tableStatistics:       statisticsCols,
+	tableCharacterSets:    charsetCols,
+	tableCollations:       collationsCols,
+	tableFiles:            filesCols,
+	tableProfiling:        profilingCols,
+	tablePartitions:       partitionsCols,
+	tableKeyColumm:        keyColumnUsageCols,
+	tableReferConst:       referConstCols,
+	tableSessionVar:       sessionVarCols,
+	tablePlugins:          pluginsCols,
+	tableCheckConsts:      checkConstsCols,
+	tableSlowQuery:        slowQueryCols,
+	tableTiDBIndexes:      tidbIndexesCols,
+	tableTiKVRegionStatus: tikvRegionStatusCols,
+	tableTiDBHotRegions:   tidbHotRegionsCols,
+	tableTableStatistics:  tableStatisticsCols,
+	tableIndexStatistics:  indexStatisticsCols,
 }
 
 func createInfoSchemaTable(handle *Handle, meta *model.TableInfo) *infoschemaTable {
@@ -594,6 +979,28 @@ func (s schemasSorter) Less(i, j int) bool {
 	return s[i].Name.L < s[j].Name.L
 }
 
+func dataForCheckConstraints(schemas []*model.DBInfo) [][]types.Datum {
+	rows := [][]types.Datum{}
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			for _, ck := range table.Checks {
+				if ck.State != model.StatePublic {
+					continue
+				}
+				record := types.MakeDatums(
+					catalogVal,    // CONSTRAINT_CATALOG
+					schema.Name.O, // CONSTRAINT_SCHEMA
+					ck.Name.O,     // CONSTRAINT_NAME
+					ck.ExprString, // CHECK_CLAUSE
+					table.Name.O,  // TABLE_NAME
+				)
+				rows = append(rows, record)
+			}
+		}
+	}
+	return rows
+}
+
 func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (fullRows [][]types.Datum, err error) {
 	is := it.handle.Get()
 	dbs := is.AllSchemas()
@@ -619,6 +1026,20 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableKeyColumm:
 	case tableReferConst:
 	case tablePlugins:
+	case tableCheckConsts:
+		fullRows = dataForCheckConstraints(dbs)
+	case tableSlowQuery:
+		fullRows, err = dataForSlowQuery(ctx)
+	case tableTiDBIndexes:
+		fullRows = dataForTiDBIndexes(dbs)
+	case tableTiKVRegionStatus:
+		fullRows, err = dataForTiKVRegionStatus(it.handle.store, dbs)
+	case tableTiDBHotRegions:
+		fullRows, err = dataForTiDBHotRegions(it.handle.store, dbs)
+	case tableTableStatistics:
+		fullRows = dataForTableStatistics(dbs)
+	case tableIndexStatistics:
+		fullRows = dataForIndexStatistics(dbs)
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
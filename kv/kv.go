@@ -15,6 +15,9 @@ package kv
 
 import (
 	"io"
+	"time"
+
+	"github.com/pingcap/tidb/util/tracing"
 )
 
 // Transaction options
@@ -33,6 +36,8 @@ const (
 	SkipCheckForWrite
 	// SchemaLeaseChecker is used for schema lease check.
 	SchemaLeaseChecker
+	// TraceSpan is the span that the transaction commit is traced in.
+	TraceSpan
 )
 
 // Those limits is enforced to make sure the transaction can be well handled by TiKV.
@@ -106,6 +111,9 @@ type Transaction interface {
 	IsReadOnly() bool
 	// StartTS returns the transaction start timestamp.
 	StartTS() uint64
+	// CommitTS returns the transaction commit timestamp, it's 0 if the transaction isn't committed
+	// or it doesn't write anything.
+	CommitTS() uint64
 	// Valid returns if the transaction is valid.
 	// A transaction become invalid after commit or rollback.
 	Valid() bool
@@ -146,6 +154,12 @@ type Request struct {
 	// ResponseIterator.Next is called. If concurrency is greater than 1, the request will be
 	// sent to multiple storage units concurrently.
 	Concurrency int
+	// If Deadline is not zero, the request is cancelled when the deadline is exceeded.
+	Deadline time.Time
+	// Span is the span that the request is traced in, it's nil if the request isn't traced.
+	Span *tracing.Span
+	// StartTs is the timestamp that the request reads at, the lock waits of the request are reported for it.
+	StartTs uint64
 }
 
 // Response represents the response returned from KV layer.
@@ -189,6 +203,69 @@ type Storage interface {
 	CurrentVersion() (Version, error)
 }
 
+// MvccVersion is a committed version of a key.
+type MvccVersion struct {
+	CommitTS uint64 `json:"commit_ts"`
+	// Value is nil if the key is deleted in the version.
+	Value []byte `json:"value"`
+}
+
+// MvccLock is the lock of a key, it's written by an uncommitted transaction.
+type MvccLock struct {
+	Primary Key    `json:"primary"`
+	StartTS uint64 `json:"start_ts"`
+	TTL     uint64 `json:"ttl"`
+}
+
+// MvccInfo contains the MVCC versions and the lock of a key.
+type MvccInfo struct {
+	Key Key `json:"key"`
+	// Versions are sorted by the commit timestamps in descending order.
+	Versions []*MvccVersion `json:"versions"`
+	Lock     *MvccLock      `json:"lock"`
+}
+
+// MvccInspector is implemented by the storages which can dump the MVCC information of the keys, it's used for debugging.
+type MvccInspector interface {
+	// MvccInfo returns all the MVCC versions and the lock of a key.
+	MvccInfo(key Key) (*MvccInfo, error)
+}
+
+// RegionStatus is the status of a region, a region is a continuous key range of the storage.
+type RegionStatus struct {
+	ID uint64
+	// StartKey and EndKey are the key range [StartKey, EndKey) of the region, an empty EndKey means
+	// there is no upper bound.
+	StartKey      Key
+	EndKey        Key
+	LeaderStoreID uint64
+	// ReadCount and WriteCount are the number of the read and write requests sent to the region by this client
+	// in the last minute.
+	ReadCount  uint64
+	WriteCount uint64
+}
+
+// RegionInspector is implemented by the storages which consist of regions, it's used for debugging.
+type RegionInspector interface {
+	// Regions returns the regions that cover the key range [startKey, endKey), an empty endKey means
+	// there is no upper bound.
+	Regions(startKey, endKey Key) ([]*RegionStatus, error)
+}
+
+// LockWaitObserver is notified when a transaction is blocked by the lock of another transaction.
+type LockWaitObserver interface {
+	// OnLockWait is called when the transaction of startTS encounters an unexpired lock of the transaction of lockTS.
+	OnLockWait(startTS, lockTS uint64, key, primary []byte, ttl uint64)
+	// OnLockWaitDone is called when the locks blocking the transaction of startTS are resolved.
+	OnLockWaitDone(startTS uint64)
+}
+
+// LockWaitObservable is implemented by the storages which can report the lock waits of the transactions.
+type LockWaitObservable interface {
+	// SetLockWaitObserver sets the observer of the lock waits, nil removes the observer.
+	SetLockWaitObserver(o LockWaitObserver)
+}
+
 // FnKeyCmp is the function for iterator the keys
 type FnKeyCmp func(key Key) bool
 
@@ -62,6 +62,10 @@ func (t *mockTxn) IsReadOnly() bool {
 func (t *mockTxn) StartTS() uint64 {
 	return uint64(0)
 }
+
+func (t *mockTxn) CommitTS() uint64 {
+	return uint64(0)
+}
 func (t *mockTxn) Get(k Key) ([]byte, error) {
 	return nil, nil
 }
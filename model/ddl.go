@@ -41,6 +41,14 @@ const (
 	ActionTruncateTable
 	ActionModifyColumn
 	ActionRenameTable
+	ActionAddCheck
+	ActionDropCheck
+	ActionAddPrimaryKey
+	ActionDropPrimaryKey
+	ActionRebaseAutoID
+	ActionSetTableComment
+	ActionModifyTableCharsetAndCollate
+	ActionRenameIndex
 )
 
 func (action ActionType) String() string {
@@ -71,6 +79,22 @@ func (action ActionType) String() string {
 		return "modify column"
 	case ActionRenameTable:
 		return "rename table"
+	case ActionAddCheck:
+		return "add check"
+	case ActionDropCheck:
+		return "drop check"
+	case ActionAddPrimaryKey:
+		return "add primary key"
+	case ActionDropPrimaryKey:
+		return "drop primary key"
+	case ActionRebaseAutoID:
+		return "rebase auto_increment ID"
+	case ActionSetTableComment:
+		return "set table comment"
+	case ActionModifyTableCharsetAndCollate:
+		return "modify table charset and collate"
+	case ActionRenameIndex:
+		return "rename index"
 	default:
 		return "none"
 	}
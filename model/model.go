@@ -65,6 +65,10 @@ type ColumnInfo struct {
 	types.FieldType `json:"type"`
 	State           SchemaState `json:"state"`
 	Comment         string      `json:"comment"`
+	// GeneratedExprString is the expression text of a generated column, it is empty for a normal column.
+	GeneratedExprString string `json:"generated_expr_string"`
+	// GeneratedStored is true if the generated column is stored in the row, or it is evaluated when read.
+	GeneratedStored bool `json:"generated_stored"`
 }
 
 // Clone clones ColumnInfo.
@@ -73,6 +77,16 @@ func (c *ColumnInfo) Clone() *ColumnInfo {
 	return &nc
 }
 
+// IsGenerated returns true if the column is a generated column.
+func (c *ColumnInfo) IsGenerated() bool {
+	return len(c.GeneratedExprString) != 0
+}
+
+// IsVirtualGenerated returns true if the column is a generated column which is not stored.
+func (c *ColumnInfo) IsVirtualGenerated() bool {
+	return c.IsGenerated() && !c.GeneratedStored
+}
+
 // TableInfo provides meta data describing a DB table.
 type TableInfo struct {
 	ID      int64  `json:"id"`
@@ -83,6 +97,7 @@ type TableInfo struct {
 	Columns     []*ColumnInfo `json:"cols"`
 	Indices     []*IndexInfo  `json:"index_info"`
 	ForeignKeys []*FKInfo     `json:"fk_info"`
+	Checks      []*CheckInfo  `json:"check_info"`
 	State       SchemaState   `json:"state"`
 	PKIsHandle  bool          `json:"pk_is_handle"`
 	Comment     string        `json:"comment"`
@@ -97,6 +112,7 @@ func (t *TableInfo) Clone() *TableInfo {
 	nt.Columns = make([]*ColumnInfo, len(t.Columns))
 	nt.Indices = make([]*IndexInfo, len(t.Indices))
 	nt.ForeignKeys = make([]*FKInfo, len(t.ForeignKeys))
+	nt.Checks = make([]*CheckInfo, len(t.Checks))
 
 	for i := range t.Columns {
 		nt.Columns[i] = t.Columns[i].Clone()
@@ -110,6 +126,10 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	for i := range t.Checks {
+		nt.Checks[i] = t.Checks[i].Clone()
+	}
+
 	return &nt
 }
 
@@ -205,6 +225,20 @@ func (fk *FKInfo) Clone() *FKInfo {
 	return &nfk
 }
 
+// CheckInfo provides meta data describing a check constraint.
+type CheckInfo struct {
+	ID         int64       `json:"id"`
+	Name       CIStr       `json:"check_name"`
+	ExprString string      `json:"expr"` // The original text of the check expression.
+	State      SchemaState `json:"state"`
+}
+
+// Clone clones CheckInfo.
+func (ck *CheckInfo) Clone() *CheckInfo {
+	nck := *ck
+	return &nck
+}
+
 // DBInfo provides meta data describing a DB.
 type DBInfo struct {
 	ID      int64        `json:"id"`      // Database ID
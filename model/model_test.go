@@ -67,6 +67,12 @@ func (*testSuite) TestClone(c *C) {
 		Columns:     []*ColumnInfo{column},
 		Indices:     []*IndexInfo{index},
 		ForeignKeys: []*FKInfo{},
+		Checks: []*CheckInfo{
+			{
+				ID:         2,
+				Name:       NewCIStr("t_chk_1"),
+				ExprString: "c > 0",
+			}},
 	}
 
 	dbInfo := &DBInfo{
@@ -28,6 +28,10 @@ const (
 	ErrHeader         byte = 0xff
 	EOFHeader         byte = 0xfe
 	LocalInFileHeader byte = 0xfb
+	// AuthSwitchHeader is the header of the AuthSwitchRequest packet, it's the same as the EOF header.
+	AuthSwitchHeader byte = 0xfe
+	// AuthMoreDataHeader is the header of the extra auth data packets sent by the server.
+	AuthMoreDataHeader byte = 0x01
 )
 
 // Server informations.
@@ -89,6 +93,14 @@ const (
 	ComResetConnection
 )
 
+// Cursor types of the COM_STMT_EXECUTE command.
+const (
+	CursorTypeNoCursor   byte = 0
+	CursorTypeReadOnly   byte = 1
+	CursorTypeForUpdate  byte = 2
+	CursorTypeScrollable byte = 4
+)
+
 // Client informations.
 const (
 	ClientLongPassword uint32 = 1 << iota
@@ -113,6 +125,11 @@ const (
 	ClientPluginAuth
 	ClientConnectAtts
 	ClientPluginAuthLenencClientData
+	ClientCanHandleExpiredPasswords
+	ClientSessionTrack
+	ClientDeprecateEOF
+	ClientOptionalResultsetMetadata
+	ClientZstdCompressionAlgorithm
 )
 
 // Cache type informations.
@@ -123,6 +140,12 @@ const (
 // Auth name informations.
 const (
 	AuthName = "mysql_native_password"
+	// AuthNativePassword is the default authentication plugin.
+	AuthNativePassword = AuthName
+	// AuthCachingSha2Password is the SHA-256 authentication plugin with a cache of verified passwords.
+	AuthCachingSha2Password = "caching_sha2_password"
+	// AuthSocket is the authentication plugin for unix socket connections.
+	AuthSocket = "auth_socket"
 )
 
 // MySQL database and tables.
@@ -882,4 +882,28 @@ const (
 	ErrMustChangePasswordLogin                                      = 1862
 	ErrRowInWrongPartition                                          = 1863
 	ErrErrorLast                                                    = 1863
+
+	// The statement timeout error code, it's added since MySQL 5.7.
+	ErrQueryTimeout = 3024
+
+	// Generated column error codes, they are added since MySQL 5.7.
+	ErrGeneratedColumnFunctionIsNotAllowed = 3102
+	ErrBadGeneratedColumn                  = 3105
+	ErrUnsupportedOnGeneratedColumn        = 3106
+	ErrGeneratedColumnNonPrior             = 3107
+	ErrDependentByGeneratedColumn          = 3108
+	ErrGeneratedColumnRefAutoInc           = 3109
+
+	// The audit plugin error code, it's added since MySQL 5.7.
+	ErrAuditAPIAbort = 3164
+
+	// The memory capacity error code, it's added since MySQL 5.7.
+	ErrCapacityExceeded = 3170
+
+	// Check constraint error codes, they are added since MySQL 8.0.
+	ErrCheckConstraintFunctionIsNotAllowed = 3814
+	ErrCheckConstraintViolated             = 3819
+	ErrCheckConstraintNotFound             = 3821
+	ErrCheckConstraintDupName              = 3822
+	ErrDependentByCheckConstraint          = 3959
 )
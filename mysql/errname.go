@@ -879,4 +879,18 @@ var MySQLErrName = map[uint16]string{
 	ErrAlterOperationNotSupportedReasonNotNull:               "cannot silently convert NULL values, as required in this SQLMODE",
 	ErrMustChangePasswordLogin:                               "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ErrRowInWrongPartition:                                   "Found a row in wrong partition %s",
+	ErrQueryTimeout:                                          "Query execution was interrupted, maximum statement execution time exceeded",
+	ErrGeneratedColumnFunctionIsNotAllowed:                   "Expression of generated column '%s' contains a disallowed function.",
+	ErrBadGeneratedColumn:                                    "The value specified for generated column '%s' in table '%s' is not allowed.",
+	ErrUnsupportedOnGeneratedColumn:                          "'%s' is not supported for generated columns.",
+	ErrGeneratedColumnNonPrior:                               "Generated column can refer only to generated columns defined prior to it.",
+	ErrDependentByGeneratedColumn:                            "Column '%s' has a generated column dependency.",
+	ErrGeneratedColumnRefAutoInc:                             "Generated column '%s' cannot refer to auto-increment column.",
+	ErrAuditAPIAbort:                                         "Aborted by Audit API ('%-.48s';%d).",
+	ErrCapacityExceeded:                                      "Memory capacity of %d bytes for '%s' exceeded. %s",
+	ErrCheckConstraintFunctionIsNotAllowed:                   "An expression of a check constraint '%-.64s' contains disallowed function.",
+	ErrCheckConstraintViolated:                               "Check constraint '%-.64s' is violated.",
+	ErrCheckConstraintNotFound:                               "Check constraint '%-.64s' is not found in the table.",
+	ErrCheckConstraintDupName:                                "Duplicate check constraint name '%-.192s'.",
+	ErrDependentByCheckConstraint:                            "Check constraint '%-.64s' uses column '%-.64s', hence column cannot be dropped or renamed.",
 }
@@ -0,0 +1,66 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Normalize returns the normalized form of a SQL statement, the statements with the same shape
+// have the same normalized form. The literals are replaced with "?", the comments are removed,
+// the tokens are lower cased and separated by single spaces.
+// For example, "SELECT * FROM t WHERE a = 1 /* comment */" is normalized to "select * from t where a = ?".
+func Normalize(sql string) string {
+	s := NewScanner(sql)
+	var buf bytes.Buffer
+	for {
+		tok, _, lit := s.scan()
+		if tok == 0 || tok == invalid || (tok == unicode.ReplacementChar && s.r.eof()) {
+			break
+		}
+		var str string
+		switch tok {
+		case stringLit, intLit, floatLit, decLit, hexLit, bitLit:
+			str = "?"
+		case quotedIdentifier:
+			str = "`" + strings.ToLower(strings.Replace(lit, "`", "``", -1)) + "`"
+		default:
+			if lit == "" && tok < unicode.MaxASCII {
+				lit = string(rune(tok))
+			}
+			str = strings.ToLower(lit)
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(str)
+	}
+	return buf.String()
+}
+
+// Digest returns the digest of a normalized SQL statement, it's the hex encoded SHA-256 hash.
+func Digest(normalized string) string {
+	hash := sha256.Sum256([]byte(normalized))
+	return fmt.Sprintf("%x", hash)
+}
+
+// NormalizeDigest returns the normalized form and the digest of a SQL statement.
+func NormalizeDigest(sql string) (normalized, digest string) {
+	normalized = Normalize(sql)
+	return normalized, Digest(normalized)
+}
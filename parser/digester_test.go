@@ -0,0 +1,51 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testDigesterSuite{})
+
+type testDigesterSuite struct {
+}
+
+func (s *testDigesterSuite) TestNormalize(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql        string
+		normalized string
+	}{
+		{"SELECT * FROM t WHERE a = 1", "select * from t where a = ?"},
+		{"select *  from t\n where a='abc' -- comment", "select * from t where a = ?"},
+		{"select /*+ MAX_EXECUTION_TIME(10) */ a from t where b > 1.5 and c < -3e2", "select a from t where b > ? and c < - ?"},
+		{"insert into `T` values (x'1f', b'01', 0x10, null)", "insert into `t` values ( ? , ? , ? , null )"},
+		{"select @a, @@global.autocommit, ?", "select @a , @@global.autocommit , ?"},
+		{"update t set a = a + 1 where b >= 2.00", "update t set a = a + ? where b >= ?"},
+		{"", ""},
+	}
+	for _, t := range tests {
+		c.Assert(Normalize(t.sql), Equals, t.normalized, Commentf("sql %s", t.sql))
+	}
+
+	n1, d1 := NormalizeDigest("select * from t where a = 1")
+	n2, d2 := NormalizeDigest("SELECT * FROM t WHERE a = 2 # another value")
+	c.Assert(n1, Equals, n2)
+	c.Assert(d1, Equals, d2)
+	c.Assert(d1, HasLen, 64)
+	_, d3 := NormalizeDigest("select * from t where b = 1")
+	c.Assert(d3, Not(Equals), d1)
+}
@@ -40,6 +40,8 @@ type Scanner struct {
 
 	// for scanning such kind of comment: /*! MySQL-specific code */
 	specialComment *specialCommentScanner
+	// lastToken is the last token returned by Lex, the optimizer hint comment is only recognized after SELECT.
+	lastToken int
 }
 
 type specialCommentScanner struct {
@@ -104,6 +106,7 @@ func (s *Scanner) Lex(v *yySymType) int {
 			tok = tok1
 		}
 	}
+	s.lastToken = tok
 
 	switch tok {
 	case intLit:
@@ -269,6 +272,11 @@ func startWithSlash(s *Scanner) (tok int, pos Pos, lit string) {
 		// See http://dev.mysql.com/doc/refman/5.7/en/comments.html
 		// Convert "/*!VersionNumber MySQL-specific-code */" to "MySQL-specific-code".
 		comment := s.r.data(&pos)
+		// The optimizer hint comment "/*+ hints */" is only recognized after SELECT, it's an ordinary comment elsewhere.
+		// See https://dev.mysql.com/doc/refman/5.7/en/optimizer-hints.html
+		if strings.HasPrefix(comment, "/*+") && s.lastToken == selectKwd {
+			return hintComment, pos, comment[len("/*+") : len(comment)-len("*/")]
+		}
 		if strings.HasPrefix(comment, "/*!") {
 			sql := specCodePattern.ReplaceAllStringFunc(comment, trimComment)
 			s.specialComment = &specialCommentScanner{
@@ -142,6 +142,7 @@ var tokenMap = map[string]int{
 	"AFTER":               after,
 	"ALL":                 all,
 	"ALTER":               alter,
+	"ALWAYS":              always,
 	"ANALYZE":             analyze,
 	"AND":                 and,
 	"ANY":                 any,
@@ -257,6 +258,7 @@ var tokenMap = map[string]int{
 	"GET_LOCK":            getLock,
 	"GLOBAL":              global,
 	"GRANT":               grant,
+	"GENERATED":           generated,
 	"GRANTS":              grants,
 	"GREATEST":            greatest,
 	"GROUP":               group,
@@ -354,6 +356,7 @@ var tokenMap = map[string]int{
 	"REPEAT":              repeat,
 	"REPEATABLE":          repeatable,
 	"REPLACE":             replace,
+	"REQUIRE":             require,
 	"RIGHT":               right,
 	"RLIKE":               rlike,
 	"ROLLBACK":            rollback,
@@ -371,6 +374,7 @@ var tokenMap = map[string]int{
 	"SET":                 set,
 	"SHARE":               share,
 	"SHOW":                show,
+	"SSL":                 ssl,
 	"SLEEP":               sleep,
 	"SIGN":                sign,
 	"SIGNED":              signed,
@@ -382,6 +386,7 @@ var tokenMap = map[string]int{
 	"STARTING":            starting,
 	"STATS_PERSISTENT":    statsPersistent,
 	"STATUS":              status,
+	"STORED":              stored,
 	"SUBDATE":             subDate,
 	"STRCMP":              strcmp,
 	"STR_TO_DATE":         strToDate,
@@ -398,6 +403,7 @@ var tokenMap = map[string]int{
 	"THAN":                than,
 	"THEN":                then,
 	"TO":                  to,
+	"TRACE":               trace,
 	"TRAILING":            trailing,
 	"TRANSACTION":         transaction,
 	"TRIGGERS":            triggers,
@@ -422,8 +428,10 @@ var tokenMap = map[string]int{
 	"VARIABLES":           variables,
 	"VERSION":             version,
 	"VIEW":                view,
+	"VIRTUAL":             virtual,
 	"WARNINGS":            warnings,
 	"WEEK":                week,
+	"X509":                x509,
 	"WEEKDAY":             weekday,
 	"WEEKOFYEAR":          weekofyear,
 	"WHEN":                when,
@@ -487,6 +495,7 @@ var tokenMap = map[string]int{
 	"RESTRICT":            restrict,
 	"CASCADE":             cascade,
 	"NO":                  no,
+	"NONE":                none,
 	"ACTION":              action,
 	"PARTITION":           partition,
 	"PARTITIONS":          partitions,
@@ -498,6 +507,11 @@ var tokenMap = map[string]int{
 	"CONV":                conv,
 	"BIT_XOR":             bitXor,
 	"CRC32":               crc32,
+
+	// The resource limits of accounts.
+	"MAX_QUERIES_PER_HOUR": maxQueriesPerHour,
+	"MAX_UPDATES_PER_HOUR": maxUpdatesPerHour,
+	"MAX_USER_CONNECTIONS": maxUserConnections,
 }
 
 func isTokenIdentifier(s string, buf *bytes.Buffer) int {
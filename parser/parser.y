@@ -48,6 +48,7 @@ import (
 	/*yy:token "%c"     */	identifier      "identifier"
 	/*yy:token "\"%c\"" */	stringLit       "string literal"
 	invalid		"a special token never used by parser, used by lexer to indicate error"
+	hintComment	"a special token for the optimizer hint comment after SELECT"
 	andand		"&&"
 	oror		"||"
 
@@ -173,6 +174,7 @@ import (
 	rename          "RENAME"
 	repeat		"REPEAT"
 	replace		"REPLACE"
+	require		"REQUIRE"
 	restrict	"RESTRICT"
 	right		"RIGHT"
 	rlike		"RLIKE"
@@ -182,6 +184,7 @@ import (
 	selectKwd	"SELECT"
 	set		"SET"
 	show		"SHOW"
+	ssl		"SSL"
 	smallIntType	"SMALLINT"
 	starting	"STARTING"
 	tableKwd	"TABLE"
@@ -308,6 +311,7 @@ import (
 	/* the following tokens belong to UnReservedKeyword*/
 	action		"ACTION"
 	after		"AFTER"
+	always		"ALWAYS"
 	any 		"ANY"
 	ascii		"ASCII"
 	at		"AT"
@@ -364,11 +368,15 @@ import (
 	level		"LEVEL"
 	mode		"MODE"
 	modify		"MODIFY"
+	maxQueriesPerHour	"MAX_QUERIES_PER_HOUR"
 	maxRows		"MAX_ROWS"
+	maxUpdatesPerHour	"MAX_UPDATES_PER_HOUR"
+	maxUserConnections	"MAX_USER_CONNECTIONS"
 	minRows		"MIN_ROWS"
 	names		"NAMES"
 	national	"NATIONAL"
 	no		"NO"
+	none		"NONE"
 	offset		"OFFSET"
 	only		"ONLY"
 	password	"PASSWORD"
@@ -395,12 +403,15 @@ import (
 	status		"STATUS"
 	some 		"SOME"
 	global		"GLOBAL"
+	generated	"GENERATED"
+	stored		"STORED"
 	tables		"TABLES"
 	textType	"TEXT"
 	than		"THAN"
 	timeType	"TIME"
 	timestampType	"TIMESTAMP"
 	timestampDiff	"TIMESTAMPDIFF"
+	trace		"TRACE"
 	transaction	"TRANSACTION"
 	triggers	"TRIGGERS"
 	truncate	"TRUNCATE"
@@ -410,8 +421,10 @@ import (
 	value		"VALUE"
 	variables	"VARIABLES"
 	view		"VIEW"
+	virtual		"VIRTUAL"
 	warnings	"WARNINGS"
 	week		"WEEK"
+	x509		"X509"
 	yearType	"YEAR"
 
 %token	<item>
@@ -455,6 +468,7 @@ import (
 	AssignmentList		"assignment list"
 	AssignmentListOpt	"assignment list opt"
 	AuthOption		"User auth option"
+	AuthPlugin		"User authentication plugin name"
 	AuthString		"Password string value"
 	BeginTransactionStmt	"BEGIN TRANSACTION statement"
 	BinlogStmt		"Binlog base64 statement"
@@ -481,6 +495,7 @@ import (
 	DatabaseOptionList	"CREATE Database specification list"
 	DatabaseOptionListOpt	"CREATE Database specification list opt"
 	CreateTableStmt		"CREATE TABLE statement"
+	CreateTableSelect	"Select statement in CREATE TABLE statement"
 	CreateUserStmt		"CREATE User statement"
 	DBName			"Database Name"
 	DeallocateStmt		"Deallocate prepared statement"
@@ -523,6 +538,7 @@ import (
 	FunctionCallKeyword	"Function call with keyword as function name"
 	FunctionCallNonKeyword	"Function call with nonkeyword as function name"
 	FuncDatetimePrec	"Function datetime precision"
+	GeneratedAlwaysOpt	"GENERATED ALWAYS, optional for generated column"
 	GlobalScope		"The scope of variable"
 	GrantStmt		"Grant statement"
 	GroupByClause		"GROUP BY clause"
@@ -549,6 +565,7 @@ import (
 	JoinTable 		"join table"
 	JoinType		"join type"
 	LikeEscapeOpt 		"like escape option"
+	LikeTableWithOrWithoutParen	"LIKE table_name or ( LIKE table_name )"
 	LimitClause		"LIMIT clause"
 	LimitOption		"Limit option could be integer or parameter marker."
 	Lines			"Lines clause"
@@ -604,6 +621,7 @@ import (
 	SelectStmtFieldList	"SELECT statement field list"
 	SelectStmtLimit		"SELECT statement optional LIMIT clause"
 	SelectStmtOpts		"Select statement options"
+	SelectStmtHintsOpt	"Select statement optimizer hints"
 	SelectStmtGroup		"SELECT statement optional GROUP BY clause"
 	SetStmt			"Set variable statement"
 	ShowStmt		"Show engines/databases/tables/columns/warnings/status statement"
@@ -638,6 +656,7 @@ import (
 	TableRef 		"table reference"
 	TableRefs 		"table references"
 	TrimDirection		"Trim string direction"
+	TraceStmt		"TRACE statement"
 	TruncateTableStmt	"TRANSACTION TABLE statement"
 	UnionOpt		"Union Option(empty/ALL/DISTINCT)"
 	UnionStmt		"Union select state ment"
@@ -648,6 +667,10 @@ import (
 	Username		"Username"
 	UsernameList		"UsernameList"
 	UserSpec		"Username and auth option"
+	RequireClauseOpt	"Optional REQUIRE clause of account"
+	ResourceOption		"Resource limit of account"
+	ResourceOptionList	"Resource limit list of account"
+	ResourceOptionsOpt	"Optional WITH clause of account resource limits"
 	UserSpecList		"Username and auth option list"
 	UserVariable		"User defined variable name"
 	UserVariableList	"User defined variable name list"
@@ -655,6 +678,7 @@ import (
 	VariableAssignment	"set variable value"
 	VariableAssignmentList	"set variable value list"
 	Variable		"User or system variable"
+	VirtualOrStored		"VIRTUAL or STORED for generated column"
 	WhereClause		"WHERE clause"
 	WhereClauseOptional	"Optinal WHERE clause"
 	WhenClause		"When clause"
@@ -855,6 +879,13 @@ AlterTableSpec:
 			Name: $4.(string),
 		}
 	}
+|	"DROP" "CHECK" Symbol
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp: ast.AlterTableDropCheck,
+			Name: $3.(string),
+		}
+	}
 |	"DISABLE" "KEYS"
 	{
 		$$ = &ast.AlterTableSpec{}
@@ -893,6 +924,14 @@ AlterTableSpec:
 			NewTable:      $3.(*ast.TableName),
 		}
 	}
+|	"RENAME" KeyOrIndex Identifier "TO" Identifier
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableRenameIndex,
+			FromKey:	model.NewCIStr($3),
+			ToKey:		model.NewCIStr($5),
+		}
+	}
 
 
 KeyOrIndex: "KEY" | "INDEX"
@@ -1103,9 +1142,37 @@ ColumnOption:
 	}
 |	"CHECK" '(' Expression ')'
 	{
-		// See https://dev.mysql.com/doc/refman/5.7/en/create-table.html
-		// The CHECK clause is parsed but ignored by all storage engines.
-		$$ = &ast.ColumnOption{}
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $3.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionCheck, Expr: expr}
+	}
+|	GeneratedAlwaysOpt "AS" '(' Expression ')' VirtualOrStored
+	{
+		startOffset := parser.startOffset(&yyS[yypt-2])
+		endOffset := parser.endOffset(&yyS[yypt-1])
+		expr := $4.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionGenerated, Expr: expr, Stored: $6.(bool)}
+	}
+
+GeneratedAlwaysOpt:
+	{}
+|	"GENERATED" "ALWAYS"
+	{}
+
+VirtualOrStored:
+	{
+		$$ = false
+	}
+|	"VIRTUAL"
+	{
+		$$ = false
+	}
+|	"STORED"
+	{
+		$$ = true
 	}
 
 ColumnOptionList:
@@ -1128,7 +1195,15 @@ ColumnOptionListOpt:
 	}
 
 ConstraintElem:
-	"PRIMARY" "KEY" IndexTypeOpt '(' IndexColNameList ')' IndexOptionList
+	"CHECK" '(' Expression ')'
+	{
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $3.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.Constraint{Tp: ast.ConstraintCheck, Expr: expr}
+	}
+|	"PRIMARY" "KEY" IndexTypeOpt '(' IndexColNameList ')' IndexOptionList
 	{
 		c := &ast.Constraint{
 			Tp: ast.ConstraintPrimaryKey,
@@ -1498,6 +1573,69 @@ CreateTableStmt:
 			Options:        $8.([]*ast.TableOption),
 		}
 	}
+|	"CREATE" "TABLE" IfNotExists TableName '(' TableElementList ')' TableOptionListOpt PartitionOpt CreateTableSelect
+	{
+		tes := $6.([]interface {})
+		var columnDefs []*ast.ColumnDef
+		var constraints []*ast.Constraint
+		for _, te := range tes {
+			switch te := te.(type) {
+			case *ast.ColumnDef:
+				columnDefs = append(columnDefs, te)
+			case *ast.Constraint:
+				constraints = append(constraints, te)
+			}
+		}
+		$$ = &ast.CreateTableStmt{
+			Table:          $4.(*ast.TableName),
+			IfNotExists:    $3.(bool),
+			Cols:           columnDefs,
+			Constraints:    constraints,
+			Options:        $8.([]*ast.TableOption),
+			Select:         $10.(ast.ResultSetNode),
+		}
+	}
+|	"CREATE" "TABLE" IfNotExists TableName TableOptionListOpt CreateTableSelect
+	{
+		$$ = &ast.CreateTableStmt{
+			Table:          $4.(*ast.TableName),
+			IfNotExists:    $3.(bool),
+			Options:        $5.([]*ast.TableOption),
+			Select:         $6.(ast.ResultSetNode),
+		}
+	}
+|	"CREATE" "TABLE" IfNotExists TableName LikeTableWithOrWithoutParen
+	{
+		$$ = &ast.CreateTableStmt{
+			Table:          $4.(*ast.TableName),
+			IfNotExists:    $3.(bool),
+			ReferTable:     $5.(*ast.TableName),
+		}
+	}
+
+CreateTableSelect:
+	SelectStmt
+	{
+		$$ = $1.(*ast.SelectStmt)
+	}
+|	"AS" SelectStmt
+	{
+		$$ = $2.(*ast.SelectStmt)
+	}
+|	"AS" UnionStmt
+	{
+		$$ = $2.(*ast.UnionStmt)
+	}
+
+LikeTableWithOrWithoutParen:
+	"LIKE" TableName
+	{
+		$$ = $2
+	}
+|	'(' "LIKE" TableName ')'
+	{
+		$$ = $3
+	}
 
 Default:
 	"DEFAULT" Expression
@@ -1701,6 +1839,12 @@ ExplainStmt:
 		$$ = &ast.ExplainStmt{Stmt: $2.(ast.StmtNode)}
 	}
 
+TraceStmt:
+	"TRACE" ExplainableStmt
+	{
+		$$ = &ast.TraceStmt{Stmt: $2.(ast.StmtNode)}
+	}
+
 LengthNum:
 	NUM
 	{
@@ -2124,12 +2268,13 @@ UnReservedKeyword:
 | "DYNAMIC"| "END" | "ENGINE" | "ENGINES" | "ESCAPE" | "EXECUTE" | "FIELDS" | "FIRST" | "FIXED" | "FULL" |"GLOBAL"
 | "HASH" | "LESS" | "LOCAL" | "NAMES" | "OFFSET" | "PASSWORD" %prec lowerThanEq | "PREPARE" | "QUICK" | "REDUNDANT" 
 | "ROLLBACK" | "SESSION" | "SIGNED" | "SNAPSHOT" | "START" | "STATUS" | "TABLES" | "TEXT" | "THAN" | "TIME" | "TIMESTAMP" 
-| "TRANSACTION" | "TRUNCATE" | "UNKNOWN" | "VALUE" | "WARNINGS" | "YEAR" | "MODE"  | "WEEK"  | "ANY" | "SOME" | "USER" | "IDENTIFIED"
+| "TRACE" | "TRANSACTION" | "TRUNCATE" | "UNKNOWN" | "VALUE" | "WARNINGS" | "YEAR" | "MODE"  | "WEEK"  | "ANY" | "SOME" | "USER" | "IDENTIFIED"
 | "COLLATION" | "COMMENT" | "AVG_ROW_LENGTH" | "CONNECTION" | "CHECKSUM" | "COMPRESSION" | "KEY_BLOCK_SIZE" | "MAX_ROWS"
 | "MIN_ROWS" | "NATIONAL" | "ROW" | "ROW_FORMAT" | "QUARTER" | "GRANTS" | "TRIGGERS" | "DELAY_KEY_WRITE" | "ISOLATION"
 | "REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES" | "SQL_CACHE" | "INDEXES" | "PROCESSLIST"
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "SPACE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
-| "TIMESTAMPDIFF"
+| "TIMESTAMPDIFF" | "GENERATED" | "ALWAYS" | "STORED" | "VIRTUAL" | "NONE" | "X509" | "MAX_QUERIES_PER_HOUR" | "MAX_UPDATES_PER_HOUR"
+| "MAX_USER_CONNECTIONS"
 
 ReservedKeyword:
 "ADD" | "ALL" | "ALTER" | "ANALYZE" | "AND" | "AS" | "ASC" | "BETWEEN" | "BIGINT"
@@ -3566,20 +3711,21 @@ RollbackStmt:
 	}
 
 SelectStmt:
-	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtLimit SelectLockOpt
+	"SELECT" SelectStmtHintsOpt SelectStmtOpts SelectStmtFieldList SelectStmtLimit SelectLockOpt
 	{
 		st := &ast.SelectStmt {
-			Distinct:      $2.(bool),
-			Fields:        $3.(*ast.FieldList),
-			LockTp:	       $5.(ast.SelectLockType),
+			Distinct:      $3.(bool),
+			TableHints:    $2.([]*ast.TableOptimizerHint),
+			Fields:        $4.(*ast.FieldList),
+			LockTp:	       $6.(ast.SelectLockType),
 		}
 		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
 		if lastField.Expr != nil && lastField.AsName.O == "" {
 			src := parser.src
 			var lastEnd int
-			if $4 != nil {
+			if $5 != nil {
 				lastEnd = yyS[yypt-1].offset-1
-			} else if $5 != ast.SelectLockNone {
+			} else if $6 != ast.SelectLockNone {
 				lastEnd = yyS[yypt].offset-1
 			} else {
 				lastEnd = len(src)
@@ -3589,40 +3735,42 @@ SelectStmt:
 			}
 			lastField.SetText(src[lastField.Offset:lastEnd])
 		}
-		if $4 != nil {
-			st.Limit = $4.(*ast.Limit)
+		if $5 != nil {
+			st.Limit = $5.(*ast.Limit)
 		}
 		$$ = st
 	}
-|	"SELECT" SelectStmtOpts SelectStmtFieldList FromDual WhereClauseOptional SelectStmtLimit SelectLockOpt
+|	"SELECT" SelectStmtHintsOpt SelectStmtOpts SelectStmtFieldList FromDual WhereClauseOptional SelectStmtLimit SelectLockOpt
 	{
 		st := &ast.SelectStmt {
-			Distinct:      $2.(bool),
-			Fields:        $3.(*ast.FieldList),
-			LockTp:	       $7.(ast.SelectLockType),
+			Distinct:      $3.(bool),
+			TableHints:    $2.([]*ast.TableOptimizerHint),
+			Fields:        $4.(*ast.FieldList),
+			LockTp:	       $8.(ast.SelectLockType),
 		}
 		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
 		if lastField.Expr != nil && lastField.AsName.O == "" {
 			lastEnd := yyS[yypt-3].offset-1
 			lastField.SetText(parser.src[lastField.Offset:lastEnd])
 		}
-		if $5 != nil {
-			st.Where = $5.(ast.ExprNode)
-		}
 		if $6 != nil {
-			st.Limit = $6.(*ast.Limit)
+			st.Where = $6.(ast.ExprNode)
+		}
+		if $7 != nil {
+			st.Limit = $7.(*ast.Limit)
 		}
 		$$ = st
 	}
-|	"SELECT" SelectStmtOpts SelectStmtFieldList "FROM"
+|	"SELECT" SelectStmtHintsOpt SelectStmtOpts SelectStmtFieldList "FROM"
 	TableRefsClause WhereClauseOptional SelectStmtGroup HavingClause OrderByOptional
 	SelectStmtLimit SelectLockOpt
 	{
 		st := &ast.SelectStmt{
-			Distinct:	$2.(bool),
-			Fields:		$3.(*ast.FieldList),
-			From:		$5.(*ast.TableRefsClause),
-			LockTp:		$11.(ast.SelectLockType),
+			Distinct:	$3.(bool),
+			TableHints:	$2.([]*ast.TableOptimizerHint),
+			Fields:		$4.(*ast.FieldList),
+			From:		$6.(*ast.TableRefsClause),
+			LockTp:		$12.(ast.SelectLockType),
 		}
 
 		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
@@ -3631,24 +3779,24 @@ SelectStmt:
 			lastField.SetText(parser.src[lastField.Offset:lastEnd])
 		}
 
-		if $6 != nil {
-			st.Where = $6.(ast.ExprNode)
-		}
-
 		if $7 != nil {
-			st.GroupBy = $7.(*ast.GroupByClause)
+			st.Where = $7.(ast.ExprNode)
 		}
 
 		if $8 != nil {
-			st.Having = $8.(*ast.HavingClause)
+			st.GroupBy = $8.(*ast.GroupByClause)
 		}
 
 		if $9 != nil {
-			st.OrderBy = $9.(*ast.OrderByClause)
+			st.Having = $9.(*ast.HavingClause)
 		}
 
 		if $10 != nil {
-			st.Limit = $10.(*ast.Limit)
+			st.OrderBy = $10.(*ast.OrderByClause)
+		}
+
+		if $11 != nil {
+			st.Limit = $11.(*ast.Limit)
 		}
 
 		$$ = st
@@ -3912,6 +4060,15 @@ SelectStmtDistinct:
 		$$ = true
 	}
 
+SelectStmtHintsOpt:
+	{
+		$$ = []*ast.TableOptimizerHint(nil)
+	}
+|	hintComment
+	{
+		$$ = parseOptimizerHints($1)
+	}
+
 SelectStmtOpts:
 	SelectStmtDistinct SelectStmtSQLCache SelectStmtCalcFoundRows
 	{
@@ -4311,10 +4468,11 @@ ShowStmt:
 			User:	$4.(string),
 		}
 	}
-|	"SHOW" "PROCESSLIST"
+|	"SHOW" OptFull "PROCESSLIST"
 	{
 		$$ = &ast.ShowStmt{
-			Tp: ast.ShowProcessList,
+			Tp:	ast.ShowProcessList,
+			Full:	$2.(bool),
 		}
 	}
 
@@ -4583,6 +4741,7 @@ Statement:
 |	UnionStmt
 |	SetStmt
 |	ShowStmt
+|	TraceStmt
 |	TruncateTableStmt
 |	UpdateStmt
 |	UseStmt
@@ -4644,11 +4803,6 @@ TableElement:
 	{
 		$$ = $1.(*ast.Constraint)
 	}
-|	"CHECK" '(' Expression ')'
-	{
-		/* Nothing to do now */
-		$$ = nil
-	}
 
 TableElementList:
 	TableElement
@@ -5303,22 +5457,26 @@ CommaOpt:
  *  https://dev.mysql.com/doc/refman/5.7/en/account-management-sql.html
  ************************************************************************************/
 CreateUserStmt:
-	"CREATE" "USER" IfNotExists UserSpecList
+	"CREATE" "USER" IfNotExists UserSpecList RequireClauseOpt ResourceOptionsOpt
 	{
  		// See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
 		$$ = &ast.CreateUserStmt{
 			IfNotExists: $3.(bool),
 			Specs: $4.([]*ast.UserSpec),
+			TLSOption: $5.(ast.TLSOptionType),
+			ResourceOptions: $6.([]*ast.ResourceOption),
 		}
 	}
 
 /* See http://dev.mysql.com/doc/refman/5.7/en/alter-user.html */
 AlterUserStmt:
-	"ALTER" "USER" IfExists UserSpecList
+	"ALTER" "USER" IfExists UserSpecList RequireClauseOpt ResourceOptionsOpt
 	{
 		$$ = &ast.AlterUserStmt{
 			IfExists: $3.(bool),
 			Specs: $4.([]*ast.UserSpec),
+			TLSOption: $5.(ast.TLSOptionType),
+			ResourceOptions: $6.([]*ast.ResourceOption),
 		}
 	}
 | 	"ALTER" "USER" IfExists "USER" '(' ')' "IDENTIFIED" "BY" AuthString
@@ -5333,6 +5491,58 @@ AlterUserStmt:
 		}
 	}
 
+/* See https://dev.mysql.com/doc/refman/5.7/en/create-user.html#create-user-tls */
+RequireClauseOpt:
+	{
+		$$ = ast.TLSOptionNotSpecified
+	}
+|	"REQUIRE" "NONE"
+	{
+		$$ = ast.TLSOptionNone
+	}
+|	"REQUIRE" "SSL"
+	{
+		$$ = ast.TLSOptionSSL
+	}
+|	"REQUIRE" "X509"
+	{
+		$$ = ast.TLSOptionX509
+	}
+
+/* See https://dev.mysql.com/doc/refman/5.7/en/create-user.html#create-user-resource-limits */
+ResourceOptionsOpt:
+	{
+		$$ = []*ast.ResourceOption{}
+	}
+|	"WITH" ResourceOptionList
+	{
+		$$ = $2
+	}
+
+ResourceOptionList:
+	ResourceOption
+	{
+		$$ = []*ast.ResourceOption{$1.(*ast.ResourceOption)}
+	}
+|	ResourceOptionList ResourceOption
+	{
+		$$ = append($1.([]*ast.ResourceOption), $2.(*ast.ResourceOption))
+	}
+
+ResourceOption:
+	"MAX_QUERIES_PER_HOUR" LengthNum
+	{
+		$$ = &ast.ResourceOption{Type: ast.MaxQueriesPerHour, Count: $2.(uint64)}
+	}
+|	"MAX_UPDATES_PER_HOUR" LengthNum
+	{
+		$$ = &ast.ResourceOption{Type: ast.MaxUpdatesPerHour, Count: $2.(uint64)}
+	}
+|	"MAX_USER_CONNECTIONS" LengthNum
+	{
+		$$ = &ast.ResourceOption{Type: ast.MaxUserConnections, Count: $2.(uint64)}
+	}
+
 UserSpec:
 	Username AuthOption
 	{
@@ -5372,6 +5582,37 @@ AuthOption:
 			HashString: $4.(string),
 		}
 	}
+|	"IDENTIFIED" "WITH" AuthPlugin
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+		}
+	}
+|	"IDENTIFIED" "WITH" AuthPlugin "BY" AuthString
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+			AuthString: $5.(string),
+			ByAuthString: true,
+		}
+	}
+|	"IDENTIFIED" "WITH" AuthPlugin "AS" HashString
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+			HashString: $5.(string),
+		}
+	}
+
+AuthPlugin:
+	Identifier
+	{
+		$$ = $1
+	}
+|	stringLit
+	{
+		$$ = $1
+	}
 
 HashString:
 	stringLit
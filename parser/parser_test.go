@@ -80,7 +80,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"auto_increment", "after", "begin", "bit", "bool", "boolean", "charset", "columns", "commit",
 		"date", "datediff", "datetime", "deallocate", "do", "from_days", "end", "engine", "engines", "execute", "first", "full",
 		"local", "names", "offset", "password", "prepare", "quick", "rollback", "session", "signed",
-		"start", "global", "tables", "text", "time", "timestamp", "transaction", "truncate", "unknown",
+		"start", "global", "tables", "text", "time", "timestamp", "trace", "transaction", "truncate", "unknown",
 		"value", "warnings", "year", "now", "substr", "substring", "mode", "any", "some", "user", "identified",
 		"collation", "comment", "avg_row_length", "checksum", "compression", "connection", "key_block_size",
 		"max_rows", "min_rows", "national", "row", "quarter", "escape", "grants", "status", "fields", "triggers",
@@ -89,7 +89,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"compact", "redundant", "sql_no_cache sql_no_cache", "sql_cache sql_cache", "action", "round",
 		"enable", "disable", "reverse", "space", "privileges", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
 		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff",
-		"ln", "log", "log2", "log10", "timestampdiff",
+		"ln", "log", "log2", "log10", "timestampdiff", "generated", "always", "stored", "virtual",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -358,6 +358,8 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		{`SHOW FULL TABLES WHERE Table_Type != 'VIEW'`, true},
 		{`SHOW GRANTS`, true},
 		{`SHOW GRANTS FOR 'test'@'localhost'`, true},
+		{`SHOW PROCESSLIST`, true},
+		{`SHOW FULL PROCESSLIST`, true},
 		{`SHOW COLUMNS FROM City;`, true},
 		{`SHOW COLUMNS FROM tv189.1_t_1_x;`, true},
 		{`SHOW FIELDS FROM City;`, true},
@@ -905,6 +907,9 @@ func (s *testParserSuite) TestDDL(c *C) {
 		// for check clause
 		{"create table t (c1 bool, c2 bool, check (c1 in (0, 1)), check (c2 in (0, 1)))", true},
 		{"CREATE TABLE Customer (SD integer CHECK (SD > 0), First_Name varchar(30));", true},
+		{"create table t (c int, constraint chk_c check (c > 0))", true},
+		{"create table t (c int, constraint check (c > 0))", true},
+		{"create table t (c int check)", false},
 
 		{"create database xxx", true},
 		{"create database if exists xxx", false},
@@ -1031,6 +1036,35 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"ALTER TABLE t CHANGE COLUMN a b varchar(255)", true},
 		{"ALTER TABLE db.t RENAME to db1.t1", true},
 		{"ALTER TABLE t RENAME as t1", true},
+		{"ALTER TABLE t ADD CHECK (a > 0)", true},
+		{"ALTER TABLE t ADD CONSTRAINT chk_a CHECK (a > 0 and b < 10)", true},
+		{"ALTER TABLE t DROP CHECK chk_a", true},
+		// For generated column.
+		{"create table t (a int, b int as (a + 1))", true},
+		{"create table t (a int, b int generated always as (a + 1) virtual not null)", true},
+		{"create table t (a varchar(10), b varchar(20) as (concat(a, 'x')) stored, index idx(b))", true},
+		{"create table t (a int, b int generated as (a + 1))", false},
+		{"create table t (a int, b int as a + 1)", false},
+		{"alter table t add column c int as (a * 2) stored", true},
+		{"ALTER TABLE t DROP CHECK", false},
+		// For create table like and create table as select.
+		{"create table t like t1", true},
+		{"create table if not exists t (like db.t1)", true},
+		{"create table t like", false},
+		{"create table t select * from t1", true},
+		{"create table t as select a, b + 1 as c from t1 where a > 0", true},
+		{"create table t (id int primary key) engine = innodb as select a from t1", true},
+		{"create table t engine = innodb select 1", true},
+		{"create table t as select a from t1 union select b from t2", true},
+		{"create table t as", false},
+		// For alter table options and rename index.
+		{"ALTER TABLE t AUTO_INCREMENT = 100", true},
+		{"ALTER TABLE t COMMENT = 'new comment'", true},
+		{"ALTER TABLE t DEFAULT CHARSET = utf8 COLLATE = utf8_bin", true},
+		{"ALTER TABLE t MODIFY COLUMN a int COMMENT 'column comment'", true},
+		{"ALTER TABLE t RENAME INDEX idx_a TO idx_b", true},
+		{"ALTER TABLE t RENAME KEY idx_a TO idx_b", true},
+		{"ALTER TABLE t RENAME INDEX idx_a", false},
 
 		// for rename table statement
 		{"RENAME TABLE t TO t1", true},
@@ -1096,6 +1130,20 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{`ALTER USER 'root'@'localhost' IDENTIFIED BY 'new-password', 'root'@'127.0.0.1' IDENTIFIED BY PASSWORD 'hashstring'`, true},
 		{`ALTER USER USER() IDENTIFIED BY 'new-password'`, true},
 		{`ALTER USER IF EXISTS USER() IDENTIFIED BY 'new-password'`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED BY 'new-password' REQUIRE SSL`, true},
+		{`CREATE USER 'root'@'%' REQUIRE X509`, true},
+		{`ALTER USER 'root'@'localhost' REQUIRE NONE`, true},
+		{`CREATE USER 'root'@'%' WITH MAX_QUERIES_PER_HOUR 10 MAX_UPDATES_PER_HOUR 5 MAX_USER_CONNECTIONS 2`, true},
+		{`CREATE USER 'root'@'%' IDENTIFIED BY 'new-password' REQUIRE SSL WITH MAX_USER_CONNECTIONS 2`, true},
+		{`ALTER USER 'root'@'localhost' WITH MAX_QUERIES_PER_HOUR 0`, true},
+		{`ALTER USER 'root'@'localhost' WITH`, false},
+		{`ALTER USER 'root'@'localhost' WITH MAX_USER_CONNECTIONS -1`, false},
+		{`CREATE USER 'root'@'localhost' REQUIRE`, false},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED WITH caching_sha2_password BY 'new-password'`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED WITH 'mysql_native_password' AS 'hashstring'`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED WITH auth_socket`, true},
+		{`ALTER USER 'root'@'localhost' IDENTIFIED WITH caching_sha2_password BY 'new-password' REQUIRE SSL`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED WITH`, false},
 		{`DROP USER 'root'@'localhost', 'root1'@'localhost'`, true},
 		{`DROP USER IF EXISTS 'root'@'localhost'`, true},
 
@@ -1211,6 +1259,31 @@ func (s *testParserSuite) TestIndexHint(c *C) {
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestOptimizerHints(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{`select /*+ MAX_EXECUTION_TIME(1000) */ * from t`, true},
+		{`select /*+ max_execution_time(10) unknown_hint(t) */ distinct a from t where a > 1`, true},
+		{`select /*+ */ 1`, true},
+		{`select /*+ MAX_EXECUTION_TIME(1000) */ 1 from dual`, true},
+		// The hint comment is an ordinary comment if it doesn't follow SELECT.
+		{`insert /*+ MAX_EXECUTION_TIME(1000) */ into t values (1)`, true},
+		{`select a /*+ MAX_EXECUTION_TIME(1000) */ from t`, true},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("select /*+ MAX_EXECUTION_TIME(1000) unknown_hint(t) */ a from t", "", "")
+	c.Assert(err, IsNil)
+	hints := stmt.(*ast.SelectStmt).TableHints
+	c.Assert(hints, HasLen, 1)
+	c.Assert(hints[0].HintName.L, Equals, "max_execution_time")
+	c.Assert(hints[0].MaxExecutionTime, Equals, uint64(1000))
+	stmt, err = parser.ParseOneStmt("select a /*+ MAX_EXECUTION_TIME(1000) */ from t", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.SelectStmt).TableHints, HasLen, 0)
+}
+
 func (s *testParserSuite) TestEscape(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
@@ -1266,3 +1339,18 @@ func (s *testParserSuite) TestExplain(c *C) {
 	}
 	s.RunTest(c, table)
 }
+
+func (s *testParserSuite) TestTrace(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"trace select c1 from t1", true},
+		{"trace delete t1, t2 from t1 inner join t2 inner join t3 where t1.id=t2.id and t2.id=t3.id;", true},
+		{"trace insert into t values (1), (2), (3)", true},
+		{"trace replace into foo values (1 || 2)", true},
+		{"trace update t set id = id + 1 order by id desc;", true},
+		{"trace select c1 from t1 union (select c2 from t2) limit 1, 1", true},
+		{"trace create table t (a int)", false},
+		{"trace", false},
+	}
+	s.RunTest(c, table)
+}
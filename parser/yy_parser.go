@@ -21,6 +21,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/hack"
@@ -43,6 +44,21 @@ var (
 	specCodeEnd     = regexp.MustCompile(`[ \t]*\*\/$`)
 )
 
+// hintPattern matches the hints supported in the optimizer hint comment, the other hints are ignored.
+var hintPattern = regexp.MustCompile(`(?i)\b(MAX_EXECUTION_TIME)\s*\(\s*([0-9]+)\s*\)`)
+
+// parseOptimizerHints parses the content of the optimizer hint comment "/*+ hints */".
+func parseOptimizerHints(comment string) []*ast.TableOptimizerHint {
+	var hints []*ast.TableOptimizerHint
+	for _, m := range hintPattern.FindAllStringSubmatch(comment, -1) {
+		hint := &ast.TableOptimizerHint{HintName: model.NewCIStr(m[1])}
+		// The value is ignored if it's out of range.
+		hint.MaxExecutionTime, _ = strconv.ParseUint(m[2], 10, 64)
+		hints = append(hints, hint)
+	}
+	return hints
+}
+
 func trimComment(txt string) string {
 	txt = specCodeStart.ReplaceAllString(txt, "")
 	return specCodeEnd.ReplaceAllString(txt, "")
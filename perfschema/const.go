@@ -36,6 +36,9 @@ const (
 	TableStagesCurrent          = "EVENTS_STAGES_CURRENT"
 	TableStagesHistory          = "EVENTS_STAGES_HISTORY"
 	TableStagesHistoryLong      = "EVENTS_STAGES_HISTORY_LONG"
+	TableStmtsSummaryByDigest   = "EVENTS_STATEMENTS_SUMMARY_BY_DIGEST"
+	TableDataLockWaits          = "DATA_LOCK_WAITS"
+	TableDataLockWaitsHistory   = "DATA_LOCK_WAITS_HISTORY"
 )
 
 // PerfSchemaTables is a shortcut to involve all table names.
@@ -55,6 +58,9 @@ var PerfSchemaTables = []string{
 	TableStagesCurrent,
 	TableStagesHistory,
 	TableStagesHistoryLong,
+	TableStmtsSummaryByDigest,
+	TableDataLockWaits,
+	TableDataLockWaitsHistory,
 }
 
 // ColumnSetupActors contains the column name definitions for table setup_actors, same as MySQL.
@@ -112,7 +118,7 @@ var ColumnSetupTimers = []string{"NAME", "TIMER_NAME"}
 // 		TIMER_WAIT		BIGINT(20) UNSIGNED,
 // 		LOCK_TIME		BIGINT(20) UNSIGNED NOT NULL,
 // 		SQL_TEXT		LONGTEXT,
-// 		DIGEST			VARCHAR(32),
+// 		DIGEST			VARCHAR(64),
 // 		DIGEST_TEXT		LONGTEXT,
 // 		CURRENT_SCHEMA	VARCHAR(64),
 // 		OBJECT_TYPE		VARCHAR(64),
@@ -200,7 +206,7 @@ var ColumnStmtsCurrent = []string{
 // 		TIMER_WAIT		BIGINT(20) UNSIGNED,
 // 		LOCK_TIME		BIGINT(20) UNSIGNED NOT NULL,
 // 		SQL_TEXT		LONGTEXT,
-// 		DIGEST			VARCHAR(32),
+// 		DIGEST			VARCHAR(64),
 // 		DIGEST_TEXT		LONGTEXT,
 // 		CURRENT_SCHEMA	VARCHAR(64),
 // 		OBJECT_TYPE		VARCHAR(64),
@@ -288,7 +294,7 @@ var ColumnStmtsHistory = []string{
 // 		TIMER_WAIT		BIGINT(20) UNSIGNED,
 // 		LOCK_TIME		BIGINT(20) UNSIGNED NOT NULL,
 // 		SQL_TEXT		LONGTEXT,
-// 		DIGEST			VARCHAR(32),
+// 		DIGEST			VARCHAR(64),
 // 		DIGEST_TEXT		LONGTEXT,
 // 		CURRENT_SCHEMA	VARCHAR(64),
 // 		OBJECT_TYPE		VARCHAR(64),
@@ -440,6 +446,7 @@ var ColumnPreparedStmtsInstances = []string{
 }
 
 // ColumnTransCurrent contains the column name definitions for table events_transactions_current, same as MySQL.
+// TRX_ID is the start timestamp of the transaction, COMMIT_TS, RETRY_COUNT and KEYS_WRITTEN are added by TiDB.
 //
 // CREATE TABLE if not exists performance_schema.events_transactions_current (
 // 		THREAD_ID		BIGINT(20) UNSIGNED NOT NULL,
@@ -465,7 +472,10 @@ var ColumnPreparedStmtsInstances = []string{
 // 		NUMBER_OF_RELEASE_SAVEPOINT		BIGINT(20) UNSIGNED,
 // 		OBJECT_INSTANCE_BEGIN	BIGINT(20) UNSIGNED,
 // 		NESTING_EVENT_ID		BIGINT(20) UNSIGNED,
-// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE'));
+// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE'),
+// 		COMMIT_TS		BIGINT(20) UNSIGNED,
+// 		RETRY_COUNT		BIGINT(20) UNSIGNED NOT NULL,
+// 		KEYS_WRITTEN	BIGINT(20) UNSIGNED NOT NULL);
 var ColumnTransCurrent = []string{
 	"THREAD_ID",
 	"EVENT_ID",
@@ -491,9 +501,12 @@ var ColumnTransCurrent = []string{
 	"OBJECT_INSTANCE_BEGIN",
 	"NESTING_EVENT_ID",
 	"NESTING_EVENT_TYPE",
+	"COMMIT_TS",
+	"RETRY_COUNT",
+	"KEYS_WRITTEN",
 }
 
-// ColumnTransHistory contains the column name definitions for table events_transactions_history, same as MySQL.
+// ColumnTransHistory contains the column name definitions for table events_transactions_history, same as events_transactions_current.
 //
 // CREATE TABLE if not exists performance_schema.events_transactions_history (
 // 		THREAD_ID		BIGINT(20) UNSIGNED NOT NULL,
@@ -519,7 +532,10 @@ var ColumnTransCurrent = []string{
 // 		NUMBER_OF_RELEASE_SAVEPOINT		BIGINT(20) UNSIGNED,
 // 		OBJECT_INSTANCE_BEGIN	BIGINT(20) UNSIGNED,
 // 		NESTING_EVENT_ID		BIGINT(20) UNSIGNED,
-// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE'));
+// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE'),
+// 		COMMIT_TS		BIGINT(20) UNSIGNED,
+// 		RETRY_COUNT		BIGINT(20) UNSIGNED NOT NULL,
+// 		KEYS_WRITTEN	BIGINT(20) UNSIGNED NOT NULL);
 var ColumnTransHistory = []string{
 	"THREAD_ID",
 	"EVENT_ID",
@@ -545,9 +561,12 @@ var ColumnTransHistory = []string{
 	"OBJECT_INSTANCE_BEGIN",
 	"NESTING_EVENT_ID",
 	"NESTING_EVENT_TYPE",
+	"COMMIT_TS",
+	"RETRY_COUNT",
+	"KEYS_WRITTEN",
 }
 
-// ColumnTransHistoryLong contains the column name definitions for table events_transactions_history_long, same as MySQL.
+// ColumnTransHistoryLong contains the column name definitions for table events_transactions_history_long, same as events_transactions_current.
 //
 // CREATE TABLE if not exists performance_schema.events_transactions_history_long (
 // 		THREAD_ID		BIGINT(20) UNSIGNED NOT NULL,
@@ -573,7 +592,10 @@ var ColumnTransHistory = []string{
 // 		NUMBER_OF_RELEASE_SAVEPOINT		BIGINT(20) UNSIGNED,
 // 		OBJECT_INSTANCE_BEGIN	BIGINT(20) UNSIGNED,
 // 		NESTING_EVENT_ID		BIGINT(20) UNSIGNED,
-// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE'));
+// 		NESTING_EVENT_TYPE		ENUM('TRANSACTION','STATEMENT','STAGE'),
+// 		COMMIT_TS		BIGINT(20) UNSIGNED,
+// 		RETRY_COUNT		BIGINT(20) UNSIGNED NOT NULL,
+// 		KEYS_WRITTEN	BIGINT(20) UNSIGNED NOT NULL);
 var ColumnTransHistoryLong = []string{
 	"THREAD_ID",
 	"EVENT_ID",
@@ -599,6 +621,9 @@ var ColumnTransHistoryLong = []string{
 	"OBJECT_INSTANCE_BEGIN",
 	"NESTING_EVENT_ID",
 	"NESTING_EVENT_TYPE",
+	"COMMIT_TS",
+	"RETRY_COUNT",
+	"KEYS_WRITTEN",
 }
 
 // ColumnStagesCurrent contains the column name definitions for table events_stages_current, same as MySQL.
@@ -690,3 +715,90 @@ var ColumnStagesHistoryLong = []string{
 	"NESTING_EVENT_ID",
 	"NESTING_EVENT_TYPE",
 }
+
+// ColumnStmtsSummaryByDigest contains the column name definitions for table events_statements_summary_by_digest,
+// same as MySQL except for the LAST_PLAN column and the metrics which are not supported.
+// The statements are aggregated by the schema and the digest, the overflow row has NULL SCHEMA_NAME and DIGEST.
+//
+// CREATE TABLE if not exists performance_schema.events_statements_summary_by_digest (
+// 		SCHEMA_NAME		VARCHAR(64),
+// 		DIGEST			VARCHAR(64),
+// 		DIGEST_TEXT		LONGTEXT,
+// 		COUNT_STAR		BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_TIMER_WAIT	BIGINT(20) UNSIGNED NOT NULL,
+// 		MIN_TIMER_WAIT	BIGINT(20) UNSIGNED NOT NULL,
+// 		AVG_TIMER_WAIT	BIGINT(20) UNSIGNED NOT NULL,
+// 		MAX_TIMER_WAIT	BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_ERRORS		BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_WARNINGS	BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_ROWS_AFFECTED		BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_ROWS_SENT	BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_ROWS_EXAMINED		BIGINT(20) UNSIGNED NOT NULL,
+// 		FIRST_SEEN		TIMESTAMP NOT NULL,
+// 		LAST_SEEN		TIMESTAMP NOT NULL,
+// 		LAST_PLAN		LONGTEXT);
+var ColumnStmtsSummaryByDigest = []string{
+	"SCHEMA_NAME",
+	"DIGEST",
+	"DIGEST_TEXT",
+	"COUNT_STAR",
+	"SUM_TIMER_WAIT",
+	"MIN_TIMER_WAIT",
+	"AVG_TIMER_WAIT",
+	"MAX_TIMER_WAIT",
+	"SUM_ERRORS",
+	"SUM_WARNINGS",
+	"SUM_ROWS_AFFECTED",
+	"SUM_ROWS_SENT",
+	"SUM_ROWS_EXAMINED",
+	"FIRST_SEEN",
+	"LAST_SEEN",
+	"LAST_PLAN",
+}
+
+// ColumnDataLockWaits contains the column name definitions for table data_lock_waits, it's similar to MySQL 8.0.
+// A row is a transaction waiting for the lock of another transaction, the transactions are identified by their
+// start timestamps, the keys are in hex.
+//
+// CREATE TABLE if not exists performance_schema.data_lock_waits (
+// 		ENGINE			VARCHAR(32) NOT NULL,
+// 		REQUESTING_ENGINE_TRANSACTION_ID	BIGINT(20) UNSIGNED NOT NULL,
+// 		BLOCKING_ENGINE_TRANSACTION_ID		BIGINT(20) UNSIGNED NOT NULL,
+// 		LOCK_KEY		VARCHAR(1024),
+// 		PRIMARY_LOCK_KEY	VARCHAR(1024),
+// 		LOCK_TTL		BIGINT(20) UNSIGNED NOT NULL,
+// 		TIMER_START		BIGINT(20) UNSIGNED);
+var ColumnDataLockWaits = []string{
+	"ENGINE",
+	"REQUESTING_ENGINE_TRANSACTION_ID",
+	"BLOCKING_ENGINE_TRANSACTION_ID",
+	"LOCK_KEY",
+	"PRIMARY_LOCK_KEY",
+	"LOCK_TTL",
+	"TIMER_START",
+}
+
+// ColumnDataLockWaitsHistory contains the column name definitions for table data_lock_waits_history,
+// a row is added when a wait in table data_lock_waits ends.
+//
+// CREATE TABLE if not exists performance_schema.data_lock_waits_history (
+// 		ENGINE			VARCHAR(32) NOT NULL,
+// 		REQUESTING_ENGINE_TRANSACTION_ID	BIGINT(20) UNSIGNED NOT NULL,
+// 		BLOCKING_ENGINE_TRANSACTION_ID		BIGINT(20) UNSIGNED NOT NULL,
+// 		LOCK_KEY		VARCHAR(1024),
+// 		PRIMARY_LOCK_KEY	VARCHAR(1024),
+// 		LOCK_TTL		BIGINT(20) UNSIGNED NOT NULL,
+// 		TIMER_START		BIGINT(20) UNSIGNED,
+// 		TIMER_END		BIGINT(20) UNSIGNED,
+// 		TIMER_WAIT		BIGINT(20) UNSIGNED);
+var ColumnDataLockWaitsHistory = []string{
+	"ENGINE",
+	"REQUESTING_ENGINE_TRANSACTION_ID",
+	"BLOCKING_ENGINE_TRANSACTION_ID",
+	"LOCK_KEY",
+	"PRIMARY_LOCK_KEY",
+	"LOCK_TTL",
+	"TIMER_START",
+	"TIMER_END",
+	"TIMER_WAIT",
+}
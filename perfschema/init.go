@@ -84,7 +84,7 @@ var stmtsCurrentCols = []columnInfo{
 	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
 	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
 	{mysql.TypeLongBlob, -1, 0, nil, nil},
-	{mysql.TypeVarchar, 32, 0, nil, nil},
+	{mysql.TypeVarchar, 64, 0, nil, nil},
 	{mysql.TypeLongBlob, -1, 0, nil, nil},
 	{mysql.TypeVarchar, 64, 0, nil, nil},
 	{mysql.TypeVarchar, 64, 0, nil, nil},
@@ -117,6 +117,25 @@ var stmtsCurrentCols = []columnInfo{
 	{mysql.TypeLong, 11, 0, nil, nil},
 }
 
+var stmtsSummaryByDigestCols = []columnInfo{
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeLongBlob, -1, 0, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeTimestamp, 19, mysql.NotNullFlag, nil, nil},
+	{mysql.TypeTimestamp, 19, mysql.NotNullFlag, nil, nil},
+	{mysql.TypeLongBlob, -1, 0, nil, nil},
+}
+
 var preparedStmtsInstancesCols = []columnInfo{
 	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
 	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
@@ -180,6 +199,9 @@ var transCurrentCols = []columnInfo{
 	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
 	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
 	{mysql.TypeEnum, -1, 0, nil, []string{"TRANSACTION", "STATEMENT", "STAGE"}},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
 }
 
 var stagesCurrentCols = []columnInfo{
@@ -197,6 +219,28 @@ var stagesCurrentCols = []columnInfo{
 	{mysql.TypeEnum, -1, 0, nil, []string{"TRANSACTION", "STATEMENT", "STAGE"}},
 }
 
+var dataLockWaitsCols = []columnInfo{
+	{mysql.TypeVarchar, 32, mysql.NotNullFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeVarchar, 1024, 0, nil, nil},
+	{mysql.TypeVarchar, 1024, 0, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+}
+
+var dataLockWaitsHistoryCols = []columnInfo{
+	{mysql.TypeVarchar, 32, mysql.NotNullFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeVarchar, 1024, 0, nil, nil},
+	{mysql.TypeVarchar, 1024, 0, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
+}
+
 func createMemoryTable(meta *model.TableInfo, alloc autoid.Allocator) (table.Table, error) {
 	tbl, _ := tables.MemoryTableFromMeta(alloc, meta)
 	return tbl, nil
@@ -222,7 +266,8 @@ func (ps *perfSchema) buildTables() error {
 		switch name {
 		case TableStmtsCurrent, TablePreparedStmtsInstances, TableTransCurrent, TableStagesCurrent:
 			tbl = createBoundedTable(meta, alloc, currentElemMax)
-		case TableStmtsHistory, TableStmtsHistoryLong, TableTransHistory, TableTransHistoryLong, TableStagesHistory, TableStagesHistoryLong:
+		case TableStmtsHistory, TableStmtsHistoryLong, TableTransHistory, TableTransHistoryLong, TableStagesHistory, TableStagesHistoryLong,
+			TableDataLockWaitsHistory:
 			tbl = createBoundedTable(meta, alloc, historyElemMax)
 		default:
 			var err error
@@ -338,6 +383,10 @@ func (ps *perfSchema) initialize() (err error) {
 	ps.tables = make(map[string]*model.TableInfo)
 	ps.mTables = make(map[string]table.Table, len(ps.tables))
 	ps.stmtHandles = make([]int64, currentElemMax)
+	ps.transHandles = make([]int64, currentElemMax)
+	ps.summaries.m = make(map[stmtSummaryKey]*stmtSummary)
+	ps.lockWaits.m = make(map[uint64]*lockWait)
+	ps.lockWaits.blocking = make(map[uint64]uint64)
 
 	allColDefs := [][]columnInfo{
 		setupActorsCols,
@@ -355,6 +404,9 @@ func (ps *perfSchema) initialize() (err error) {
 		stagesCurrentCols,
 		stagesCurrentCols, // same as above
 		stagesCurrentCols, // same as above
+		stmtsSummaryByDigestCols,
+		dataLockWaitsCols,
+		dataLockWaitsHistoryCols,
 	}
 
 	allColNames := [][]string{
@@ -367,12 +419,15 @@ func (ps *perfSchema) initialize() (err error) {
 		ColumnStmtsHistory,
 		ColumnStmtsHistoryLong,
 		ColumnPreparedStmtsInstances,
-		ColumnStmtsCurrent,
-		ColumnStmtsHistory,
-		ColumnStmtsHistoryLong,
+		ColumnTransCurrent,
+		ColumnTransHistory,
+		ColumnTransHistoryLong,
 		ColumnStagesCurrent,
 		ColumnStagesHistory,
 		ColumnStagesHistoryLong,
+		ColumnStmtsSummaryByDigest,
+		ColumnDataLockWaits,
+		ColumnDataLockWaitsHistory,
 	}
 
 	// initialize all table, column and result field definitions
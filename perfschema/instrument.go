@@ -15,22 +15,11 @@ package perfschema
 
 import (
 	"fmt"
-	"sync"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/util/types"
 )
 
-// EnumCallerName is used as a parameter to avoid calling runtime.Caller(1) since
-// it is too expensive (500ns+ per call), we don't want to invoke it repeatedly for
-// each instrument.
-type EnumCallerName int
-
-const (
-	// CallerNameSessionExecute is for session.go:Execute() method.
-	CallerNameSessionExecute EnumCallerName = iota + 1
-)
-
 const (
 	stageInstrumentPrefix       = "stage/"
 	statementInstrumentPrefix   = "statement/"
@@ -56,11 +45,6 @@ const (
 	timerNameMillisec
 )
 
-var (
-	callerNames = make(map[EnumCallerName]string)
-	callerLock  = &sync.RWMutex{}
-)
-
 // addInstrument is used to add an item to setup_instruments table.
 func (ps *perfSchema) addInstrument(name string) (uint64, error) {
 	record := types.MakeDatums(name, types.Enum{Name: "YES", Value: 1}, types.Enum{Name: "YES", Value: 1})
@@ -70,10 +54,11 @@ func (ps *perfSchema) addInstrument(name string) (uint64, error) {
 }
 
 func (ps *perfSchema) getTimerName(flag int) (enumTimerName, error) {
-	if flag < 0 || flag >= len(setupTimersRecords) {
+	// The flags start from 1, the records are in the same order.
+	if flag < flagStage || flag > len(setupTimersRecords) {
 		return timerNameNone, errInvalidTimerFlag.Gen("Unknown timerName flag %d", flag)
 	}
-	timerName := fmt.Sprintf("%s", setupTimersRecords[flag][1].GetString())
+	timerName := fmt.Sprintf("%s", setupTimersRecords[flag-1][1].GetString())
 	switch timerName {
 	case "NANOSECOND":
 		return timerNameNanosec, nil
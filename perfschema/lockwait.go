@@ -0,0 +1,177 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// lockWaitEngine is the ENGINE column of the lock wait tables.
+const lockWaitEngine = "TiKV"
+
+// lockWait is a row in table data_lock_waits.
+type lockWait struct {
+	handle    int64
+	lockTS    uint64
+	startTime time.Time
+	record    []types.Datum
+}
+
+// lockWaits are the waiting transactions by their start timestamps.
+type lockWaits struct {
+	sync.Mutex
+	m map[uint64]*lockWait
+	// blocking are the start timestamps of the transactions blocking the waiting transactions, they are
+	// used to count the lock waits by the tables whether the performance schema is enabled or not.
+	blocking map[uint64]uint64
+}
+
+// OnLockWait implements the kv.LockWaitObserver interface.
+func (ps *perfSchema) OnLockWait(startTS, lockTS uint64, key, primary []byte, ttl uint64) {
+	ps.countLockWait(startTS, lockTS, key)
+	if !enablePerfSchema {
+		return
+	}
+	tbl := ps.mTables[TableDataLockWaits]
+	if tbl == nil {
+		return
+	}
+	timerName, err := ps.getTimerName(flagTransaction)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Error("Unable to check setup_timers table")
+		return
+	}
+
+	ps.lockWaits.Lock()
+	defer ps.lockWaits.Unlock()
+	w, ok := ps.lockWaits.m[startTS]
+	if !ok && int64(len(ps.lockWaits.m)) >= currentElemMax {
+		return
+	}
+	if !ok || w.lockTS != lockTS {
+		// A new wait starts if the transaction is blocked by another transaction.
+		w = &lockWait{handle: w.getHandle(), lockTS: lockTS, startTime: time.Now()}
+	}
+	w.record = types.MakeDatums(
+		lockWaitEngine,              // ENGINE
+		startTS,                     // REQUESTING_ENGINE_TRANSACTION_ID
+		lockTS,                      // BLOCKING_ENGINE_TRANSACTION_ID
+		hex.EncodeToString(key),     // LOCK_KEY
+		hex.EncodeToString(primary), // PRIMARY_LOCK_KEY
+		ttl,                         // LOCK_TTL
+		timerValue(timerName, w.startTime.UnixNano()), // TIMER_START
+	)
+	if w.handle == 0 {
+		w.handle, err = tbl.AddRecord(nil, w.record)
+	} else {
+		err = tbl.UpdateRecord(nil, w.handle, nil, w.record, nil)
+	}
+	if err != nil {
+		log.Errorf("Unable to update data_lock_waits table %v", errors.ErrorStack(err))
+		return
+	}
+	ps.lockWaits.m[startTS] = w
+}
+
+// countLockWait counts a lock wait by the table of the key if the transaction isn't waiting for
+// the transaction of the lock yet.
+func (ps *perfSchema) countLockWait(startTS, lockTS uint64, key []byte) {
+	ps.lockWaits.Lock()
+	blockingTS, ok := ps.lockWaits.blocking[startTS]
+	ps.lockWaits.blocking[startTS] = lockTS
+	ps.lockWaits.Unlock()
+	if ok && blockingTS == lockTS {
+		return
+	}
+	if tableID := tablecodec.DecodeTableID(key); tableID != 0 {
+		tables.CountLockWait(tableID)
+	}
+}
+
+func (w *lockWait) getHandle() int64 {
+	if w == nil {
+		return 0
+	}
+	return w.handle
+}
+
+// OnLockWaitDone implements the kv.LockWaitObserver interface.
+func (ps *perfSchema) OnLockWaitDone(startTS uint64) {
+	if !enablePerfSchema {
+		ps.lockWaits.Lock()
+		delete(ps.lockWaits.blocking, startTS)
+		ps.lockWaits.Unlock()
+		return
+	}
+	ps.endLockWait(startTS, time.Now())
+}
+
+// endLockWait moves the lock wait of the transaction from table data_lock_waits to table data_lock_waits_history.
+func (ps *perfSchema) endLockWait(startTS uint64, endTime time.Time) {
+	ps.lockWaits.Lock()
+	delete(ps.lockWaits.blocking, startTS)
+	w, ok := ps.lockWaits.m[startTS]
+	if ok {
+		delete(ps.lockWaits.m, startTS)
+	}
+	ps.lockWaits.Unlock()
+	if !ok {
+		return
+	}
+
+	if tbl := ps.mTables[TableDataLockWaits]; tbl != nil {
+		err := tbl.RemoveRecord(nil, w.handle, w.record)
+		if err != nil {
+			log.Errorf("Unable to remove from data_lock_waits table %v", errors.ErrorStack(err))
+		}
+	}
+	tbl := ps.mTables[TableDataLockWaitsHistory]
+	if tbl == nil {
+		return
+	}
+	timerName, err := ps.getTimerName(flagTransaction)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Error("Unable to check setup_timers table")
+		return
+	}
+	timerStart := timerValue(timerName, w.startTime.UnixNano())
+	timerEnd := timerValue(timerName, endTime.UnixNano())
+	record := append(w.record[:len(w.record)-1:len(w.record)-1], types.MakeDatums(
+		timerStart,          // TIMER_START
+		timerEnd,            // TIMER_END
+		timerEnd-timerStart, // TIMER_WAIT
+	)...)
+	_, err = tbl.AddRecord(nil, record)
+	if err != nil {
+		log.Errorf("Unable to append to data_lock_waits_history table %v", errors.ErrorStack(err))
+	}
+}
+
+// resetDataLockWaits removes all the rows in table data_lock_waits.
+func (ps *perfSchema) resetDataLockWaits(tbl truncatableTable) {
+	ps.lockWaits.Lock()
+	defer ps.lockWaits.Unlock()
+	tbl.Truncate()
+	ps.lockWaits.m = make(map[uint64]*lockWait)
+}
@@ -15,10 +15,12 @@ package perfschema
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
 )
@@ -28,20 +30,23 @@ var (
 	errInvalidTimerFlag       = terror.ClassPerfSchema.New(codeInvalidTimerFlag, "invalid timer flag")
 )
 
-// StatementInstrument defines the methods for statement instrumentation points
-type StatementInstrument interface {
-	RegisterStatement(category, name string, elem interface{})
-
-	StartStatement(sql string, connID uint64, callerName EnumCallerName, elem interface{}) *StatementState
-
-	EndStatement(state *StatementState)
-}
+// ErrWrongPerfSchemaUsage is returned if a performance schema table is used in an unsupported way.
+var ErrWrongPerfSchemaUsage = terror.ClassPerfSchema.New(codeWrongPerfSchemaUsage, mysql.MySQLErrName[mysql.ErrWrongPerfschemaUsage])
 
 // PerfSchema defines the methods to be invoked by the executor
 type PerfSchema interface {
-
-	// For statement instrumentation only.
-	StatementInstrument
+	// RecordStatement records a finished statement to the statement event tables and
+	// table events_statements_summary_by_digest.
+	RecordStatement(stats *StatementStats)
+	// RecordTransaction records a committed or rolled back transaction to the transaction event tables,
+	// the lock wait of the transaction ends if there is any.
+	RecordTransaction(stats *TransactionStats)
+	// TruncateTable removes all the rows of a table, only the event tables and
+	// table events_statements_summary_by_digest can be truncated.
+	TruncateTable(name string) error
+
+	// PerfSchema records the lock waits of the transactions to table data_lock_waits and data_lock_waits_history.
+	kv.LockWaitObserver
 
 	// GetDBMeta returns db info for PerformanceSchema.
 	GetDBMeta() *model.DBInfo
@@ -56,6 +61,11 @@ type perfSchema struct {
 	mTables     map[string]table.Table // Memory tables for perfSchema
 	stmtHandles []int64
 	stmtInfos   map[reflect.Type]*statementInfo
+	summaries   stmtSummaries
+	// transHandles are the handles of the rows in table events_transactions_current by the connections.
+	transHandles []int64
+	transInfo    *transactionInfo
+	lockWaits    lockWaits
 }
 
 var (
@@ -70,6 +80,11 @@ func EnablePerfSchema() {
 	enablePerfSchema = true
 }
 
+// Enabled returns whether perfschema is enabled.
+func Enabled() bool {
+	return enablePerfSchema
+}
+
 // NewPerfHandle creates a new perfSchema on store.
 func NewPerfHandle() (PerfSchema, error) {
 	schema := &perfSchema{}
@@ -78,11 +93,53 @@ func NewPerfHandle() (PerfSchema, error) {
 		return nil, errors.Trace(err)
 	}
 	schema.registerStatements()
+	schema.registerTransaction()
 	return schema, nil
 }
 
+// truncatableTable is the table which can be truncated, the memory tables and the bounded tables.
+type truncatableTable interface {
+	Truncate()
+}
+
+func (ps *perfSchema) TruncateTable(name string) error {
+	tbl, ok := ps.mTables[strings.ToUpper(name)]
+	if !ok {
+		return errInvalidPerfSchemaTable.Gen("Unknown PerformanceSchema table: %s", name)
+	}
+	t, ok := tbl.(truncatableTable)
+	if !ok {
+		return errors.Trace(ErrWrongPerfSchemaUsage)
+	}
+	switch tbl.Meta().Name.O {
+	case TableStmtsCurrent:
+		ps.resetEventsStmtsCurrent(t)
+	case TableStmtsHistory, TableStmtsHistoryLong:
+		t.Truncate()
+	case TableStmtsSummaryByDigest:
+		ps.resetStmtSummary(t)
+	case TableTransCurrent:
+		ps.resetEventsTransCurrent(t)
+	case TableTransHistory, TableTransHistoryLong, TableDataLockWaitsHistory:
+		t.Truncate()
+	case TableDataLockWaits:
+		ps.resetDataLockWaits(t)
+	default:
+		return errors.Trace(ErrWrongPerfSchemaUsage)
+	}
+	return nil
+}
+
 // perfschema error codes.
 const (
 	codeInvalidPerfSchemaTable terror.ErrCode = 1
 	codeInvalidTimerFlag                      = 2
+	codeWrongPerfSchemaUsage                  = 3
 )
+
+func init() {
+	perfSchemaMySQLErrCodes := map[terror.ErrCode]uint16{
+		codeWrongPerfSchemaUsage: mysql.ErrWrongPerfschemaUsage,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassPerfSchema] = perfSchemaMySQLErrCodes
+}
@@ -23,7 +23,10 @@ import (
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/perfschema"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -155,6 +158,92 @@ func (p *testPerfSchemaSuit) TestConcurrentStatement(c *C) {
 	wg.Wait()
 }
 
+func (p *testPerfSchemaSuit) TestStmtSummary(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory + "/test_stmt_summary")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	tk.MustExec("create database test_stmt_summary")
+	tk.MustExec("use test_stmt_summary")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("truncate table performance_schema.events_statements_summary_by_digest")
+
+	tk.MustExec("insert t values (1, 1), (2, 2), (3, 3)")
+	tk.MustQuery("select b from t where a > 1").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("SELECT b FROM t WHERE a > 2").Check(testkit.Rows("3"))
+	// The error is returned when reading the result.
+	rs, err := tk.Exec("select a from t t1 where b = (select b from t t2 where t2.a > t1.a)")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(err, NotNil)
+	tk.MustQuery(`select digest_text, count_star, sum_errors, sum_rows_affected, sum_rows_sent,
+		sum_rows_examined, sum_timer_wait >= max_timer_wait, max_timer_wait >= min_timer_wait, last_plan
+		from performance_schema.events_statements_summary_by_digest where schema_name = 'test_stmt_summary'
+		and digest_text like 'select%' order by digest_text`).Check(testkit.Rows(
+		"select a from t t1 where b = ( select b from t t2 where t2 . a > t1 . a ) 1 1 0 0 6 1 1 Apply{Table(t)->Table(t)->Cache->Selection->Projection->MaxOneRow}->Projection",
+		"select b from t where a > ? 2 0 0 3 3 1 1 Table(t)->Projection",
+	))
+	tk.MustQuery(`select digest_text, count_star, sum_rows_affected
+		from performance_schema.events_statements_summary_by_digest where digest_text like 'insert%'`).Check(testkit.Rows(
+		"insert t values ( ? , ? ) , ( ? , ? ) , ( ? , ? ) 1 3",
+	))
+	// The statement which fails to compile is recorded too.
+	_, err = tk.Exec("select c from t where a > 1")
+	c.Assert(err, NotNil)
+	tk.MustQuery(`select count_star, sum_errors from performance_schema.events_statements_summary_by_digest
+		where digest_text = 'select c from t where a > ?'`).Check(testkit.Rows("1 1"))
+	_, digest := parser.NormalizeDigest("select b from t where a > 1")
+	tk.MustQuery("select count(*) from performance_schema.events_statements_history where digest = ?", digest).Check(testkit.Rows("2"))
+
+	// The statement summary is reset by truncating the table, the truncate statement is recorded after it finishes.
+	tk.MustExec("truncate table performance_schema.events_statements_summary_by_digest")
+	tk.MustQuery("select digest_text from performance_schema.events_statements_summary_by_digest").Check(testkit.Rows(
+		"truncate table performance_schema . events_statements_summary_by_digest"))
+	tk.MustQuery("select count(*) from performance_schema.events_statements_summary_by_digest").Check(testkit.Rows("2"))
+	_, err = tk.Exec("truncate table performance_schema.setup_actors")
+	c.Assert(terror.ErrorEqual(err, perfschema.ErrWrongPerfSchemaUsage), IsTrue)
+}
+
+func (p *testPerfSchemaSuit) TestTransaction(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory + "/test_transaction")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	tk.MustExec("create database test_transaction")
+	tk.MustExec("use test_transaction")
+	tk.MustExec("create table t (a int primary key, b int, index idx(b))")
+	tk.MustExec("truncate table performance_schema.events_transactions_history")
+
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (1, 1), (2, 2)")
+	tk.MustExec("commit")
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (3, 3)")
+	tk.MustExec("rollback")
+	tk.MustExec("insert t values (4, 4)")
+	tk.MustQuery(`select event_name, state, access_mode, isolation_level, autocommit, commit_ts > trx_id,
+		retry_count, keys_written, timer_end >= timer_start from performance_schema.events_transactions_history
+		where access_mode = 'READ WRITE' order by trx_id`).Check(testkit.Rows(
+		"transaction COMMITTED READ WRITE REPEATABLE READ NO 1 0 4 1",
+		"transaction ROLLED BACK READ WRITE REPEATABLE READ NO <nil> 0 2 1",
+		"transaction COMMITTED READ WRITE REPEATABLE READ YES 1 0 2 1",
+	))
+	tk.MustQuery(`select count(*) from performance_schema.events_transactions_history_long
+		where access_mode = 'READ WRITE'`).Check(testkit.Rows("3"))
+	tk.MustQuery(`select state, access_mode from performance_schema.events_transactions_current`).Check(testkit.Rows(
+		"COMMITTED READ ONLY"))
+
+	tk.MustExec("truncate table performance_schema.events_transactions_current")
+	tk.MustExec("truncate table performance_schema.events_transactions_history")
+	tk.MustQuery("select count(*) from performance_schema.events_transactions_history where access_mode = 'READ WRITE'").Check(testkit.Rows("0"))
+}
+
 func exec(se tidb.Session, sql string, args ...interface{}) (ast.RecordSet, error) {
 	if len(args) == 0 {
 		rs, err := se.Execute(sql)
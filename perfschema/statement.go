@@ -16,13 +16,15 @@ package perfschema
 import (
 	"fmt"
 	"reflect"
-	"runtime"
 	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -34,67 +36,33 @@ type statementInfo struct {
 	name string
 }
 
-// StatementState provides temporary storage to a statement runtime statistics.
-// TODO:
-// 1. support statement digest.
-// 2. support prepared statement.
-type StatementState struct {
+// StatementStats contains the runtime statistics of a finished statement.
+// TODO: support the lock time and the sort, join and temporary table metrics.
+type StatementStats struct {
 	// Connection identifier
-	connID uint64
-	// Statement information
-	info *statementInfo
-	// Statement type
-	stmtType reflect.Type
-	// Source file and line number
-	source string
-	// Timer name
-	timerName enumTimerName
-	// Timer start
-	timerStart int64
-	// Timer end
-	timerEnd int64
-	// Locked time
-	lockTime int64
+	ConnID uint64
+	// The statement, it decides the instrument of the statement event.
+	Stmt ast.StmtNode
 	// SQL statement string
-	sqlText string
+	SQLText string
 	// Current schema name
-	schemaName string
-	// Number of errors
-	errNum uint32
+	SchemaName string
+	// Start time of the statement
+	StartTime time.Time
+	// End time of the statement
+	EndTime time.Time
+	// The error returned by the statement, it's nil if the statement succeeds.
+	Err error
 	// Number of warnings
-	warnNum uint32
+	Warnings uint64
 	// Rows affected
-	rowsAffected uint64
+	RowsAffected uint64
 	// Rows sent
-	rowsSent uint64
+	RowsSent uint64
 	// Rows examined
-	rowsExamined uint64
-	// Metric, temporary tables created on disk
-	createdTmpDiskTables uint32
-	// Metric, temproray tables created
-	createdTmpTables uint32
-	// Metric, number of select full join
-	selectFullJoin uint32
-	// Metric, number of select full range join
-	selectFullRangeJoin uint32
-	// Metric, number of select range
-	selectRange uint32
-	// Metric, number of select range check
-	selectRangeCheck uint32
-	// Metric, number of select scan
-	selectScan uint32
-	// Metric, number of sort merge passes
-	sortMergePasses uint32
-	// Metric, number of sort merge
-	sortRange uint32
-	// Metric, number of sort rows
-	sortRows uint32
-	// Metric, number of sort scans
-	sortScan uint32
-	// Metric, no index used flag
-	noIndexUsed uint8
-	// Metric, no good index used flag
-	noGoodIndexUsed uint8
+	RowsExamined uint64
+	// The plan of the statement
+	Plan string
 }
 
 func (ps *perfSchema) RegisterStatement(category, name string, elem interface{}) {
@@ -112,95 +80,28 @@ func (ps *perfSchema) RegisterStatement(category, name string, elem interface{})
 	}
 }
 
-func (ps *perfSchema) StartStatement(sql string, connID uint64, callerName EnumCallerName, elem interface{}) *StatementState {
+func (ps *perfSchema) RecordStatement(stats *StatementStats) {
 	if !enablePerfSchema {
-		return nil
+		return
 	}
-	stmtType := reflect.TypeOf(elem)
-	info, ok := ps.stmtInfos[stmtType]
+	digestText, digest := parser.NormalizeDigest(stats.SQLText)
+	ps.updateStmtSummary(stats, digest, digestText)
+
+	info, ok := ps.stmtInfos[reflect.TypeOf(stats.Stmt)]
 	if !ok {
 		// just ignore, do nothing else.
-		log.Errorf("No instrument registered for statement %s", stmtType)
-		return nil
+		log.Debugf("No instrument registered for statement %T", stats.Stmt)
+		return
 	}
-
 	// check and apply the configuration parameter in table setup_timers.
 	timerName, err := ps.getTimerName(flagStatement)
 	if err != nil {
 		// just ignore, do nothing else.
 		log.Error("Unable to check setup_timers table")
-		return nil
-	}
-	var timerStart int64
-	switch timerName {
-	case timerNameNanosec:
-		timerStart = time.Now().UnixNano()
-	case timerNameMicrosec:
-		timerStart = time.Now().UnixNano() / int64(time.Microsecond)
-	case timerNameMillisec:
-		timerStart = time.Now().UnixNano() / int64(time.Millisecond)
-	default:
-		return nil
-	}
-
-	// TODO: check and apply the additional configuration parameters in:
-	// - table setup_actors
-	// - table setup_setup_consumers
-	// - table setup_instruments
-	// - table setup_objects
-
-	var source string
-	callerLock.RLock()
-	source, ok = callerNames[callerName]
-	callerLock.RUnlock()
-	if !ok {
-		_, fileName, fileLine, ok := runtime.Caller(1)
-		if !ok {
-			// just ignore, do nothing else.
-			log.Error("Unable to get runtime.Caller(1)")
-			return nil
-		}
-		source = fmt.Sprintf("%s:%d", fileName, fileLine)
-
-		callerLock.Lock()
-		callerNames[callerName] = source
-		callerLock.Unlock()
-	}
-
-	return &StatementState{
-		connID:     connID,
-		info:       info,
-		stmtType:   stmtType,
-		source:     source,
-		timerName:  timerName,
-		timerStart: timerStart,
-		sqlText:    sql,
-	}
-}
-
-func (ps *perfSchema) EndStatement(state *StatementState) {
-	if !enablePerfSchema {
-		return
-	}
-	if state == nil {
-		return
-	}
-
-	switch state.timerName {
-	case timerNameNanosec:
-		state.timerEnd = time.Now().UnixNano()
-	case timerNameMicrosec:
-		state.timerEnd = time.Now().UnixNano() / int64(time.Microsecond)
-	case timerNameMillisec:
-		state.timerEnd = time.Now().UnixNano() / int64(time.Millisecond)
-	default:
 		return
 	}
-
-	log.Debugf("EndStatement: sql %s, connection id %d, type %s", state.sqlText, state.connID, state.stmtType)
-
-	record := state2Record(state)
-	err := ps.updateEventsStmtsCurrent(state.connID, record)
+	record := stats2Record(stats, info, timerName, digest, digestText)
+	err = ps.updateEventsStmtsCurrent(stats.ConnID, record)
 	if err != nil {
 		log.Error("Unable to update events_statements_current table")
 	}
@@ -210,49 +111,80 @@ func (ps *perfSchema) EndStatement(state *StatementState) {
 	}
 }
 
-func state2Record(state *StatementState) []types.Datum {
+// timerValue converts a time or a duration in nanoseconds to the unit of the timer.
+func timerValue(timerName enumTimerName, nanos int64) uint64 {
+	switch timerName {
+	case timerNameMicrosec:
+		nanos /= int64(time.Microsecond)
+	case timerNameMillisec:
+		nanos /= int64(time.Millisecond)
+	}
+	return uint64(nanos)
+}
+
+// maxMessageTextLen is the length of the MESSAGE_TEXT column.
+const maxMessageTextLen = 128
+
+func stats2Record(stats *StatementStats, info *statementInfo, timerName enumTimerName, digest, digestText string) []types.Datum {
+	var errNum uint64
+	errNo, sqlState, message := interface{}(nil), interface{}(nil), interface{}(nil)
+	if stats.Err != nil {
+		errNum = 1
+		var sqlErr *mysql.SQLError
+		if te, ok := errors.Cause(stats.Err).(*terror.Error); ok {
+			sqlErr = te.ToSQLError()
+		} else {
+			sqlErr = mysql.NewErrf(mysql.ErrUnknown, "%s", stats.Err.Error())
+		}
+		if len(sqlErr.Message) > maxMessageTextLen {
+			sqlErr.Message = sqlErr.Message[:maxMessageTextLen]
+		}
+		errNo, sqlState, message = int64(sqlErr.Code), sqlErr.State, sqlErr.Message
+	}
+	timerStart := timerValue(timerName, stats.StartTime.UnixNano())
+	timerEnd := timerValue(timerName, stats.EndTime.UnixNano())
 	return types.MakeDatums(
-		state.connID,             // THREAD_ID
-		state.info.key,           // EVENT_ID
-		nil,                      // END_EVENT_ID
-		state.info.name,          // EVENT_NAME
-		state.source,             // SOURCE
-		uint64(state.timerStart), // TIMER_START
-		uint64(state.timerEnd),   // TIMER_END
-		nil, // TIMER_WAIT
-		uint64(state.lockTime),             // LOCK_TIME
-		state.sqlText,                      // SQL_TEXT
-		nil,                                // DIGEST
-		nil,                                // DIGEST_TEXT
-		state.schemaName,                   // CURRENT_SCHEMA
-		nil,                                // OBJECT_TYPE
-		nil,                                // OBJECT_SCHEMA
-		nil,                                // OBJECT_NAME
-		nil,                                // OBJECT_INSTANCE_BEGIN
-		nil,                                // MYSQL_ERRNO,
-		nil,                                // RETURNED_SQLSTATE
-		nil,                                // MESSAGE_TEXT
-		uint64(state.errNum),               // ERRORS
-		uint64(state.warnNum),              // WARNINGS
-		state.rowsAffected,                 // ROWS_AFFECTED
-		state.rowsSent,                     // ROWS_SENT
-		state.rowsExamined,                 // ROWS_EXAMINED
-		uint64(state.createdTmpDiskTables), // CREATED_TMP_DISK_TABLES
-		uint64(state.createdTmpTables),     // CREATED_TMP_TABLES
-		uint64(state.selectFullJoin),       // SELECT_FULL_JOIN
-		uint64(state.selectFullRangeJoin),  // SELECT_FULL_RANGE_JOIN
-		uint64(state.selectRange),          // SELECT_RANGE
-		uint64(state.selectRangeCheck),     // SELECT_RANGE_CHECK
-		uint64(state.selectScan),           // SELECT_SCAN
-		uint64(state.sortMergePasses),      // SORT_MERGE_PASSES
-		uint64(state.sortRange),            // SORT_RANGE
-		uint64(state.sortRows),             // SORT_ROWS
-		uint64(state.sortScan),             // SORT_SCAN
-		uint64(state.noIndexUsed),          // NO_INDEX_USED
-		uint64(state.noGoodIndexUsed),      // NO_GOOD_INDEX_USED
-		nil, // NESTING_EVENT_ID
-		nil, // NESTING_EVENT_TYPE
-		nil, // NESTING_EVENT_LEVEL
+		stats.ConnID,        // THREAD_ID
+		info.key,            // EVENT_ID
+		nil,                 // END_EVENT_ID
+		info.name,           // EVENT_NAME
+		nil,                 // SOURCE
+		timerStart,          // TIMER_START
+		timerEnd,            // TIMER_END
+		timerEnd-timerStart, // TIMER_WAIT
+		uint64(0),           // LOCK_TIME
+		stats.SQLText,       // SQL_TEXT
+		digest,              // DIGEST
+		digestText,          // DIGEST_TEXT
+		stats.SchemaName,    // CURRENT_SCHEMA
+		nil,                 // OBJECT_TYPE
+		nil,                 // OBJECT_SCHEMA
+		nil,                 // OBJECT_NAME
+		nil,                 // OBJECT_INSTANCE_BEGIN
+		errNo,               // MYSQL_ERRNO,
+		sqlState,            // RETURNED_SQLSTATE
+		message,             // MESSAGE_TEXT
+		errNum,              // ERRORS
+		stats.Warnings,      // WARNINGS
+		stats.RowsAffected,  // ROWS_AFFECTED
+		stats.RowsSent,      // ROWS_SENT
+		stats.RowsExamined,  // ROWS_EXAMINED
+		uint64(0),           // CREATED_TMP_DISK_TABLES
+		uint64(0),           // CREATED_TMP_TABLES
+		uint64(0),           // SELECT_FULL_JOIN
+		uint64(0),           // SELECT_FULL_RANGE_JOIN
+		uint64(0),           // SELECT_RANGE
+		uint64(0),           // SELECT_RANGE_CHECK
+		uint64(0),           // SELECT_SCAN
+		uint64(0),           // SORT_MERGE_PASSES
+		uint64(0),           // SORT_RANGE
+		uint64(0),           // SORT_ROWS
+		uint64(0),           // SORT_SCAN
+		uint64(0),           // NO_INDEX_USED
+		uint64(0),           // NO_GOOD_INDEX_USED
+		nil,                 // NESTING_EVENT_ID
+		nil,                 // NESTING_EVENT_TYPE
+		nil,                 // NESTING_EVENT_LEVEL
 	)
 }
 
@@ -278,6 +210,14 @@ func (ps *perfSchema) updateEventsStmtsCurrent(connID uint64, record []types.Dat
 	return nil
 }
 
+// resetEventsStmtsCurrent removes all the rows in table events_statements_current.
+func (ps *perfSchema) resetEventsStmtsCurrent(tbl truncatableTable) {
+	tbl.Truncate()
+	for i := range ps.stmtHandles {
+		atomic.StoreInt64(&ps.stmtHandles[i], 0)
+	}
+}
+
 func (ps *perfSchema) appendEventsStmtsHistory(record []types.Datum) error {
 	tbl := ps.mTables[TableStmtsHistory]
 	if tbl == nil {
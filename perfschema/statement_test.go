@@ -14,6 +14,9 @@
 package perfschema
 
 import (
+	"fmt"
+	"time"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/util/testleak"
@@ -42,9 +45,39 @@ func (p *testStatementSuit) TestDisablePS(c *C) {
 	defer testleak.AfterTest(c)()
 	ps, err := NewPerfHandle()
 	c.Assert(err, IsNil)
-	s := ps.StartStatement("select * from t", uint64(1), CallerNameSessionExecute, &ast.SelectStmt{})
-	c.Assert(s, IsNil)
+	stats := &StatementStats{
+		ConnID:    1,
+		Stmt:      &ast.SelectStmt{},
+		SQLText:   "select * from t",
+		StartTime: time.Now(),
+		EndTime:   time.Now(),
+	}
+	ps.RecordStatement(stats)
+	c.Assert(ps.(*perfSchema).summaries.m, HasLen, 0)
 	EnablePerfSchema()
-	s = ps.StartStatement("select * from t", uint64(1), CallerNameSessionExecute, &ast.SelectStmt{})
-	c.Assert(s, NotNil)
+	ps.RecordStatement(stats)
+	c.Assert(ps.(*perfSchema).summaries.m, HasLen, 1)
+}
+
+func (p *testStatementSuit) TestStmtSummaryOverflow(c *C) {
+	defer testleak.AfterTest(c)()
+	EnablePerfSchema()
+	handle, err := NewPerfHandle()
+	c.Assert(err, IsNil)
+	ps := handle.(*perfSchema)
+	for i := 0; i < summaryElemMax+10; i++ {
+		ps.RecordStatement(&StatementStats{
+			Stmt:      &ast.SelectStmt{},
+			SQLText:   fmt.Sprintf("select a%d from t", i),
+			StartTime: time.Now(),
+			EndTime:   time.Now(),
+		})
+	}
+	c.Assert(ps.summaries.m, HasLen, summaryElemMax)
+	c.Assert(ps.summaries.m[overflowSummaryKey].execCount, Equals, uint64(11))
+
+	c.Assert(ps.TruncateTable("events_statements_summary_by_digest"), IsNil)
+	c.Assert(ps.summaries.m, HasLen, 0)
+	c.Assert(ps.TruncateTable(TableSetupActors), NotNil)
+	c.Assert(ps.TruncateTable("no_such_table"), NotNil)
 }
@@ -0,0 +1,170 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Maximum number of rows in table events_statements_summary_by_digest, the statements
+// are aggregated in the row with NULL SCHEMA_NAME and DIGEST when the table is full, same as MySQL.
+// TODO: make it configurable?
+const summaryElemMax = 10000
+
+// stmtSummaryKey is the key of a row in table events_statements_summary_by_digest.
+type stmtSummaryKey struct {
+	schemaName string
+	digest     string
+}
+
+// overflowSummaryKey is the key of the row that aggregates the statements when the table is full.
+var overflowSummaryKey = stmtSummaryKey{}
+
+// stmtSummary is the aggregated statistics of the statements with the same schema and digest.
+type stmtSummary struct {
+	// Handle of the row in table events_statements_summary_by_digest
+	handle          int64
+	digestText      string
+	execCount       uint64
+	sumLatency      time.Duration
+	minLatency      time.Duration
+	maxLatency      time.Duration
+	sumErrors       uint64
+	sumWarnings     uint64
+	sumRowsAffected uint64
+	sumRowsSent     uint64
+	sumRowsExamined uint64
+	firstSeen       time.Time
+	lastSeen        time.Time
+	lastPlan        string
+}
+
+// stmtSummaries is the statements summary by digest, it's kept in sync with table events_statements_summary_by_digest.
+type stmtSummaries struct {
+	sync.Mutex
+	m map[stmtSummaryKey]*stmtSummary
+}
+
+func (s *stmtSummary) add(stats *StatementStats) {
+	latency := stats.EndTime.Sub(stats.StartTime)
+	if s.execCount == 0 || latency < s.minLatency {
+		s.minLatency = latency
+	}
+	if latency > s.maxLatency {
+		s.maxLatency = latency
+	}
+	if s.execCount == 0 {
+		s.firstSeen = stats.EndTime
+	}
+	s.execCount++
+	s.sumLatency += latency
+	if stats.Err != nil {
+		s.sumErrors++
+	}
+	s.sumWarnings += stats.Warnings
+	s.sumRowsAffected += stats.RowsAffected
+	s.sumRowsSent += stats.RowsSent
+	s.sumRowsExamined += stats.RowsExamined
+	s.lastSeen = stats.EndTime
+	s.lastPlan = stats.Plan
+}
+
+func (s *stmtSummary) toRecord(key stmtSummaryKey, timerName enumTimerName) []types.Datum {
+	timer := func(d time.Duration) uint64 {
+		return timerValue(timerName, int64(d))
+	}
+	timestamp := func(t time.Time) types.Time {
+		return types.Time{Time: types.FromGoTime(t), Type: mysql.TypeTimestamp}
+	}
+	avgLatency := s.sumLatency / time.Duration(s.execCount)
+	record := types.MakeDatums(
+		key.schemaName,         // SCHEMA_NAME
+		key.digest,             // DIGEST
+		s.digestText,           // DIGEST_TEXT
+		s.execCount,            // COUNT_STAR
+		timer(s.sumLatency),    // SUM_TIMER_WAIT
+		timer(s.minLatency),    // MIN_TIMER_WAIT
+		timer(avgLatency),      // AVG_TIMER_WAIT
+		timer(s.maxLatency),    // MAX_TIMER_WAIT
+		s.sumErrors,            // SUM_ERRORS
+		s.sumWarnings,          // SUM_WARNINGS
+		s.sumRowsAffected,      // SUM_ROWS_AFFECTED
+		s.sumRowsSent,          // SUM_ROWS_SENT
+		s.sumRowsExamined,      // SUM_ROWS_EXAMINED
+		timestamp(s.firstSeen), // FIRST_SEEN
+		timestamp(s.lastSeen),  // LAST_SEEN
+		s.lastPlan,             // LAST_PLAN
+	)
+	if key == overflowSummaryKey {
+		record[0].SetNull()
+		record[1].SetNull()
+		record[2].SetNull()
+		record[15].SetNull()
+	}
+	return record
+}
+
+// updateStmtSummary aggregates a finished statement to table events_statements_summary_by_digest.
+func (ps *perfSchema) updateStmtSummary(stats *StatementStats, digest, digestText string) {
+	tbl := ps.mTables[TableStmtsSummaryByDigest]
+	if tbl == nil {
+		return
+	}
+	timerName, err := ps.getTimerName(flagStatement)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Error("Unable to check setup_timers table")
+		return
+	}
+
+	ps.summaries.Lock()
+	defer ps.summaries.Unlock()
+	key := stmtSummaryKey{schemaName: stats.SchemaName, digest: digest}
+	summary, ok := ps.summaries.m[key]
+	if !ok && len(ps.summaries.m) >= summaryElemMax-1 {
+		// Keeps a row for the overflow.
+		key = overflowSummaryKey
+		summary, ok = ps.summaries.m[key]
+	}
+	if !ok {
+		summary = &stmtSummary{digestText: digestText}
+	}
+	summary.add(stats)
+	record := summary.toRecord(key, timerName)
+	if ok {
+		err = tbl.UpdateRecord(nil, summary.handle, nil, record, nil)
+	} else {
+		summary.handle, err = tbl.AddRecord(nil, record)
+		if err == nil {
+			ps.summaries.m[key] = summary
+		}
+	}
+	if err != nil {
+		log.Errorf("Unable to update events_statements_summary_by_digest table %v", errors.ErrorStack(err))
+	}
+}
+
+// resetStmtSummary removes all the rows in table events_statements_summary_by_digest.
+func (ps *perfSchema) resetStmtSummary(tbl truncatableTable) {
+	ps.summaries.Lock()
+	defer ps.summaries.Unlock()
+	tbl.Truncate()
+	ps.summaries.m = make(map[stmtSummaryKey]*stmtSummary)
+}
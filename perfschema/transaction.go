@@ -0,0 +1,192 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// transactionInfo defines transaction instrument information.
+type transactionInfo struct {
+	// The registered transaction key
+	key uint64
+	// The name of the transaction instrument
+	name string
+}
+
+// TransactionStats contains the runtime statistics of a committed or rolled back transaction.
+type TransactionStats struct {
+	// Connection identifier
+	ConnID uint64
+	// Start timestamp of the transaction, it's the TRX_ID of the transaction event.
+	StartTS uint64
+	// Commit timestamp of the transaction, it's 0 if the transaction is rolled back or read only.
+	CommitTS uint64
+	// Start time of the transaction
+	StartTime time.Time
+	// End time of the transaction
+	EndTime time.Time
+	// Committed is false if the transaction is rolled back or fails to commit.
+	Committed bool
+	// ReadOnly is true if the transaction doesn't write anything.
+	ReadOnly bool
+	// Autocommit is true if the transaction is started by a statement in autocommit mode.
+	Autocommit bool
+	// Number of the times that the transaction is retried
+	RetryCount uint64
+	// Number of the keys written by the transaction
+	KeysWritten uint64
+}
+
+func (ps *perfSchema) registerTransaction() {
+	key, err := ps.addInstrument(transactionInstrumentPrefix)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Errorf("Unable to register instrument %s", transactionInstrumentPrefix)
+		return
+	}
+	ps.transInfo = &transactionInfo{
+		key:  key,
+		name: transactionInstrumentPrefix,
+	}
+}
+
+func (ps *perfSchema) RecordTransaction(stats *TransactionStats) {
+	if !enablePerfSchema {
+		return
+	}
+	ps.endLockWait(stats.StartTS, stats.EndTime)
+	if ps.transInfo == nil {
+		return
+	}
+	// check and apply the configuration parameter in table setup_timers.
+	timerName, err := ps.getTimerName(flagTransaction)
+	if err != nil {
+		// just ignore, do nothing else.
+		log.Error("Unable to check setup_timers table")
+		return
+	}
+	record := trans2Record(stats, ps.transInfo, timerName)
+	err = ps.updateEventsTransCurrent(stats.ConnID, record)
+	if err != nil {
+		log.Error("Unable to update events_transactions_current table")
+	}
+	err = ps.appendEventsTransHistory(record)
+	if err != nil {
+		log.Errorf("Unable to append to events_transactions_history table %v", errors.ErrorStack(err))
+	}
+}
+
+// isolationLevel is the isolation level of the transactions, TiDB uses snapshot isolation, which is
+// reported as REPEATABLE READ.
+const isolationLevel = "REPEATABLE READ"
+
+func trans2Record(stats *TransactionStats, info *transactionInfo, timerName enumTimerName) []types.Datum {
+	state := types.Enum{Name: "ROLLED BACK", Value: 3}
+	if stats.Committed {
+		state = types.Enum{Name: "COMMITTED", Value: 2}
+	}
+	accessMode := types.Enum{Name: "READ WRITE", Value: 2}
+	if stats.ReadOnly {
+		accessMode = types.Enum{Name: "READ ONLY", Value: 1}
+	}
+	autocommit := types.Enum{Name: "NO", Value: 2}
+	if stats.Autocommit {
+		autocommit = types.Enum{Name: "YES", Value: 1}
+	}
+	commitTS := interface{}(nil)
+	if stats.CommitTS != 0 {
+		commitTS = stats.CommitTS
+	}
+	timerStart := timerValue(timerName, stats.StartTime.UnixNano())
+	timerEnd := timerValue(timerName, stats.EndTime.UnixNano())
+	return types.MakeDatums(
+		stats.ConnID,        // THREAD_ID
+		info.key,            // EVENT_ID
+		nil,                 // END_EVENT_ID
+		info.name,           // EVENT_NAME
+		state,               // STATE
+		stats.StartTS,       // TRX_ID
+		nil,                 // GTID
+		nil,                 // XID_FORMAT_ID
+		nil,                 // XID_GTRID
+		nil,                 // XID_BQUAL
+		nil,                 // XA_STATE
+		nil,                 // SOURCE
+		timerStart,          // TIMER_START
+		timerEnd,            // TIMER_END
+		timerEnd-timerStart, // TIMER_WAIT
+		accessMode,          // ACCESS_MODE
+		isolationLevel,      // ISOLATION_LEVEL
+		autocommit,          // AUTOCOMMIT
+		uint64(0),           // NUMBER_OF_SAVEPOINTS
+		uint64(0),           // NUMBER_OF_ROLLBACK_TO_SAVEPOINT
+		uint64(0),           // NUMBER_OF_RELEASE_SAVEPOINT
+		nil,                 // OBJECT_INSTANCE_BEGIN
+		nil,                 // NESTING_EVENT_ID
+		nil,                 // NESTING_EVENT_TYPE
+		commitTS,            // COMMIT_TS
+		stats.RetryCount,    // RETRY_COUNT
+		stats.KeysWritten,   // KEYS_WRITTEN
+	)
+}
+
+func (ps *perfSchema) updateEventsTransCurrent(connID uint64, record []types.Datum) error {
+	tbl := ps.mTables[TableTransCurrent]
+	if tbl == nil {
+		return nil
+	}
+	index := connID % uint64(currentElemMax)
+	handle := atomic.LoadInt64(&ps.transHandles[index])
+	if handle == 0 {
+		newHandle, err := tbl.AddRecord(nil, record)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		atomic.StoreInt64(&ps.transHandles[index], newHandle)
+		return nil
+	}
+	err := tbl.UpdateRecord(nil, handle, nil, record, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// resetEventsTransCurrent removes all the rows in table events_transactions_current.
+func (ps *perfSchema) resetEventsTransCurrent(tbl truncatableTable) {
+	tbl.Truncate()
+	for i := range ps.transHandles {
+		atomic.StoreInt64(&ps.transHandles[i], 0)
+	}
+}
+
+func (ps *perfSchema) appendEventsTransHistory(record []types.Datum) error {
+	for _, name := range []string{TableTransHistory, TableTransHistoryLong} {
+		tbl := ps.mTables[name]
+		if tbl == nil {
+			continue
+		}
+		_, err := tbl.AddRecord(nil, record)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+type testTransactionSuit struct {
+}
+
+var _ = Suite(&testTransactionSuit{})
+
+func (p *testTransactionSuit) TestLockWait(c *C) {
+	defer testleak.AfterTest(c)()
+	EnablePerfSchema()
+	handle, err := NewPerfHandle()
+	c.Assert(err, IsNil)
+	ps := handle.(*perfSchema)
+	waits := ps.mTables[TableDataLockWaits]
+	history := ps.mTables[TableDataLockWaitsHistory]
+
+	ps.OnLockWait(10, 5, []byte("k1"), []byte("p"), 3000)
+	c.Assert(ps.lockWaits.m, HasLen, 1)
+	w := ps.lockWaits.m[10]
+	row, err := waits.Row(nil, w.handle)
+	c.Assert(err, IsNil)
+	c.Assert(row[:6], DeepEquals, types.MakeDatums("TiKV", uint64(10), uint64(5), "6b31", "70", uint64(3000)))
+	timerStart := row[6].GetUint64()
+
+	// The wait goes on if the transaction is blocked by the same transaction.
+	ps.OnLockWait(10, 5, []byte("k2"), []byte("p"), 3000)
+	c.Assert(ps.lockWaits.m[10].handle, Equals, w.handle)
+	row, err = waits.Row(nil, w.handle)
+	c.Assert(err, IsNil)
+	c.Assert(row[3].GetString(), Equals, "6b32")
+	c.Assert(row[6].GetUint64(), Equals, timerStart)
+
+	// The wait ends when the locks are resolved.
+	time.Sleep(time.Millisecond)
+	ps.OnLockWaitDone(10)
+	c.Assert(ps.lockWaits.m, HasLen, 0)
+	_, err = waits.Row(nil, w.handle)
+	c.Assert(terror.ErrorEqual(err, table.ErrRowNotFound), IsTrue)
+	h, ok, err := history.Seek(nil, -1)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	row, err = history.Row(nil, h)
+	c.Assert(err, IsNil)
+	c.Assert(row[:7], DeepEquals, types.MakeDatums("TiKV", uint64(10), uint64(5), "6b32", "70", uint64(3000), timerStart))
+	c.Assert(row[8].GetUint64(), Equals, row[7].GetUint64()-timerStart)
+	c.Assert(row[8].GetUint64() >= uint64(time.Millisecond), IsTrue)
+
+	// The wait ends when the transaction finishes.
+	ps.OnLockWait(11, 5, []byte("k1"), []byte("p"), 3000)
+	w = ps.lockWaits.m[11]
+	ps.RecordTransaction(&TransactionStats{StartTS: 11, StartTime: time.Now(), EndTime: time.Now()})
+	c.Assert(ps.lockWaits.m, HasLen, 0)
+	_, err = waits.Row(nil, w.handle)
+	c.Assert(terror.ErrorEqual(err, table.ErrRowNotFound), IsTrue)
+
+	ps.OnLockWait(12, 5, []byte("k1"), []byte("p"), 3000)
+	c.Assert(ps.TruncateTable(TableDataLockWaits), IsNil)
+	c.Assert(ps.lockWaits.m, HasLen, 0)
+	ps.OnLockWaitDone(12)
+
+	// The lock waits on the records are counted by the tables.
+	key := tablecodec.EncodeRowKeyWithHandle(1001, 1)
+	ps.OnLockWait(13, 5, key, key, 3000)
+	ps.OnLockWait(13, 5, key, key, 3000)
+	c.Assert(tables.GetTableStats(1001).LockWaits, Equals, uint64(1))
+	// A new wait starts if the transaction is blocked by another transaction.
+	ps.OnLockWait(13, 6, key, key, 3000)
+	c.Assert(tables.GetTableStats(1001).LockWaits, Equals, uint64(2))
+	ps.OnLockWaitDone(13)
+	c.Assert(ps.lockWaits.blocking, HasLen, 0)
+}
+
+func (p *testTransactionSuit) TestCountLockWait(c *C) {
+	defer testleak.AfterTest(c)()
+	handle, err := NewPerfHandle()
+	c.Assert(err, IsNil)
+	ps := handle.(*perfSchema)
+	enablePerfSchema = false
+	defer EnablePerfSchema()
+
+	// The lock waits are counted by the tables even if the performance schema is disabled.
+	key := tablecodec.EncodeRowKeyWithHandle(1002, 1)
+	ps.OnLockWait(14, 5, key, key, 3000)
+	ps.OnLockWait(14, 5, key, key, 3000)
+	c.Assert(tables.GetTableStats(1002).LockWaits, Equals, uint64(1))
+	c.Assert(ps.lockWaits.m, HasLen, 0)
+	ps.OnLockWaitDone(14)
+	c.Assert(ps.lockWaits.blocking, HasLen, 0)
+	ps.OnLockWait(14, 5, key, key, 3000)
+	c.Assert(tables.GetTableStats(1002).LockWaits, Equals, uint64(2))
+	ps.OnLockWaitDone(14)
+}
@@ -112,7 +112,7 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 		case *ast.UnionStmt:
 			p = b.buildUnion(v)
 		case *ast.TableName:
-			p = b.buildDataSource(v)
+			p = b.buildDataSource(v, &x.AsName)
 		default:
 			b.err = ErrUnsupportedType.Gen("unsupported table source type %T", v)
 			return nil
@@ -120,9 +120,6 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 		if b.err != nil {
 			return nil
 		}
-		if v, ok := p.(*DataSource); ok {
-			v.TableAsName = &x.AsName
-		}
 		if x.AsName.L != "" {
 			for _, col := range p.Schema().Columns {
 				col.TblName = x.AsName
@@ -911,7 +908,7 @@ func (b *planBuilder) buildTableDual() LogicalPlan {
 	return dual
 }
 
-func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
+func (b *planBuilder) buildDataSource(tn *ast.TableName, asName *model.CIStr) LogicalPlan {
 	statisticTable := statscache.GetStatisticsTableCache(b.ctx, tn.TableInfo)
 	if b.err != nil {
 		return nil
@@ -933,11 +930,16 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 		baseLogicalPlan: newBaseLogicalPlan(Tbl, b.allocator),
 		statisticTable:  statisticTable,
 		DBName:          &schemaName,
+		TableAsName:     asName,
 	}
 	p.self = p
 	p.initIDAndContext(b.ctx)
 	// Equal condition contains a column from previous joined table.
 	schema := expression.NewSchema(make([]*expression.Column, 0, len(tableInfo.Columns))...)
+	// fullSchema contains the virtual generated columns which are not read from the data source.
+	fullSchema := expression.NewSchema(make([]*expression.Column, 0, len(tableInfo.Columns))...)
+	fullCols := make([]*model.ColumnInfo, 0, len(tableInfo.Columns))
+	hasGenerated := false
 	for i, col := range tableInfo.Columns {
 		if b.inUpdateStmt {
 			switch col.State {
@@ -948,18 +950,85 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 		} else if col.State != model.StatePublic {
 			continue
 		}
-		p.Columns = append(p.Columns, col)
-		schema.Append(&expression.Column{
+		newCol := &expression.Column{
 			FromID:   p.id,
 			ColName:  col.Name,
 			TblName:  tableInfo.Name,
 			DBName:   schemaName,
 			RetType:  &col.FieldType,
 			Position: i,
-			ID:       col.ID})
+			ID:       col.ID}
+		fullCols = append(fullCols, col)
+		fullSchema.Append(newCol)
+		hasGenerated = hasGenerated || col.IsGenerated()
+		if col.IsVirtualGenerated() {
+			continue
+		}
+		p.Columns = append(p.Columns, col)
+		schema.Append(newCol)
 	}
 	p.SetSchema(schema)
-	return p
+	if !hasGenerated {
+		return p
+	}
+	return b.buildGeneratedColumns(p, fullCols, fullSchema)
+}
+
+// buildGeneratedColumns rewrites the expressions of the generated columns of the data source.
+// The indexed stored generated columns are recorded in the data source, so the predicates on their expressions
+// can be matched to the indices. If there are virtual generated columns, a projection is built on the data source
+// to evaluate them from the columns read.
+func (b *planBuilder) buildGeneratedColumns(p *DataSource, fullCols []*model.ColumnInfo, fullSchema *expression.Schema) LogicalPlan {
+	exprs := make([]expression.Expression, 0, fullSchema.Len())
+	for _, col := range fullSchema.Columns {
+		exprs = append(exprs, col)
+	}
+	hasVirtual := false
+	for i, col := range fullCols {
+		if !col.IsGenerated() {
+			continue
+		}
+		expr, err := rewriteTableExpr(b.ctx, col.GeneratedExprString, p.tableInfo, fullCols, fullSchema)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		// A generated column can only refer to the generated columns defined prior to it,
+		// so the virtual columns it refers to have been substituted by their expressions.
+		expr = expression.ColumnSubstitute(expr, fullSchema, exprs)
+		if col.GeneratedStored {
+			if isIndexedColumn(p.tableInfo, col) {
+				p.genExprs = append(p.genExprs, &generatedExpr{expr: expr, col: fullSchema.Columns[i]})
+			}
+			continue
+		}
+		hasVirtual = true
+		exprs[i] = expression.NewCastFunc(&col.FieldType, expr, b.ctx)
+	}
+	if !hasVirtual {
+		return p
+	}
+
+	proj := &Projection{
+		Exprs:           exprs,
+		baseLogicalPlan: newBaseLogicalPlan(Proj, b.allocator),
+	}
+	proj.self = proj
+	proj.initIDAndContext(b.ctx)
+	proj.SetSchema(fullSchema)
+	addChild(proj, p)
+	return proj
+}
+
+func isIndexedColumn(tblInfo *model.TableInfo, col *model.ColumnInfo) bool {
+	for _, idx := range tblInfo.Indices {
+		for _, idxCol := range idx.Columns {
+			if idxCol.Name.L == col.Name.L {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // ApplyConditionChecker checks whether all or any output of apply matches a condition.
@@ -187,6 +187,15 @@ type DataSource struct {
 	LimitCount *int64
 
 	statisticTable *statistics.Table
+
+	// genExprs are the expressions of the indexed stored generated columns.
+	genExprs []*generatedExpr
+}
+
+// generatedExpr is the expression of a generated column.
+type generatedExpr struct {
+	expr expression.Expression
+	col  *expression.Column
 }
 
 // Trim trims extra columns in src rows.
@@ -52,6 +52,8 @@ type logicalOptRule interface {
 // Optimize does optimization and creates a Plan.
 // The node must be prepared first.
 func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, error) {
+	span := ctx.GetSessionVars().StmtCtx.Span.StartChild("plan.Optimize")
+	defer span.Finish()
 	// We have to infer type again because after parameter is set, the expression type may change.
 	if err := InferType(ctx.GetSessionVars().StmtCtx, node); err != nil {
 		return nil, errors.Trace(err)
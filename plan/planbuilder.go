@@ -36,15 +36,17 @@ var (
 	ErrUnknownColumn        = terror.ClassOptimizerPlan.New(CodeUnknownColumn, "Unknown column '%s' in '%s'")
 	ErrWrongArguments       = terror.ClassOptimizerPlan.New(CodeWrongArguments, "Incorrect arguments to EXECUTE")
 	ErrAmbiguous            = terror.ClassOptimizerPlan.New(CodeAmbiguous, "Column '%s' in field list is ambiguous")
+	ErrDisallowedTableExpr  = terror.ClassOptimizerPlan.New(CodeDisallowedTableExpr, "Expression '%s' is not allowed in table definition")
 )
 
 // Error codes.
 const (
-	CodeUnsupportedType terror.ErrCode = 1
-	SystemInternalError terror.ErrCode = 2
-	CodeAmbiguous       terror.ErrCode = 1052
-	CodeUnknownColumn   terror.ErrCode = 1054
-	CodeWrongArguments  terror.ErrCode = 1210
+	CodeUnsupportedType     terror.ErrCode = 1
+	SystemInternalError     terror.ErrCode = 2
+	CodeDisallowedTableExpr terror.ErrCode = 3
+	CodeAmbiguous           terror.ErrCode = 1052
+	CodeUnknownColumn       terror.ErrCode = 1054
+	CodeWrongArguments      terror.ErrCode = 1210
 )
 
 func init() {
@@ -100,6 +102,8 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildExecute(x)
 	case *ast.ExplainStmt:
 		return b.buildExplain(x)
+	case *ast.TraceStmt:
+		return b.buildTrace(x)
 	case *ast.InsertStmt:
 		return b.buildInsert(x)
 	case *ast.LoadDataStmt:
@@ -581,6 +585,10 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		baseLogicalPlan: newBaseLogicalPlan(Ins, b.allocator),
 	}
 	cols := table.Cols()
+	if err := checkInsertGeneratedColumns(insert, table); err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
 	for _, valuesItem := range insert.Lists {
 		exprList := make([]expression.Expression, 0, len(valuesItem))
 		for i, valueItem := range valuesItem {
@@ -664,6 +672,49 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 	return insertPlan
 }
 
+// checkInsertGeneratedColumns returns an error if a value other than DEFAULT is specified for a generated column.
+func checkInsertGeneratedColumns(insert *ast.InsertStmt, t table.Table) error {
+	tblInfo := t.Meta()
+	targetCol := func(i int) *table.Column {
+		if len(insert.Columns) == 0 {
+			if i < len(t.Cols()) {
+				return t.Cols()[i]
+			}
+			return nil
+		}
+		if i < len(insert.Columns) {
+			return table.FindCol(t.Cols(), insert.Columns[i].Name.O)
+		}
+		return nil
+	}
+	for _, valuesItem := range insert.Lists {
+		for i, valueItem := range valuesItem {
+			if _, ok := valueItem.(*ast.DefaultExpr); ok {
+				continue
+			}
+			if col := targetCol(i); col != nil && col.IsGenerated() {
+				return table.ErrBadGeneratedColumn.GenByArgs(col.Name.O, tblInfo.Name.O)
+			}
+		}
+	}
+	for _, assign := range insert.Setlist {
+		if _, ok := assign.Expr.(*ast.DefaultExpr); ok {
+			continue
+		}
+		if col := table.FindCol(t.Cols(), assign.Column.Name.O); col != nil && col.IsGenerated() {
+			return table.ErrBadGeneratedColumn.GenByArgs(col.Name.O, tblInfo.Name.O)
+		}
+	}
+	if insert.Select != nil {
+		for i := range t.Cols() {
+			if col := targetCol(i); col != nil && col.IsGenerated() {
+				return table.ErrBadGeneratedColumn.GenByArgs(col.Name.O, tblInfo.Name.O)
+			}
+		}
+	}
+	return nil
+}
+
 func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 	p := &LoadData{
 		IsLocal:    ld.IsLocal,
@@ -678,6 +729,14 @@ func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 
 func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 	p := &DDL{Statement: node}
+	if v, ok := node.(*ast.CreateTableStmt); ok && v.Select != nil {
+		selectPlan, err := Optimize(b.ctx, v.Select, b.is)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		p.SelectPlan = selectPlan
+	}
 	p.SetSchema(expression.NewSchema())
 	return p
 }
@@ -710,6 +769,31 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 	return p
 }
 
+func (b *planBuilder) buildTrace(trace *ast.TraceStmt) Plan {
+	targetPlan, err := Optimize(b.ctx, trace.Stmt, b.is)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	p := &Trace{StmtPlan: targetPlan}
+	addChild(p, targetPlan)
+	schema := expression.NewSchema(make([]*expression.Column, 0, 3)...)
+	schema.Append(&expression.Column{
+		ColName: model.NewCIStr("operation"),
+		RetType: types.NewFieldType(mysql.TypeString),
+	})
+	schema.Append(&expression.Column{
+		ColName: model.NewCIStr("startTS"),
+		RetType: types.NewFieldType(mysql.TypeString),
+	})
+	schema.Append(&expression.Column{
+		ColName: model.NewCIStr("duration"),
+		RetType: types.NewFieldType(mysql.TypeString),
+	})
+	p.SetSchema(schema)
+	return p
+}
+
 func buildShowProcedureSchema() *expression.Schema {
 	tblName := "ROUTINES"
 	schema := expression.NewSchema(make([]*expression.Column, 0, 11)...)
@@ -814,9 +898,10 @@ func getShowColNamesAndTypes(s *ast.ShowStmt) (names []string, ftypes []byte) {
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeLonglong,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
 	case ast.ShowProcessList:
-		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"}
+		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info", "Mem"}
 		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
-			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar, mysql.TypeString}
+			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar, mysql.TypeString,
+			mysql.TypeLonglong}
 	}
 	return
 }
@@ -212,6 +212,8 @@ type DDL struct {
 	basePlan
 
 	Statement ast.DDLNode
+	// SelectPlan is the plan of the select statement in CREATE TABLE ... SELECT.
+	SelectPlan Plan
 }
 
 // Explain represents a explain plan.
@@ -220,3 +222,10 @@ type Explain struct {
 
 	StmtPlan Plan
 }
+
+// Trace represents a trace plan.
+type Trace struct {
+	basePlan
+
+	StmtPlan Plan
+}
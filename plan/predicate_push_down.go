@@ -55,9 +55,44 @@ func (p *Selection) PredicatePushDown(predicates []expression.Expression) ([]exp
 
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *DataSource) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
+	if len(p.genExprs) > 0 {
+		for i, cond := range predicates {
+			predicates[i] = p.substituteGeneratedExpr(cond)
+		}
+	}
 	return predicates, p, nil
 }
 
+// substituteGeneratedExpr substitutes the expressions of the indexed stored generated columns with the columns,
+// so the predicates on those expressions can use the indices.
+func (p *DataSource) substituteGeneratedExpr(expr expression.Expression) expression.Expression {
+	for _, gen := range p.genExprs {
+		if expr.Equal(gen.expr, p.ctx) && expr.GetType().Tp == gen.col.RetType.Tp {
+			return gen.col.Clone()
+		}
+	}
+	fun, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return expr
+	}
+	args := fun.GetArgs()
+	newArgs := make([]expression.Expression, 0, len(args))
+	changed := false
+	for _, arg := range args {
+		newArg := p.substituteGeneratedExpr(arg)
+		changed = changed || newArg != arg
+		newArgs = append(newArgs, newArg)
+	}
+	if !changed {
+		return expr
+	}
+	newFun, err := expression.NewFunction(fun.GetCtx(), fun.FuncName.L, fun.RetType, newArgs...)
+	if err != nil {
+		return expr
+	}
+	return newFun
+}
+
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *TableDual) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
 	return predicates, p, nil
@@ -175,6 +175,15 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 				return inNode, true
 			}
 		}
+	case *ast.ColumnOption:
+		if v.Tp == ast.ColumnOptionCheck || v.Tp == ast.ColumnOptionGenerated {
+			// Check and generated column expressions are resolved against the table definition in ddl.
+			return inNode, true
+		}
+	case *ast.Constraint:
+		if v.Tp == ast.ConstraintCheck {
+			return inNode, true
+		}
 	case *ast.CreateIndexStmt:
 		nr.pushContext()
 	case *ast.CreateTableStmt:
@@ -930,9 +939,10 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeLonglong,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
 	case ast.ShowProcessList:
-		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"}
+		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info", "Mem"}
 		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
-			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar, mysql.TypeString}
+			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar, mysql.TypeString,
+			mysql.TypeLonglong}
 	}
 	for i, name := range names {
 		f := &ast.ResultField{
@@ -0,0 +1,203 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser"
+)
+
+// disallowedTableExprFuncs are the functions whose results are not determined by the row,
+// so they can't be used in an expression stored in the table definition.
+var disallowedTableExprFuncs = map[string]struct{}{
+	"connection_id":     {},
+	"curdate":           {},
+	"current_date":      {},
+	"current_time":      {},
+	"current_timestamp": {},
+	"current_user":      {},
+	"curtime":           {},
+	"database":          {},
+	"found_rows":        {},
+	"get_lock":          {},
+	"last_insert_id":    {},
+	"localtime":         {},
+	"localtimestamp":    {},
+	"now":               {},
+	"rand":              {},
+	"release_lock":      {},
+	"row_count":         {},
+	"schema":            {},
+	"sleep":             {},
+	"sysdate":           {},
+	"unix_timestamp":    {},
+	"user":              {},
+	"utc_date":          {},
+	"uuid":              {},
+	"version":           {},
+}
+
+// ParseTableExpr parses the text of an expression stored in the table definition.
+func ParseTableExpr(exprStr string) (ast.ExprNode, error) {
+	stmt, err := parser.New().ParseOneStmt("select "+exprStr, "", "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sel, ok := stmt.(*ast.SelectStmt)
+	if !ok || sel.From != nil || sel.Where != nil || len(sel.Fields.Fields) != 1 || sel.Fields.Fields[0].Expr == nil {
+		return nil, ErrDisallowedTableExpr.GenByArgs(exprStr)
+	}
+	return sel.Fields.Fields[0].Expr, nil
+}
+
+// RewriteTableExpr parses the text of an expression stored in the table definition,
+// and rewrites it to an expression.Expression that can be evaluated directly on a row of the table.
+// The row must contain all the writable columns of the table, ordered by their offsets.
+func RewriteTableExpr(ctx context.Context, exprStr string, tblInfo *model.TableInfo) (expression.Expression, error) {
+	cols := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	schema := expression.NewSchema()
+	for _, col := range tblInfo.Columns {
+		if col.State == model.StateDeleteOnly || col.State == model.StateDeleteReorganization {
+			continue
+		}
+		cols = append(cols, col)
+		schema.Append(&expression.Column{
+			FromID:   "table_expr",
+			ColName:  col.Name,
+			TblName:  tblInfo.Name,
+			RetType:  &col.FieldType,
+			Position: col.Offset,
+			ID:       col.ID,
+		})
+	}
+	expr, err := rewriteTableExpr(ctx, exprStr, tblInfo, cols, schema)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	expr.ResolveIndices(schema)
+	return expr, nil
+}
+
+// rewriteTableExpr rewrites the text of an expression stored in the table definition against the schema,
+// cols are the column infos of the columns in the schema.
+func rewriteTableExpr(ctx context.Context, exprStr string, tblInfo *model.TableInfo, cols []*model.ColumnInfo, schema *expression.Schema) (expression.Expression, error) {
+	expr, err := ParseTableExpr(exprStr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	checker := &tableExprChecker{tblInfo: tblInfo, cols: cols, schema: schema, exprStr: exprStr}
+	expr.Accept(checker)
+	if checker.err != nil {
+		return nil, errors.Trace(checker.err)
+	}
+	if err = InferType(ctx.GetSessionVars().StmtCtx, expr); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	b := &planBuilder{
+		ctx:       ctx,
+		allocator: new(idAllocator),
+		colMapper: make(map[*ast.ColumnNameExpr]int),
+	}
+	er := &expressionRewriter{
+		b:        b,
+		asScalar: true,
+		ctx:      ctx,
+		schema:   schema,
+	}
+	expr.Accept(er)
+	if er.err != nil {
+		return nil, errors.Trace(er.err)
+	}
+	if len(er.ctxStack) != 1 || getRowLen(er.ctxStack[0]) != 1 {
+		return nil, ErrDisallowedTableExpr.GenByArgs(exprStr)
+	}
+	return er.ctxStack[0], nil
+}
+
+// ExtractColumnNames extracts the names of all the columns referenced by the expression.
+func ExtractColumnNames(expr ast.ExprNode) []model.CIStr {
+	extractor := &columnNameExtractor{}
+	expr.Accept(extractor)
+	return extractor.names
+}
+
+type columnNameExtractor struct {
+	names []model.CIStr
+}
+
+// Enter implements ast.Visitor interface.
+func (e *columnNameExtractor) Enter(inNode ast.Node) (outNode ast.Node, skipChildren bool) {
+	if v, ok := inNode.(*ast.ColumnNameExpr); ok {
+		e.names = append(e.names, v.Name.Name)
+	}
+	return inNode, false
+}
+
+// Leave implements ast.Visitor interface.
+func (e *columnNameExtractor) Leave(inNode ast.Node) (node ast.Node, ok bool) {
+	return inNode, true
+}
+
+// tableExprChecker checks whether an expression can be stored in the table definition.
+// Subqueries, aggregate functions, variables, parameter markers and nondeterministic functions are not allowed,
+// and all the column names must refer to the table itself.
+// It also sets the referenced column of each column name for type inference.
+type tableExprChecker struct {
+	tblInfo *model.TableInfo
+	cols    []*model.ColumnInfo
+	schema  *expression.Schema
+	exprStr string
+	err     error
+}
+
+// Enter implements ast.Visitor interface.
+func (c *tableExprChecker) Enter(inNode ast.Node) (outNode ast.Node, skipChildren bool) {
+	switch v := inNode.(type) {
+	case *ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.AggregateFuncExpr,
+		*ast.VariableExpr, *ast.ParamMarkerExpr, *ast.DefaultExpr, *ast.ValuesExpr:
+		c.err = ErrDisallowedTableExpr.GenByArgs(c.exprStr)
+	case *ast.PatternInExpr:
+		if v.Sel != nil {
+			c.err = ErrDisallowedTableExpr.GenByArgs(c.exprStr)
+		}
+	case *ast.FuncCallExpr:
+		if _, ok := disallowedTableExprFuncs[v.FnName.L]; ok {
+			c.err = ErrDisallowedTableExpr.GenByArgs(c.exprStr)
+		}
+	case *ast.ColumnNameExpr:
+		col, err := c.schema.FindColumn(v.Name)
+		if err != nil {
+			c.err = errors.Trace(err)
+		} else if col == nil {
+			c.err = ErrUnknownColumn.GenByArgs(v.Name.Name.O, "table definition")
+		} else {
+			v.Refer = &ast.ResultField{
+				Column:    c.cols[c.schema.ColumnIndex(col)],
+				Table:     c.tblInfo,
+				TableName: &ast.TableName{Name: c.tblInfo.Name, TableInfo: c.tblInfo},
+			}
+		}
+	}
+	return inNode, c.err != nil
+}
+
+// Leave implements ast.Visitor interface.
+func (c *tableExprChecker) Leave(inNode ast.Node) (node ast.Node, ok bool) {
+	return inNode, c.err == nil
+}
@@ -43,6 +43,13 @@ type typeInferrer struct {
 }
 
 func (v *typeInferrer) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
+	switch x := in.(type) {
+	case *ast.ColumnOption:
+		// Check and generated column expressions are not resolved by the name resolver, their types are inferred in ddl.
+		return in, x.Tp == ast.ColumnOptionCheck || x.Tp == ast.ColumnOptionGenerated
+	case *ast.Constraint:
+		return in, x.Tp == ast.ConstraintCheck
+	}
 	return in, false
 }
 
@@ -271,6 +271,12 @@ func (p *UserPrivileges) loadGlobalPrivileges(ctx context.Context) error {
 			break
 		}
 		for i := userTablePrivColumnStartIndex; i < len(fs); i++ {
+			f := fs[i]
+			p, ok := mysql.Col2PrivType[f.ColumnAsName.O]
+			if !ok {
+				// The columns after the privilege columns, such as Ssl_type and plugin, are not privileges.
+				continue
+			}
 			d := row.Data[i]
 			if d.Kind() != types.KindMysqlEnum {
 				return errInvalidPrivilegeType.Gen("Privilege should be mysql.Enum: %v(%T)", d, d)
@@ -279,11 +285,6 @@ func (p *UserPrivileges) loadGlobalPrivileges(ctx context.Context) error {
 			if ed.String() != "Y" {
 				continue
 			}
-			f := fs[i]
-			p, ok := mysql.Col2PrivType[f.ColumnAsName.O]
-			if !ok {
-				return errInvalidPrivilegeType.Gen("Unknown Privilege Type!")
-			}
 			ps.add(p)
 		}
 	}
@@ -0,0 +1,259 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/mysql"
+)
+
+// authPlugin is an authentication method of the MySQL protocol.
+// See https://dev.mysql.com/doc/internals/en/authentication-method.html
+type authPlugin interface {
+	// name returns the plugin name, which is stored in mysql.user and sent to the client.
+	name() string
+	// switchRequired reports whether the auth data must be computed by the client plugin of the same name,
+	// the server sends an AuthSwitchRequest if the client uses another one.
+	switchRequired() bool
+	// authenticate verifies the auth data sent by the client, it may exchange more packets with the client.
+	authenticate(cc *clientConn, user string, authData []byte) (bool, error)
+}
+
+var authPlugins = make(map[string]authPlugin)
+
+func registerAuthPlugin(plugin authPlugin) {
+	authPlugins[plugin.name()] = plugin
+}
+
+func init() {
+	registerAuthPlugin(nativePasswordPlugin{})
+	registerAuthPlugin(cachingSha2PasswordPlugin{})
+	registerAuthPlugin(socketPlugin{})
+}
+
+// authenticate authenticates the client with the plugin of the account. clientPlugin is the plugin
+// the client uses to compute authData.
+func (cc *clientConn) authenticate(host, clientPlugin string, authData []byte) error {
+	user := fmt.Sprintf("%s@%s", cc.user, host)
+	errAccessDenied := mysql.NewErr(mysql.ErrAccessDenied, cc.user, host, "Yes")
+	pluginName, err := cc.ctx.GetAuthPlugin(user)
+	if err != nil {
+		log.Errorf("[%d] get authentication plugin of user %s error %v", cc.connectionID, user, err)
+		return errors.Trace(errAccessDenied)
+	}
+	plugin, ok := authPlugins[pluginName]
+	if !ok {
+		log.Errorf("[%d] user %s uses unknown authentication plugin %s", cc.connectionID, user, pluginName)
+		return errors.Trace(errAccessDenied)
+	}
+	if plugin.switchRequired() && clientPlugin != pluginName {
+		if cc.capability&mysql.ClientPluginAuth == 0 {
+			return errors.Trace(errAccessDenied)
+		}
+		authData, err = cc.writeAuthSwitchRequest(pluginName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	ok, err = plugin.authenticate(cc, user, authData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !ok {
+		return errors.Trace(errAccessDenied)
+	}
+	return nil
+}
+
+// writeAuthSwitchRequest asks the client to authenticate with the plugin, it returns the auth data
+// in the AuthSwitchResponse packet.
+func (cc *clientConn) writeAuthSwitchRequest(plugin string) ([]byte, error) {
+	data := cc.alloc.AllocWithLen(4, 4+1+len(plugin)+1+len(cc.salt)+1)
+	data = append(data, mysql.AuthSwitchHeader)
+	data = append(data, plugin...)
+	data = append(data, 0)
+	data = append(data, cc.salt...)
+	data = append(data, 0)
+	if err := cc.writePacket(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := cc.flush(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	data, err := cc.readPacket()
+	return data, errors.Trace(err)
+}
+
+// writeAuthMoreData sends the extra auth data of the plugin to the client.
+func (cc *clientConn) writeAuthMoreData(moreData []byte) error {
+	data := cc.alloc.AllocWithLen(4, 4+1+len(moreData))
+	data = append(data, mysql.AuthMoreDataHeader)
+	data = append(data, moreData...)
+	if err := cc.writePacket(data); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// isSecure reports whether the password can be sent in clear text over the connection.
+func (cc *clientConn) isSecure() bool {
+	return cc.tlsState != nil || cc.unixConn != nil
+}
+
+// nativePasswordPlugin implements mysql_native_password.
+type nativePasswordPlugin struct{}
+
+func (p nativePasswordPlugin) name() string {
+	return mysql.AuthNativePassword
+}
+
+func (p nativePasswordPlugin) switchRequired() bool {
+	return true
+}
+
+func (p nativePasswordPlugin) authenticate(cc *clientConn, user string, authData []byte) (bool, error) {
+	return cc.ctx.Auth(user, authData, cc.salt), nil
+}
+
+// The status of caching_sha2_password sent to the client, and the request of the public key.
+const (
+	cachingSha2RequestPublicKey byte = 2
+	cachingSha2FastAuthSuccess  byte = 3
+	cachingSha2PerformFullAuth  byte = 4
+)
+
+// cachingSha2PasswordPlugin implements caching_sha2_password.
+// See https://dev.mysql.com/doc/dev/mysql-server/latest/page_caching_sha2_authentication_exchanges.html
+type cachingSha2PasswordPlugin struct{}
+
+func (p cachingSha2PasswordPlugin) name() string {
+	return mysql.AuthCachingSha2Password
+}
+
+func (p cachingSha2PasswordPlugin) switchRequired() bool {
+	return true
+}
+
+func (p cachingSha2PasswordPlugin) authenticate(cc *clientConn, user string, authData []byte) (bool, error) {
+	if len(authData) == 0 {
+		// The client sends empty auth data if the password is empty.
+		return cc.ctx.AuthCleartext(user, nil), nil
+	}
+	// Fast authentication succeeds if the user has passed the full authentication before.
+	if cc.ctx.AuthCachingSha2(user, authData, cc.salt) {
+		return true, errors.Trace(cc.writeAuthMoreData([]byte{cachingSha2FastAuthSuccess}))
+	}
+
+	if err := cc.writeAuthMoreData([]byte{cachingSha2PerformFullAuth}); err != nil {
+		return false, errors.Trace(err)
+	}
+	data, err := cc.readPacket()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if cc.isSecure() {
+		// The password is sent in clear text.
+		return cc.ctx.AuthCleartext(user, trimNullTerminator(data)), nil
+	}
+	if len(data) != 1 || data[0] != cachingSha2RequestPublicKey {
+		// The password must not be sent in clear text over insecure connections.
+		return false, nil
+	}
+	key, publicKeyPEM, err := cc.server.getRSAKey()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if err = cc.writeAuthMoreData(publicKeyPEM); err != nil {
+		return false, errors.Trace(err)
+	}
+	data, err = cc.readPacket()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	// The client encrypts the password XOR the salt with the public key.
+	password, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, data, nil)
+	if err != nil {
+		log.Warnf("[%d] decrypt password of user %s error %v", cc.connectionID, user, err)
+		return false, nil
+	}
+	for i := range password {
+		password[i] ^= cc.salt[i%len(cc.salt)]
+	}
+	return cc.ctx.AuthCleartext(user, trimNullTerminator(password)), nil
+}
+
+func trimNullTerminator(data []byte) []byte {
+	if len(data) > 0 && data[len(data)-1] == 0 {
+		return data[:len(data)-1]
+	}
+	return data
+}
+
+// rsaKeyBits is the size of the RSA key used by caching_sha2_password.
+const rsaKeyBits = 2048
+
+// getRSAKey gets the RSA key and the public key in PEM format, the key is generated at the first use.
+func (s *Server) getRSAKey() (*rsa.PrivateKey, []byte, error) {
+	s.rsaKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			s.rsaKeyErr = errors.Trace(err)
+			return
+		}
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			s.rsaKeyErr = errors.Trace(err)
+			return
+		}
+		s.rsaKey = key
+		s.rsaPublicKeyPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	})
+	return s.rsaKey, s.rsaPublicKeyPEM, s.rsaKeyErr
+}
+
+// socketPlugin implements auth_socket, it authenticates the clients connected by the unix socket
+// whose operating system user name is the same as the user name.
+type socketPlugin struct{}
+
+func (p socketPlugin) name() string {
+	return mysql.AuthSocket
+}
+
+func (p socketPlugin) switchRequired() bool {
+	// The auth data is not used, so the client can use any plugin.
+	return false
+}
+
+func (p socketPlugin) authenticate(cc *clientConn, user string, authData []byte) (bool, error) {
+	if cc.unixConn == nil {
+		return false, nil
+	}
+	osUser, err := getPeerUser(cc.unixConn)
+	if err != nil {
+		log.Warnf("[%d] get peer user of the unix socket error %v", cc.connectionID, err)
+		return false, nil
+	}
+	if osUser != cc.user {
+		return false, nil
+	}
+	return cc.ctx.AuthTrusted(user), nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !linux
+
+package server
+
+import (
+	"net"
+
+	"github.com/juju/errors"
+)
+
+// getPeerUser gets the operating system user name of the process on the other side of the unix socket.
+func getPeerUser(conn *net.UnixConn) (string, error) {
+	return "", errors.New("auth_socket is only supported on linux")
+}
@@ -0,0 +1,227 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pingcap/tidb/mysql"
+)
+
+// The compression algorithms of the compressed protocol.
+const (
+	compressionZlib = iota + 1
+	compressionZstd
+)
+
+const (
+	// compressedHeaderLen is the length of the compressed packet header, which contains the length of
+	// the compressed payload, the sequence and the length of the payload before compression.
+	compressedHeaderLen = 7
+	// minCompressLength is the minimum length of the payload to compress, shorter payloads are sent as is.
+	minCompressLength = 50
+	// defaultZstdLevel is the compression level of zstd if the client doesn't specify it.
+	defaultZstdLevel = 3
+)
+
+// zstdDecoder is shared by the connections, DecodeAll can be called concurrently.
+// The memory is limited to the max payload length, so a small frame can't make the server
+// allocate a huge buffer.
+var zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(mysql.MaxPayloadLen)))
+
+// compressedIO implements the compressed protocol, it reads and writes the compressed packets which
+// wrap the MySQL packets, so the packetIO over it is not aware of the compression.
+// See https://dev.mysql.com/doc/internals/en/compression.html
+type compressedIO struct {
+	rb        *bufio.Reader
+	wb        *bufio.Writer
+	algorithm int
+	encoder   *zstd.Encoder
+	// sequence is shared by reading and writing, the server continues the sequence of the client.
+	sequence uint8
+	// data is the decompressed payload which has not been read.
+	data []byte
+}
+
+func newCompressedIO(rb *bufio.Reader, wb *bufio.Writer, algorithm int, zstdLevel int) (*compressedIO, error) {
+	cio := &compressedIO{rb: rb, wb: wb, algorithm: algorithm}
+	if algorithm == compressionZstd {
+		if zstdLevel == 0 {
+			zstdLevel = defaultZstdLevel
+		}
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(zstdLevel)), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		cio.encoder = encoder
+	}
+	return cio, nil
+}
+
+// Read implements io.Reader, it reads the decompressed payload of the compressed packets.
+func (cio *compressedIO) Read(b []byte) (int, error) {
+	for len(cio.data) == 0 {
+		data, err := cio.readCompressedPacket()
+		if err != nil {
+			return 0, err
+		}
+		cio.data = data
+	}
+	n := copy(b, cio.data)
+	cio.data = cio.data[n:]
+	return n, nil
+}
+
+func (cio *compressedIO) readCompressedPacket() ([]byte, error) {
+	var header [compressedHeaderLen]byte
+	if _, err := io.ReadFull(cio.rb, header[:]); err != nil {
+		// io.EOF is returned as is, so the reader over it knows the connection is closed.
+		return nil, err
+	}
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	uncompressedLength := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+	cio.sequence = header[3] + 1
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(cio.rb, data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if uncompressedLength == 0 {
+		// The payload is not compressed.
+		return data, nil
+	}
+	data, err := cio.decompress(data, uncompressedLength)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(data) != uncompressedLength {
+		return nil, errors.Trace(mysql.ErrMalformPacket)
+	}
+	return data, nil
+}
+
+func (cio *compressedIO) decompress(data []byte, uncompressedLength int) ([]byte, error) {
+	if cio.algorithm == compressionZstd {
+		// Reject the frame whose content size doesn't match the header before decoding it.
+		var header zstd.Header
+		if err := header.Decode(data); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if header.HasFCS && header.FrameContentSize != uint64(uncompressedLength) {
+			return nil, errors.Trace(mysql.ErrMalformPacket)
+		}
+		data, err := zstdDecoder.DecodeAll(data, make([]byte, 0, uncompressedLength))
+		return data, errors.Trace(err)
+	}
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer r.Close()
+	uncompressed := make([]byte, uncompressedLength)
+	if _, err = io.ReadFull(r, uncompressed); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return uncompressed, nil
+}
+
+// Write implements io.Writer, it writes the data in compressed packets and flushes them.
+func (cio *compressedIO) Write(data []byte) (int, error) {
+	n := len(data)
+	for len(data) > 0 {
+		payload := data
+		if len(payload) > mysql.MaxPayloadLen {
+			payload = payload[:mysql.MaxPayloadLen]
+		}
+		if err := cio.writeCompressedPacket(payload); err != nil {
+			return 0, errors.Trace(err)
+		}
+		data = data[len(payload):]
+	}
+	if err := cio.wb.Flush(); err != nil {
+		return 0, errors.Trace(err)
+	}
+	return n, nil
+}
+
+func (cio *compressedIO) writeCompressedPacket(payload []byte) error {
+	uncompressedLength := 0
+	if len(payload) >= minCompressLength {
+		compressed, err := cio.compress(payload)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// Send the payload as is if it can't be compressed.
+		if len(compressed) < len(payload) {
+			uncompressedLength = len(payload)
+			payload = compressed
+		}
+	}
+
+	header := []byte{
+		byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), cio.sequence,
+		byte(uncompressedLength), byte(uncompressedLength >> 8), byte(uncompressedLength >> 16),
+	}
+	cio.sequence++
+	if _, err := cio.wb.Write(header); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	if _, err := cio.wb.Write(payload); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	return nil
+}
+
+func (cio *compressedIO) compress(data []byte) ([]byte, error) {
+	if cio.algorithm == compressionZstd {
+		return cio.encoder.EncodeAll(data, nil), nil
+	}
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// enableCompression switches to the compressed protocol if the client asks for it in the handshake.
+func (cc *clientConn) enableCompression() error {
+	if cc.capability&mysql.ClientZstdCompressionAlgorithm > 0 {
+		return errors.Trace(cc.pkt.enableCompression(compressionZstd, int(cc.zstdLevel)))
+	}
+	if cc.capability&mysql.ClientCompress > 0 {
+		return errors.Trace(cc.pkt.enableCompression(compressionZlib, 0))
+	}
+	return nil
+}
+
+// enableCompression makes the packetIO read and write over the compressed protocol.
+func (p *packetIO) enableCompression(algorithm int, zstdLevel int) error {
+	cio, err := newCompressedIO(p.rb, p.wb, algorithm, zstdLevel)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	p.rb = bufio.NewReaderSize(cio, defaultReaderSize)
+	p.wb = bufio.NewWriterSize(cio, defaultWriterSize)
+	p.cio = cio
+	return nil
+}
@@ -21,4 +21,22 @@ type Config struct {
 	StatusAddr   string `json:"status_addr" toml:"status_addr"`
 	Socket       string `json:"socket" toml:"socket"`
 	ReportStatus bool   `json:"report_status" toml:"report_status"`
+	// SocketSkipAuth skips the authentication of the connections from Socket, otherwise they authenticate
+	// by auth_socket or passwords like the TCP connections.
+	SocketSkipAuth bool `json:"socket_skip_auth" toml:"socket_skip_auth"`
+	// SSLCA, SSLCert and SSLKey are the paths of the PEM files used by TLS connections,
+	// TLS is enabled when both SSLCert and SSLKey are set.
+	SSLCA   string `json:"ssl_ca" toml:"ssl_ca"`
+	SSLCert string `json:"ssl_cert" toml:"ssl_cert"`
+	SSLKey  string `json:"ssl_key" toml:"ssl_key"`
+	// PGAddr is the address of the PostgreSQL protocol server, it's disabled if empty.
+	PGAddr string `json:"pg_addr" toml:"pg_addr"`
+	// PGAuthMethod is the authentication method of the PostgreSQL protocol server,
+	// which is scram-sha-256, md5 or password.
+	PGAuthMethod string `json:"pg_auth_method" toml:"pg_auth_method"`
+	// UserTokenLimit is the max number of the concurrently handled commands of each account,
+	// the commands of an account wait for its own tokens before the global ones. 0 means no limit.
+	UserTokenLimit int `json:"user_token_limit" toml:"user_token_limit"`
+	// DrainTimeout is the seconds to wait for the running commands and transactions when the server is draining.
+	DrainTimeout int `json:"drain_timeout" toml:"drain_timeout"`
 }
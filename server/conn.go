@@ -35,7 +35,9 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/tls"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -47,36 +49,44 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/audit"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tidb/util/generallog"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/types"
 )
 
 var defaultCapability = mysql.ClientLongPassword | mysql.ClientLongFlag |
 	mysql.ClientConnectWithDB | mysql.ClientProtocol41 |
 	mysql.ClientTransactions | mysql.ClientSecureConnection | mysql.ClientFoundRows |
 	mysql.ClientMultiStatements | mysql.ClientMultiResults | mysql.ClientLocalFiles |
-	mysql.ClientConnectAtts
+	mysql.ClientConnectAtts | mysql.ClientPluginAuth | mysql.ClientCompress | mysql.ClientZstdCompressionAlgorithm
 
 // clientConn represents a connection between server and client, it maintains connection specific state,
 // handles client query.
 type clientConn struct {
 	pkt          *packetIO // a helper to read and write data in packet format.
 	conn         net.Conn
-	server       *Server           // a reference of server instance.
-	capability   uint32            // client capability affects the way server handles client request.
-	connectionID uint32            // atomically allocated by a global variable, unique in process scope.
-	collation    uint8             // collation used by client, may be different from the collation used by database.
-	user         string            // user of the client.
-	dbname       string            // default database name.
-	salt         []byte            // random bytes used for authentication.
-	alloc        arena.Allocator   // an memory allocator for reducing memory allocation.
-	lastCmd      string            // latest sql query string, currently used for logging error.
-	ctx          QueryCtx          // an interface to execute sql statements.
-	attrs        map[string]string // attributes parsed from client handshake response, not used for now.
+	server       *Server              // a reference of server instance.
+	capability   uint32               // client capability affects the way server handles client request.
+	connectionID uint32               // atomically allocated by a global variable, unique in process scope.
+	collation    uint8                // collation used by client, may be different from the collation used by database.
+	user         string               // user of the client.
+	dbname       string               // default database name.
+	salt         []byte               // random bytes used for authentication.
+	alloc        arena.Allocator      // an memory allocator for reducing memory allocation.
+	lastCmd      string               // latest sql query string, currently used for logging error.
+	ctx          QueryCtx             // an interface to execute sql statements.
+	attrs        map[string]string    // attributes parsed from client handshake response, not used for now.
+	tlsState     *tls.ConnectionState // TLS state of the connection, nil if the connection is not secured.
+	unixConn     *net.UnixConn        // the underlying unix socket connection, nil if the client connects by TCP.
+	zstdLevel    uint8                // zstd compression level requested by the client.
+	usage        *accountUsage        // resource usage of the account of the user, nil if the authentication is skipped.
+	drain        drainState           // state of the connection used by the draining server.
 }
 
 func (cc *clientConn) String() string {
@@ -94,6 +104,12 @@ func (cc *clientConn) handshake() error {
 		return errors.Trace(err)
 	}
 	if err := cc.readHandshakeResponse(); err != nil {
+		cc.auditConnection(audit.EventConnect, err)
+		cc.writeError(err)
+		return errors.Trace(err)
+	}
+	// The connection is refused if it can't be audited.
+	if err := cc.auditConnection(audit.EventConnect, nil); err != nil {
 		cc.writeError(err)
 		return errors.Trace(err)
 	}
@@ -111,7 +127,11 @@ func (cc *clientConn) handshake() error {
 		return errors.Trace(err)
 	}
 
-	return errors.Trace(cc.flush())
+	if err = cc.flush(); err != nil {
+		return errors.Trace(err)
+	}
+	// The packets after the handshake are compressed if the client asks for it.
+	return errors.Trace(cc.enableCompression())
 }
 
 func (cc *clientConn) Close() error {
@@ -121,6 +141,10 @@ func (cc *clientConn) Close() error {
 	cc.server.rwlock.Unlock()
 	connGauge.Set(float64(connections))
 	cc.conn.Close()
+	if cc.usage != nil {
+		cc.usage.disconnect()
+		cc.usage = nil
+	}
 	if cc.ctx != nil {
 		return cc.ctx.Close()
 	}
@@ -143,15 +167,16 @@ func (cc *clientConn) writeInitialHandshake() error {
 	data = append(data, cc.salt[0:8]...)
 	// filler [00]
 	data = append(data, 0)
-	// capability flag lower 2 bytes, using default capability here
-	data = append(data, byte(defaultCapability), byte(defaultCapability>>8))
+	capability := cc.server.capability()
+	// capability flag lower 2 bytes, using server capability here
+	data = append(data, byte(capability), byte(capability>>8))
 	// charset, utf-8 default
 	data = append(data, uint8(mysql.DefaultCollationID))
 	//status
 	data = append(data, dumpUint16(mysql.ServerStatusAutocommit)...)
 	// below 13 byte may not be used
-	// capability flag upper 2 bytes, using default capability here
-	data = append(data, byte(defaultCapability>>16), byte(defaultCapability>>24))
+	// capability flag upper 2 bytes, using server capability here
+	data = append(data, byte(capability>>16), byte(capability>>24))
 	// filler [0x15], for wireshark dump, value is 0x15
 	data = append(data, 0x15)
 	// reserved 10 [00]
@@ -160,6 +185,9 @@ func (cc *clientConn) writeInitialHandshake() error {
 	data = append(data, cc.salt[8:]...)
 	// filler [00]
 	data = append(data, 0)
+	// auth-plugin name
+	data = append(data, mysql.AuthNativePassword...)
+	data = append(data, 0)
 	err := cc.writePacket(data)
 	if err != nil {
 		return errors.Trace(err)
@@ -181,7 +209,9 @@ type handshakeResponse41 struct {
 	User       string
 	DBName     string
 	Auth       []byte
+	AuthPlugin string
 	Attrs      map[string]string
+	ZstdLevel  uint8
 }
 
 func handshakeResponseFromData(packet *handshakeResponse41, data []byte) (err error) {
@@ -237,10 +267,15 @@ func handshakeResponseFromData(packet *handshakeResponse41, data []byte) (err er
 		}
 	}
 
+	// The auth data is computed by mysql_native_password if the client doesn't support plugin auth.
+	packet.AuthPlugin = mysql.AuthNativePassword
 	if capability&mysql.ClientPluginAuth > 0 {
-		// TODO: Support mysql.ClientPluginAuth, skip it now
-		idx := bytes.IndexByte(data[pos:], 0)
-		pos = pos + idx + 1
+		if idx := bytes.IndexByte(data[pos:], 0); idx > 0 {
+			packet.AuthPlugin = string(data[pos : pos+idx])
+			pos = pos + idx + 1
+		} else if idx == 0 {
+			pos++
+		}
 	}
 
 	if capability&mysql.ClientConnectAtts > 0 {
@@ -259,6 +294,10 @@ func handshakeResponseFromData(packet *handshakeResponse41, data []byte) (err er
 			pos += int(num)
 		}
 	}
+
+	if capability&mysql.ClientZstdCompressionAlgorithm > 0 && len(data[pos:]) > 0 {
+		packet.ZstdLevel = data[pos]
+	}
 	return nil
 }
 
@@ -287,35 +326,138 @@ func (cc *clientConn) readHandshakeResponse() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if isSSLRequest(data) && cc.server.tlsConfig != nil {
+		// The client sends a SSL request packet and then starts the TLS handshake,
+		// the real handshake response is sent over the secured connection.
+		if err = cc.upgradeToTLS(); err != nil {
+			return errors.Trace(err)
+		}
+		data, err = cc.readPacket()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	var p handshakeResponse41
 	if err = handshakeResponseFromData(&p, data); err != nil {
 		return errors.Trace(err)
 	}
-	cc.capability = p.Capability & defaultCapability
+	cc.capability = p.Capability & cc.server.capability()
 	cc.user = p.User
 	cc.dbname = p.DBName
 	cc.collation = p.Collation
 	cc.attrs = p.Attrs
+	cc.zstdLevel = p.ZstdLevel
 
 	// Open session and do auth
-	cc.ctx, err = cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, uint8(cc.collation), cc.dbname)
+	cc.ctx, err = cc.openCtx(cc.dbname)
 	if err != nil {
 		cc.Close()
 		return errors.Trace(err)
 	}
-	if !cc.server.skipAuth() {
-		// Do Auth
-		addr := cc.conn.RemoteAddr().String()
-		host, _, err1 := net.SplitHostPort(addr)
-		if err1 != nil {
-			return errors.Trace(mysql.NewErr(mysql.ErrAccessDenied, cc.user, addr, "Yes"))
-		}
-		user := fmt.Sprintf("%s@%s", cc.user, host)
-		if !cc.ctx.Auth(user, p.Auth, cc.salt) {
-			return errors.Trace(mysql.NewErr(mysql.ErrAccessDenied, cc.user, host, "Yes"))
-		}
+	if err = cc.authenticateUser(p.AuthPlugin, p.Auth); err != nil {
+		return errors.Trace(err)
 	}
+	if !cc.skipAuth() {
+		cc.usage, err = cc.server.connectUser(cc.ctx)
+	}
+	return errors.Trace(err)
+}
+
+// openCtx opens a new session for the connection.
+func (cc *clientConn) openCtx(dbname string) (QueryCtx, error) {
+	ctx, err := cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, uint8(cc.collation), dbname)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ctx.SetTLSState(cc.tlsState)
+	ctx.SetSessionManager(cc.server)
+	return ctx, nil
+}
+
+// skipAuth returns whether the authentication of the connection is skipped.
+func (cc *clientConn) skipAuth() bool {
+	return cc.server.skipAuth() || cc.unixConn != nil && cc.server.cfg.SocketSkipAuth
+}
+
+// authenticateUser authenticates cc.user with the auth data sent by the client, it does nothing if auth is skipped.
+func (cc *clientConn) authenticateUser(clientPlugin string, authData []byte) error {
+	if cc.skipAuth() {
+		return nil
+	}
+	host, err := cc.peerHost()
+	if err != nil {
+		return errors.Trace(mysql.NewErr(mysql.ErrAccessDenied, cc.user, cc.conn.RemoteAddr().String(), "Yes"))
+	}
+	return errors.Trace(cc.authenticate(host, clientPlugin, authData))
+}
+
+// auditConnection passes the connection event of the current user to the audit plugins, err is the error
+// of the connection. The error of the audit plugins is returned if the connection succeeds, otherwise it's logged.
+func (cc *clientConn) auditConnection(typ string, err error) error {
+	if !audit.Enabled() {
+		return nil
+	}
+	host, hostErr := cc.peerHost()
+	if hostErr != nil {
+		host = cc.conn.RemoteAddr().String()
+	}
+	e := &audit.Event{
+		Type:   typ,
+		ConnID: uint64(cc.connectionID),
+		User:   cc.user,
+		Host:   host,
+		DB:     cc.dbname,
+	}
+	e.SetError(err)
+	auditErr := audit.Log(e)
+	if err != nil && auditErr != nil {
+		log.Errorf("[%d] audit connection error: %v", cc.connectionID, auditErr)
+		return nil
+	}
+	return errors.Trace(auditErr)
+}
+
+// peerHost returns the host of the client, the clients connected by the unix socket are from localhost.
+func (cc *clientConn) peerHost() (string, error) {
+	if cc.unixConn != nil {
+		return "localhost", nil
+	}
+	host, _, err := net.SplitHostPort(cc.conn.RemoteAddr().String())
+	return host, errors.Trace(err)
+}
+
+// sslRequestLen is the length of the SSL request packet, it is a truncated handshake response
+// which only contains the capability flags, max packet size, charset and the reserved bytes.
+const sslRequestLen = 32
+
+func isSSLRequest(data []byte) bool {
+	return len(data) == sslRequestLen && binary.LittleEndian.Uint32(data[:4])&mysql.ClientSSL > 0
+}
+
+// bufferedReadConn is a net.Conn that reads from the buffered reader of the connection first,
+// so the data of the TLS handshake which has been buffered is not lost.
+type bufferedReadConn struct {
+	net.Conn
+	rb *bufio.Reader
+}
+
+func (conn bufferedReadConn) Read(b []byte) (int, error) {
+	return conn.rb.Read(b)
+}
+
+// upgradeToTLS does the TLS handshake on the connection and replaces the connection with the secured one.
+func (cc *clientConn) upgradeToTLS() error {
+	tlsConn := tls.Server(bufferedReadConn{Conn: cc.conn, rb: cc.pkt.rb}, cc.server.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return errors.Trace(err)
+	}
+	sequence := cc.pkt.sequence
+	cc.conn = tlsConn
+	cc.pkt = newPacketIO(tlsConn)
+	cc.pkt.sequence = sequence
+	state := tlsConn.ConnectionState()
+	cc.tlsState = &state
 	return nil
 }
 
@@ -343,6 +485,9 @@ func (cc *clientConn) Run() {
 			}
 			return
 		}
+		if !cc.drain.startDispatch() {
+			return
+		}
 
 		startTime := time.Now()
 		if err = cc.dispatch(data); err != nil {
@@ -356,6 +501,9 @@ func (cc *clientConn) Run() {
 		}
 		cc.addMetrics(data[0], startTime, err)
 		cc.pkt.sequence = 0
+		if !cc.drain.finishDispatch(cc.ctx.Status()&mysql.ServerStatusInTrans > 0, cc.server.isDraining()) {
+			return
+		}
 	}
 }
 
@@ -375,36 +523,45 @@ func errStrForLog(err error) string {
 	return errors.ErrorStack(err)
 }
 
-func (cc *clientConn) addMetrics(cmd byte, startTime time.Time, err error) {
-	var label string
+// commandName returns the name of the command, it's the label of the metrics and the command of the general query log.
+func commandName(cmd byte) string {
 	switch cmd {
 	case mysql.ComSleep:
-		label = "Sleep"
+		return "Sleep"
 	case mysql.ComQuit:
-		label = "Quit"
+		return "Quit"
 	case mysql.ComQuery:
-		label = "Query"
+		return "Query"
 	case mysql.ComPing:
-		label = "Ping"
+		return "Ping"
 	case mysql.ComInitDB:
-		label = "InitDB"
+		return "InitDB"
 	case mysql.ComFieldList:
-		label = "FieldList"
+		return "FieldList"
 	case mysql.ComStmtPrepare:
-		label = "StmtPrepare"
+		return "StmtPrepare"
 	case mysql.ComStmtExecute:
-		label = "StmtExecute"
+		return "StmtExecute"
 	case mysql.ComStmtClose:
-		label = "StmtClose"
+		return "StmtClose"
 	case mysql.ComStmtSendLongData:
-		label = "StmtSendLongData"
+		return "StmtSendLongData"
 	case mysql.ComStmtReset:
-		label = "StmtReset"
+		return "StmtReset"
+	case mysql.ComStmtFetch:
+		return "StmtFetch"
 	case mysql.ComSetOption:
-		label = "SetOption"
-	default:
-		label = strconv.Itoa(int(cmd))
+		return "SetOption"
+	case mysql.ComChangeUser:
+		return "ChangeUser"
+	case mysql.ComResetConnection:
+		return "ResetConnection"
 	}
+	return strconv.Itoa(int(cmd))
+}
+
+func (cc *clientConn) addMetrics(cmd byte, startTime time.Time, err error) {
+	label := commandName(cmd)
 	if err != nil {
 		queryCounter.WithLabelValues(label, "Error").Inc()
 	} else {
@@ -420,6 +577,16 @@ func (cc *clientConn) dispatch(data []byte) error {
 	cmd := data[0]
 	data = data[1:]
 	cc.lastCmd = hack.String(data)
+	if cmd != mysql.ComStmtExecute && generallog.Enabled() {
+		cc.writeGeneralLog(cmd, generalLogArg(cmd, data))
+	}
+	// The token of the account is got before the global one, so an account waiting for its own
+	// tokens doesn't hold the global tokens.
+	if cc.usage != nil && cc.usage.tokens != nil {
+		tokens := cc.usage.tokens
+		userToken := tokens.Get()
+		defer tokens.Put(userToken)
+	}
 	token := cc.server.getToken()
 	defer func() {
 		cc.server.releaseToken(token)
@@ -432,6 +599,9 @@ func (cc *clientConn) dispatch(data []byte) error {
 		// Investigate this command and write test case later.
 		return nil
 	case mysql.ComQuit:
+		if err := cc.auditConnection(audit.EventDisconnect, nil); err != nil {
+			log.Errorf("[%d] audit connection error: %v", cc.connectionID, err)
+		}
 		return io.EOF
 	case mysql.ComQuery: // Most frequently used command.
 		// For issue 1989
@@ -461,8 +631,14 @@ func (cc *clientConn) dispatch(data []byte) error {
 		return cc.handleStmtSendLongData(data)
 	case mysql.ComStmtReset:
 		return cc.handleStmtReset(data)
+	case mysql.ComStmtFetch:
+		return cc.handleStmtFetch(data)
 	case mysql.ComSetOption:
 		return cc.handleSetOption(data)
+	case mysql.ComChangeUser:
+		return cc.handleChangeUser(data)
+	case mysql.ComResetConnection:
+		return cc.handleResetConnection()
 	default:
 		return mysql.NewErrf(mysql.ErrUnknown, "command %d not supported now", cmd)
 	}
@@ -479,6 +655,149 @@ func (cc *clientConn) useDB(db string) (err error) {
 	return
 }
 
+// changeUserFromData parses the COM_CHANGE_USER packet without the command byte.
+// See https://dev.mysql.com/doc/internals/en/com-change-user.html
+func changeUserFromData(packet *handshakeResponse41, data []byte, capability uint32) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("change user panic, packet data: %v", data)
+			err = mysql.ErrMalformPacket
+		}
+	}()
+	pos := bytes.IndexByte(data, 0)
+	packet.User = string(data[:pos])
+	pos++
+	if capability&mysql.ClientSecureConnection > 0 {
+		authLen := int(data[pos])
+		pos++
+		packet.Auth = data[pos : pos+authLen]
+		pos += authLen
+	} else {
+		packet.Auth = data[pos : pos+bytes.IndexByte(data[pos:], 0)]
+		pos += len(packet.Auth) + 1
+	}
+	idx := bytes.IndexByte(data[pos:], 0)
+	packet.DBName = string(data[pos : pos+idx])
+	pos += idx + 1
+
+	packet.AuthPlugin = mysql.AuthNativePassword
+	if len(data[pos:]) < 2 {
+		return nil
+	}
+	// The character set is 2 bytes, only the lower byte is used as the collation ID.
+	packet.Collation = data[pos]
+	pos += 2
+	if capability&mysql.ClientPluginAuth > 0 && len(data[pos:]) > 0 {
+		idx = bytes.IndexByte(data[pos:], 0)
+		if idx > 0 {
+			packet.AuthPlugin = string(data[pos : pos+idx])
+		}
+		pos += idx + 1
+	}
+	if capability&mysql.ClientConnectAtts > 0 && len(data[pos:]) > 0 {
+		if num, null, off := parseLengthEncodedInt(data[pos:]); !null {
+			pos += off
+			attrs, err := parseAttrs(data[pos : pos+int(num)])
+			if err != nil {
+				return errors.Trace(err)
+			}
+			packet.Attrs = attrs
+		}
+	}
+	return nil
+}
+
+// handleChangeUser handles the COM_CHANGE_USER command, it authenticates the new user and replaces the session
+// with a new one, so the state of the old session is discarded. The connection is closed if the authentication fails.
+func (cc *clientConn) handleChangeUser(data []byte) error {
+	var p handshakeResponse41
+	if err := changeUserFromData(&p, data, cc.capability); err != nil {
+		return errors.Trace(err)
+	}
+	oldCtx, oldUser, oldDBName, oldCollation := cc.ctx, cc.user, cc.dbname, cc.collation
+	cc.dbname = p.DBName
+	if p.Collation != 0 {
+		cc.collation = p.Collation
+	}
+	var usage *accountUsage
+	ctx, err := cc.openCtx(p.DBName)
+	if err == nil {
+		cc.setSession(ctx, p.User)
+		err = cc.authenticateUser(p.AuthPlugin, p.Auth)
+		if err == nil && !cc.skipAuth() {
+			usage, err = cc.server.connectUser(ctx)
+		}
+		if err == nil {
+			// The new user is refused if it can't be audited.
+			if err = cc.auditConnection(audit.EventConnect, nil); err != nil && usage != nil {
+				usage.disconnect()
+			}
+		} else {
+			cc.auditConnection(audit.EventConnect, err)
+		}
+		if err != nil {
+			ctx.Close()
+		}
+	}
+	if err != nil {
+		cc.setSession(oldCtx, oldUser)
+		cc.dbname, cc.collation = oldDBName, oldCollation
+		// Like MySQL, the connection is closed after the error is sent, it can't go on
+		// with the session of the old user.
+		log.Warnf("[%d] change user to %s error %v, close the connection", cc.connectionID, p.User, err)
+		cc.writeError(err)
+		return io.EOF
+	}
+	if cc.usage != nil {
+		cc.usage.disconnect()
+	}
+	cc.usage = usage
+
+	if err = oldCtx.Close(); err != nil {
+		log.Errorf("[%d] close session error %v", cc.connectionID, err)
+	}
+	if p.Attrs != nil {
+		cc.attrs = p.Attrs
+	}
+	return cc.writeOK()
+}
+
+// handleResetConnection handles the COM_RESET_CONNECTION command, it replaces the session with a new one
+// of the same user and database, which rolls back the open transaction and clears the session variables,
+// the user variables and the prepared statements.
+func (cc *clientConn) handleResetConnection() error {
+	ctx, err := cc.openCtx(cc.dbname)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !cc.skipAuth() {
+		// The user has been authenticated, so only check that the account can still log in.
+		host, err1 := cc.peerHost()
+		if err1 != nil || !ctx.AuthTrusted(fmt.Sprintf("%s@%s", cc.user, host)) {
+			ctx.Close()
+			return errors.Trace(mysql.NewErr(mysql.ErrAccessDenied, cc.user, cc.conn.RemoteAddr().String(), "Yes"))
+		}
+	}
+
+	if cc.usage != nil {
+		ctx.SetStmtChecker(cc.usage.checkStmts)
+	}
+	oldCtx := cc.ctx
+	cc.setSession(ctx, cc.user)
+	if err = oldCtx.Close(); err != nil {
+		log.Errorf("[%d] close session error %v", cc.connectionID, err)
+	}
+	return cc.writeOK()
+}
+
+// setSession replaces the session and the user of the connection. They're read by the other goroutines
+// with the server lock held, e.g. SHOW PROCESSLIST and Drain, so they're replaced with the lock held.
+func (cc *clientConn) setSession(ctx QueryCtx, user string) {
+	cc.server.rwlock.Lock()
+	cc.ctx, cc.user = ctx, user
+	cc.server.rwlock.Unlock()
+}
+
 func (cc *clientConn) flush() error {
 	return cc.pkt.flush()
 }
@@ -537,16 +856,21 @@ func (cc *clientConn) writeError(e error) error {
 // If "more" is true, a mysql.ServerMoreResultsExists bit would be set
 // in the packet.
 func (cc *clientConn) writeEOF(more bool) error {
+	status := cc.ctx.Status()
+	if more {
+		status |= mysql.ServerMoreResultsExists
+	}
+	return cc.writeEOFWithStatus(status)
+}
+
+// writeEOFWithStatus writes an EOF packet with the server status, it won't flush the stream either.
+func (cc *clientConn) writeEOFWithStatus(status uint16) error {
 	data := cc.alloc.AllocWithLen(4, 9)
 
 	data = append(data, mysql.EOFHeader)
 	if cc.capability&mysql.ClientProtocol41 > 0 {
 		data = append(data, dumpUint16(cc.ctx.WarningCount())...)
-		status := cc.ctx.Status()
-		if more {
-			status |= mysql.ServerMoreResultsExists
-		}
-		data = append(data, dumpUint16(cc.ctx.Status())...)
+		data = append(data, dumpUint16(status)...)
 	}
 
 	err := cc.writePacket(data)
@@ -716,25 +1040,14 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 	if err != nil {
 		return errors.Trace(err)
 	}
-	columnLen := dumpLengthEncodedInt(uint64(len(columns)))
-	data := cc.alloc.AllocWithLen(4, 1024)
-	data = append(data, columnLen...)
-	if err = cc.writePacket(data); err != nil {
+	if err = cc.writeColumnInfo(columns); err != nil {
 		return errors.Trace(err)
 	}
-
-	for _, v := range columns {
-		data = data[0:4]
-		data = append(data, v.Dump(cc.alloc)...)
-		if err = cc.writePacket(data); err != nil {
-			return errors.Trace(err)
-		}
-	}
-
 	if err = cc.writeEOF(false); err != nil {
 		return errors.Trace(err)
 	}
 
+	data := cc.alloc.AllocWithLen(4, 1024)
 	for {
 		if err != nil {
 			return errors.Trace(err)
@@ -742,30 +1055,7 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 		if row == nil {
 			break
 		}
-		data = data[0:4]
-		if binary {
-			var rowData []byte
-			rowData, err = dumpRowValuesBinary(cc.alloc, columns, row)
-			if err != nil {
-				return errors.Trace(err)
-			}
-			data = append(data, rowData...)
-		} else {
-			for i, value := range row {
-				if value.IsNull() {
-					data = append(data, 0xfb)
-					continue
-				}
-				var valData []byte
-				valData, err = dumpTextValue(columns[i].Type, value)
-				if err != nil {
-					return errors.Trace(err)
-				}
-				data = append(data, dumpLengthEncodedString(valData, cc.alloc)...)
-			}
-		}
-
-		if err = cc.writePacket(data); err != nil {
+		if err = cc.writeRow(data[0:4], columns, row, binary); err != nil {
 			return errors.Trace(err)
 		}
 		row, err = rs.Next()
@@ -779,6 +1069,48 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 	return errors.Trace(cc.flush())
 }
 
+// writeColumnInfo writes the column count and the column definitions of a resultset.
+func (cc *clientConn) writeColumnInfo(columns []*ColumnInfo) error {
+	data := cc.alloc.AllocWithLen(4, 1024)
+	data = append(data, dumpLengthEncodedInt(uint64(len(columns)))...)
+	if err := cc.writePacket(data); err != nil {
+		return errors.Trace(err)
+	}
+	for _, v := range columns {
+		data = data[0:4]
+		data = append(data, v.Dump(cc.alloc)...)
+		if err := cc.writePacket(data); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// writeRow writes a row of a resultset, data is the buffer which has 4 bytes reserved for the packet header.
+// The row is encoded in BINARY format if binary is true.
+func (cc *clientConn) writeRow(data []byte, columns []*ColumnInfo, row []types.Datum, binary bool) error {
+	if binary {
+		rowData, err := dumpRowValuesBinary(cc.alloc, columns, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		data = append(data, rowData...)
+	} else {
+		for i, value := range row {
+			if value.IsNull() {
+				data = append(data, 0xfb)
+				continue
+			}
+			valData, err := dumpTextValue(columns[i].Type, value)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			data = append(data, dumpLengthEncodedString(valData, cc.alloc)...)
+		}
+	}
+	return errors.Trace(cc.writePacket(data))
+}
+
 func (cc *clientConn) writeMultiResultset(rss []ResultSet, binary bool) error {
 	for _, rs := range rss {
 		if err := cc.writeResultset(rs, binary, true); err != nil {
@@ -41,7 +41,9 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/generallog"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/types"
 )
 
 func (cc *clientConn) handleStmtPrepare(sql string) error {
@@ -118,10 +120,11 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 
 	flag := data[pos]
 	pos++
-	//now we only support CURSOR_TYPE_NO_CURSOR flag
-	if flag != 0 {
+	// Only CURSOR_TYPE_NO_CURSOR and CURSOR_TYPE_READ_ONLY are supported.
+	if flag&^mysql.CursorTypeReadOnly != 0 {
 		return mysql.NewErrf(mysql.ErrUnknown, "unsupported flag %d", flag)
 	}
+	useCursor := flag&mysql.CursorTypeReadOnly > 0
 
 	//skip iteration-count, always 1
 	pos += 4
@@ -163,6 +166,13 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 			return errors.Trace(err)
 		}
 	}
+	if generallog.Enabled() {
+		cc.writeGeneralLog(mysql.ComStmtExecute, stmtExecuteLogArg(stmtID, args))
+	}
+	// Close the cursor opened by the last execution.
+	if err = stmt.StoreResultSet(nil); err != nil {
+		return errors.Trace(err)
+	}
 	rs, err := stmt.Execute(args...)
 	if err != nil {
 		return errors.Trace(err)
@@ -170,10 +180,109 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 	if rs == nil {
 		return errors.Trace(cc.writeOK())
 	}
+	if useCursor {
+		return errors.Trace(cc.openCursor(stmt, rs))
+	}
 
 	return errors.Trace(cc.writeResultset(rs, true, false))
 }
 
+// cursorResultSet is the result set of an open cursor, the first row has been read to get the columns.
+type cursorResultSet struct {
+	ResultSet
+	columns  []*ColumnInfo
+	firstRow []types.Datum
+	started  bool
+}
+
+func (crs *cursorResultSet) Columns() ([]*ColumnInfo, error) {
+	return crs.columns, nil
+}
+
+func (crs *cursorResultSet) Next() ([]types.Datum, error) {
+	if !crs.started {
+		crs.started = true
+		return crs.firstRow, nil
+	}
+	row, err := crs.ResultSet.Next()
+	return row, errors.Trace(err)
+}
+
+// openCursor keeps the result set open on the statement and only writes the column definitions,
+// the rows are sent in batches by COM_STMT_FETCH.
+func (cc *clientConn) openCursor(stmt PreparedStatement, rs ResultSet) error {
+	// We need to call Next before we get columns.
+	row, err := rs.Next()
+	if err != nil {
+		rs.Close()
+		return errors.Trace(err)
+	}
+	columns, err := rs.Columns()
+	if err != nil {
+		rs.Close()
+		return errors.Trace(err)
+	}
+	if err = stmt.StoreResultSet(&cursorResultSet{ResultSet: rs, columns: columns, firstRow: row}); err != nil {
+		return errors.Trace(err)
+	}
+	if err = cc.writeColumnInfo(columns); err != nil {
+		return errors.Trace(err)
+	}
+	if err = cc.writeEOFWithStatus(cc.ctx.Status() | mysql.ServerStatusCursorExists); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// handleStmtFetch sends at most the requested number of rows of the open cursor, the cursor is closed
+// after the last row is sent.
+// See https://dev.mysql.com/doc/internals/en/com-stmt-fetch.html
+func (cc *clientConn) handleStmtFetch(data []byte) (err error) {
+	if len(data) < 8 {
+		return mysql.ErrMalformPacket
+	}
+
+	stmtID := binary.LittleEndian.Uint32(data[0:4])
+	fetchSize := binary.LittleEndian.Uint32(data[4:8])
+	stmt := cc.ctx.GetStatement(int(stmtID))
+	if stmt == nil {
+		return mysql.NewErr(mysql.ErrUnknownStmtHandler,
+			strconv.FormatUint(uint64(stmtID), 10), "stmt_fetch")
+	}
+	rs := stmt.GetResultSet()
+	if rs == nil {
+		return mysql.NewErrf(mysql.ErrStmtHasNoOpenCursor, "The statement (%d) has no open cursor.", stmtID)
+	}
+	columns, err := rs.Columns()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	status := cc.ctx.Status() | mysql.ServerStatusCursorExists
+	data = cc.alloc.AllocWithLen(4, 1024)
+	for i := uint32(0); i < fetchSize; i++ {
+		row, err := rs.Next()
+		if err != nil {
+			stmt.StoreResultSet(nil)
+			return errors.Trace(err)
+		}
+		if row == nil {
+			status |= mysql.ServerStatusLastRowSend
+			if err = stmt.StoreResultSet(nil); err != nil {
+				return errors.Trace(err)
+			}
+			break
+		}
+		if err = cc.writeRow(data[0:4], columns, row, true); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err = cc.writeEOFWithStatus(status); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
 func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTypes, paramValues []byte) (err error) {
 	pos := 0
 	var v []byte
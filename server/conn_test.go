@@ -14,8 +14,18 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 )
 
 type ConnTestSuite struct{}
@@ -97,6 +107,63 @@ func (ts ConnTestSuite) TestIssue1768(c *C) {
 	c.Assert(len(p.Auth) > 0, IsTrue)
 }
 
+func (ts ConnTestSuite) TestChangeUserFromData(c *C) {
+	c.Parallel()
+	data := []byte("root\x00")
+	data = append(data, 3, 1, 2, 3)
+	data = append(data, "test\x00"...)
+	data = append(data, mysql.DefaultCollationID, 0)
+	data = append(data, "caching_sha2_password\x00"...)
+	data = append(data, 8, 3, 'k', 'e', 'y', 3, 'v', 'a', 'l')
+	capability := mysql.ClientProtocol41 | mysql.ClientSecureConnection | mysql.ClientPluginAuth | mysql.ClientConnectAtts
+	p := handshakeResponse41{}
+	err := changeUserFromData(&p, data, capability)
+	c.Assert(err, IsNil)
+	c.Assert(p.User, Equals, "root")
+	c.Assert(p.Auth, DeepEquals, []byte{1, 2, 3})
+	c.Assert(p.DBName, Equals, "test")
+	c.Assert(p.Collation, Equals, uint8(mysql.DefaultCollationID))
+	c.Assert(p.AuthPlugin, Equals, mysql.AuthCachingSha2Password)
+	c.Assert(p.Attrs, DeepEquals, map[string]string{"key": "val"})
+
+	// The old clients don't send the character set and the plugin name.
+	p = handshakeResponse41{}
+	err = changeUserFromData(&p, []byte("root\x00\x00\x00"), capability)
+	c.Assert(err, IsNil)
+	c.Assert(p.User, Equals, "root")
+	c.Assert(p.DBName, Equals, "")
+	c.Assert(p.AuthPlugin, Equals, mysql.AuthNativePassword)
+
+	p = handshakeResponse41{}
+	err = changeUserFromData(&p, []byte("root\x00\x10"), capability)
+	c.Assert(err, NotNil)
+}
+
+func (ts ConnTestSuite) TestCompressedPacketIO(c *C) {
+	c.Parallel()
+	for _, algorithm := range []int{compressionZlib, compressionZstd} {
+		var buf bytes.Buffer
+		w := &packetIO{rb: bufio.NewReader(&buf), wb: bufio.NewWriter(&buf)}
+		c.Assert(w.enableCompression(algorithm, 0), IsNil)
+		// The packets which are larger than the max payload length are split, so are the compressed packets.
+		large := bytes.Repeat([]byte{'a'}, mysql.MaxPayloadLen+10)
+		small := []byte("small")
+		for _, payload := range [][]byte{large, small} {
+			c.Assert(w.writePacket(append(make([]byte, 4), payload...)), IsNil)
+		}
+		c.Assert(w.flush(), IsNil)
+		c.Assert(buf.Len(), Less, len(large))
+
+		r := &packetIO{rb: bufio.NewReader(&buf), wb: bufio.NewWriter(&buf)}
+		c.Assert(r.enableCompression(algorithm, 0), IsNil)
+		for _, payload := range [][]byte{large, small} {
+			data, err := r.readPacket()
+			c.Assert(err, IsNil)
+			c.Assert(data, DeepEquals, payload)
+		}
+	}
+}
+
 func mapIdentical(m1, m2 map[string]string) bool {
 	return mapBelong(m1, m2) && mapBelong(m2, m1)
 }
@@ -110,3 +177,71 @@ func mapBelong(m1, m2 map[string]string) bool {
 	}
 	return true
 }
+
+func (ts ConnTestSuite) TestCompressedIOZstdContentSize(c *C) {
+	c.Parallel()
+	encoder, err := zstd.NewWriter(nil)
+	c.Assert(err, IsNil)
+	// A small frame of a large payload.
+	frame := encoder.EncodeAll(make([]byte, 1<<20), nil)
+	c.Assert(len(frame) < 1<<10, IsTrue)
+
+	read := func(uncompressedLength int) ([]byte, error) {
+		var buf bytes.Buffer
+		buf.Write([]byte{byte(len(frame)), byte(len(frame) >> 8), byte(len(frame) >> 16), 0})
+		buf.Write([]byte{byte(uncompressedLength), byte(uncompressedLength >> 8), byte(uncompressedLength >> 16)})
+		buf.Write(frame)
+		cio, err1 := newCompressedIO(bufio.NewReader(&buf), nil, compressionZstd, 0)
+		c.Assert(err1, IsNil)
+		return cio.readCompressedPacket()
+	}
+	// The frame is rejected before decoding if its content size doesn't match the header.
+	_, err = read(100)
+	c.Assert(terror.ErrorEqual(err, mysql.ErrMalformPacket), IsTrue, Commentf("err %v", err))
+	data, err := read(1 << 20)
+	c.Assert(err, IsNil)
+	c.Assert(data, HasLen, 1<<20)
+}
+
+// TestResetConnectionWithProcessList resets a connection while SHOW PROCESSLIST reads its session,
+// it's run with -race to check that the session is replaced safely.
+func (ts ConnTestSuite) TestResetConnectionWithProcessList(c *C) {
+	store, err := tidb.NewStore("memory:///tmp/tidb_reset_conn")
+	c.Assert(err, IsNil)
+	c.Assert(tidb.BootstrapSession(store), IsNil)
+	cfg := &Config{
+		Addr:     ":4008",
+		LogLevel: "debug",
+		SkipAuth: true,
+	}
+	server, err := NewServer(cfg, NewTiDBDriver(store))
+	c.Assert(err, IsNil)
+	defer server.Close()
+
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	go io.Copy(ioutil.Discard, clientConn)
+	cc := server.newConn(serverConn)
+	cc.user = "root"
+	cc.ctx, err = cc.openCtx("test")
+	c.Assert(err, IsNil)
+	defer cc.Close()
+	server.rwlock.Lock()
+	server.clients[cc.connectionID] = cc
+	server.rwlock.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			c.Check(cc.handleResetConnection(), IsNil)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		pi := server.ShowProcessList()
+		c.Assert(pi, HasLen, 1)
+		c.Assert(pi[0].User, Equals, "root")
+	}
+	wg.Wait()
+}
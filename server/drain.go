@@ -0,0 +1,155 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ngaut/log"
+)
+
+// The states of a connection, they tell the draining server which connections can be closed.
+const (
+	// connStatusIdle means the connection is waiting for the next command out of a transaction.
+	connStatusIdle int32 = iota
+	// connStatusInTxn means the connection is waiting for the next command in a transaction.
+	connStatusInTxn
+	connStatusDispatching
+	connStatusClosing
+)
+
+// The labels of the connection states in the metrics.
+const (
+	connStateIdle      = "idle"
+	connStateIdleInTxn = "idle_in_txn"
+	connStateExecuting = "executing"
+	connStateClosing   = "closing"
+)
+
+const drainCheckInterval = 50 * time.Millisecond
+
+// drainState tracks the state of a connection, it's changed by both the connection and the draining server.
+type drainState struct {
+	status int32
+}
+
+// startDispatch marks the connection as dispatching a command,
+// it returns false if the connection has been closed by the draining server.
+func (d *drainState) startDispatch() bool {
+	return atomic.CompareAndSwapInt32(&d.status, connStatusIdle, connStatusDispatching) ||
+		atomic.CompareAndSwapInt32(&d.status, connStatusInTxn, connStatusDispatching)
+}
+
+// dispatching returns whether the connection is dispatching a command.
+func (d *drainState) dispatching() bool {
+	return atomic.LoadInt32(&d.status) == connStatusDispatching
+}
+
+// stateLabel returns the label of the connection state in the metrics.
+func (d *drainState) stateLabel() string {
+	switch atomic.LoadInt32(&d.status) {
+	case connStatusInTxn:
+		return connStateIdleInTxn
+	case connStatusDispatching:
+		return connStateExecuting
+	case connStatusClosing:
+		return connStateClosing
+	}
+	return connStateIdle
+}
+
+// finishDispatch marks the connection as waiting for the next command. It returns false if the connection
+// should be closed, which happens when the server is draining, or when the connection has been closed by
+// the draining server after the deadline. The transaction of the closed connection is rolled back.
+func (d *drainState) finishDispatch(inTxn, draining bool) bool {
+	if draining {
+		atomic.StoreInt32(&d.status, connStatusClosing)
+		return false
+	}
+	status := connStatusIdle
+	if inTxn {
+		status = connStatusInTxn
+	}
+	return atomic.CompareAndSwapInt32(&d.status, connStatusDispatching, status)
+}
+
+// closeIfDrained closes conn if the connection is waiting for a command, the idle transaction is rolled back
+// when the connection is closed. After the deadline, the running command of ctx is killed and its connection
+// is closed too.
+func (d *drainState) closeIfDrained(conn net.Conn, ctx QueryCtx, deadlineExceeded bool) {
+	if atomic.CompareAndSwapInt32(&d.status, connStatusIdle, connStatusClosing) ||
+		atomic.CompareAndSwapInt32(&d.status, connStatusInTxn, connStatusClosing) {
+		conn.Close()
+		return
+	}
+	if deadlineExceeded && atomic.CompareAndSwapInt32(&d.status, connStatusDispatching, connStatusClosing) {
+		ctx.Kill()
+		conn.Close()
+	}
+}
+
+// isDraining returns whether the server is draining its connections.
+func (s *Server) isDraining() bool {
+	return atomic.LoadInt32(&s.draining) == 1
+}
+
+// Drain shuts down the server gracefully. It stops accepting connections, then closes the connections
+// which are waiting for commands, their idle transactions are rolled back. The connections running a
+// command are closed after the command finishes, the commands still running after cfg.DrainTimeout
+// seconds are killed. Drain returns after all the connections are closed, it can be called multiple times.
+func (s *Server) Drain() {
+	s.drainOnce.Do(func() {
+		atomic.StoreInt32(&s.draining, 1)
+		log.Infof("Server is draining, the timeout is %d seconds", s.cfg.DrainTimeout)
+		s.Close()
+		deadline := time.Now().Add(time.Duration(s.cfg.DrainTimeout) * time.Second)
+		for {
+			deadlineExceeded := !time.Now().Before(deadline)
+			if s.closeDrainedConns(deadlineExceeded) == 0 {
+				break
+			}
+			time.Sleep(drainCheckInterval)
+		}
+		log.Infof("Server is drained")
+		connStateMetrics.removeServer(s)
+		close(s.drained)
+	})
+	<-s.drained
+}
+
+// closeDrainedConns closes the drained connections, it returns the number of the connections before closing.
+func (s *Server) closeDrainedConns(deadlineExceeded bool) int {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	for _, cc := range s.clients {
+		cc.drain.closeIfDrained(cc.conn, cc.ctx, deadlineExceeded)
+	}
+	for _, pc := range s.pgClients {
+		pc.drain.closeIfDrained(pc.conn, pc.ctx, deadlineExceeded)
+	}
+	return len(s.clients) + len(s.pgClients)
+}
+
+// handleDrain starts draining the server, it's the HTTP API for rolling restarts.
+func (s *Server) handleDrain(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	go s.Drain()
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -14,8 +14,12 @@
 package server
 
 import (
+	"crypto/tls"
 	"fmt"
 
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -60,6 +64,9 @@ type QueryCtx interface {
 	// SetClientCapability sets client capability flags
 	SetClientCapability(uint32)
 
+	// SetTLSState sets the TLS state of the connection, nil means the connection is not secured.
+	SetTLSState(*tls.ConnectionState)
+
 	// Prepare prepares a statement.
 	Prepare(sql string) (statement PreparedStatement, columns, params []*ColumnInfo, err error)
 
@@ -74,6 +81,41 @@ type QueryCtx interface {
 
 	// Auth verifies user's authentication.
 	Auth(user string, auth []byte, salt []byte) bool
+
+	// GetAuthPlugin gets the authentication plugin of the user.
+	GetAuthPlugin(user string) (string, error)
+
+	// AuthCleartext verifies user's cleartext password.
+	AuthCleartext(user string, password []byte) bool
+
+	// AuthCachingSha2 verifies user's caching_sha2_password scramble with the cached password hash.
+	AuthCachingSha2(user string, auth []byte, salt []byte) bool
+
+	// AuthTrusted logs in the user whose identity has been verified by the authentication plugin.
+	AuthTrusted(user string) bool
+
+	// GetPGPassword gets the password verifier of the user for the authentication method of the PostgreSQL protocol.
+	GetPGPassword(user string, method string) (string, error)
+
+	// GetUserResources gets the resource limits of the account of the authenticated user.
+	GetUserResources() (*variable.UserResources, error)
+
+	// Kill aborts the running statement and the statements after it, it's called when the connection is
+	// being closed by other goroutines.
+	Kill()
+
+	// SetStmtChecker sets the checker which is called with the parsed statements before they are executed,
+	// the statements are refused if it returns an error.
+	SetStmtChecker(checker func(stmts []ast.StmtNode) error)
+
+	// SetSessionManager sets the session manager which is used by SHOW PROCESSLIST.
+	SetSessionManager(util.SessionManager)
+
+	// ShowProcess returns the information of the current or the last statement for SHOW PROCESSLIST.
+	ShowProcess() util.ProcessInfo
+
+	// TxnStartTS returns the start timestamp of the open transaction, it returns 0 if there isn't any.
+	TxnStartTS() uint64
 }
 
 // PreparedStatement is the interface to use a prepared statement.
@@ -99,9 +141,15 @@ type PreparedStatement interface {
 	// GetParamsType returns the type for parameters.
 	GetParamsType() []byte
 
-	// Reset removes all bound parameters.
+	// Reset removes all bound parameters and closes the open cursor.
 	Reset()
 
+	// StoreResultSet stores the result set of the open cursor, the result set of the previous cursor is closed.
+	StoreResultSet(rs ResultSet) error
+
+	// GetResultSet returns the result set of the open cursor, it returns nil if there is no open cursor.
+	GetResultSet() ResultSet
+
 	// Close closes the statement.
 	Close() error
 }
@@ -14,13 +14,18 @@
 package server
 
 import (
+	"crypto/tls"
 	"fmt"
 
 	"github.com/juju/errors"
+	"github.com/ngaut/log"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -52,6 +57,7 @@ type TiDBStatement struct {
 	boundParams [][]byte
 	paramsType  []byte
 	ctx         *TiDBContext
+	rs          ResultSet // the result set of the open cursor.
 }
 
 // ID implements PreparedStatement ID method.
@@ -108,11 +114,32 @@ func (ts *TiDBStatement) Reset() {
 	for i := range ts.boundParams {
 		ts.boundParams[i] = nil
 	}
+	if err := ts.StoreResultSet(nil); err != nil {
+		log.Errorf("close the cursor of statement %d error %v", ts.id, err)
+	}
+}
+
+// StoreResultSet implements PreparedStatement StoreResultSet method.
+func (ts *TiDBStatement) StoreResultSet(rs ResultSet) error {
+	var err error
+	if ts.rs != nil {
+		err = ts.rs.Close()
+	}
+	ts.rs = rs
+	return errors.Trace(err)
+}
+
+// GetResultSet implements PreparedStatement GetResultSet method.
+func (ts *TiDBStatement) GetResultSet() ResultSet {
+	return ts.rs
 }
 
 // Close implements PreparedStatement Close method.
 func (ts *TiDBStatement) Close() error {
 	//TODO close at tidb level
+	if err := ts.StoreResultSet(nil); err != nil {
+		return errors.Trace(err)
+	}
 	err := ts.ctx.session.DropPreparedStmt(ts.id)
 	if err != nil {
 		return errors.Trace(err)
@@ -211,8 +238,19 @@ func (tc *TiDBContext) SetClientCapability(flags uint32) {
 	tc.session.SetClientCapability(flags)
 }
 
+// SetTLSState implements QueryCtx SetTLSState method.
+func (tc *TiDBContext) SetTLSState(state *tls.ConnectionState) {
+	tc.session.GetSessionVars().TLSConnectionState = state
+}
+
 // Close implements QueryCtx Close method.
 func (tc *TiDBContext) Close() (err error) {
+	// Close the open cursors before the session is closed.
+	for _, stmt := range tc.stmts {
+		if err1 := stmt.StoreResultSet(nil); err1 != nil {
+			log.Errorf("close the cursor of statement %d error %v", stmt.id, err1)
+		}
+	}
 	return tc.session.Close()
 }
 
@@ -221,6 +259,65 @@ func (tc *TiDBContext) Auth(user string, auth []byte, salt []byte) bool {
 	return tc.session.Auth(user, auth, salt)
 }
 
+// GetAuthPlugin implements QueryCtx GetAuthPlugin method.
+func (tc *TiDBContext) GetAuthPlugin(user string) (string, error) {
+	return tc.session.GetAuthPlugin(user)
+}
+
+// AuthCleartext implements QueryCtx AuthCleartext method.
+func (tc *TiDBContext) AuthCleartext(user string, password []byte) bool {
+	return tc.session.AuthCleartext(user, password)
+}
+
+// AuthCachingSha2 implements QueryCtx AuthCachingSha2 method.
+func (tc *TiDBContext) AuthCachingSha2(user string, auth []byte, salt []byte) bool {
+	return tc.session.AuthCachingSha2(user, auth, salt)
+}
+
+// AuthTrusted implements QueryCtx AuthTrusted method.
+func (tc *TiDBContext) AuthTrusted(user string) bool {
+	return tc.session.AuthTrusted(user)
+}
+
+// GetUserResources implements QueryCtx GetUserResources method.
+func (tc *TiDBContext) GetUserResources() (*variable.UserResources, error) {
+	return tc.session.GetUserResources()
+}
+
+// Kill implements QueryCtx Kill method.
+func (tc *TiDBContext) Kill() {
+	tc.session.GetSessionVars().Kill()
+}
+
+// SetStmtChecker implements QueryCtx SetStmtChecker method.
+func (tc *TiDBContext) SetStmtChecker(checker func(stmts []ast.StmtNode) error) {
+	tc.session.SetStmtChecker(checker)
+}
+
+// SetSessionManager implements QueryCtx SetSessionManager method.
+func (tc *TiDBContext) SetSessionManager(sm util.SessionManager) {
+	sessionctx.BindSessionManager(tc.session, sm)
+}
+
+// ShowProcess implements QueryCtx ShowProcess method.
+func (tc *TiDBContext) ShowProcess() util.ProcessInfo {
+	return tc.session.ShowProcess()
+}
+
+// TxnStartTS implements QueryCtx TxnStartTS method.
+func (tc *TiDBContext) TxnStartTS() uint64 {
+	txn := tc.session.Txn()
+	if txn == nil || !txn.Valid() {
+		return 0
+	}
+	return txn.StartTS()
+}
+
+// GetPGPassword implements QueryCtx GetPGPassword method.
+func (tc *TiDBContext) GetPGPassword(user string, method string) (string, error) {
+	return tc.session.GetPGPassword(user, method)
+}
+
 // FieldList implements QueryCtx FieldList method.
 func (tc *TiDBContext) FieldList(table string) (colums []*ColumnInfo, err error) {
 	rs, err := tc.Execute("SELECT * FROM `" + table + "` LIMIT 0")
@@ -0,0 +1,88 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/generallog"
+	"github.com/pingcap/tidb/util/hack"
+)
+
+// writeGeneralLog writes the command to the general query log, the caller should check generallog.Enabled first.
+func (cc *clientConn) writeGeneralLog(cmd byte, arg string) {
+	var txnStartTS uint64
+	if cc.ctx != nil {
+		txnStartTS = cc.ctx.TxnStartTS()
+	}
+	generallog.Write(&generallog.Entry{
+		ConnID:     uint64(cc.connectionID),
+		User:       cc.user,
+		Command:    commandName(cmd),
+		TxnStartTS: txnStartTS,
+		Arg:        arg,
+	})
+}
+
+// generalLogArg returns the argument of the command in the general query log, data is the payload of the command.
+// COM_STMT_EXECUTE is logged with its bound parameters by handleStmtExecute.
+func generalLogArg(cmd byte, data []byte) string {
+	switch cmd {
+	case mysql.ComQuery, mysql.ComStmtPrepare:
+		return hack.String(bytes.TrimSuffix(data, []byte{0}))
+	case mysql.ComInitDB:
+		return hack.String(data)
+	case mysql.ComFieldList:
+		// The table name is followed by the wildcard.
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			data = data[:i]
+		}
+		return hack.String(data)
+	case mysql.ComStmtClose, mysql.ComStmtReset, mysql.ComStmtSendLongData, mysql.ComStmtFetch:
+		if len(data) >= 4 {
+			return strconv.FormatUint(uint64(binary.LittleEndian.Uint32(data)), 10)
+		}
+	}
+	return ""
+}
+
+// stmtExecuteLogArg formats the statement ID and the bound parameters of COM_STMT_EXECUTE, for example:
+//
+//	1 [10 'abc' NULL]
+func stmtExecuteLogArg(stmtID uint32, args []interface{}) string {
+	var buf bytes.Buffer
+	buf.WriteString(strconv.FormatUint(uint64(stmtID), 10))
+	buf.WriteString(" [")
+	for i, arg := range args {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		switch x := arg.(type) {
+		case nil:
+			buf.WriteString("NULL")
+		case string:
+			buf.WriteString("'" + x + "'")
+		case []byte:
+			buf.WriteString("'" + hack.String(x) + "'")
+		default:
+			fmt.Fprintf(&buf, "%v", x)
+		}
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
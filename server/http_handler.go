@@ -0,0 +1,257 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+)
+
+// The paths of the status HTTP API to inspect the schema, the regions and the MVCC data.
+const (
+	pathSchema     = "/schema/"
+	pathTables     = "/tables/"
+	pathMvccKey    = "/mvcc/key/"
+	pathDDLHistory = "/ddl/history"
+)
+
+// httpHandler serves the status HTTP API on the store.
+type httpHandler struct {
+	store kv.Storage
+}
+
+func (h *httpHandler) register(mux *http.ServeMux) {
+	// GET /schema/{db}/{table}
+	mux.HandleFunc(pathSchema, h.handleSchema)
+	// GET /tables/{db}/{table}/regions
+	mux.HandleFunc(pathTables, h.handleTableRegions)
+	// GET /mvcc/key/{db}/{table}/{handle}
+	mux.HandleFunc(pathMvccKey, h.handleMvccKey)
+	// GET /ddl/history
+	mux.HandleFunc(pathDDLHistory, h.handleDDLHistory)
+}
+
+// parsePathParams splits the path after prefix to n parameters, it returns false if the number of parameters isn't n.
+func parsePathParams(path, prefix string, n int) ([]string, bool) {
+	params := strings.Split(strings.TrimPrefix(path, prefix), "/")
+	if len(params) != n {
+		return nil, false
+	}
+	for _, p := range params {
+		if p == "" {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	js, err := json.Marshal(v)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, errors.Trace(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
+func writeHTTPError(w http.ResponseWriter, code int, err error) {
+	if code == http.StatusInternalServerError {
+		log.Errorf("[http] %v", errors.ErrorStack(err))
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// getTable gets the table from the latest information schema, it writes the error to w if it fails.
+func (h *httpHandler) getTable(w http.ResponseWriter, dbName, tableName string) (table.Table, bool) {
+	do, err := tidb.GetDomain(h.store)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, errors.Trace(err))
+		return nil, false
+	}
+	tbl, err := do.InfoSchema().TableByName(model.NewCIStr(dbName), model.NewCIStr(tableName))
+	if err != nil {
+		if terror.ErrorEqual(err, infoschema.ErrTableNotExists) {
+			writeHTTPError(w, http.StatusNotFound, err)
+		} else {
+			writeHTTPError(w, http.StatusInternalServerError, errors.Trace(err))
+		}
+		return nil, false
+	}
+	return tbl, true
+}
+
+// handleSchema returns the table info of a table.
+func (h *httpHandler) handleSchema(w http.ResponseWriter, req *http.Request) {
+	params, ok := parsePathParams(req.URL.Path, pathSchema, 2)
+	if !ok {
+		writeHTTPError(w, http.StatusBadRequest, errors.New("the path should be /schema/{db}/{table}"))
+		return
+	}
+	tbl, ok := h.getTable(w, params[0], params[1])
+	if !ok {
+		return
+	}
+	writeJSON(w, tbl.Meta())
+}
+
+// regionInfo is a region of a table and its leader.
+type regionInfo struct {
+	ID            uint64 `json:"region_id"`
+	StartKey      string `json:"start_key"`
+	EndKey        string `json:"end_key"`
+	LeaderStoreID uint64 `json:"leader_store_id"`
+	LeaderAddr    string `json:"leader_addr"`
+}
+
+// indexRegions is the regions of an index.
+type indexRegions struct {
+	Name    string        `json:"name"`
+	ID      int64         `json:"id"`
+	Regions []*regionInfo `json:"regions"`
+}
+
+// tableRegions is the regions of the records and the indices of a table.
+type tableRegions struct {
+	Name          string          `json:"name"`
+	ID            int64           `json:"id"`
+	RecordRegions []*regionInfo   `json:"record_regions"`
+	Indices       []*indexRegions `json:"indices"`
+}
+
+// locateRegions returns the regions of the key range with the prefix, the keys are hex encoded.
+func (h *httpHandler) locateRegions(prefix kv.Key) ([]*regionInfo, error) {
+	regions, err := tikv.LocateRegions(h.store, prefix, prefix.PrefixNext())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	infos := make([]*regionInfo, 0, len(regions))
+	for _, r := range regions {
+		infos = append(infos, &regionInfo{
+			ID:            r.ID,
+			StartKey:      hex.EncodeToString(r.StartKey),
+			EndKey:        hex.EncodeToString(r.EndKey),
+			LeaderStoreID: r.LeaderStoreID,
+			LeaderAddr:    r.LeaderAddr,
+		})
+	}
+	return infos, nil
+}
+
+// handleTableRegions returns the regions of the records and the indices of a table, it's only supported by TiKV.
+func (h *httpHandler) handleTableRegions(w http.ResponseWriter, req *http.Request) {
+	params, ok := parsePathParams(req.URL.Path, pathTables, 3)
+	if !ok || params[2] != "regions" {
+		writeHTTPError(w, http.StatusBadRequest, errors.New("the path should be /tables/{db}/{table}/regions"))
+		return
+	}
+	if !tikv.IsTiKVStore(h.store) {
+		writeHTTPError(w, http.StatusNotImplemented, errors.New("the regions are only supported by TiKV"))
+		return
+	}
+	tbl, ok := h.getTable(w, params[0], params[1])
+	if !ok {
+		return
+	}
+	tblInfo := tbl.Meta()
+	result := &tableRegions{
+		Name:    tblInfo.Name.O,
+		ID:      tblInfo.ID,
+		Indices: make([]*indexRegions, 0, len(tblInfo.Indices)),
+	}
+	var err error
+	result.RecordRegions, err = h.locateRegions(tablecodec.GenTableRecordPrefix(tblInfo.ID))
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, errors.Trace(err))
+		return
+	}
+	for _, idx := range tblInfo.Indices {
+		regions, err := h.locateRegions(tablecodec.EncodeTableIndexPrefix(tblInfo.ID, idx.ID))
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, errors.Trace(err))
+			return
+		}
+		result.Indices = append(result.Indices, &indexRegions{Name: idx.Name.O, ID: idx.ID, Regions: regions})
+	}
+	writeJSON(w, result)
+}
+
+// handleMvccKey returns all the MVCC versions and the lock of a row. It's only supported by the local stores,
+// TiKV doesn't return the older versions and the commit timestamps by its RPC, so it responds 501 for TiKV.
+func (h *httpHandler) handleMvccKey(w http.ResponseWriter, req *http.Request) {
+	params, ok := parsePathParams(req.URL.Path, pathMvccKey, 3)
+	if !ok {
+		writeHTTPError(w, http.StatusBadRequest, errors.New("the path should be /mvcc/key/{db}/{table}/{handle}"))
+		return
+	}
+	handle, err := strconv.ParseInt(params[2], 10, 64)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, errors.Errorf("invalid handle %s", params[2]))
+		return
+	}
+	inspector, ok := h.store.(kv.MvccInspector)
+	if !ok {
+		writeHTTPError(w, http.StatusNotImplemented, errors.New("the store doesn't support MVCC inspection"))
+		return
+	}
+	tbl, ok := h.getTable(w, params[0], params[1])
+	if !ok {
+		return
+	}
+	info, err := inspector.MvccInfo(tablecodec.EncodeRowKeyWithHandle(tbl.Meta().ID, handle))
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, errors.Trace(err))
+		return
+	}
+	writeJSON(w, info)
+}
+
+type jobsByID []*model.Job
+
+func (s jobsByID) Len() int           { return len(s) }
+func (s jobsByID) Less(i, j int) bool { return s[i].ID < s[j].ID }
+func (s jobsByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// handleDDLHistory returns the finished DDL jobs ordered by the job IDs.
+func (h *httpHandler) handleDDLHistory(w http.ResponseWriter, req *http.Request) {
+	txn, err := h.store.Begin()
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, errors.Trace(err))
+		return
+	}
+	defer txn.Rollback()
+	jobs, err := meta.NewMeta(txn).GetAllHistoryDDLJobs()
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, errors.Trace(err))
+		return
+	}
+	sort.Sort(jobsByID(jobs))
+	writeJSON(w, jobs)
+}
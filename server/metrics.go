@@ -15,6 +15,7 @@ package server
 
 import (
 	"strconv"
+	"sync"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/terror"
@@ -56,10 +57,59 @@ var (
 		}, []string{"type"})
 )
 
+// connStates are the states of the connections reported by connStateCollector.
+var connStates = []string{connStateIdle, connStateIdleInTxn, connStateExecuting, connStateClosing}
+
+// connStateCollector reports the connections of the running servers by their states,
+// the states are collected when the metrics are scraped.
+type connStateCollector struct {
+	desc *prometheus.Desc
+
+	mu      sync.Mutex
+	servers map[*Server]struct{}
+}
+
+var connStateMetrics = &connStateCollector{
+	desc: prometheus.NewDesc(prometheus.BuildFQName("tidb", "server", "connections_by_state"),
+		"Number of connections by state.", []string{"state"}, nil),
+	servers: make(map[*Server]struct{}),
+}
+
+func (c *connStateCollector) addServer(s *Server) {
+	c.mu.Lock()
+	c.servers[s] = struct{}{}
+	c.mu.Unlock()
+}
+
+func (c *connStateCollector) removeServer(s *Server) {
+	c.mu.Lock()
+	delete(c.servers, s)
+	c.mu.Unlock()
+}
+
+// Describe implements the prometheus.Collector Describe interface.
+func (c *connStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements the prometheus.Collector Collect interface.
+func (c *connStateCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := make(map[string]int, len(connStates))
+	c.mu.Lock()
+	for s := range c.servers {
+		s.countConnStates(counts)
+	}
+	c.mu.Unlock()
+	for _, state := range connStates {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts[state]), state)
+	}
+}
+
 func init() {
 	prometheus.MustRegister(queryHistogram)
 	prometheus.MustRegister(queryCounter)
 	prometheus.MustRegister(connGauge)
+	prometheus.MustRegister(connStateMetrics)
 }
 
 func executeErrorToLabel(err error) string {
@@ -0,0 +1,68 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"sync"
+
+	. "github.com/pingcap/check"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type MetricsTestSuite struct{}
+
+var _ = Suite(MetricsTestSuite{})
+
+func (ts MetricsTestSuite) TestConnStateCollector(c *C) {
+	s := &Server{
+		rwlock:    &sync.RWMutex{},
+		clients:   make(map[uint32]*clientConn),
+		pgClients: make(map[uint32]*pgConn),
+	}
+	statuses := []int32{connStatusIdle, connStatusInTxn, connStatusDispatching, connStatusDispatching, connStatusClosing}
+	for i, status := range statuses {
+		s.clients[uint32(i)] = &clientConn{drain: drainState{status: status}}
+	}
+	s.pgClients[0] = &pgConn{drain: drainState{status: connStatusInTxn}}
+
+	collector := &connStateCollector{desc: connStateMetrics.desc, servers: make(map[*Server]struct{})}
+	collect := func() map[string]float64 {
+		ch := make(chan prometheus.Metric, len(connStates))
+		collector.Collect(ch)
+		close(ch)
+		values := make(map[string]float64)
+		for m := range ch {
+			pb := &dto.Metric{}
+			c.Assert(m.Write(pb), IsNil)
+			values[pb.GetLabel()[0].GetValue()] = pb.GetGauge().GetValue()
+		}
+		return values
+	}
+
+	collector.addServer(s)
+	c.Assert(collect(), DeepEquals, map[string]float64{
+		connStateIdle:      1,
+		connStateIdleInTxn: 2,
+		connStateExecuting: 2,
+		connStateClosing:   1,
+	})
+	collector.removeServer(s)
+	c.Assert(collect(), DeepEquals, map[string]float64{
+		connStateIdle:      0,
+		connStateIdleInTxn: 0,
+		connStateExecuting: 0,
+		connStateClosing:   0,
+	})
+}
@@ -54,6 +54,8 @@ type packetIO struct {
 	wb *bufio.Writer
 
 	sequence uint8
+	// cio is the compressed protocol the packets are sent over, it's nil if the packets are not compressed.
+	cio *compressedIO
 }
 
 func newPacketIO(conn net.Conn) *packetIO {
@@ -73,11 +75,12 @@ func (p *packetIO) readOnePacket() ([]byte, error) {
 	}
 
 	sequence := uint8(header[3])
-	if sequence != p.sequence {
+	if sequence != p.sequence && p.cio == nil {
 		return nil, errInvalidSequence.Gen("invalid sequence %d != %d", sequence, p.sequence)
 	}
-
-	p.sequence++
+	// The clients don't keep the sequence of the packets wrapped in the compressed packets,
+	// the sequence of the compressed packets is checked instead.
+	p.sequence = sequence + 1
 
 	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
 
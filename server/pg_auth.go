@@ -0,0 +1,293 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util"
+)
+
+// The authentication request codes of the PostgreSQL protocol.
+const (
+	pgAuthOK                = 0
+	pgAuthCleartextPassword = 3
+	pgAuthMD5Password       = 5
+	pgAuthSASL              = 10
+	pgAuthSASLContinue      = 11
+	pgAuthSASLFinal         = 12
+)
+
+const (
+	pgScramMechanism = "SCRAM-SHA-256"
+	pgScramNonceLen  = 18
+)
+
+// authenticate authenticates the user with the authentication method of the server. The users without a
+// password are logged in without asking for it, like the trust method of PostgreSQL.
+func (pc *pgConn) authenticate() error {
+	if pc.server.skipAuth() {
+		return errors.Trace(pc.writeAuthRequest(pgAuthOK, nil))
+	}
+	host, err := pc.peerHost()
+	if err != nil {
+		return errors.Trace(mysql.NewErr(mysql.ErrAccessDenied, pc.user, pc.conn.RemoteAddr().String(), "Yes"))
+	}
+	user := fmt.Sprintf("%s@%s", pc.user, host)
+	errAccessDenied := mysql.NewErr(mysql.ErrAccessDenied, pc.user, host, "Yes")
+	if pc.ctx.AuthCleartext(user, nil) {
+		return errors.Trace(pc.writeAuthRequest(pgAuthOK, nil))
+	}
+
+	var ok bool
+	switch pc.server.pgAuthMethod() {
+	case util.PGAuthPassword:
+		ok, err = pc.authCleartext(user)
+	case util.PGAuthMD5:
+		ok, err = pc.authMD5(user)
+	default:
+		ok, err = pc.authScram(user)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !ok {
+		return errors.Trace(errAccessDenied)
+	}
+	return errors.Trace(pc.writeAuthRequest(pgAuthOK, nil))
+}
+
+// writeAuthRequest writes an authentication request message and flushes it.
+func (pc *pgConn) writeAuthRequest(code uint32, data []byte) error {
+	buf := newPGBuffer()
+	buf.putUint32(code)
+	buf.putBytes(data)
+	if err := pc.writeMessage(pgMsgAuthentication, buf.bytes()); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(pc.flush())
+}
+
+// readPassword reads the PasswordMessage, SASLInitialResponse or SASLResponse message of the client.
+func (pc *pgConn) readPassword() ([]byte, error) {
+	typ, data, err := pc.readMessage()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if typ != pgMsgPassword {
+		return nil, newPGError(pgStateProtocolViolation, "expected password response, got message type %c", typ)
+	}
+	return data, nil
+}
+
+func (pc *pgConn) authCleartext(user string) (bool, error) {
+	if err := pc.writeAuthRequest(pgAuthCleartextPassword, nil); err != nil {
+		return false, errors.Trace(err)
+	}
+	data, err := pc.readPassword()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return pc.ctx.AuthCleartext(user, trimNullTerminator(data)), nil
+}
+
+// authMD5 verifies "md5" + md5(md5(password + user) + salt) sent by the client with the stored md5(password + user).
+func (pc *pgConn) authMD5(user string) (bool, error) {
+	salt := make([]byte, 4)
+	if _, err := rand.Read(salt); err != nil {
+		return false, errors.Trace(err)
+	}
+	if err := pc.writeAuthRequest(pgAuthMD5Password, salt); err != nil {
+		return false, errors.Trace(err)
+	}
+	data, err := pc.readPassword()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	stored, err := pc.ctx.GetPGPassword(user, util.PGAuthMD5)
+	if err != nil || len(stored) != len("md5")+md5.Size*2 {
+		log.Warnf("[%d] user %s has no md5 password", pc.connectionID, user)
+		return false, nil
+	}
+	sum := md5.Sum(append([]byte(stored[len("md5"):]), salt...))
+	expected := "md5" + hex.EncodeToString(sum[:])
+	if !hmac.Equal([]byte(expected), trimNullTerminator(data)) {
+		return false, nil
+	}
+	return pc.ctx.AuthTrusted(user), nil
+}
+
+// authScram runs the SCRAM-SHA-256 exchange, channel binding is not supported.
+// See https://tools.ietf.org/html/rfc5802 and https://tools.ietf.org/html/rfc7677
+func (pc *pgConn) authScram(user string) (bool, error) {
+	buf := newPGBuffer()
+	buf.putString(pgScramMechanism)
+	buf.putByte(0)
+	if err := pc.writeAuthRequest(pgAuthSASL, buf.bytes()); err != nil {
+		return false, errors.Trace(err)
+	}
+
+	// SASLInitialResponse contains the mechanism and the client-first-message.
+	data, err := pc.readPassword()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	r := newPGReader(data)
+	mechanism := r.getString()
+	clientFirst := r.getLengthPrefixedBytes()
+	if r.err != nil {
+		return false, errors.Trace(r.err)
+	}
+	if mechanism != pgScramMechanism {
+		return false, newPGError(pgStateProtocolViolation, "unsupported SASL mechanism %s", mechanism)
+	}
+	gs2Header, clientFirstBare, clientNonce, err := parseScramClientFirst(string(clientFirst))
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+
+	// The exchange goes on with a mock verifier if the user has no SCRAM password,
+	// so the client can't tell whether the user exists.
+	verifierOK := true
+	stored, err := pc.ctx.GetPGPassword(user, util.PGAuthScramSha256)
+	if err != nil {
+		stored = ""
+	}
+	iterations, salt, storedKey, serverKey, err := util.DecodePGScramPassword(stored)
+	if err != nil {
+		log.Warnf("[%d] user %s has no SCRAM-SHA-256 password", pc.connectionID, user)
+		verifierOK = false
+		iterations, salt, storedKey, serverKey = util.PGScramIterations, make([]byte, 16), nil, nil
+		if _, err = rand.Read(salt); err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+
+	serverNonce := make([]byte, pgScramNonceLen)
+	if _, err = rand.Read(serverNonce); err != nil {
+		return false, errors.Trace(err)
+	}
+	nonce := clientNonce + base64.StdEncoding.EncodeToString(serverNonce)
+	serverFirst := fmt.Sprintf("r=%s,s=%s,i=%d", nonce, base64.StdEncoding.EncodeToString(salt), iterations)
+	if err = pc.writeAuthRequest(pgAuthSASLContinue, []byte(serverFirst)); err != nil {
+		return false, errors.Trace(err)
+	}
+
+	data, err = pc.readPassword()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	clientFinal := string(data)
+	clientFinalWithoutProof, proof, err := parseScramClientFinal(clientFinal, gs2Header, nonce)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !verifierOK {
+		return false, nil
+	}
+	authMessage := []byte(clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof)
+	serverSignature, ok := verifyScramProof(storedKey, serverKey, authMessage, proof)
+	if !ok {
+		return false, nil
+	}
+	serverFinal := "v=" + base64.StdEncoding.EncodeToString(serverSignature)
+	if err = pc.writeAuthRequest(pgAuthSASLFinal, []byte(serverFinal)); err != nil {
+		return false, errors.Trace(err)
+	}
+	return pc.ctx.AuthTrusted(user), nil
+}
+
+// verifyScramProof verifies the ClientProof with the StoredKey, it returns the ServerSignature if the proof is valid.
+func verifyScramProof(storedKey, serverKey, authMessage, proof []byte) ([]byte, bool) {
+	clientSignature := util.HmacSha256(storedKey, authMessage)
+	if len(proof) != len(clientSignature) {
+		return nil, false
+	}
+	clientKey := make([]byte, len(proof))
+	for i := range proof {
+		clientKey[i] = proof[i] ^ clientSignature[i]
+	}
+	if !hmac.Equal(util.Sha256Hash(clientKey), storedKey) {
+		return nil, false
+	}
+	return util.HmacSha256(serverKey, authMessage), true
+}
+
+// parseScramClientFirst parses client-first-message: gs2-header client-first-message-bare,
+// the gs2-header is "n,," or "y,," as channel binding is not supported.
+func parseScramClientFirst(msg string) (gs2Header, clientFirstBare, nonce string, err error) {
+	errMalformed := newPGError(pgStateProtocolViolation, "malformed SCRAM message")
+	if !strings.HasPrefix(msg, "n,") && !strings.HasPrefix(msg, "y,") {
+		if strings.HasPrefix(msg, "p=") {
+			return "", "", "", newPGError(pgStateProtocolViolation, "channel binding is not supported")
+		}
+		return "", "", "", errMalformed
+	}
+	// The authzid is ignored.
+	i := strings.IndexByte(msg[2:], ',')
+	if i < 0 {
+		return "", "", "", errMalformed
+	}
+	gs2Header, clientFirstBare = msg[:2+i+1], msg[2+i+1:]
+	for _, attr := range strings.Split(clientFirstBare, ",") {
+		if strings.HasPrefix(attr, "r=") {
+			nonce = attr[2:]
+		}
+	}
+	if nonce == "" {
+		return "", "", "", errMalformed
+	}
+	return gs2Header, clientFirstBare, nonce, nil
+}
+
+// parseScramClientFinal parses client-final-message: c=<base64 gs2-header>,r=<nonce>,p=<base64 proof>.
+func parseScramClientFinal(msg, gs2Header, nonce string) (withoutProof string, proof []byte, err error) {
+	errMalformed := newPGError(pgStateProtocolViolation, "malformed SCRAM message")
+	i := strings.LastIndex(msg, ",p=")
+	if i < 0 {
+		return "", nil, errMalformed
+	}
+	withoutProof = msg[:i]
+	proof, err = base64.StdEncoding.DecodeString(msg[i+len(",p="):])
+	if err != nil {
+		return "", nil, errMalformed
+	}
+	var binding []byte
+	var clientNonce string
+	for _, attr := range strings.Split(withoutProof, ",") {
+		switch {
+		case strings.HasPrefix(attr, "c="):
+			binding, err = base64.StdEncoding.DecodeString(attr[2:])
+			if err != nil {
+				return "", nil, errMalformed
+			}
+		case strings.HasPrefix(attr, "r="):
+			clientNonce = attr[2:]
+		}
+	}
+	if !bytes.Equal(binding, []byte(gs2Header)) || clientNonce != nonce {
+		return "", nil, errMalformed
+	}
+	return withoutProof, proof, nil
+}
@@ -0,0 +1,1114 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The message types sent by the frontend.
+// See https://www.postgresql.org/docs/current/protocol-message-formats.html
+const (
+	pgMsgBind      byte = 'B'
+	pgMsgClose     byte = 'C'
+	pgMsgDescribe  byte = 'D'
+	pgMsgExecute   byte = 'E'
+	pgMsgFlush     byte = 'H'
+	pgMsgParse     byte = 'P'
+	pgMsgQuery     byte = 'Q'
+	pgMsgSync      byte = 'S'
+	pgMsgTerminate byte = 'X'
+	pgMsgPassword  byte = 'p'
+)
+
+// The message types sent by the backend.
+const (
+	pgMsgAuthentication       byte = 'R'
+	pgMsgBackendKeyData       byte = 'K'
+	pgMsgBindComplete         byte = '2'
+	pgMsgCloseComplete        byte = '3'
+	pgMsgCommandComplete      byte = 'C'
+	pgMsgDataRow              byte = 'D'
+	pgMsgEmptyQueryResponse   byte = 'I'
+	pgMsgErrorResponse        byte = 'E'
+	pgMsgNoData               byte = 'n'
+	pgMsgParameterDescription byte = 't'
+	pgMsgParameterStatus      byte = 'S'
+	pgMsgParseComplete        byte = '1'
+	pgMsgPortalSuspended      byte = 's'
+	pgMsgReadyForQuery        byte = 'Z'
+	pgMsgRowDescription       byte = 'T'
+)
+
+// The codes of the packets sent before the startup message is accepted.
+const (
+	pgProtocolVersion3  = 196608
+	pgCancelRequestCode = 80877102
+	pgSSLRequestCode    = 80877103
+	pgGSSEncRequestCode = 80877104
+)
+
+const (
+	// pgMaxMessageLen is the max length of the messages, which is the same as PostgreSQL.
+	pgMaxMessageLen = 1 << 30
+	// pgMaxStartupLen is the max length of the startup packets.
+	pgMaxStartupLen = 10000
+)
+
+// pgParameterStatuses are the run-time parameters reported to the client after the authentication.
+// Backslashes are escape characters in string literals as in MySQL, so standard_conforming_strings is off.
+var pgParameterStatuses = [][2]string{
+	{"server_version", "9.6.0"},
+	{"server_encoding", "UTF8"},
+	{"client_encoding", "UTF8"},
+	{"DateStyle", "ISO, MDY"},
+	{"integer_datetimes", "on"},
+	{"standard_conforming_strings", "off"},
+}
+
+var errPGMalformedMessage = newPGError(pgStateProtocolViolation, "invalid message format")
+
+// pgConn represents a connection of the PostgreSQL protocol server. It shares the QueryCtx with the
+// MySQL protocol server, so the SQL statements are in the MySQL dialect except the $n parameters.
+type pgConn struct {
+	server       *Server
+	conn         net.Conn
+	rb           *bufio.Reader
+	wb           *bufio.Writer
+	connectionID uint32
+	secretKey    uint32
+	user         string
+	dbname       string
+	tlsState     *tls.ConnectionState
+	ctx          QueryCtx
+	usage        *accountUsage
+	stmts        map[string]*pgStatement
+	portals      map[string]*pgPortal
+	// skipTillSync is set after an error of the extended query protocol, the messages are discarded until Sync.
+	skipTillSync bool
+	drain        drainState
+}
+
+// pgStatement is a prepared statement created by the Parse message.
+type pgStatement struct {
+	sql string
+	// stmt is nil for the empty query.
+	stmt    PreparedStatement
+	columns []*ColumnInfo
+	// paramOIDs are the types of $1, $2... which are specified by the client or text.
+	paramOIDs []uint32
+	// paramOrder maps the placeholders of the rewritten statement to the indexes of $n.
+	paramOrder []int
+}
+
+// pgPortal is a statement bound with parameters by the Bind message.
+type pgPortal struct {
+	stmt     *pgStatement
+	args     []interface{}
+	formats  []int16
+	executed bool
+	rs       ResultSet
+	// next is the row fetched in advance to know whether the portal is suspended or completed.
+	next []types.Datum
+}
+
+func (s *Server) newPGConn(conn net.Conn) *pgConn {
+	pc := &pgConn{
+		server:       s,
+		conn:         conn,
+		rb:           bufio.NewReaderSize(conn, defaultReaderSize),
+		wb:           bufio.NewWriterSize(conn, defaultWriterSize),
+		connectionID: atomic.AddUint32(&baseConnID, 1),
+		secretKey:    rand.Uint32(),
+		stmts:        make(map[string]*pgStatement),
+		portals:      make(map[string]*pgPortal),
+	}
+	log.Infof("[%d] new PostgreSQL connection %s", pc.connectionID, conn.RemoteAddr().String())
+	return pc
+}
+
+// handshake reads the startup message, authenticates the user and reports the run-time parameters.
+func (pc *pgConn) handshake() error {
+	params, err := pc.readStartup()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	pc.user, pc.dbname = params["user"], params["database"]
+	if pc.user == "" {
+		err = newPGError(pgStateProtocolViolation, "no user name specified in startup packet")
+		pc.writeError("FATAL", err)
+		return errors.Trace(err)
+	}
+	pc.ctx, err = pc.server.driver.OpenCtx(uint64(pc.connectionID), 0, mysql.DefaultCollationID, pc.dbname)
+	if err != nil {
+		pc.writeError("FATAL", err)
+		return errors.Trace(err)
+	}
+	pc.ctx.SetTLSState(pc.tlsState)
+	pc.ctx.SetSessionManager(pc.server)
+	if err = pc.authenticate(); err != nil {
+		pc.writeError("FATAL", err)
+		return errors.Trace(err)
+	}
+	if pc.usage, err = pc.server.connectUser(pc.ctx); err != nil {
+		pc.writeError("FATAL", err)
+		return errors.Trace(err)
+	}
+
+	for _, param := range pgParameterStatuses {
+		buf := newPGBuffer()
+		buf.putString(param[0])
+		buf.putString(param[1])
+		if err = pc.writeMessage(pgMsgParameterStatus, buf.bytes()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	buf := newPGBuffer()
+	buf.putUint32(pc.connectionID)
+	buf.putUint32(pc.secretKey)
+	if err = pc.writeMessage(pgMsgBackendKeyData, buf.bytes()); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(pc.writeReadyForQuery())
+}
+
+// readStartup reads the startup packets until the startup message, it answers the SSL and GSSAPI
+// encryption requests, and returns the parameters of the startup message.
+func (pc *pgConn) readStartup() (map[string]string, error) {
+	for {
+		data, err := pc.readStartupPacket()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		r := newPGReader(data)
+		switch code := r.getUint32(); code {
+		case pgSSLRequestCode:
+			if pc.server.tlsConfig == nil || pc.tlsState != nil {
+				if err = pc.writeByte('N'); err != nil {
+					return nil, errors.Trace(err)
+				}
+				continue
+			}
+			if err = pc.writeByte('S'); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if err = pc.upgradeToTLS(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		case pgGSSEncRequestCode:
+			if err = pc.writeByte('N'); err != nil {
+				return nil, errors.Trace(err)
+			}
+		case pgCancelRequestCode:
+			return nil, errors.New("cancel request is not supported")
+		case pgProtocolVersion3:
+			params := make(map[string]string)
+			for {
+				key := r.getString()
+				if r.err != nil || key == "" {
+					break
+				}
+				params[key] = r.getString()
+			}
+			return params, errors.Trace(r.err)
+		default:
+			err = newPGError(pgStateFeatureNotSupported, "unsupported frontend protocol %d.%d", code>>16, code&0xffff)
+			pc.writeError("FATAL", err)
+			return nil, errors.Trace(err)
+		}
+	}
+}
+
+func (pc *pgConn) readStartupPacket() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(pc.rb, header[:]); err != nil {
+		return nil, errors.Trace(err)
+	}
+	length := int(binary.BigEndian.Uint32(header[:]))
+	if length < 8 || length > pgMaxStartupLen {
+		return nil, errors.Trace(errPGMalformedMessage)
+	}
+	data := make([]byte, length-4)
+	if _, err := io.ReadFull(pc.rb, data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return data, nil
+}
+
+// writeByte writes the single byte response of the SSL and GSSAPI encryption requests.
+func (pc *pgConn) writeByte(b byte) error {
+	if err := pc.wb.WriteByte(b); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(pc.flush())
+}
+
+// upgradeToTLS does the TLS handshake on the connection and replaces the connection with the secured one.
+func (pc *pgConn) upgradeToTLS() error {
+	tlsConn := tls.Server(bufferedReadConn{Conn: pc.conn, rb: pc.rb}, pc.server.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return errors.Trace(err)
+	}
+	pc.conn = tlsConn
+	pc.rb = bufio.NewReaderSize(tlsConn, defaultReaderSize)
+	pc.wb = bufio.NewWriterSize(tlsConn, defaultWriterSize)
+	state := tlsConn.ConnectionState()
+	pc.tlsState = &state
+	return nil
+}
+
+// peerHost returns the host of the client.
+func (pc *pgConn) peerHost() (string, error) {
+	host, _, err := net.SplitHostPort(pc.conn.RemoteAddr().String())
+	return host, errors.Trace(err)
+}
+
+// readMessage reads a message, which is the message type followed by the length and the payload.
+func (pc *pgConn) readMessage() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(pc.rb, header[:]); err != nil {
+		// io.EOF is returned as is, so Run knows the connection is closed by the client.
+		return 0, nil, err
+	}
+	length := int(binary.BigEndian.Uint32(header[1:]))
+	if length < 4 || length > pgMaxMessageLen {
+		return 0, nil, errors.Trace(errPGMalformedMessage)
+	}
+	data := make([]byte, length-4)
+	if _, err := io.ReadFull(pc.rb, data); err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	return header[0], data, nil
+}
+
+// writeMessage writes a message to the buffer, it's sent to the client when the buffer is flushed.
+func (pc *pgConn) writeMessage(typ byte, data []byte) error {
+	var header [5]byte
+	header[0] = typ
+	binary.BigEndian.PutUint32(header[1:], uint32(len(data)+4))
+	if _, err := pc.wb.Write(header[:]); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	if _, err := pc.wb.Write(data); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	return nil
+}
+
+func (pc *pgConn) flush() error {
+	return errors.Trace(pc.wb.Flush())
+}
+
+// writeError writes an ErrorResponse message with the SQLSTATE code mapped from the error.
+func (pc *pgConn) writeError(severity string, e error) error {
+	state, message := toPGError(e)
+	buf := newPGBuffer()
+	for _, field := range []struct {
+		code  byte
+		value string
+	}{{'S', severity}, {'V', severity}, {'C', state}, {'M', message}} {
+		buf.putByte(field.code)
+		buf.putString(field.value)
+	}
+	buf.putByte(0)
+	if err := pc.writeMessage(pgMsgErrorResponse, buf.bytes()); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(pc.flush())
+}
+
+// writeReadyForQuery writes a ReadyForQuery message with the transaction status and flushes it.
+func (pc *pgConn) writeReadyForQuery() error {
+	status := byte('I')
+	if pc.ctx.Status()&mysql.ServerStatusInTrans > 0 {
+		status = 'T'
+	}
+	if err := pc.writeMessage(pgMsgReadyForQuery, []byte{status}); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(pc.flush())
+}
+
+func (pc *pgConn) writeCommandComplete(tag string) error {
+	buf := newPGBuffer()
+	buf.putString(tag)
+	return errors.Trace(pc.writeMessage(pgMsgCommandComplete, buf.bytes()))
+}
+
+// Run reads the messages of the client and handles them in for loop, the connection is closed if
+// there is an IO error, a protocol violation or a panic.
+func (pc *pgConn) Run() {
+	const size = 4096
+	defer func() {
+		r := recover()
+		if r != nil {
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			log.Errorf("[%d] PostgreSQL connection panic %v, %s", pc.connectionID, r, buf)
+		}
+		pc.Close()
+	}()
+
+	for {
+		typ, data, err := pc.readMessage()
+		if err != nil {
+			if terror.ErrorNotEqual(err, io.EOF) {
+				log.Error(errors.ErrorStack(err))
+			}
+			return
+		}
+		if pc.skipTillSync && typ != pgMsgSync {
+			continue
+		}
+		if !pc.drain.startDispatch() {
+			return
+		}
+
+		startTime := time.Now()
+		if err = pc.dispatch(typ, data); err != nil {
+			if terror.ErrorEqual(err, io.EOF) {
+				return
+			}
+			log.Warnf("[%d] PostgreSQL dispatch error:\n%c %s\n%s",
+				pc.connectionID, typ, queryStrForLog(string(data)), errStrForLog(err))
+			pc.writeError("ERROR", err)
+			if pe, ok := errors.Cause(err).(*pgError); ok && pe.state == pgStateProtocolViolation {
+				return
+			}
+			if typ == pgMsgQuery {
+				pc.writeReadyForQuery()
+			} else {
+				pc.skipTillSync = true
+			}
+		}
+		pc.addMetrics(typ, startTime, err)
+		// The extended query isn't finished until Sync, the connection is kept like in a transaction.
+		inTxn := (typ != pgMsgQuery && typ != pgMsgSync) || pc.ctx.Status()&mysql.ServerStatusInTrans > 0
+		if !pc.drain.finishDispatch(inTxn, pc.server.isDraining()) {
+			return
+		}
+	}
+}
+
+func (pc *pgConn) dispatch(typ byte, data []byte) error {
+	if pc.usage != nil && pc.usage.tokens != nil {
+		userToken := pc.usage.tokens.Get()
+		defer pc.usage.tokens.Put(userToken)
+	}
+	token := pc.server.getToken()
+	defer func() {
+		pc.server.releaseToken(token)
+	}()
+
+	switch typ {
+	case pgMsgQuery:
+		return pc.handleQuery(string(trimNullTerminator(data)))
+	case pgMsgParse:
+		return pc.handleParse(data)
+	case pgMsgBind:
+		return pc.handleBind(data)
+	case pgMsgDescribe:
+		return pc.handleDescribe(data)
+	case pgMsgExecute:
+		return pc.handleExecute(data)
+	case pgMsgClose:
+		return pc.handleClose(data)
+	case pgMsgSync:
+		return pc.handleSync()
+	case pgMsgFlush:
+		return pc.flush()
+	case pgMsgTerminate:
+		return io.EOF
+	default:
+		return newPGError(pgStateProtocolViolation, "invalid frontend message type %d", typ)
+	}
+}
+
+func (pc *pgConn) addMetrics(typ byte, startTime time.Time, err error) {
+	var label string
+	switch typ {
+	case pgMsgQuery:
+		label = "PGQuery"
+	case pgMsgParse:
+		label = "PGParse"
+	case pgMsgBind:
+		label = "PGBind"
+	case pgMsgDescribe:
+		label = "PGDescribe"
+	case pgMsgExecute:
+		label = "PGExecute"
+	case pgMsgClose:
+		label = "PGClose"
+	case pgMsgSync:
+		label = "PGSync"
+	case pgMsgFlush:
+		label = "PGFlush"
+	default:
+		label = fmt.Sprintf("PG%d", typ)
+	}
+	if err != nil {
+		queryCounter.WithLabelValues(label, "Error").Inc()
+	} else {
+		queryCounter.WithLabelValues(label, "OK").Inc()
+	}
+	queryHistogram.Observe(time.Since(startTime).Seconds())
+}
+
+// Close closes the statements, the portals and the session of the connection.
+func (pc *pgConn) Close() error {
+	pc.server.rwlock.Lock()
+	delete(pc.server.pgClients, pc.connectionID)
+	pc.server.rwlock.Unlock()
+	for name := range pc.stmts {
+		pc.closeStatement(name)
+	}
+	if pc.usage != nil {
+		pc.usage.disconnect()
+		pc.usage = nil
+	}
+	pc.conn.Close()
+	if pc.ctx != nil {
+		return pc.ctx.Close()
+	}
+	return nil
+}
+
+// handleQuery handles the simple query protocol, the query may contain multiple statements.
+func (pc *pgConn) handleQuery(sql string) error {
+	if strings.TrimSpace(sql) == "" {
+		if err := pc.writeMessage(pgMsgEmptyQueryResponse, nil); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(pc.writeReadyForQuery())
+	}
+	rss, err := pc.ctx.Execute(rewritePGBegin(sql))
+	if err != nil {
+		executeErrorCounter.WithLabelValues(executeErrorToLabel(err)).Inc()
+		return errors.Trace(err)
+	}
+	if err = pc.checkLoadData(); err != nil {
+		return errors.Trace(err)
+	}
+	if len(rss) == 0 {
+		if err = pc.writeCommandComplete(pgCommandTag(sql, pc.ctx.AffectedRows())); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for i, rs := range rss {
+		columns, err := rs.Columns()
+		if err == nil {
+			err = pc.writeRowDescription(columns, nil)
+		}
+		if err == nil {
+			err = pc.writeRows(&pgPortal{stmt: &pgStatement{columns: columns}, rs: rs}, 0)
+		}
+		if err != nil {
+			for _, rs := range rss[i:] {
+				rs.Close()
+			}
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(pc.writeReadyForQuery())
+}
+
+// checkLoadData returns an error if the statement is LOAD DATA LOCAL, which is not supported by the protocol.
+func (pc *pgConn) checkLoadData() error {
+	if pc.ctx.Value(executor.LoadDataVarKey) == nil {
+		return nil
+	}
+	pc.ctx.SetValue(executor.LoadDataVarKey, nil)
+	return newPGError(pgStateFeatureNotSupported, "LOAD DATA LOCAL is not supported")
+}
+
+// pgCommandTag returns the tag of CommandComplete for the statement which has no result set.
+func pgCommandTag(sql string, affectedRows uint64) string {
+	fields := strings.Fields(strings.Replace(sql, ";", " ", -1))
+	if len(fields) == 0 {
+		return ""
+	}
+	keyword := strings.ToUpper(fields[0])
+	switch keyword {
+	case "INSERT", "REPLACE":
+		return fmt.Sprintf("INSERT 0 %d", affectedRows)
+	case "UPDATE", "DELETE":
+		return fmt.Sprintf("%s %d", keyword, affectedRows)
+	case "CREATE", "DROP", "ALTER", "TRUNCATE":
+		if len(fields) > 1 {
+			return keyword + " " + strings.ToUpper(fields[1])
+		}
+	case "START":
+		return "BEGIN"
+	}
+	return keyword
+}
+
+// writeRowDescription writes a RowDescription message, formats are the format codes of the columns as in Bind.
+func (pc *pgConn) writeRowDescription(columns []*ColumnInfo, formats []int16) error {
+	buf := newPGBuffer()
+	buf.putUint16(uint16(len(columns)))
+	for i, column := range columns {
+		oid := pgTypeOID(column)
+		buf.putString(column.Name)
+		// The OID of the table and the attribute number of the column.
+		buf.putUint32(0)
+		buf.putUint16(0)
+		buf.putUint32(oid)
+		buf.putUint16(uint16(pgTypeSize(oid)))
+		// The type modifier.
+		buf.putUint32(0xffffffff)
+		buf.putUint16(uint16(pgFormat(formats, i)))
+	}
+	return errors.Trace(pc.writeMessage(pgMsgRowDescription, buf.bytes()))
+}
+
+// pgFormat returns the format code of the i-th value, a single format code applies to all the values.
+func pgFormat(formats []int16, i int) int16 {
+	switch len(formats) {
+	case 0:
+		return pgFormatText
+	case 1:
+		return formats[0]
+	default:
+		if i < len(formats) {
+			return formats[i]
+		}
+		return pgFormatText
+	}
+}
+
+// writeRows writes at most maxRows rows of the portal, zero means no limit. It writes PortalSuspended
+// if there are more rows, or closes the result set and writes CommandComplete.
+func (pc *pgConn) writeRows(p *pgPortal, maxRows int) error {
+	columns := p.stmt.columns
+	if rsColumns, err := p.rs.Columns(); err == nil && len(rsColumns) != len(columns) {
+		columns = rsColumns
+	}
+	oids := make([]uint32, len(columns))
+	for i, column := range columns {
+		oids[i] = pgTypeOID(column)
+	}
+
+	count := 0
+	for maxRows <= 0 || count < maxRows {
+		row := p.next
+		p.next = nil
+		if row == nil {
+			var err error
+			if row, err = p.rs.Next(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if row == nil {
+			break
+		}
+		if err := pc.writeDataRow(columns, oids, p.formats, row); err != nil {
+			return errors.Trace(err)
+		}
+		count++
+	}
+	if maxRows > 0 && count == maxRows {
+		var err error
+		if p.next, err = p.rs.Next(); err != nil {
+			return errors.Trace(err)
+		}
+		if p.next != nil {
+			return errors.Trace(pc.writeMessage(pgMsgPortalSuspended, nil))
+		}
+	}
+	p.close()
+	return errors.Trace(pc.writeCommandComplete(fmt.Sprintf("SELECT %d", count)))
+}
+
+func (pc *pgConn) writeDataRow(columns []*ColumnInfo, oids []uint32, formats []int16, row []types.Datum) error {
+	buf := newPGBuffer()
+	buf.putUint16(uint16(len(row)))
+	for i, value := range row {
+		if value.IsNull() {
+			buf.putUint32(0xffffffff)
+			continue
+		}
+		var (
+			data []byte
+			err  error
+		)
+		if pgFormat(formats, i) == pgFormatBinary {
+			data, err = dumpPGBinaryValue(columns[i], oids[i], value)
+		} else {
+			data, err = dumpPGTextValue(columns[i], oids[i], value)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		buf.putUint32(uint32(len(data)))
+		buf.putBytes(data)
+	}
+	return errors.Trace(pc.writeMessage(pgMsgDataRow, buf.bytes()))
+}
+
+// handleParse handles the Parse message, it creates a prepared statement with the $n parameters rewritten to ?.
+func (pc *pgConn) handleParse(data []byte) error {
+	r := newPGReader(data)
+	name := r.getString()
+	query := r.getString()
+	oids := make([]uint32, r.getUint16())
+	for i := range oids {
+		oids[i] = r.getUint32()
+	}
+	if r.err != nil {
+		return errors.Trace(r.err)
+	}
+	if name != "" {
+		if _, ok := pc.stmts[name]; ok {
+			return newPGError(pgStateDuplicatePreparedStmt, "prepared statement \"%s\" already exists", name)
+		}
+	} else {
+		pc.closeStatement(name)
+	}
+
+	sql, paramOrder, paramCount := rewritePGParams(query)
+	stmt := &pgStatement{sql: sql, paramOrder: paramOrder}
+	if strings.TrimSpace(sql) != "" {
+		ps, columns, _, err := pc.ctx.Prepare(sql)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if ps.NumParams() != len(paramOrder) {
+			ps.Close()
+			return newPGError(pgStateProtocolViolation, "the statement has %d parameters, but %d $n placeholders",
+				ps.NumParams(), len(paramOrder))
+		}
+		stmt.stmt, stmt.columns = ps, columns
+	}
+	if len(oids) > paramCount {
+		paramCount = len(oids)
+	}
+	stmt.paramOIDs = make([]uint32, paramCount)
+	for i := range stmt.paramOIDs {
+		stmt.paramOIDs[i] = pgTypeText
+		if i < len(oids) && oids[i] != 0 {
+			stmt.paramOIDs[i] = oids[i]
+		}
+	}
+	pc.stmts[name] = stmt
+	return errors.Trace(pc.writeMessage(pgMsgParseComplete, nil))
+}
+
+// pgBeginRegexp matches the BEGIN statement with the transaction modes of PostgreSQL, such as "BEGIN READ WRITE" sent by lib/pq.
+var pgBeginRegexp = regexp.MustCompile(`(?i)^\s*(BEGIN|START\s+TRANSACTION)(\s+(TRANSACTION|WORK))?` +
+	`(\s*,?\s*(READ\s+WRITE|READ\s+ONLY|(NOT\s+)?DEFERRABLE|ISOLATION\s+LEVEL\s+(SERIALIZABLE|REPEATABLE\s+READ|READ\s+(UN)?COMMITTED)))+\s*;?\s*$`)
+
+// rewritePGBegin rewrites the BEGIN statement with the transaction modes to BEGIN, the modes are ignored
+// as the transactions always have the snapshot isolation.
+func rewritePGBegin(sql string) string {
+	if pgBeginRegexp.MatchString(sql) {
+		return "BEGIN"
+	}
+	return sql
+}
+
+// rewritePGParams rewrites the $n parameters out of the quoted strings and identifiers to ?, it returns
+// the rewritten statement, the 0-based n of the placeholders, and the max n.
+func rewritePGParams(query string) (string, []int, int) {
+	var (
+		buf        = make([]byte, 0, len(query))
+		paramOrder []int
+		paramCount int
+		quote      byte
+	)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' && i+1 < len(query) {
+				buf = append(buf, c)
+				i++
+				c = query[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '$' && i+1 < len(query) && query[i+1] >= '1' && query[i+1] <= '9' && !isIdentChar(query, i-1):
+			j := i + 1
+			n := 0
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				n = n*10 + int(query[j]-'0')
+				j++
+			}
+			paramOrder = append(paramOrder, n-1)
+			if n > paramCount {
+				paramCount = n
+			}
+			buf = append(buf, '?')
+			i = j - 1
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return string(buf), paramOrder, paramCount
+}
+
+func isIdentChar(s string, i int) bool {
+	if i < 0 {
+		return false
+	}
+	c := s[i]
+	return c == '_' || c == '$' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// handleBind handles the Bind message, it creates a portal of the prepared statement with the parameters.
+func (pc *pgConn) handleBind(data []byte) error {
+	r := newPGReader(data)
+	portalName := r.getString()
+	stmtName := r.getString()
+	paramFormats := make([]int16, r.getUint16())
+	for i := range paramFormats {
+		paramFormats[i] = int16(r.getUint16())
+	}
+	params := make([][]byte, r.getUint16())
+	for i := range params {
+		params[i] = r.getLengthPrefixedBytes()
+	}
+	formats := make([]int16, r.getUint16())
+	for i := range formats {
+		formats[i] = int16(r.getUint16())
+	}
+	if r.err != nil {
+		return errors.Trace(r.err)
+	}
+
+	stmt, ok := pc.stmts[stmtName]
+	if !ok {
+		return newPGError(pgStateInvalidStatementName, "prepared statement \"%s\" does not exist", stmtName)
+	}
+	if len(params) != len(stmt.paramOIDs) {
+		return newPGError(pgStateProtocolViolation, "bind message supplies %d parameters, but prepared statement \"%s\" requires %d",
+			len(params), stmtName, len(stmt.paramOIDs))
+	}
+	values := make([]interface{}, len(params))
+	for i, param := range params {
+		if param == nil {
+			continue
+		}
+		var err error
+		if pgFormat(paramFormats, i) == pgFormatBinary {
+			values[i], err = parsePGBinaryParam(stmt.paramOIDs[i], param)
+		} else {
+			values[i], err = parsePGTextParam(stmt.paramOIDs[i], param)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	args := make([]interface{}, len(stmt.paramOrder))
+	for i, n := range stmt.paramOrder {
+		args[i] = values[n]
+	}
+
+	if portalName != "" {
+		if _, ok = pc.portals[portalName]; ok {
+			return newPGError(pgStateInvalidCursorName, "portal \"%s\" already exists", portalName)
+		}
+	} else {
+		pc.closePortal(portalName)
+	}
+	pc.portals[portalName] = &pgPortal{stmt: stmt, args: args, formats: formats}
+	return errors.Trace(pc.writeMessage(pgMsgBindComplete, nil))
+}
+
+// parsePGTextParam decodes the parameter sent in the text format, the values are converted by the
+// statement as the strings of the MySQL protocol, except bytea which is sent in hex.
+func parsePGTextParam(oid uint32, data []byte) (interface{}, error) {
+	if oid == pgTypeBytea && len(data) >= 2 && data[0] == '\\' && data[1] == 'x' {
+		value := make([]byte, hex.DecodedLen(len(data)-2))
+		if _, err := hex.Decode(value, data[2:]); err != nil {
+			return nil, errors.Trace(errPGMalformedMessage)
+		}
+		return value, nil
+	}
+	return string(data), nil
+}
+
+// handleDescribe handles the Describe message of a prepared statement or a portal.
+func (pc *pgConn) handleDescribe(data []byte) error {
+	r := newPGReader(data)
+	typ := r.getByte()
+	name := r.getString()
+	if r.err != nil {
+		return errors.Trace(r.err)
+	}
+	switch typ {
+	case 'S':
+		stmt, ok := pc.stmts[name]
+		if !ok {
+			return newPGError(pgStateInvalidStatementName, "prepared statement \"%s\" does not exist", name)
+		}
+		buf := newPGBuffer()
+		buf.putUint16(uint16(len(stmt.paramOIDs)))
+		for _, oid := range stmt.paramOIDs {
+			buf.putUint32(oid)
+		}
+		if err := pc.writeMessage(pgMsgParameterDescription, buf.bytes()); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(pc.writeColumnsDescription(stmt.columns, nil))
+	case 'P':
+		p, ok := pc.portals[name]
+		if !ok {
+			return newPGError(pgStateInvalidCursorName, "portal \"%s\" does not exist", name)
+		}
+		return errors.Trace(pc.writeColumnsDescription(p.stmt.columns, p.formats))
+	default:
+		return newPGError(pgStateProtocolViolation, "invalid DESCRIBE message subtype %d", typ)
+	}
+}
+
+// writeColumnsDescription writes RowDescription, or NoData if the statement has no result set.
+func (pc *pgConn) writeColumnsDescription(columns []*ColumnInfo, formats []int16) error {
+	if len(columns) == 0 {
+		return errors.Trace(pc.writeMessage(pgMsgNoData, nil))
+	}
+	return errors.Trace(pc.writeRowDescription(columns, formats))
+}
+
+// handleExecute handles the Execute message, the portal is executed at the first time and
+// the rows are fetched at most the max rows each time.
+func (pc *pgConn) handleExecute(data []byte) error {
+	r := newPGReader(data)
+	name := r.getString()
+	maxRows := int32(r.getUint32())
+	if r.err != nil {
+		return errors.Trace(r.err)
+	}
+	p, ok := pc.portals[name]
+	if !ok {
+		return newPGError(pgStateInvalidCursorName, "portal \"%s\" does not exist", name)
+	}
+	if p.stmt.stmt == nil {
+		return errors.Trace(pc.writeMessage(pgMsgEmptyQueryResponse, nil))
+	}
+	if p.executed {
+		if p.rs == nil {
+			// The portal has been completed.
+			return errors.Trace(pc.writeCommandComplete("SELECT 0"))
+		}
+		return errors.Trace(pc.writeRows(p, int(maxRows)))
+	}
+
+	p.executed = true
+	rs, err := p.stmt.stmt.Execute(p.args...)
+	if err != nil {
+		executeErrorCounter.WithLabelValues(executeErrorToLabel(err)).Inc()
+		return errors.Trace(err)
+	}
+	if err = pc.checkLoadData(); err != nil {
+		return errors.Trace(err)
+	}
+	if rs == nil {
+		return errors.Trace(pc.writeCommandComplete(pgCommandTag(p.stmt.sql, pc.ctx.AffectedRows())))
+	}
+	p.rs = rs
+	return errors.Trace(pc.writeRows(p, int(maxRows)))
+}
+
+// handleClose handles the Close message, it's not an error to close a nonexistent statement or portal.
+func (pc *pgConn) handleClose(data []byte) error {
+	r := newPGReader(data)
+	typ := r.getByte()
+	name := r.getString()
+	if r.err != nil {
+		return errors.Trace(r.err)
+	}
+	switch typ {
+	case 'S':
+		pc.closeStatement(name)
+	case 'P':
+		pc.closePortal(name)
+	default:
+		return newPGError(pgStateProtocolViolation, "invalid CLOSE message subtype %d", typ)
+	}
+	return errors.Trace(pc.writeMessage(pgMsgCloseComplete, nil))
+}
+
+// handleSync handles the Sync message, the portals are closed at the end of the transaction.
+func (pc *pgConn) handleSync() error {
+	pc.skipTillSync = false
+	if pc.ctx.Status()&mysql.ServerStatusInTrans == 0 {
+		for name := range pc.portals {
+			pc.closePortal(name)
+		}
+	}
+	return errors.Trace(pc.writeReadyForQuery())
+}
+
+// closeStatement closes the prepared statement and the portals created from it.
+func (pc *pgConn) closeStatement(name string) {
+	stmt, ok := pc.stmts[name]
+	if !ok {
+		return
+	}
+	for portalName, p := range pc.portals {
+		if p.stmt == stmt {
+			pc.closePortal(portalName)
+		}
+	}
+	if stmt.stmt != nil {
+		stmt.stmt.Close()
+	}
+	delete(pc.stmts, name)
+}
+
+func (pc *pgConn) closePortal(name string) {
+	if p, ok := pc.portals[name]; ok {
+		p.close()
+		delete(pc.portals, name)
+	}
+}
+
+func (p *pgPortal) close() {
+	if p.rs != nil {
+		p.rs.Close()
+		p.rs = nil
+	}
+	p.next = nil
+}
+
+// pgBuffer builds the payload of a message.
+type pgBuffer struct {
+	data []byte
+}
+
+func newPGBuffer() *pgBuffer {
+	return &pgBuffer{data: make([]byte, 0, 64)}
+}
+
+func (b *pgBuffer) putByte(c byte) {
+	b.data = append(b.data, c)
+}
+
+func (b *pgBuffer) putUint16(n uint16) {
+	b.data = append(b.data, byte(n>>8), byte(n))
+}
+
+func (b *pgBuffer) putUint32(n uint32) {
+	b.data = append(b.data, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// putString puts the null-terminated string.
+func (b *pgBuffer) putString(s string) {
+	b.data = append(b.data, s...)
+	b.data = append(b.data, 0)
+}
+
+func (b *pgBuffer) putBytes(data []byte) {
+	b.data = append(b.data, data...)
+}
+
+func (b *pgBuffer) bytes() []byte {
+	return b.data
+}
+
+// pgReader reads the fields of a message, err is set if the message is shorter than expected.
+type pgReader struct {
+	data []byte
+	err  error
+}
+
+func newPGReader(data []byte) *pgReader {
+	return &pgReader{data: data}
+}
+
+func (r *pgReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data) {
+		r.err = errPGMalformedMessage
+		return nil
+	}
+	data := r.data[:n]
+	r.data = r.data[n:]
+	return data
+}
+
+func (r *pgReader) getByte() byte {
+	if data := r.next(1); data != nil {
+		return data[0]
+	}
+	return 0
+}
+
+func (r *pgReader) getUint16() uint16 {
+	if data := r.next(2); data != nil {
+		return binary.BigEndian.Uint16(data)
+	}
+	return 0
+}
+
+func (r *pgReader) getUint32() uint32 {
+	if data := r.next(4); data != nil {
+		return binary.BigEndian.Uint32(data)
+	}
+	return 0
+}
+
+// getString gets the null-terminated string.
+func (r *pgReader) getString() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.data, 0)
+	if i < 0 {
+		r.err = errPGMalformedMessage
+		return ""
+	}
+	s := string(r.data[:i])
+	r.data = r.data[i+1:]
+	return s
+}
+
+// getLengthPrefixedBytes gets the bytes prefixed with the int32 length, -1 means NULL and nil is returned.
+func (r *pgReader) getLengthPrefixedBytes() []byte {
+	length := int32(r.getUint32())
+	if r.err != nil || length < 0 {
+		return nil
+	}
+	data := r.next(int(length))
+	if data == nil {
+		return nil
+	}
+	// An empty value is not NULL.
+	return append(make([]byte, 0, len(data)), data...)
+}
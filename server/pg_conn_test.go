@@ -0,0 +1,190 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/base64"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/types"
+)
+
+type PGConnTestSuite struct{}
+
+var _ = Suite(PGConnTestSuite{})
+
+func (ts PGConnTestSuite) TestRewritePGParams(c *C) {
+	tests := []struct {
+		query      string
+		sql        string
+		paramOrder []int
+		paramCount int
+	}{
+		{"select 1", "select 1", nil, 0},
+		{"select $1, $2", "select ?, ?", []int{0, 1}, 2},
+		{"select $2 + $1, $2", "select ? + ?, ?", []int{1, 0, 1}, 2},
+		{"select $10, $3", "select ?, ?", []int{9, 2}, 10},
+		{"select '$1', \"$2\", `$3`, $4", "select '$1', \"$2\", `$3`, ?", []int{3}, 4},
+		{`select 'it\'s $1', $1`, `select 'it\'s $1', ?`, []int{0}, 1},
+		{"select a$1, $$1 from t", "select a$1, $$1 from t", nil, 0},
+	}
+	for _, t := range tests {
+		sql, paramOrder, paramCount := rewritePGParams(t.query)
+		c.Assert(sql, Equals, t.sql, Commentf("%s", t.query))
+		c.Assert(paramOrder, DeepEquals, t.paramOrder, Commentf("%s", t.query))
+		c.Assert(paramCount, Equals, t.paramCount, Commentf("%s", t.query))
+	}
+}
+
+func (ts PGConnTestSuite) TestRewritePGBegin(c *C) {
+	c.Assert(rewritePGBegin("BEGIN READ WRITE"), Equals, "BEGIN")
+	c.Assert(rewritePGBegin("begin transaction isolation level repeatable read, read only;"), Equals, "BEGIN")
+	c.Assert(rewritePGBegin("START TRANSACTION NOT DEFERRABLE"), Equals, "BEGIN")
+	c.Assert(rewritePGBegin("BEGIN"), Equals, "BEGIN")
+	c.Assert(rewritePGBegin("begin; select 1"), Equals, "begin; select 1")
+}
+
+func (ts PGConnTestSuite) TestPGCommandTag(c *C) {
+	c.Assert(pgCommandTag("insert into t values (1), (2)", 2), Equals, "INSERT 0 2")
+	c.Assert(pgCommandTag("UPDATE t SET a = 1", 3), Equals, "UPDATE 3")
+	c.Assert(pgCommandTag("delete from t", 0), Equals, "DELETE 0")
+	c.Assert(pgCommandTag("create table t (a int)", 0), Equals, "CREATE TABLE")
+	c.Assert(pgCommandTag("drop table t;", 0), Equals, "DROP TABLE")
+	c.Assert(pgCommandTag("start transaction", 0), Equals, "BEGIN")
+	c.Assert(pgCommandTag("commit", 0), Equals, "COMMIT")
+}
+
+func (ts PGConnTestSuite) TestPGTypeOID(c *C) {
+	tests := []struct {
+		column *ColumnInfo
+		oid    uint32
+	}{
+		{&ColumnInfo{Type: mysql.TypeTiny}, pgTypeInt2},
+		{&ColumnInfo{Type: mysql.TypeLong}, pgTypeInt4},
+		{&ColumnInfo{Type: mysql.TypeLong, Flag: mysql.UnsignedFlag}, pgTypeInt8},
+		{&ColumnInfo{Type: mysql.TypeLonglong}, pgTypeInt8},
+		{&ColumnInfo{Type: mysql.TypeLonglong, Flag: mysql.UnsignedFlag}, pgTypeNumeric},
+		{&ColumnInfo{Type: mysql.TypeDouble}, pgTypeFloat8},
+		{&ColumnInfo{Type: mysql.TypeNewDecimal}, pgTypeNumeric},
+		{&ColumnInfo{Type: mysql.TypeDate}, pgTypeDate},
+		{&ColumnInfo{Type: mysql.TypeDatetime}, pgTypeTimestamp},
+		{&ColumnInfo{Type: mysql.TypeDuration}, pgTypeTime},
+		{&ColumnInfo{Type: mysql.TypeVarString, Charset: mysql.DefaultCollationID}, pgTypeVarchar},
+		{&ColumnInfo{Type: mysql.TypeBlob, Charset: mysql.DefaultCollationID}, pgTypeText},
+		{&ColumnInfo{Type: mysql.TypeBlob, Charset: mysql.BinaryCollationID}, pgTypeBytea},
+		{&ColumnInfo{Type: mysql.TypeEnum}, pgTypeText},
+	}
+	for _, t := range tests {
+		c.Assert(pgTypeOID(t.column), Equals, t.oid, Commentf("%v", t.column))
+	}
+}
+
+func (ts PGConnTestSuite) TestDumpPGValue(c *C) {
+	column := &ColumnInfo{Type: mysql.TypeLong}
+	data, err := dumpPGBinaryValue(column, pgTypeInt4, types.NewIntDatum(-2))
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, []byte{0xff, 0xff, 0xff, 0xfe})
+	data, err = dumpPGBinaryValue(column, pgTypeInt2, types.NewIntDatum(258))
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, []byte{1, 2})
+
+	column = &ColumnInfo{Type: mysql.TypeDatetime}
+	t, err := types.ParseDatetime("2000-01-02 00:00:01")
+	c.Assert(err, IsNil)
+	data, err = dumpPGBinaryValue(column, pgTypeTimestamp, types.NewDatum(t))
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, []byte{0, 0, 0, 0x14, 0x1d, 0xe6, 0xa2, 0x40})
+	column = &ColumnInfo{Type: mysql.TypeDate}
+	t, err = types.ParseDate("1999-12-31")
+	c.Assert(err, IsNil)
+	data, err = dumpPGBinaryValue(column, pgTypeDate, types.NewDatum(t))
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, []byte{0xff, 0xff, 0xff, 0xff})
+
+	column = &ColumnInfo{Type: mysql.TypeBlob, Charset: mysql.BinaryCollationID}
+	data, err = dumpPGTextValue(column, pgTypeBytea, types.NewBytesDatum([]byte{0, 0xab}))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `\x00ab`)
+}
+
+func (ts PGConnTestSuite) TestDumpPGNumeric(c *C) {
+	tests := []struct {
+		s    string
+		data []byte
+	}{
+		// ndigits, weight, sign, dscale and the base 10000 digits.
+		{"0", []byte{0, 0, 0, 0, 0, 0, 0, 0}},
+		{"0.00", []byte{0, 0, 0, 0, 0, 0, 0, 2}},
+		{"12345.678", []byte{0, 3, 0, 1, 0, 0, 0, 3, 0, 1, 0x09, 0x29, 0x1a, 0x7c}},
+		{"-0.0001", []byte{0, 1, 0xff, 0xff, 0x40, 0, 0, 4, 0, 1}},
+		{"100000000", []byte{0, 1, 0, 2, 0, 0, 0, 0, 0, 1}},
+	}
+	for _, t := range tests {
+		data, err := dumpPGNumeric(t.s)
+		c.Assert(err, IsNil)
+		c.Assert(data, DeepEquals, t.data, Commentf("%s", t.s))
+	}
+}
+
+func (ts PGConnTestSuite) TestPGError(c *C) {
+	state, msg := toPGError(kv.ErrKeyExists.Gen("Duplicate entry '1' for key 'PRIMARY'"))
+	c.Assert(state, Equals, "23505")
+	c.Assert(msg, Equals, "Duplicate entry '1' for key 'PRIMARY'")
+	state, _ = toPGError(mysql.NewErr(mysql.ErrBadDB, "db"))
+	c.Assert(state, Equals, "3D000")
+	state, _ = toPGError(mysql.NewErr(mysql.ErrUnknown))
+	c.Assert(state, Equals, pgStateInternalError)
+	state, msg = toPGError(newPGError(pgStateInvalidCursorName, "portal \"%s\" does not exist", "p"))
+	c.Assert(state, Equals, pgStateInvalidCursorName)
+	c.Assert(msg, Equals, "portal \"p\" does not exist")
+}
+
+func (ts PGConnTestSuite) TestScram(c *C) {
+	// The example of SCRAM-SHA-256 in RFC 7677.
+	gs2Header, clientFirstBare, clientNonce, err := parseScramClientFirst("n,,n=user,r=rOprNGfwEbeRWgbNEkqO")
+	c.Assert(err, IsNil)
+	c.Assert(gs2Header, Equals, "n,,")
+	c.Assert(clientFirstBare, Equals, "n=user,r=rOprNGfwEbeRWgbNEkqO")
+	c.Assert(clientNonce, Equals, "rOprNGfwEbeRWgbNEkqO")
+	nonce := "rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+	serverFirst := "r=" + nonce + ",s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+	clientFinal := "c=biws,r=" + nonce + ",p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+	withoutProof, proof, err := parseScramClientFinal(clientFinal, gs2Header, nonce)
+	c.Assert(err, IsNil)
+
+	salt, err := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	c.Assert(err, IsNil)
+	_, _, storedKey, serverKey, err := util.DecodePGScramPassword(util.EncodePGScramPassword("pencil", salt, 4096))
+	c.Assert(err, IsNil)
+	authMessage := []byte(clientFirstBare + "," + serverFirst + "," + withoutProof)
+	serverSignature, ok := verifyScramProof(storedKey, serverKey, authMessage, proof)
+	c.Assert(ok, IsTrue)
+	c.Assert(base64.StdEncoding.EncodeToString(serverSignature), Equals, "6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=")
+
+	_, _, storedKey, serverKey, err = util.DecodePGScramPassword(util.EncodePGScramPassword("pencil2", salt, 4096))
+	c.Assert(err, IsNil)
+	_, ok = verifyScramProof(storedKey, serverKey, authMessage, proof)
+	c.Assert(ok, IsFalse)
+
+	// The nonce and the channel binding must match the previous messages.
+	_, _, err = parseScramClientFinal(clientFinal, gs2Header, clientNonce)
+	c.Assert(err, NotNil)
+	_, _, err = parseScramClientFinal(clientFinal, "y,,", nonce)
+	c.Assert(err, NotNil)
+	_, _, _, err = parseScramClientFirst("p=tls-server-end-point,,n=user,r=abc")
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,368 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The type OIDs of PostgreSQL, see src/include/catalog/pg_type.h of PostgreSQL.
+const (
+	pgTypeBool      uint32 = 16
+	pgTypeBytea     uint32 = 17
+	pgTypeInt8      uint32 = 20
+	pgTypeInt2      uint32 = 21
+	pgTypeInt4      uint32 = 23
+	pgTypeText      uint32 = 25
+	pgTypeFloat4    uint32 = 700
+	pgTypeFloat8    uint32 = 701
+	pgTypeVarchar   uint32 = 1043
+	pgTypeDate      uint32 = 1082
+	pgTypeTime      uint32 = 1083
+	pgTypeTimestamp uint32 = 1114
+	pgTypeNumeric   uint32 = 1700
+)
+
+// The format codes of the values.
+const (
+	pgFormatText   int16 = 0
+	pgFormatBinary int16 = 1
+)
+
+// pgTypeOID maps the field type of a column to the type OID of PostgreSQL.
+func pgTypeOID(column *ColumnInfo) uint32 {
+	unsigned := column.Flag&mysql.UnsignedFlag > 0
+	switch column.Type {
+	case mysql.TypeTiny, mysql.TypeYear:
+		return pgTypeInt2
+	case mysql.TypeShort:
+		if unsigned {
+			return pgTypeInt4
+		}
+		return pgTypeInt2
+	case mysql.TypeInt24:
+		return pgTypeInt4
+	case mysql.TypeLong:
+		if unsigned {
+			return pgTypeInt8
+		}
+		return pgTypeInt4
+	case mysql.TypeLonglong:
+		if unsigned {
+			return pgTypeNumeric
+		}
+		return pgTypeInt8
+	case mysql.TypeFloat:
+		return pgTypeFloat4
+	case mysql.TypeDouble:
+		return pgTypeFloat8
+	case mysql.TypeNewDecimal, mysql.TypeDecimal:
+		return pgTypeNumeric
+	case mysql.TypeDate, mysql.TypeNewDate:
+		return pgTypeDate
+	case mysql.TypeDatetime, mysql.TypeTimestamp:
+		return pgTypeTimestamp
+	case mysql.TypeDuration:
+		return pgTypeTime
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString, mysql.TypeTinyBlob,
+		mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		if column.Charset == mysql.BinaryCollationID {
+			return pgTypeBytea
+		}
+		if column.Type == mysql.TypeVarchar || column.Type == mysql.TypeVarString {
+			return pgTypeVarchar
+		}
+		return pgTypeText
+	default:
+		return pgTypeText
+	}
+}
+
+// pgTypeSize returns the typlen of the type, it's -1 for the types of variable length.
+func pgTypeSize(oid uint32) int16 {
+	switch oid {
+	case pgTypeBool:
+		return 1
+	case pgTypeInt2:
+		return 2
+	case pgTypeInt4, pgTypeFloat4, pgTypeDate:
+		return 4
+	case pgTypeInt8, pgTypeFloat8, pgTypeTime, pgTypeTimestamp:
+		return 8
+	default:
+		return -1
+	}
+}
+
+// dumpPGTextValue encodes the value in the text format of the type.
+func dumpPGTextValue(column *ColumnInfo, oid uint32, value types.Datum) ([]byte, error) {
+	data, err := dumpTextValue(column.Type, value)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if oid == pgTypeBytea {
+		buf := make([]byte, 2+hex.EncodedLen(len(data)))
+		buf[0], buf[1] = '\\', 'x'
+		hex.Encode(buf[2:], data)
+		return buf, nil
+	}
+	return data, nil
+}
+
+// pgEpoch is the epoch of the binary format of date and timestamp.
+var pgEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// dumpPGBinaryValue encodes the value in the binary format of the type.
+func dumpPGBinaryValue(column *ColumnInfo, oid uint32, value types.Datum) ([]byte, error) {
+	switch oid {
+	case pgTypeInt2, pgTypeInt4, pgTypeInt8:
+		var v int64
+		switch value.Kind() {
+		case types.KindInt64:
+			v = value.GetInt64()
+		case types.KindUint64:
+			v = int64(value.GetUint64())
+		default:
+			return nil, errInvalidType.Gen("invalid type %d for %d", value.Kind(), oid)
+		}
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, uint64(v))
+		return data[8-pgTypeSize(oid):], nil
+	case pgTypeFloat4:
+		data := make([]byte, 4)
+		binary.BigEndian.PutUint32(data, math.Float32bits(float32(value.GetFloat64())))
+		return data, nil
+	case pgTypeFloat8:
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, math.Float64bits(value.GetFloat64()))
+		return data, nil
+	case pgTypeDate, pgTypeTimestamp:
+		if value.Kind() != types.KindMysqlTime {
+			return nil, errInvalidType.Gen("invalid type %d for %d", value.Kind(), oid)
+		}
+		t := value.GetMysqlTime().Time
+		year, month, day := t.Year(), t.Month(), t.Day()
+		if year == 0 && month == 0 && day == 0 {
+			year, month, day = 1, 1, 1
+		}
+		goTime := time.Date(year, time.Month(month), day, t.Hour(), t.Minute(), t.Second(), t.Microsecond()*1000, time.UTC)
+		if oid == pgTypeDate {
+			data := make([]byte, 4)
+			days := int64(math.Floor(goTime.Sub(pgEpoch).Hours() / 24))
+			binary.BigEndian.PutUint32(data, uint32(int32(days)))
+			return data, nil
+		}
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, uint64(pgMicroseconds(goTime)))
+		return data, nil
+	case pgTypeTime:
+		if value.Kind() != types.KindMysqlDuration {
+			return nil, errInvalidType.Gen("invalid type %d for %d", value.Kind(), oid)
+		}
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, uint64(value.GetMysqlDuration().Duration/time.Microsecond))
+		return data, nil
+	case pgTypeNumeric:
+		text, err := dumpTextValue(column.Type, value)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return dumpPGNumeric(string(text))
+	default:
+		return dumpTextValue(column.Type, value)
+	}
+}
+
+// pgMicroseconds returns the microseconds since pgEpoch, time.Time.Sub overflows out of about 292 years.
+func pgMicroseconds(t time.Time) int64 {
+	return (t.Unix()-pgEpoch.Unix())*int64(time.Second/time.Microsecond) + int64(t.Nanosecond()/1000)
+}
+
+// dumpPGNumeric encodes the decimal string in the binary format of numeric, which is a sequence of
+// base 10000 digits with the weight of the first digit, the sign and the display scale.
+func dumpPGNumeric(s string) ([]byte, error) {
+	const (
+		numericPositive = 0x0000
+		numericNegative = 0x4000
+	)
+	sign := numericPositive
+	if strings.HasPrefix(s, "-") {
+		sign = numericNegative
+		s = s[1:]
+	}
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	dscale := len(fracPart)
+	// Align the digits to the decimal point in groups of 4.
+	if n := len(intPart) % 4; n > 0 {
+		intPart = strings.Repeat("0", 4-n) + intPart
+	}
+	if n := len(fracPart) % 4; n > 0 {
+		fracPart += strings.Repeat("0", 4-n)
+	}
+	all := intPart + fracPart
+	digits := make([]int16, 0, len(all)/4)
+	for i := 0; i < len(all); i += 4 {
+		d, err := strconv.ParseInt(all[i:i+4], 10, 16)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		digits = append(digits, int16(d))
+	}
+	weight := len(intPart)/4 - 1
+	for len(digits) > 0 && digits[0] == 0 {
+		digits = digits[1:]
+		weight--
+	}
+	for len(digits) > 0 && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	if len(digits) == 0 {
+		sign, weight = numericPositive, 0
+	}
+
+	data := make([]byte, 8+2*len(digits))
+	binary.BigEndian.PutUint16(data[0:], uint16(len(digits)))
+	binary.BigEndian.PutUint16(data[2:], uint16(int16(weight)))
+	binary.BigEndian.PutUint16(data[4:], uint16(sign))
+	binary.BigEndian.PutUint16(data[6:], uint16(dscale))
+	for i, d := range digits {
+		binary.BigEndian.PutUint16(data[8+2*i:], uint16(d))
+	}
+	return data, nil
+}
+
+// parsePGBinaryParam decodes the parameter sent in the binary format of the type.
+func parsePGBinaryParam(oid uint32, data []byte) (interface{}, error) {
+	switch oid {
+	case pgTypeBool:
+		if len(data) != 1 {
+			return nil, errors.Trace(mysql.ErrMalformPacket)
+		}
+		return int64(data[0]), nil
+	case pgTypeInt2:
+		if len(data) != 2 {
+			return nil, errors.Trace(mysql.ErrMalformPacket)
+		}
+		return int64(int16(binary.BigEndian.Uint16(data))), nil
+	case pgTypeInt4:
+		if len(data) != 4 {
+			return nil, errors.Trace(mysql.ErrMalformPacket)
+		}
+		return int64(int32(binary.BigEndian.Uint32(data))), nil
+	case pgTypeInt8:
+		if len(data) != 8 {
+			return nil, errors.Trace(mysql.ErrMalformPacket)
+		}
+		return int64(binary.BigEndian.Uint64(data)), nil
+	case pgTypeFloat4:
+		if len(data) != 4 {
+			return nil, errors.Trace(mysql.ErrMalformPacket)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	case pgTypeFloat8:
+		if len(data) != 8 {
+			return nil, errors.Trace(mysql.ErrMalformPacket)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case pgTypeBytea:
+		return data, nil
+	case pgTypeText, pgTypeVarchar:
+		return string(data), nil
+	default:
+		return nil, newPGError(pgStateFeatureNotSupported, "binary format of parameter type %d is not supported", oid)
+	}
+}
+
+// The SQLSTATE codes used by the PostgreSQL protocol server.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgStateProtocolViolation     = "08P01"
+	pgStateFeatureNotSupported   = "0A000"
+	pgStateInvalidPassword       = "28P01"
+	pgStateInvalidStatementName  = "26000"
+	pgStateInvalidCursorName     = "34000"
+	pgStateDuplicatePreparedStmt = "42P05"
+	pgStateInternalError         = "XX000"
+)
+
+// pgSQLStates maps the MySQL error codes to the SQLSTATE codes of PostgreSQL whose meanings are different
+// from the SQLSTATE of MySQL.
+var pgSQLStates = map[uint16]string{
+	mysql.ErrDupEntry:       "23505",
+	mysql.ErrNoSuchTable:    "42P01",
+	mysql.ErrBadField:       "42703",
+	mysql.ErrParse:          "42601",
+	mysql.ErrSyntax:         "42601",
+	mysql.ErrTableExists:    "42P07",
+	mysql.ErrDBCreateExists: "42P04",
+	mysql.ErrBadDB:          "3D000",
+	mysql.ErrAccessDenied:   pgStateInvalidPassword,
+	mysql.ErrBadNull:        "23502",
+	mysql.ErrDataTooLong:    "22001",
+	mysql.ErrDivisionByZero: "22012",
+	// too_many_connections and configuration_limit_exceeded.
+	mysql.ErrTooManyUserConnections: "53300",
+	mysql.ErrUserLimitReached:       "53400",
+}
+
+// pgError is an error of the PostgreSQL protocol which has no MySQL error code.
+type pgError struct {
+	state   string
+	message string
+}
+
+func newPGError(state string, format string, args ...interface{}) *pgError {
+	return &pgError{state: state, message: fmt.Sprintf(format, args...)}
+}
+
+func (e *pgError) Error() string {
+	return e.message
+}
+
+// toPGError converts the error to the SQLSTATE code and the message.
+func toPGError(e error) (string, string) {
+	originErr := errors.Cause(e)
+	if pe, ok := originErr.(*pgError); ok {
+		return pe.state, pe.message
+	}
+	var m *mysql.SQLError
+	if te, ok := originErr.(*terror.Error); ok {
+		m = te.ToSQLError()
+	} else if me, ok := originErr.(*mysql.SQLError); ok {
+		m = me
+	} else {
+		return pgStateInternalError, e.Error()
+	}
+	if state, ok := pgSQLStates[m.Code]; ok {
+		return state, m.Message
+	}
+	if m.State == "" || m.State == mysql.DefaultMySQLState {
+		return pgStateInternalError, m.Message
+	}
+	return m.State, m.Message
+}
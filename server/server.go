@@ -29,7 +29,11 @@
 package server
 
 import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"io/ioutil"
 	"math/rand"
 	"net"
 	"net/http"
@@ -42,7 +46,9 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/arena"
 	"github.com/pingcap/tidb/util/printer"
 	"github.com/prometheus/client_golang/prometheus"
@@ -59,6 +65,10 @@ var (
 	errInvalidType       = terror.ClassServer.New(codeInvalidType, "invalid type")
 	errNotAllowedCommand = terror.ClassServer.New(codeNotAllowedCommand,
 		"the used command is not allowed with this TiDB version")
+	errTooManyUserConnections = terror.ClassServer.New(codeTooManyUserConnections,
+		"User %-.64s already has more than 'max_user_connections' active connections")
+	errUserLimitReached = terror.ClassServer.New(codeUserLimitReached,
+		"User '%-.64s' has exceeded the '%s' resource (current value: %d)")
 )
 
 // Server is the MySQL protocol server
@@ -69,17 +79,72 @@ type Server struct {
 	rwlock            *sync.RWMutex
 	concurrentLimiter *TokenLimiter
 	clients           map[uint32]*clientConn
+	tlsConfig         *tls.Config
+	userResources     *userResources
+
+	// pgListener accepts the connections of the PostgreSQL protocol, it's nil if cfg.PGAddr is empty.
+	pgListener net.Listener
+	pgClients  map[uint32]*pgConn
+
+	// rsaKey is used by caching_sha2_password to exchange passwords over insecure connections.
+	rsaKeyOnce      sync.Once
+	rsaKey          *rsa.PrivateKey
+	rsaPublicKeyPEM []byte
+	rsaKeyErr       error
+
+	// draining is set to 1 when the server starts draining, drained is closed after all the connections are closed.
+	draining  int32
+	drainOnce sync.Once
+	drained   chan struct{}
 }
 
 // ConnectionCount gets current connection count.
 func (s *Server) ConnectionCount() int {
 	var cnt int
 	s.rwlock.RLock()
-	cnt = len(s.clients)
+	cnt = len(s.clients) + len(s.pgClients)
 	s.rwlock.RUnlock()
 	return cnt
 }
 
+// ShowProcessList implements the util.SessionManager interface.
+func (s *Server) ShowProcessList() []util.ProcessInfo {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	rs := make([]util.ProcessInfo, 0, len(s.clients)+len(s.pgClients))
+	for _, cc := range s.clients {
+		rs = append(rs, connProcessInfo(cc.ctx, cc.connectionID, cc.user, cc.conn, &cc.drain))
+	}
+	for _, pc := range s.pgClients {
+		rs = append(rs, connProcessInfo(pc.ctx, pc.connectionID, pc.user, pc.conn, &pc.drain))
+	}
+	return rs
+}
+
+// countConnStates adds the numbers of the connections in each state to counts.
+func (s *Server) countConnStates(counts map[string]int) {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	for _, cc := range s.clients {
+		counts[cc.drain.stateLabel()]++
+	}
+	for _, pc := range s.pgClients {
+		counts[pc.drain.stateLabel()]++
+	}
+}
+
+// connProcessInfo returns the process information of a connection, the connection is sleeping if it isn't dispatching a command.
+func connProcessInfo(ctx QueryCtx, connID uint32, user string, conn net.Conn, drain *drainState) util.ProcessInfo {
+	pi := ctx.ShowProcess()
+	pi.ID, pi.User, pi.Host = uint64(connID), user, conn.RemoteAddr().String()
+	if drain.dispatching() {
+		pi.State = "executing"
+	} else {
+		pi.Command, pi.State, pi.Info = "Sleep", "", ""
+	}
+	return pi
+}
+
 func (s *Server) getToken() *Token {
 	return s.concurrentLimiter.Get()
 }
@@ -112,6 +177,7 @@ func (s *Server) newConn(conn net.Conn) *clientConn {
 		collation:    mysql.DefaultCollationID,
 		alloc:        arena.NewAllocator(32 * 1024),
 	}
+	cc.unixConn, _ = conn.(*net.UnixConn)
 	log.Infof("[%d] new connection %s", cc.connectionID, conn.RemoteAddr().String())
 	cc.salt = randomBuf(20)
 	return cc
@@ -121,6 +187,51 @@ func (s *Server) skipAuth() bool {
 	return s.cfg.SkipAuth
 }
 
+// pgAuthMethod returns the authentication method of the PostgreSQL protocol server.
+func (s *Server) pgAuthMethod() string {
+	if s.cfg.PGAuthMethod == "" {
+		return util.PGAuthScramSha256
+	}
+	return s.cfg.PGAuthMethod
+}
+
+// capability returns the capability flags the server sends to clients in the initial handshake.
+func (s *Server) capability() uint32 {
+	if s.tlsConfig != nil {
+		return defaultCapability | mysql.ClientSSL
+	}
+	return defaultCapability
+}
+
+// loadTLSConfig loads the certificates in cfg, it returns nil if TLS is not configured.
+func loadTLSConfig(cfg *Config) (*tls.Config, error) {
+	if cfg.SSLCert == "" || cfg.SSLKey == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.SSLCert, cfg.SSLKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	if cfg.SSLCA != "" {
+		caCert, err := ioutil.ReadFile(cfg.SSLCA)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.Errorf("failed to load CA certificates from %s", cfg.SSLCA)
+		}
+		// Client certificates are optional, accounts created with REQUIRE X509 reject
+		// connections without a verified client certificate.
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
 const tokenLimit = 1000
 
 // NewServer creates a new Server.
@@ -131,11 +242,20 @@ func NewServer(cfg *Config, driver IDriver) (*Server, error) {
 		concurrentLimiter: NewTokenLimiter(tokenLimit),
 		rwlock:            &sync.RWMutex{},
 		clients:           make(map[uint32]*clientConn),
+		pgClients:         make(map[uint32]*pgConn),
+		userResources:     newUserResources(cfg.UserTokenLimit),
+		drained:           make(chan struct{}),
 	}
 
 	var err error
+	s.tlsConfig, err = loadTLSConfig(cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if s.tlsConfig != nil {
+		log.Infof("Secure connection is enabled")
+	}
 	if cfg.Socket != "" {
-		cfg.SkipAuth = true
 		s.listener, err = net.Listen("unix", cfg.Socket)
 	} else {
 		s.listener, err = net.Listen("tcp", s.cfg.Addr)
@@ -143,10 +263,25 @@ func NewServer(cfg *Config, driver IDriver) (*Server, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if cfg.PGAddr != "" {
+		switch s.pgAuthMethod() {
+		case util.PGAuthScramSha256, util.PGAuthMD5, util.PGAuthPassword:
+		default:
+			s.listener.Close()
+			return nil, errors.Errorf("unknown PostgreSQL authentication method %s", cfg.PGAuthMethod)
+		}
+		if s.pgListener, err = net.Listen("tcp", cfg.PGAddr); err != nil {
+			s.listener.Close()
+			return nil, errors.Trace(err)
+		}
+		log.Infof("Server run PostgreSQL Protocol Listen at [%s]", cfg.PGAddr)
+	}
 
 	// Init rand seed for randomBuf()
 	rand.Seed(time.Now().UTC().UnixNano())
 	log.Infof("Server run MySQL Protocol Listen at [%s]", s.cfg.Addr)
+	variable.RegisterStatistics(s)
+	connStateMetrics.addServer(s)
 	return s, nil
 }
 
@@ -157,12 +292,18 @@ func (s *Server) Run() error {
 	if s.cfg.ReportStatus {
 		s.startStatusHTTP()
 	}
+	if s.pgListener != nil {
+		go s.runPG(s.pgListener)
+	}
 
 	for {
 		conn, err := s.listener.Accept()
 		if err != nil {
 			if opErr, ok := err.(*net.OpError); ok {
 				if opErr.Err.Error() == "use of closed network connection" {
+					if s.isDraining() {
+						<-s.drained
+					}
 					return nil
 				}
 			}
@@ -183,6 +324,61 @@ func (s *Server) Close() {
 		s.listener.Close()
 		s.listener = nil
 	}
+	if s.pgListener != nil {
+		s.pgListener.Close()
+		s.pgListener = nil
+	}
+	variable.UnregisterStatistics(s)
+	// The draining server reports its connections until they are closed.
+	if !s.isDraining() {
+		connStateMetrics.removeServer(s)
+	}
+}
+
+// runPG accepts the connections of the PostgreSQL protocol until the listener is closed.
+func (s *Server) runPG(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if opErr, ok := err.(*net.OpError); ok {
+				if opErr.Err.Error() == "use of closed network connection" {
+					return
+				}
+			}
+			log.Errorf("accept PostgreSQL connection error %s", err.Error())
+			return
+		}
+
+		go s.onPGConn(conn)
+	}
+}
+
+// onPGConn runs in its own goroutine, handles the messages of the PostgreSQL protocol connection.
+func (s *Server) onPGConn(c net.Conn) {
+	conn := s.newPGConn(c)
+	defer func() {
+		log.Infof("[%d] close PostgreSQL connection", conn.connectionID)
+	}()
+
+	if err := conn.handshake(); err != nil {
+		log.Infof("PostgreSQL handshake error %s", errors.ErrorStack(err))
+		conn.conn.Close()
+		if conn.ctx != nil {
+			conn.ctx.Close()
+		}
+		return
+	}
+
+	s.rwlock.Lock()
+	if s.isDraining() {
+		s.rwlock.Unlock()
+		conn.Close()
+		return
+	}
+	s.pgClients[conn.connectionID] = conn
+	s.rwlock.Unlock()
+
+	conn.Run()
 }
 
 // onConn runs in its own goroutine, handles queries from this connection.
@@ -201,6 +397,11 @@ func (s *Server) onConn(c net.Conn) {
 	}
 
 	s.rwlock.Lock()
+	if s.isDraining() {
+		s.rwlock.Unlock()
+		conn.Close()
+		return
+	}
 	s.clients[conn.connectionID] = conn
 	connections := len(s.clients)
 	s.rwlock.Unlock()
@@ -216,22 +417,12 @@ const defaultStatusAddr = ":10080"
 func (s *Server) startStatusHTTP() {
 	once.Do(func() {
 		go func() {
-			http.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
-				w.Header().Set("Content-Type", "application/json")
-				s := status{
-					Connections: s.ConnectionCount(),
-					Version:     mysql.ServerVersion,
-					GitHash:     printer.TiDBGitHash,
-				}
-				js, err := json.Marshal(s)
-				if err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
-					log.Error("Encode json error", err)
-				} else {
-					w.Write(js)
-				}
-
-			})
+			http.HandleFunc("/status", s.handleStatus)
+			http.HandleFunc("/drain", s.handleDrain)
+			if d, ok := s.driver.(*TiDBDriver); ok {
+				h := &httpHandler{store: d.store}
+				h.register(http.DefaultServeMux)
+			}
 			// HTTP path for prometheus.
 			http.Handle("/metrics", prometheus.Handler())
 			addr := s.cfg.StatusAddr
@@ -247,11 +438,33 @@ func (s *Server) startStatusHTTP() {
 	})
 }
 
+// handleStatus reports the status of the server, the server is unhealthy when it's draining.
+func (s *Server) handleStatus(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	st := status{
+		Connections: s.ConnectionCount(),
+		Version:     mysql.ServerVersion,
+		GitHash:     printer.TiDBGitHash,
+		Draining:    s.isDraining(),
+	}
+	js, err := json.Marshal(st)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Error("Encode json error", err)
+		return
+	}
+	if st.Draining {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(js)
+}
+
 // TiDB status
 type status struct {
 	Connections int    `json:"connections"`
 	Version     string `json:"version"`
 	GitHash     string `json:"git_hash"`
+	Draining    bool   `json:"draining"`
 }
 
 // Server error codes.
@@ -261,12 +474,16 @@ const (
 	codeInvalidSequence   = 3
 	codeInvalidType       = 4
 
-	codeNotAllowedCommand = 1148
+	codeNotAllowedCommand      = 1148
+	codeTooManyUserConnections = 1203
+	codeUserLimitReached       = 1226
 )
 
 func init() {
 	serverMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeNotAllowedCommand: mysql.ErrNotAllowedCommand,
+		codeNotAllowedCommand:      mysql.ErrNotAllowedCommand,
+		codeTooManyUserConnections: mysql.ErrTooManyUserConnections,
+		codeUserLimitReached:       mysql.ErrUserLimitReached,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassServer] = serverMySQLErrCodes
 }
@@ -14,20 +14,40 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
 	"database/sql"
+	"encoding/binary"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/audit"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
 	tmysql "github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/generallog"
 	"github.com/pingcap/tidb/util/printer"
 )
 
@@ -474,6 +494,787 @@ func runTestAuth(c *C) {
 	db.Close()
 }
 
+func getSSLCipher(dbt *DBTest) string {
+	var name, value string
+	rows := dbt.mustQuery("SHOW STATUS LIKE 'Ssl_cipher'")
+	dbt.Check(rows.Next(), IsTrue)
+	err := rows.Scan(&name, &value)
+	dbt.Check(err, IsNil)
+	rows.Close()
+	return value
+}
+
+func runTestTLSConnection(c *C) {
+	tlsDsn := "root@tcp(localhost:4002)/test?strict=true&tls=skip-verify"
+	runTests(c, tlsDsn, func(dbt *DBTest) {
+		dbt.Check(getSSLCipher(dbt), Not(Equals), "")
+		dbt.mustExec(`CREATE USER 'tls_ssl'@'%' REQUIRE SSL;`)
+		dbt.mustExec(`CREATE USER 'tls_x509'@'%' REQUIRE X509;`)
+		dbt.mustExec(`CREATE USER 'tls_none'@'%' REQUIRE NONE;`)
+	})
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.Check(getSSLCipher(dbt), Equals, "")
+	})
+
+	// Accounts without the REQUIRE clause can connect with or without TLS.
+	runTests(c, "tls_none@tcp(localhost:4001)/test?strict=true", func(dbt *DBTest) {
+		dbt.mustExec(`USE mysql;`)
+	})
+	runTests(c, "tls_none@tcp(localhost:4002)/test?strict=true&tls=skip-verify", func(dbt *DBTest) {
+		dbt.mustExec(`USE mysql;`)
+	})
+
+	db, err := sql.Open("mysql", "tls_ssl@tcp(localhost:4001)/test?strict=true")
+	c.Assert(err, IsNil)
+	_, err = db.Query("USE mysql;")
+	c.Assert(err, NotNil, Commentf("REQUIRE SSL account without TLS should be failed"))
+	db.Close()
+	runTests(c, "tls_ssl@tcp(localhost:4002)/test?strict=true&tls=skip-verify", func(dbt *DBTest) {
+		dbt.mustExec(`USE mysql;`)
+	})
+
+	db, err = sql.Open("mysql", "tls_x509@tcp(localhost:4002)/test?strict=true&tls=skip-verify")
+	c.Assert(err, IsNil)
+	_, err = db.Query("USE mysql;")
+	c.Assert(err, NotNil, Commentf("REQUIRE X509 account without client certificate should be failed"))
+	db.Close()
+	runTests(c, "tls_x509@tcp(localhost:4002)/test?strict=true&tls=client-certificate", func(dbt *DBTest) {
+		dbt.mustExec(`USE mysql;`)
+	})
+
+	// Drop the REQUIRE clause of the account.
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`ALTER USER 'tls_ssl'@'%' REQUIRE NONE;`)
+	})
+	runTests(c, "tls_ssl@tcp(localhost:4001)/test?strict=true", func(dbt *DBTest) {
+		dbt.mustExec(`USE mysql;`)
+	})
+}
+
+// testAuthClient is a client which only does the handshake, it's used to test the authentication
+// plugins which are not supported by the MySQL driver.
+type testAuthClient struct {
+	*C
+	conn net.Conn
+	pkt  *packetIO
+	salt []byte
+	// capability is added to the capability of the handshake response.
+	capability uint32
+	zstdLevel  uint8
+}
+
+func newTestAuthClient(c *C, network, addr string) *testAuthClient {
+	conn, err := net.Dial(network, addr)
+	c.Assert(err, IsNil)
+	cli := &testAuthClient{C: c, conn: conn, pkt: newPacketIO(conn)}
+	data := cli.readPacket()
+	// Skip the protocol version, the server version and the connection ID.
+	pos := 1 + bytes.IndexByte(data[1:], 0) + 1 + 4
+	cli.salt = append(cli.salt, data[pos:pos+8]...)
+	// Skip the filler, the capability, the charset, the status and the reserved bytes.
+	pos += 8 + 1 + 2 + 1 + 2 + 2 + 1 + 10
+	cli.salt = append(cli.salt, data[pos:pos+12]...)
+	pos += 12 + 1
+	c.Assert(string(data[pos:len(data)-1]), Equals, tmysql.AuthNativePassword)
+	return cli
+}
+
+func (cli *testAuthClient) writeHandshakeResponse(user, plugin string, authData []byte) {
+	capability := tmysql.ClientProtocol41 | tmysql.ClientSecureConnection | tmysql.ClientPluginAuth | tmysql.ClientLongPassword | cli.capability
+	data := make([]byte, 4, 128)
+	data = append(data, dumpUint32(capability)...)
+	// max packet size, charset and the reserved bytes.
+	data = append(data, 0, 0, 0, 0, tmysql.DefaultCollationID)
+	data = append(data, make([]byte, 23)...)
+	data = append(data, user...)
+	data = append(data, 0, byte(len(authData)))
+	data = append(data, authData...)
+	data = append(data, plugin...)
+	data = append(data, 0)
+	if capability&tmysql.ClientZstdCompressionAlgorithm > 0 {
+		data = append(data, cli.zstdLevel)
+	}
+	cli.writePacket(data[4:])
+}
+
+func (cli *testAuthClient) writePacket(payload []byte) {
+	data := append(make([]byte, 4), payload...)
+	cli.Assert(cli.pkt.writePacket(data), IsNil)
+	cli.Assert(cli.pkt.flush(), IsNil)
+}
+
+func (cli *testAuthClient) readPacket() []byte {
+	data, err := cli.pkt.readPacket()
+	cli.Assert(err, IsNil)
+	return data
+}
+
+// writeCommand starts a new command with the payload.
+func (cli *testAuthClient) writeCommand(cmd byte, payload []byte) {
+	cli.pkt.sequence = 0
+	if cli.pkt.cio != nil {
+		cli.pkt.cio.sequence = 0
+	}
+	cli.writePacket(append([]byte{cmd}, payload...))
+}
+
+func (cli *testAuthClient) writeChangeUser(user, plugin string, authData []byte, dbName string) {
+	data := append([]byte(user), 0, byte(len(authData)))
+	data = append(data, authData...)
+	data = append(data, dbName...)
+	data = append(data, 0, tmysql.DefaultCollationID, 0)
+	data = append(data, plugin...)
+	data = append(data, 0)
+	cli.writeCommand(tmysql.ComChangeUser, data)
+}
+
+// exec executes the statement and returns the header of the response.
+func (cli *testAuthClient) exec(sql string) byte {
+	cli.writeCommand(tmysql.ComQuery, []byte(sql))
+	return cli.readPacket()[0]
+}
+
+// queryValue executes the query which returns a single value in text format, it returns "NULL" for the NULL value.
+func (cli *testAuthClient) queryValue(sql string) string {
+	cli.writeCommand(tmysql.ComQuery, []byte(sql))
+	return cli.readValue(sql)
+}
+
+// readValue reads the result of the query sent by queryValue.
+func (cli *testAuthClient) readValue(sql string) string {
+	data := cli.readPacket()
+	cli.Assert(data[0], Equals, byte(1), Commentf("query %s returns %v", sql, data))
+	cli.readPacket()
+	cli.Assert(cli.readPacket()[0], Equals, tmysql.EOFHeader)
+	data = cli.readPacket()
+	value := "NULL"
+	if data[0] != 0xfb {
+		b, _, _, err := parseLengthEncodedBytes(data)
+		cli.Assert(err, IsNil)
+		value = string(b)
+	}
+	cli.Assert(cli.readPacket()[0], Equals, tmysql.EOFHeader)
+	return value
+}
+
+// prepare prepares the statement which has no parameters and returns the statement ID.
+func (cli *testAuthClient) prepare(sql string) uint32 {
+	cli.writeCommand(tmysql.ComStmtPrepare, []byte(sql))
+	data := cli.readPacket()
+	cli.Assert(data[0], Equals, tmysql.OKHeader)
+	stmtID := binary.LittleEndian.Uint32(data[1:5])
+	numColumns := binary.LittleEndian.Uint16(data[5:7])
+	for i := uint16(0); i < numColumns; i++ {
+		cli.readPacket()
+	}
+	if numColumns > 0 {
+		cli.Assert(cli.readPacket()[0], Equals, tmysql.EOFHeader)
+	}
+	return stmtID
+}
+
+// readEOFStatus reads the EOF packet and returns the server status.
+func (cli *testAuthClient) readEOFStatus() uint16 {
+	data := cli.readPacket()
+	cli.Assert(data[0], Equals, tmysql.EOFHeader)
+	return binary.LittleEndian.Uint16(data[3:5])
+}
+
+func (cli *testAuthClient) close() {
+	cli.conn.Close()
+}
+
+func runTestAuthPlugins(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE USER 'sha2'@'%' IDENTIFIED WITH caching_sha2_password BY '123';`)
+		dbt.mustExec(`CREATE USER 'sha2_empty'@'%' IDENTIFIED WITH 'caching_sha2_password';`)
+		dbt.mustExec(`CREATE USER 'native'@'%' IDENTIFIED WITH mysql_native_password BY '123';`)
+		_, err := dbt.db.Exec(`CREATE USER 'unknown_plugin'@'%' IDENTIFIED WITH unknown_plugin;`)
+		checkErrorCode(c, err, tmysql.ErrPluginIsNotLoaded)
+	})
+
+	// The MySQL driver doesn't support caching_sha2_password.
+	db, err := sql.Open("mysql", "sha2:123@tcp(localhost:4001)/test?strict=true")
+	c.Assert(err, IsNil)
+	_, err = db.Query("USE mysql;")
+	c.Assert(err, NotNil)
+	db.Close()
+
+	// The first login does the full authentication with the RSA public key.
+	cli := newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("sha2", tmysql.AuthCachingSha2Password, util.CalcSha2Password(cli.salt, []byte("123")))
+	c.Assert(cli.readPacket(), DeepEquals, []byte{tmysql.AuthMoreDataHeader, cachingSha2PerformFullAuth})
+	cli.writePacket([]byte{cachingSha2RequestPublicKey})
+	data := cli.readPacket()
+	c.Assert(data[0], Equals, tmysql.AuthMoreDataHeader)
+	block, _ := pem.Decode(data[1:])
+	c.Assert(block, NotNil)
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	c.Assert(err, IsNil)
+	password := []byte("123\x00")
+	for i := range password {
+		password[i] ^= cli.salt[i%len(cli.salt)]
+	}
+	encrypted, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, publicKey.(*rsa.PublicKey), password, nil)
+	c.Assert(err, IsNil)
+	cli.writePacket(encrypted)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	cli.close()
+
+	// The password is cached, so the fast authentication succeeds.
+	cli = newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("sha2", tmysql.AuthCachingSha2Password, util.CalcSha2Password(cli.salt, []byte("123")))
+	c.Assert(cli.readPacket(), DeepEquals, []byte{tmysql.AuthMoreDataHeader, cachingSha2FastAuthSuccess})
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	cli.close()
+
+	// A wrong password needs the full authentication, and the password can't be sent in clear text over TCP.
+	cli = newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("sha2", tmysql.AuthCachingSha2Password, util.CalcSha2Password(cli.salt, []byte("456")))
+	c.Assert(cli.readPacket(), DeepEquals, []byte{tmysql.AuthMoreDataHeader, cachingSha2PerformFullAuth})
+	cli.writePacket([]byte("123\x00"))
+	c.Assert(cli.readPacket()[0], Equals, tmysql.ErrHeader)
+	cli.close()
+
+	// Empty password.
+	cli = newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("sha2_empty", tmysql.AuthCachingSha2Password, nil)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	cli.close()
+
+	// The server asks the client to switch to the plugin of the user.
+	cli = newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("native", tmysql.AuthCachingSha2Password, util.CalcSha2Password(cli.salt, []byte("123")))
+	data = cli.readPacket()
+	expected := append([]byte{tmysql.AuthSwitchHeader}, tmysql.AuthNativePassword...)
+	expected = append(expected, 0)
+	expected = append(expected, cli.salt...)
+	c.Assert(data, DeepEquals, append(expected, 0))
+	cli.writePacket(util.CalcPassword(cli.salt, util.Sha1Hash([]byte("123"))))
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	cli.close()
+}
+
+func runTestChangeUserAndResetConnection(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE USER 'change_user'@'%' IDENTIFIED BY '123';`)
+		dbt.mustExec(`CREATE USER 'change_sha2'@'%' IDENTIFIED WITH caching_sha2_password;`)
+		dbt.mustExec(`CREATE TABLE test.reset_conn (a int);`)
+	})
+
+	cli := newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("root", tmysql.AuthNativePassword, nil)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("SET @a = 1;"), Equals, tmysql.OKHeader)
+
+	// The user variables are discarded after the user is changed.
+	cli.writeChangeUser("change_user", tmysql.AuthNativePassword, util.CalcPassword(cli.salt, util.Sha1Hash([]byte("123"))), "test")
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(strings.HasPrefix(cli.queryValue("SELECT current_user();"), "change_user@"), IsTrue)
+	c.Assert(cli.queryValue("SELECT @a;"), Equals, "NULL")
+	c.Assert(cli.queryValue("SELECT database();"), Equals, "test")
+
+	// The connection is closed if the authentication fails.
+	cli.writeChangeUser("change_user", tmysql.AuthNativePassword, util.CalcPassword(cli.salt, util.Sha1Hash([]byte("456"))), "")
+	c.Assert(cli.readPacket()[0], Equals, tmysql.ErrHeader)
+	_, err := cli.pkt.readPacket()
+	c.Assert(err, NotNil)
+	cli.close()
+
+	cli = newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("change_user", tmysql.AuthNativePassword, util.CalcPassword(cli.salt, util.Sha1Hash([]byte("123"))))
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(strings.HasPrefix(cli.queryValue("SELECT current_user();"), "change_user@"), IsTrue)
+
+	// The server asks the client to switch to the plugin of the new user.
+	cli.writeChangeUser("change_sha2", tmysql.AuthNativePassword, util.CalcPassword(cli.salt, util.Sha1Hash([]byte("123"))), "")
+	data := cli.readPacket()
+	expected := append([]byte{tmysql.AuthSwitchHeader}, tmysql.AuthCachingSha2Password...)
+	c.Assert(data[:len(expected)], DeepEquals, expected)
+	cli.writePacket(nil)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(strings.HasPrefix(cli.queryValue("SELECT current_user();"), "change_sha2@"), IsTrue)
+
+	// Reset the connection, the user and the database are kept.
+	cli.writeChangeUser("root", tmysql.AuthNativePassword, nil, "test")
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("SET @a = 1;"), Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("SET autocommit = 0;"), Equals, tmysql.OKHeader)
+	c.Assert(cli.queryValue("SELECT @@autocommit;"), Equals, "0")
+	c.Assert(cli.exec("PREPARE stmt FROM 'SELECT 1';"), Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("INSERT INTO reset_conn VALUES (1);"), Equals, tmysql.OKHeader)
+	c.Assert(cli.queryValue("SELECT count(*) FROM reset_conn;"), Equals, "1")
+	cli.writeCommand(tmysql.ComResetConnection, nil)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(strings.HasPrefix(cli.queryValue("SELECT current_user();"), "root@"), IsTrue)
+	c.Assert(cli.queryValue("SELECT database();"), Equals, "test")
+	c.Assert(cli.queryValue("SELECT @a;"), Equals, "NULL")
+	c.Assert(cli.queryValue("SELECT @@autocommit;"), Equals, "ON")
+	c.Assert(cli.exec("EXECUTE stmt;"), Equals, tmysql.ErrHeader)
+	c.Assert(cli.queryValue("SELECT count(*) FROM reset_conn;"), Equals, "0")
+	cli.close()
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`DROP USER 'change_user'@'%', 'change_sha2'@'%';`)
+		dbt.mustExec(`DROP TABLE test.reset_conn;`)
+	})
+}
+
+func runTestCursorFetch(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE TABLE test.cursor_fetch (a int);`)
+		dbt.mustExec(`INSERT INTO test.cursor_fetch VALUES (1), (2), (3);`)
+	})
+
+	cli := newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("root", tmysql.AuthNativePassword, nil)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	stmtID := cli.prepare("SELECT a FROM test.cursor_fetch ORDER BY a;")
+	execute := func() {
+		// The flag is CURSOR_TYPE_READ_ONLY and the iteration count is always 1.
+		data := append(dumpUint32(stmtID), tmysql.CursorTypeReadOnly, 1, 0, 0, 0)
+		cli.writeCommand(tmysql.ComStmtExecute, data)
+		c.Assert(cli.readPacket(), DeepEquals, []byte{1})
+		cli.readPacket()
+		// No rows are sent until the client fetches them.
+		c.Assert(cli.readEOFStatus()&tmysql.ServerStatusCursorExists, Equals, tmysql.ServerStatusCursorExists)
+	}
+	fetch := func(fetchSize uint32) {
+		cli.writeCommand(tmysql.ComStmtFetch, append(dumpUint32(stmtID), dumpUint32(fetchSize)...))
+	}
+
+	execute()
+	fetch(2)
+	// The binary row has the header, the NULL bitmap and the value.
+	c.Assert(cli.readPacket(), DeepEquals, []byte{0, 0, 1, 0, 0, 0})
+	c.Assert(cli.readPacket(), DeepEquals, []byte{0, 0, 2, 0, 0, 0})
+	status := cli.readEOFStatus()
+	c.Assert(status&tmysql.ServerStatusCursorExists, Equals, tmysql.ServerStatusCursorExists)
+	c.Assert(status&tmysql.ServerStatusLastRowSend, Equals, uint16(0))
+	fetch(2)
+	c.Assert(cli.readPacket(), DeepEquals, []byte{0, 0, 3, 0, 0, 0})
+	c.Assert(cli.readEOFStatus()&tmysql.ServerStatusLastRowSend, Equals, tmysql.ServerStatusLastRowSend)
+	// The cursor is closed after the last row is sent.
+	fetch(2)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.ErrHeader)
+
+	// Executing the statement again opens a new cursor.
+	execute()
+	execute()
+	fetch(1)
+	c.Assert(cli.readPacket(), DeepEquals, []byte{0, 0, 1, 0, 0, 0})
+	cli.readEOFStatus()
+
+	// COM_STMT_RESET closes the cursor.
+	cli.writeCommand(tmysql.ComStmtReset, dumpUint32(stmtID))
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	fetch(1)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.ErrHeader)
+
+	// COM_STMT_CLOSE closes the cursor and the statement.
+	execute()
+	cli.writeCommand(tmysql.ComStmtClose, dumpUint32(stmtID))
+	fetch(1)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.ErrHeader)
+
+	// The cursor is closed when the client disconnects.
+	stmtID = cli.prepare("SELECT a FROM test.cursor_fetch ORDER BY a;")
+	execute()
+	cli.close()
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`DROP TABLE test.cursor_fetch;`)
+	})
+}
+
+func runTestCompression(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE TABLE test.compression (a text);`)
+	})
+
+	value := strings.Repeat("a", 1000)
+	testCompression := func(capability uint32, algorithm int, zstdLevel uint8) {
+		cli := newTestAuthClient(c, "tcp", "localhost:4001")
+		cli.capability, cli.zstdLevel = capability, zstdLevel
+		cli.writeHandshakeResponse("root", tmysql.AuthNativePassword, nil)
+		// The packets are compressed after the handshake.
+		c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+		c.Assert(cli.pkt.enableCompression(algorithm, int(zstdLevel)), IsNil)
+		c.Assert(cli.queryValue(fmt.Sprintf("SELECT '%s';", value)), Equals, value)
+
+		// The file of LOAD DATA LOCAL is sent over the compressed protocol too.
+		c.Assert(cli.exec("DELETE FROM test.compression;"), Equals, tmysql.OKHeader)
+		cli.writeCommand(tmysql.ComQuery, []byte("LOAD DATA LOCAL INFILE 'compression.txt' INTO TABLE test.compression;"))
+		c.Assert(cli.readPacket(), DeepEquals, []byte("\xfbcompression.txt"))
+		cli.writePacket([]byte(value + "\n" + value + "\n"))
+		cli.writePacket(nil)
+		c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+		c.Assert(cli.queryValue(fmt.Sprintf("SELECT count(*) FROM test.compression WHERE a = '%s';", value)), Equals, "2")
+		cli.close()
+	}
+	testCompression(tmysql.ClientCompress|tmysql.ClientLocalFiles, compressionZlib, 0)
+	testCompression(tmysql.ClientZstdCompressionAlgorithm|tmysql.ClientLocalFiles, compressionZstd, 0)
+	testCompression(tmysql.ClientZstdCompressionAlgorithm|tmysql.ClientLocalFiles, compressionZstd, 19)
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`DROP TABLE test.compression;`)
+	})
+}
+
+func runTestUnixSocketAuth(c *C, osUser, socket string) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(fmt.Sprintf(`CREATE USER '%s'@'localhost' IDENTIFIED WITH auth_socket;`, osUser))
+		dbt.mustExec(`CREATE USER 'socket_mismatch'@'localhost' IDENTIFIED WITH auth_socket;`)
+		dbt.mustExec(`CREATE USER 'socket_sha2'@'%' IDENTIFIED WITH caching_sha2_password BY '123';`)
+	})
+
+	runTests(c, fmt.Sprintf("%s@unix(%s)/test?strict=true", osUser, socket), func(dbt *DBTest) {
+		dbt.mustExec(`USE mysql;`)
+	})
+	db, err := sql.Open("mysql", fmt.Sprintf("socket_mismatch@unix(%s)/test?strict=true", socket))
+	c.Assert(err, IsNil)
+	_, err = db.Query("USE mysql;")
+	c.Assert(err, NotNil, Commentf("The operating system user doesn't match"))
+	db.Close()
+
+	// The unix socket is secure, so the password of caching_sha2_password is sent in clear text.
+	cli := newTestAuthClient(c, "unix", socket)
+	cli.writeHandshakeResponse("socket_sha2", tmysql.AuthCachingSha2Password, util.CalcSha2Password(cli.salt, []byte("123")))
+	c.Assert(cli.readPacket(), DeepEquals, []byte{tmysql.AuthMoreDataHeader, cachingSha2PerformFullAuth})
+	cli.writePacket([]byte("123\x00"))
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	cli.close()
+	// The wrong password is refused, the socket connections aren't trusted without SocketSkipAuth.
+	cli = newTestAuthClient(c, "unix", socket)
+	cli.writeHandshakeResponse("socket_sha2", tmysql.AuthCachingSha2Password, util.CalcSha2Password(cli.salt, []byte("456")))
+	c.Assert(cli.readPacket(), DeepEquals, []byte{tmysql.AuthMoreDataHeader, cachingSha2PerformFullAuth})
+	cli.writePacket([]byte("456\x00"))
+	c.Assert(cli.readPacket()[0], Equals, tmysql.ErrHeader)
+	cli.close()
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(fmt.Sprintf(`DROP USER '%s'@'localhost', 'socket_mismatch'@'localhost', 'socket_sha2'@'%%';`, osUser))
+	})
+}
+
+func runTestSocketSkipAuth(c *C, socket string) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE USER 'socket_skip'@'%' IDENTIFIED BY '123';`)
+	})
+
+	// The connections from the socket log in without checking the passwords.
+	cli := newTestAuthClient(c, "unix", socket)
+	cli.writeHandshakeResponse("socket_skip", tmysql.AuthNativePassword, util.CalcPassword(cli.salt, util.Sha1Hash([]byte("456"))))
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(cli.queryValue("SELECT 1;"), Equals, "1")
+	cli.close()
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`DROP USER 'socket_skip'@'%';`)
+	})
+}
+
+func runTestUserResources(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE USER 'res_user'@'%' IDENTIFIED BY '123' WITH MAX_USER_CONNECTIONS 1 MAX_QUERIES_PER_HOUR 4 MAX_UPDATES_PER_HOUR 1;`)
+		dbt.mustExec(`GRANT ALL ON test.* TO 'res_user'@'%';`)
+		dbt.mustExec(`CREATE TABLE test.user_res (a int);`)
+	})
+	errCode := func(data []byte) uint16 {
+		c.Assert(data[0], Equals, tmysql.ErrHeader)
+		return binary.LittleEndian.Uint16(data[1:3])
+	}
+	login := func() (*testAuthClient, []byte) {
+		cli := newTestAuthClient(c, "tcp", "localhost:4005")
+		cli.writeHandshakeResponse("res_user", tmysql.AuthNativePassword, util.CalcPassword(cli.salt, util.Sha1Hash([]byte("123"))))
+		return cli, cli.readPacket()
+	}
+
+	cli, data := login()
+	c.Assert(data[0], Equals, tmysql.OKHeader)
+	cli2, data := login()
+	c.Assert(errCode(data), Equals, uint16(tmysql.ErrTooManyUserConnections))
+	cli2.close()
+
+	c.Assert(cli.exec("INSERT INTO test.user_res VALUES (1);"), Equals, tmysql.OKHeader)
+	cli.writeCommand(tmysql.ComQuery, []byte("UPDATE test.user_res SET a = 2;"))
+	c.Assert(errCode(cli.readPacket()), Equals, uint16(tmysql.ErrUserLimitReached))
+	// The prepared statements are counted when they are executed.
+	stmtID := cli.prepare("DELETE FROM test.user_res;")
+	cli.writeCommand(tmysql.ComStmtExecute, append(dumpUint32(stmtID), 0, 1, 0, 0, 0))
+	c.Assert(errCode(cli.readPacket()), Equals, uint16(tmysql.ErrUserLimitReached))
+	c.Assert(cli.queryValue("SELECT a FROM test.user_res;"), Equals, "1")
+	c.Assert(cli.queryValue("SELECT 1;"), Equals, "1")
+	c.Assert(cli.queryValue("SELECT 2;"), Equals, "2")
+	cli.writeCommand(tmysql.ComQuery, []byte("SELECT 3;"))
+	c.Assert(errCode(cli.readPacket()), Equals, uint16(tmysql.ErrUserLimitReached))
+	cli.close()
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`DROP USER 'res_user'@'%';`)
+		dbt.mustExec(`DROP TABLE test.user_res;`)
+	})
+}
+
+func runTestDrain(c *C, server *Server) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE TABLE test.drain (a int);`)
+		dbt.mustExec(`CREATE TABLE test.drain_join (a int);`)
+		for i := 0; i < 100; i++ {
+			dbt.mustExec(`INSERT INTO test.drain_join VALUES (?);`, i)
+		}
+	})
+	login := func() *testAuthClient {
+		cli := newTestAuthClient(c, "tcp", "localhost:4006")
+		cli.writeHandshakeResponse("root", tmysql.AuthNativePassword, nil)
+		c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+		return cli
+	}
+	// waitClosed waits until the connection is closed by the server.
+	waitClosed := func(cli *testAuthClient) {
+		_, err := cli.pkt.readPacket()
+		c.Assert(err, NotNil)
+		cli.close()
+	}
+	status := func() int {
+		w := httptest.NewRecorder()
+		server.handleStatus(w, httptest.NewRequest("GET", "/status", nil))
+		return w.Code
+	}
+
+	idle := login()
+	c.Assert(idle.exec("INSERT INTO test.drain VALUES (1);"), Equals, tmysql.OKHeader)
+	rolledBack := login()
+	c.Assert(rolledBack.exec("BEGIN;"), Equals, tmysql.OKHeader)
+	c.Assert(rolledBack.exec("INSERT INTO test.drain VALUES (2);"), Equals, tmysql.OKHeader)
+	finished := login()
+	killed := login()
+	c.Assert(status(), Equals, http.StatusOK)
+	// The statement finishes within the deadline, the join outlives the deadline and is killed.
+	finished.writeCommand(tmysql.ComQuery, []byte("SELECT SLEEP(0.5);"))
+	killed.writeCommand(tmysql.ComQuery, []byte("SELECT COUNT(*) FROM test.drain_join a, test.drain_join b, test.drain_join c, test.drain_join d, test.drain_join e;"))
+	time.Sleep(100 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	server.handleDrain(w, httptest.NewRequest("GET", "/drain", nil))
+	c.Assert(w.Code, Equals, http.StatusMethodNotAllowed)
+	w = httptest.NewRecorder()
+	server.handleDrain(w, httptest.NewRequest("POST", "/drain", nil))
+	c.Assert(w.Code, Equals, http.StatusAccepted)
+
+	drainStart := time.Now()
+	// The idle connections are closed, the idle transaction is rolled back, and no more connections are accepted.
+	waitClosed(idle)
+	waitClosed(rolledBack)
+	c.Assert(time.Since(drainStart), Less, time.Second)
+	_, err := net.Dial("tcp", "localhost:4006")
+	c.Assert(err, NotNil)
+	c.Assert(status(), Equals, http.StatusServiceUnavailable)
+
+	// The running statement is finished, then the connection is closed.
+	c.Assert(finished.readValue("SELECT SLEEP(0.5);"), Equals, "0")
+	waitClosed(finished)
+	// The statement is killed after the deadline.
+	waitClosed(killed)
+	server.Drain()
+	c.Assert(server.ConnectionCount(), Equals, 0)
+	c.Assert(time.Since(drainStart), Less, 5*time.Second)
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		rows := dbt.mustQuery(`SELECT a FROM test.drain;`)
+		var values []int
+		for rows.Next() {
+			var a int
+			c.Assert(rows.Scan(&a), IsNil)
+			values = append(values, a)
+		}
+		rows.Close()
+		c.Assert(values, DeepEquals, []int{1})
+		dbt.mustExec(`DROP TABLE test.drain, test.drain_join;`)
+	})
+}
+
+const pgDSN = "postgres://%s@localhost:5433/test?sslmode=disable"
+
+func runTestPostgreSQL(c *C, setAuthMethod func(method string)) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE USER 'pg_user'@'%' IDENTIFIED BY '123';`)
+		dbt.mustExec(`CREATE TABLE test.pg (id int PRIMARY KEY, name varchar(20), price decimal(10,2), created datetime, data blob);`)
+	})
+
+	for _, method := range []string{util.PGAuthScramSha256, util.PGAuthMD5, util.PGAuthPassword} {
+		setAuthMethod(method)
+		db, err := sql.Open("postgres", fmt.Sprintf(pgDSN, "pg_user:123"))
+		c.Assert(err, IsNil)
+		var v int
+		c.Assert(db.QueryRow("SELECT 1").Scan(&v), IsNil, Commentf("%s", method))
+		c.Assert(v, Equals, 1)
+		db.Close()
+
+		db, err = sql.Open("postgres", fmt.Sprintf(pgDSN, "pg_user:456"))
+		c.Assert(err, IsNil)
+		checkPGErrorCode(c, db.Ping(), "28P01")
+		db.Close()
+	}
+	setAuthMethod("")
+
+	// root has no password, so it's logged in without the authentication exchange.
+	db, err := sql.Open("postgres", fmt.Sprintf(pgDSN, "root"))
+	c.Assert(err, IsNil)
+	defer db.Close()
+	dbt := &DBTest{c, db}
+	dbt.mustExec("INSERT INTO pg VALUES ($1, $2, $3, $4, $5), ($6, $2, NULL, NULL, NULL)", 1, "a", "1.50", "2016-01-02 03:04:05", []byte{0, 1}, 2)
+	var (
+		name    string
+		price   string
+		created time.Time
+		data    []byte
+	)
+	err = db.QueryRow("SELECT name, price, created, data FROM pg WHERE id = $1", 1).Scan(&name, &price, &created, &data)
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "a")
+	c.Assert(price, Equals, "1.50")
+	c.Assert(created.Format("2006-01-02 15:04:05"), Equals, "2016-01-02 03:04:05")
+	c.Assert(data, DeepEquals, []byte{0, 1})
+
+	res := dbt.mustExec("UPDATE pg SET name = $1 WHERE id > $2", "b", 0)
+	rows, err := res.RowsAffected()
+	c.Assert(err, IsNil)
+	c.Assert(rows, Equals, int64(2))
+
+	// The named prepared statement.
+	stmt, err := db.Prepare("SELECT count(*) FROM pg WHERE name = $1 AND id >= $2")
+	c.Assert(err, IsNil)
+	var count int
+	c.Assert(stmt.QueryRow("b", 2).Scan(&count), IsNil)
+	c.Assert(count, Equals, 1)
+	stmt.Close()
+
+	// The errors are mapped to SQLSTATE.
+	_, err = db.Exec("INSERT INTO pg (id) VALUES ($1)", 1)
+	checkPGErrorCode(c, err, "23505")
+	_, err = db.Exec("SELECT * FROM pg_not_exists")
+	checkPGErrorCode(c, err, "42P01")
+
+	tx, err := db.Begin()
+	c.Assert(err, IsNil)
+	_, err = tx.Exec("INSERT INTO pg (id) VALUES ($1)", 3)
+	c.Assert(err, IsNil)
+	c.Assert(tx.Rollback(), IsNil)
+	c.Assert(db.QueryRow("SELECT count(*) FROM pg").Scan(&count), IsNil)
+	c.Assert(count, Equals, 2)
+
+	// The portal is suspended after max rows, and the rows are sent in the binary format.
+	cli := newTestPGClient(c, "root")
+	cli.writeMessage(pgMsgParse, "", "SELECT id, price FROM pg ORDER BY id", []byte{0, 0})
+	cli.writeMessage(pgMsgBind, "", "", []byte{0, 0, 0, 0, 0, 1, 0, 1})
+	cli.writeMessage(pgMsgExecute, "", []byte{0, 0, 0, 1})
+	cli.writeMessage(pgMsgExecute, "", []byte{0, 0, 0, 0})
+	cli.writeMessage(pgMsgSync)
+	cli.expectMessage(pgMsgParseComplete)
+	cli.expectMessage(pgMsgBindComplete)
+	c.Assert(cli.expectMessage(pgMsgDataRow), DeepEquals, []byte{
+		0, 2, 0, 0, 0, 4, 0, 0, 0, 1, 0, 0, 0, 12, 0, 2, 0, 0, 0, 0, 0, 2, 0, 1, 0x13, 0x88})
+	cli.expectMessage(pgMsgPortalSuspended)
+	c.Assert(cli.expectMessage(pgMsgDataRow), DeepEquals, []byte{0, 2, 0, 0, 0, 4, 0, 0, 0, 2, 0xff, 0xff, 0xff, 0xff})
+	c.Assert(cli.expectMessage(pgMsgCommandComplete), DeepEquals, []byte("SELECT 1\x00"))
+	c.Assert(cli.expectMessage(pgMsgReadyForQuery), DeepEquals, []byte("I"))
+
+	// The messages after an error are discarded until Sync.
+	cli.writeMessage(pgMsgExecute, "unknown", []byte{0, 0, 0, 0})
+	cli.writeMessage(pgMsgParse, "", "SELECT 1", []byte{0, 0})
+	cli.writeMessage(pgMsgSync)
+	cli.expectMessage(pgMsgErrorResponse)
+	cli.expectMessage(pgMsgReadyForQuery)
+	cli.writeMessage(pgMsgQuery, "")
+	cli.expectMessage(pgMsgEmptyQueryResponse)
+	cli.expectMessage(pgMsgReadyForQuery)
+	cli.close()
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`DROP USER 'pg_user'@'%';`)
+		dbt.mustExec(`DROP TABLE test.pg;`)
+	})
+}
+
+func checkPGErrorCode(c *C, e error, code string) {
+	pe, ok := e.(*pq.Error)
+	c.Assert(ok, IsTrue, Commentf("err: %v", e))
+	c.Assert(string(pe.Code), Equals, code)
+}
+
+// testPGClient is a PostgreSQL protocol client which sends the raw messages.
+type testPGClient struct {
+	*C
+	conn net.Conn
+	rb   *bufio.Reader
+}
+
+func newTestPGClient(c *C, user string) *testPGClient {
+	conn, err := net.Dial("tcp", "localhost:5433")
+	c.Assert(err, IsNil)
+	cli := &testPGClient{C: c, conn: conn, rb: bufio.NewReader(conn)}
+	buf := newPGBuffer()
+	buf.putUint32(pgProtocolVersion3)
+	buf.putString("user")
+	buf.putString(user)
+	buf.putString("database")
+	buf.putString("test")
+	buf.putByte(0)
+	_, err = conn.Write(append(dumpUint32BigEndian(uint32(len(buf.bytes())+4)), buf.bytes()...))
+	c.Assert(err, IsNil)
+	c.Assert(cli.expectMessage(pgMsgAuthentication), DeepEquals, []byte{0, 0, 0, pgAuthOK})
+	for {
+		typ, _ := cli.readMessage()
+		if typ == pgMsgReadyForQuery {
+			return cli
+		}
+	}
+}
+
+// writeMessage writes the message, the strings are null-terminated and the bytes are written as is.
+func (cli *testPGClient) writeMessage(typ byte, fields ...interface{}) {
+	buf := newPGBuffer()
+	for _, field := range fields {
+		switch x := field.(type) {
+		case string:
+			buf.putString(x)
+		case []byte:
+			buf.putBytes(x)
+		}
+	}
+	data := append([]byte{typ}, dumpUint32BigEndian(uint32(len(buf.bytes())+4))...)
+	_, err := cli.conn.Write(append(data, buf.bytes()...))
+	cli.Assert(err, IsNil)
+}
+
+func (cli *testPGClient) readMessage() (byte, []byte) {
+	header := make([]byte, 5)
+	_, err := io.ReadFull(cli.rb, header)
+	cli.Assert(err, IsNil)
+	data := make([]byte, binary.BigEndian.Uint32(header[1:])-4)
+	_, err = io.ReadFull(cli.rb, data)
+	cli.Assert(err, IsNil)
+	return header[0], data
+}
+
+func (cli *testPGClient) expectMessage(typ byte) []byte {
+	t, data := cli.readMessage()
+	cli.Assert(t, Equals, typ, Commentf("%q", data))
+	return data
+}
+
+func (cli *testPGClient) close() {
+	cli.writeMessage(pgMsgTerminate)
+	cli.conn.Close()
+}
+
+func dumpUint32BigEndian(n uint32) []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, n)
+	return data
+}
+
 func runTestIssues(c *C) {
 	// For issue #263
 	unExistsSchemaDsn := "root@tcp(localhost:4001)/unexists_schema?strict=true"
@@ -506,6 +1307,98 @@ func runTestStatusAPI(c *C) {
 	c.Assert(data.GitHash, Equals, printer.TiDBGitHash)
 }
 
+func getStatusAPI(c *C, path string) *http.Response {
+	resp, err := http.Get("http://127.0.0.1:10090" + path)
+	c.Assert(err, IsNil)
+	return resp
+}
+
+func runTestHTTPAPI(c *C) {
+	runTestsOnNewDB(c, "http_api", func(dbt *DBTest) {
+		dbt.mustExec("create table test (a int primary key, b int, index idx_b (b))")
+		dbt.mustExec("insert test values (1, 1)")
+		dbt.mustExec("update test set b = 2 where a = 1")
+
+		resp := getStatusAPI(c, "/schema/http_api/test")
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+		var tblInfo model.TableInfo
+		err := json.NewDecoder(resp.Body).Decode(&tblInfo)
+		resp.Body.Close()
+		c.Assert(err, IsNil)
+		c.Assert(tblInfo.Name.L, Equals, "test")
+		c.Assert(tblInfo.Columns, HasLen, 2)
+		c.Assert(tblInfo.Indices, HasLen, 1)
+
+		resp = getStatusAPI(c, "/mvcc/key/http_api/test/1")
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+		var info kv.MvccInfo
+		err = json.NewDecoder(resp.Body).Decode(&info)
+		resp.Body.Close()
+		c.Assert(err, IsNil)
+		c.Assert(info.Versions, HasLen, 2)
+		c.Assert(info.Versions[0].CommitTS, Greater, info.Versions[1].CommitTS)
+		c.Assert(info.Lock, IsNil)
+
+		resp = getStatusAPI(c, "/ddl/history")
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+		var jobs []*model.Job
+		err = json.NewDecoder(resp.Body).Decode(&jobs)
+		resp.Body.Close()
+		c.Assert(err, IsNil)
+		c.Assert(len(jobs), Greater, 0)
+		lastJob := jobs[len(jobs)-1]
+		c.Assert(lastJob.Type, Equals, model.ActionCreateTable)
+		c.Assert(lastJob.TableID, Equals, tblInfo.ID)
+
+		// The regions are only supported by TiKV.
+		resp = getStatusAPI(c, "/tables/http_api/test/regions")
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusNotImplemented)
+
+		for path, code := range map[string]int{
+			"/schema/http_api/not_exist":      http.StatusNotFound,
+			"/schema/not_exist/test":          http.StatusNotFound,
+			"/schema/http_api":                http.StatusBadRequest,
+			"/mvcc/key/http_api/test/abc":     http.StatusBadRequest,
+			"/tables/http_api/test/not_exist": http.StatusBadRequest,
+		} {
+			resp = getStatusAPI(c, path)
+			resp.Body.Close()
+			c.Assert(resp.StatusCode, Equals, code, Commentf("path %s", path))
+		}
+	})
+}
+
+func runTestShowProcessList(c *C) {
+	runTestsOnNewDB(c, "ShowProcessList", func(dbt *DBTest) {
+		rows := dbt.mustQuery("SHOW FULL PROCESSLIST")
+		cols, err := rows.Columns()
+		c.Assert(err, IsNil)
+		c.Assert(cols, DeepEquals, []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info", "Mem"})
+		var found bool
+		for rows.Next() {
+			var (
+				id                         uint64
+				user, host, command, state string
+				db, info                   sql.NullString
+				t, mem                     int64
+			)
+			err = rows.Scan(&id, &user, &host, &db, &command, &t, &state, &info, &mem)
+			c.Assert(err, IsNil)
+			if info.String != "SHOW FULL PROCESSLIST" {
+				continue
+			}
+			found = true
+			c.Assert(user, Equals, "root")
+			c.Assert(db.String, Equals, "ShowProcessList")
+			c.Assert(command, Equals, "Query")
+			c.Assert(state, Equals, "executing")
+		}
+		c.Assert(rows.Close(), IsNil)
+		c.Assert(found, IsTrue)
+	})
+}
+
 func runTestMultiStatements(c *C) {
 	runTestsOnNewDB(c, "MultiStatements", func(dbt *DBTest) {
 		// Create Table
@@ -586,3 +1479,91 @@ func getStmtCnt(content string) (stmtCnt map[string]int) {
 	}
 	return stmtCnt
 }
+
+func runTestAudit(c *C, path string) {
+	cli := newTestAuthClient(c, "tcp", "localhost:4007")
+	cli.writeHandshakeResponse("root", tmysql.AuthNativePassword, nil)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("CREATE TABLE test.audit (a int);"), Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("INSERT INTO test.audit VALUES (1);"), Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("INSERT INTO test.audit_not_exists VALUES (1);"), Equals, tmysql.ErrHeader)
+	cli.writeCommand(tmysql.ComQuit, nil)
+	_, err := cli.pkt.readPacket()
+	c.Assert(err, NotNil)
+	cli.close()
+
+	// The failed connections are audited too.
+	cli = newTestAuthClient(c, "tcp", "localhost:4007")
+	cli.writeHandshakeResponse("audit_nobody", tmysql.AuthNativePassword, nil)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.ErrHeader)
+	cli.close()
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	c.Assert(lines, HasLen, 7, Commentf("%s", data))
+	expected := []struct {
+		typ       string
+		user      string
+		class     string
+		succeeded bool
+	}{
+		{audit.EventConnect, "root", "", true},
+		{audit.EventQueryStart, "root", "CreateTable", true},
+		{audit.EventQuery, "root", "CreateTable", true},
+		{audit.EventQuery, "root", "Insert", true},
+		{audit.EventQuery, "root", "Insert", false},
+		{audit.EventDisconnect, "root", "", true},
+		{audit.EventConnect, "audit_nobody", "", false},
+	}
+	for i, line := range lines {
+		var e audit.Event
+		c.Assert(json.Unmarshal([]byte(line), &e), IsNil)
+		c.Assert(e.Type, Equals, expected[i].typ, Commentf("%s", line))
+		c.Assert(e.User, Equals, expected[i].user, Commentf("%s", line))
+		c.Assert(e.Host, Not(Equals), "")
+		c.Assert(e.StmtClass, Equals, expected[i].class, Commentf("%s", line))
+		c.Assert(e.Succeeded, Equals, expected[i].succeeded, Commentf("%s", line))
+	}
+}
+
+func runTestGeneralLog(c *C) {
+	dir, err := ioutil.TempDir("", "generallog")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "general.log")
+	c.Assert(generallog.SetFile(path), IsNil)
+	defer generallog.SetFile("")
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec("SELECT 'not logged'")
+		dbt.mustExec("SET GLOBAL general_log = 1")
+		dbt.mustExec("CREATE TABLE test (a int, b varchar(10))")
+		// The arguments are bound to a prepared statement.
+		dbt.mustExec("INSERT INTO test VALUES (?, ?)", 1, "abc")
+		dbt.mustExec("SET GLOBAL general_log = OFF")
+		dbt.mustExec("SELECT 'not logged either'")
+	})
+	// The statements in a transaction are logged with the start timestamp of the transaction.
+	cli := newTestAuthClient(c, "tcp", "localhost:4001")
+	cli.writeHandshakeResponse("root", tmysql.AuthNativePassword, nil)
+	c.Assert(cli.readPacket()[0], Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("SET GLOBAL general_log = 'ON';"), Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("BEGIN;"), Equals, tmysql.OKHeader)
+	c.Assert(cli.exec("SELECT 'in txn';"), Equals, byte(1))
+	cli.close()
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec("SET GLOBAL general_log = 0")
+	})
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	log := string(data)
+	c.Assert(log, Not(Matches), "(?s).*not logged.*")
+	c.Assert(log, Matches, "(?s).*\\troot\\tQuery\\t0\\tCREATE TABLE test \\(a int, b varchar\\(10\\)\\)\\n.*")
+	c.Assert(log, Matches, "(?s).*\\troot\\tStmtPrepare\\t0\\tINSERT INTO test VALUES \\(\\?, \\?\\)\\n.*")
+	c.Assert(log, Matches, "(?s).*\\troot\\tStmtExecute\\t0\\t[0-9]+ \\[1 'abc'\\]\\n.*")
+	c.Assert(log, Matches, "(?s).*\\troot\\tStmtClose\\t0\\t[0-9]+\\n.*")
+	c.Assert(log, Matches, "(?s).*\\troot\\tQuery\\t[1-9][0-9]*\\tSELECT 'in txn';\\n.*")
+	c.Assert(log, Matches, "(?s).*\\tQuery\\t0\\tSET GLOBAL general_log = OFF\\n.*")
+}
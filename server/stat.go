@@ -0,0 +1,52 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+var (
+	sslCipher  = "Ssl_cipher"
+	sslVersion = "Ssl_version"
+)
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLSv1",
+	tls.VersionTLS11: "TLSv1.1",
+	tls.VersionTLS12: "TLSv1.2",
+	tls.VersionTLS13: "TLSv1.3",
+}
+
+// GetScope gets the status variables scope.
+func (s *Server) GetScope(status string) variable.ScopeFlag {
+	// The TLS status variables describe the connection of the session.
+	return variable.ScopeSession
+}
+
+// Stats returns the server statistics.
+func (s *Server) Stats(vars *variable.SessionVars) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	m[sslCipher] = ""
+	m[sslVersion] = ""
+	if vars == nil || vars.TLSConnectionState == nil {
+		return m, nil
+	}
+	state := vars.TLSConnectionState
+	m[sslCipher] = tls.CipherSuiteName(state.CipherSuite)
+	m[sslVersion] = tlsVersionNames[state.Version]
+	return m, nil
+}
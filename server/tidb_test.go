@@ -15,12 +15,27 @@
 package server
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"database/sql"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/ngaut/log"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/audit"
 )
 
 type TidbTestSuite struct {
@@ -121,11 +136,24 @@ func (ts *TidbTestSuite) TestStatusAPI(c *C) {
 	runTestStatusAPI(c)
 }
 
+func (ts *TidbTestSuite) TestHTTPAPI(c *C) {
+	runTestHTTPAPI(c)
+}
+
+func (ts *TidbTestSuite) TestGeneralLog(c *C) {
+	runTestGeneralLog(c)
+}
+
 func (ts *TidbTestSuite) TestMultiStatements(c *C) {
 	c.Parallel()
 	runTestMultiStatements(c)
 }
 
+func (ts *TidbTestSuite) TestShowProcessList(c *C) {
+	c.Parallel()
+	runTestShowProcessList(c)
+}
+
 func (ts *TidbTestSuite) TestSocket(c *C) {
 	c.Parallel()
 	cfg := &Config{
@@ -143,3 +171,204 @@ func (ts *TidbTestSuite) TestSocket(c *C) {
 	dsn = tcpDsn
 	server.Close()
 }
+
+func (ts *TidbTestSuite) TestAuthPlugins(c *C) {
+	runTestAuthPlugins(c)
+}
+
+func (ts *TidbTestSuite) TestChangeUserAndResetConnection(c *C) {
+	runTestChangeUserAndResetConnection(c)
+}
+
+func (ts *TidbTestSuite) TestCursorFetch(c *C) {
+	runTestCursorFetch(c)
+}
+
+func (ts *TidbTestSuite) TestCompression(c *C) {
+	runTestCompression(c)
+}
+
+func (ts *TidbTestSuite) TestPostgreSQL(c *C) {
+	cfg := &Config{
+		Addr:       ":4004",
+		LogLevel:   "debug",
+		StatusAddr: ":10094",
+		PGAddr:     ":5433",
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	runTestPostgreSQL(c, func(method string) {
+		cfg.PGAuthMethod = method
+	})
+	server.Close()
+
+	cfg.PGAuthMethod = "trust"
+	_, err = NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, NotNil)
+}
+
+func (ts *TidbTestSuite) TestUserResources(c *C) {
+	cfg := &Config{
+		Addr:           ":4005",
+		LogLevel:       "debug",
+		StatusAddr:     ":10095",
+		UserTokenLimit: 1,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	runTestUserResources(c)
+	server.Close()
+}
+
+func (ts *TidbTestSuite) TestDrain(c *C) {
+	cfg := &Config{
+		Addr:         ":4006",
+		LogLevel:     "debug",
+		StatusAddr:   ":10096",
+		DrainTimeout: 1,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	done := make(chan error)
+	go func() {
+		done <- server.Run()
+	}()
+	time.Sleep(time.Millisecond * 100)
+	runTestDrain(c, server)
+	// Run returns after the server is drained.
+	c.Assert(<-done, IsNil)
+}
+
+func (ts *TidbTestSuite) TestAudit(c *C) {
+	dir, err := ioutil.TempDir("", "audit")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+	w, err := audit.NewFileWriter(path, 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(audit.Register(w, &audit.Filter{Users: []string{"root", "audit_nobody"}}), IsNil)
+	defer audit.Unregister(w.Name())
+
+	cfg := &Config{
+		Addr:       ":4007",
+		LogLevel:   "debug",
+		StatusAddr: ":10097",
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	runTestAudit(c, path)
+	server.Close()
+}
+
+func (ts *TidbTestSuite) TestUnixSocketAuth(c *C) {
+	if runtime.GOOS != "linux" {
+		c.Skip("auth_socket is only supported on linux")
+	}
+	osUser, err := user.Current()
+	c.Assert(err, IsNil)
+	cfg := &Config{
+		LogLevel:   "debug",
+		StatusAddr: ":10093",
+		Socket:     "/tmp/tidbtest-auth.sock",
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	runTestUnixSocketAuth(c, osUser.Username, cfg.Socket)
+	server.Close()
+}
+
+func (ts *TidbTestSuite) TestSocketSkipAuth(c *C) {
+	cfg := &Config{
+		LogLevel:       "debug",
+		StatusAddr:     ":10095",
+		Socket:         "/tmp/tidbtest-skip-auth.sock",
+		SocketSkipAuth: true,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	runTestSocketSkipAuth(c, cfg.Socket)
+	server.Close()
+}
+
+func (ts *TidbTestSuite) TestTLS(c *C) {
+	dir, err := ioutil.TempDir("", "tidb-tls-test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	caCert, caKey := generateCert(c, 1, "TiDB Test CA", nil, nil, dir, "ca")
+	generateCert(c, 2, "tidb-server", caCert, caKey, dir, "server")
+	generateCert(c, 3, "tidb-client", caCert, caKey, dir, "client")
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client-cert.pem"), filepath.Join(dir, "client-key.pem"))
+	c.Assert(err, IsNil)
+	err = mysql.RegisterTLSConfig("client-certificate", &tls.Config{
+		Certificates:       []tls.Certificate{clientCert},
+		InsecureSkipVerify: true,
+	})
+	c.Assert(err, IsNil)
+
+	cfg := &Config{
+		Addr:       ":4002",
+		LogLevel:   "debug",
+		StatusAddr: ":10092",
+		SSLCA:      filepath.Join(dir, "ca-cert.pem"),
+		SSLCert:    filepath.Join(dir, "server-cert.pem"),
+		SSLKey:     filepath.Join(dir, "server-key.pem"),
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	runTestTLSConnection(c)
+	server.Close()
+
+	// A server with a bad certificate can't be created.
+	cfg.Addr = ":4003"
+	cfg.SSLCert = filepath.Join(dir, "ca-key.pem")
+	_, err = NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, NotNil)
+}
+
+// generateCert generates a certificate signed by parent, the certificate is self-signed if parent is nil.
+// The certificate and the private key are written to name-cert.pem and name-key.pem in dir.
+func generateCert(c *C, sn int64, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+	dir, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(sn),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	c.Assert(err, IsNil)
+	cert, err := x509.ParseCertificate(der)
+	c.Assert(err, IsNil)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, IsNil)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	err = ioutil.WriteFile(filepath.Join(dir, name+"-cert.pem"), certPEM, 0600)
+	c.Assert(err, IsNil)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	err = ioutil.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0600)
+	c.Assert(err, IsNil)
+	return cert, key
+}
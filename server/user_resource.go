@@ -0,0 +1,201 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+// accountUsage is the resource usage of an account, it's shared by the connections of the account.
+type accountUsage struct {
+	ur      *userResources
+	account string
+	// tokens limits the concurrently handled commands of the account, it's nil if the per-user token pool is disabled.
+	tokens *TokenLimiter
+
+	mu          sync.Mutex
+	limits      variable.UserResources
+	connections uint64
+	// The statements are counted in the hour which starts from hourStart.
+	hourStart time.Time
+	queries   uint64
+	updates   uint64
+}
+
+// userResources tracks the resource usage of the accounts. The usage of an account is kept after
+// its connections are closed until its hourly counters expire, so they can't be reset by reconnecting.
+type userResources struct {
+	mu         sync.Mutex
+	tokenLimit int
+	accounts   map[string]*accountUsage
+	// lastPrune is the last time the idle accounts were removed.
+	lastPrune time.Time
+}
+
+// pruneInterval is the minimum interval between the prunings of the idle accounts.
+const pruneInterval = time.Minute
+
+func newUserResources(tokenLimit int) *userResources {
+	return &userResources{
+		tokenLimit: tokenLimit,
+		accounts:   make(map[string]*accountUsage),
+	}
+}
+
+// connect adds a connection to the usage of the account with the latest limits in mysql.user,
+// the connection is refused if the account already has max_user_connections connections.
+func (ur *userResources) connect(limits *variable.UserResources) (*accountUsage, error) {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	now := time.Now()
+	if now.Sub(ur.lastPrune) >= pruneInterval {
+		ur.pruneLocked(now)
+	}
+	usage, ok := ur.accounts[limits.Account]
+	if !ok {
+		usage = &accountUsage{ur: ur, account: limits.Account}
+		if ur.tokenLimit > 0 {
+			usage.tokens = NewTokenLimiter(ur.tokenLimit)
+		}
+		ur.accounts[limits.Account] = usage
+	}
+
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.limits = *limits
+	if max := limits.MaxUserConnections; max > 0 && usage.connections >= max {
+		return nil, errTooManyUserConnections.GenByArgs(usage.userName())
+	}
+	usage.connections++
+	return usage, nil
+}
+
+// pruneLocked removes the accounts which have no connections and no hourly counts, ur.mu must be held.
+func (ur *userResources) pruneLocked(now time.Time) {
+	ur.lastPrune = now
+	for account, usage := range ur.accounts {
+		usage.mu.Lock()
+		if usage.idleLocked(now) {
+			delete(ur.accounts, account)
+		}
+		usage.mu.Unlock()
+	}
+}
+
+// disconnect removes a connection from the usage of the account, the usage is removed if the account
+// has no connections and no hourly counts left.
+func (u *accountUsage) disconnect() {
+	u.ur.mu.Lock()
+	defer u.ur.mu.Unlock()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.connections--
+	if u.idleLocked(time.Now()) && u.ur.accounts[u.account] == u {
+		delete(u.ur.accounts, u.account)
+	}
+}
+
+// idleLocked returns whether the account has no connections and no statements counted in the current hour,
+// u.mu must be held.
+func (u *accountUsage) idleLocked(now time.Time) bool {
+	return u.connections == 0 && (u.queries == 0 && u.updates == 0 || now.Sub(u.hourStart) >= time.Hour)
+}
+
+// hasHourlyLimits returns whether the statements of the account need to be counted.
+func (u *accountUsage) hasHourlyLimits() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.limits.MaxQueriesPerHour > 0 || u.limits.MaxUpdatesPerHour > 0
+}
+
+// checkStatements counts the statements of the account in the current hour, the statements are
+// refused and not counted if the account would exceed max_questions or max_updates.
+func (u *accountUsage) checkStatements(queries, updates uint64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if now := time.Now(); now.Sub(u.hourStart) >= time.Hour {
+		u.hourStart, u.queries, u.updates = now, 0, 0
+	}
+	if max := u.limits.MaxQueriesPerHour; max > 0 && u.queries+queries > max {
+		return errUserLimitReached.GenByArgs(u.userName(), "max_questions", max)
+	}
+	if max := u.limits.MaxUpdatesPerHour; max > 0 && u.updates+updates > max {
+		return errUserLimitReached.GenByArgs(u.userName(), "max_updates", max)
+	}
+	u.queries += queries
+	u.updates += updates
+	return nil
+}
+
+// checkStmts is the statement checker of the sessions of the account, it counts the statements
+// which are parsed by the session before they are executed.
+func (u *accountUsage) checkStmts(stmts []ast.StmtNode) error {
+	if !u.hasHourlyLimits() {
+		return nil
+	}
+	return errors.Trace(u.checkStatements(countStatements(stmts)))
+}
+
+// userName returns the user name of the account.
+func (u *accountUsage) userName() string {
+	if i := strings.LastIndex(u.account, "@"); i >= 0 {
+		return u.account[:i]
+	}
+	return u.account
+}
+
+// connectUser adds the connection of the authenticated user of ctx to the usage of its account and
+// counts the statements of ctx in the usage, the usage is nil if the authentication is skipped.
+func (s *Server) connectUser(ctx QueryCtx) (*accountUsage, error) {
+	if s.skipAuth() {
+		return nil, nil
+	}
+	limits, err := ctx.GetUserResources()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	usage, err := s.userResources.connect(limits)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ctx.SetStmtChecker(usage.checkStmts)
+	return usage, nil
+}
+
+// countStatements returns the number of the statements and the number of the statements which
+// modify tables or databases.
+func countStatements(stmts []ast.StmtNode) (queries, updates uint64) {
+	for _, stmt := range stmts {
+		if isUpdateStmt(stmt) {
+			updates++
+		}
+	}
+	return uint64(len(stmts)), updates
+}
+
+// isUpdateStmt returns whether the statement is counted by max_updates.
+func isUpdateStmt(stmt ast.StmtNode) bool {
+	switch stmt.(type) {
+	case ast.DDLNode, *ast.InsertStmt, *ast.UpdateStmt, *ast.DeleteStmt, *ast.LoadDataStmt,
+		*ast.GrantStmt, *ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.SetPwdStmt:
+		return true
+	}
+	return false
+}
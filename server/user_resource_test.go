@@ -0,0 +1,129 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
+)
+
+type UserResourceTestSuite struct{}
+
+var _ = Suite(UserResourceTestSuite{})
+
+func (ts UserResourceTestSuite) TestMaxUserConnections(c *C) {
+	ur := newUserResources(0)
+	limits := &variable.UserResources{Account: "u@%", MaxUserConnections: 2}
+	u1, err := ur.connect(limits)
+	c.Assert(err, IsNil)
+	c.Assert(u1.tokens, IsNil)
+	u2, err := ur.connect(limits)
+	c.Assert(err, IsNil)
+	c.Assert(u2, Equals, u1)
+	_, err = ur.connect(limits)
+	c.Assert(terror.ErrorEqual(err, errTooManyUserConnections), IsTrue)
+	c.Assert(err.Error(), Matches, ".*User u already has more than 'max_user_connections' active connections")
+
+	u1.disconnect()
+	_, err = ur.connect(limits)
+	c.Assert(err, IsNil)
+	// The limits are refreshed by the new connections.
+	_, err = ur.connect(&variable.UserResources{Account: "u@%"})
+	c.Assert(err, IsNil)
+	_, err = ur.connect(&variable.UserResources{Account: "u@localhost", MaxUserConnections: 1})
+	c.Assert(err, IsNil)
+}
+
+func (ts UserResourceTestSuite) TestHourlyLimits(c *C) {
+	ur := newUserResources(2)
+	u, err := ur.connect(&variable.UserResources{Account: "u@%"})
+	c.Assert(err, IsNil)
+	c.Assert(u.hasHourlyLimits(), IsFalse)
+	c.Assert(u.tokens.count, Equals, 2)
+
+	u, err = ur.connect(&variable.UserResources{Account: "u@%", MaxQueriesPerHour: 3, MaxUpdatesPerHour: 1})
+	c.Assert(err, IsNil)
+	c.Assert(u.hasHourlyLimits(), IsTrue)
+	c.Assert(u.checkStatements(1, 1), IsNil)
+	err = u.checkStatements(1, 1)
+	c.Assert(terror.ErrorEqual(err, errUserLimitReached), IsTrue)
+	c.Assert(err.Error(), Matches, ".*User 'u' has exceeded the 'max_updates' resource \\(current value: 1\\)")
+	// The refused statements are not counted.
+	c.Assert(u.checkStatements(2, 0), IsNil)
+	err = u.checkStatements(1, 0)
+	c.Assert(err.Error(), Matches, ".*User 'u' has exceeded the 'max_questions' resource \\(current value: 3\\)")
+
+	// The counters are reset in the next hour.
+	u.hourStart = u.hourStart.Add(-time.Hour)
+	c.Assert(u.checkStatements(1, 1), IsNil)
+}
+
+func (ts UserResourceTestSuite) TestCountStatements(c *C) {
+	tests := []struct {
+		sql     string
+		queries uint64
+		updates uint64
+	}{
+		{"select 1", 1, 0},
+		{"insert into t values (1)", 1, 1},
+		{"select 1; update t set a = 1; delete from t", 3, 2},
+		{"create table t (a int)", 1, 1},
+		{"grant select on *.* to 'u'@'%'", 1, 1},
+		{"set password = 'abc'", 1, 1},
+		{"begin; commit", 2, 0},
+	}
+	for _, t := range tests {
+		stmts, err := parser.New().Parse(t.sql, "", "")
+		c.Assert(err, IsNil)
+		queries, updates := countStatements(stmts)
+		c.Assert(queries, Equals, t.queries, Commentf("%s", t.sql))
+		c.Assert(updates, Equals, t.updates, Commentf("%s", t.sql))
+	}
+}
+
+func (ts UserResourceTestSuite) TestPruneAccounts(c *C) {
+	ur := newUserResources(2)
+	limits := &variable.UserResources{Account: "u@%", MaxQueriesPerHour: 10}
+	u1, err := ur.connect(limits)
+	c.Assert(err, IsNil)
+	u2, err := ur.connect(limits)
+	c.Assert(err, IsNil)
+	// The account without connections and hourly counts is removed.
+	u1.disconnect()
+	c.Assert(ur.accounts, HasLen, 1)
+	u2.disconnect()
+	c.Assert(ur.accounts, HasLen, 0)
+
+	// The account is kept until its hourly counts expire.
+	u, err := ur.connect(limits)
+	c.Assert(err, IsNil)
+	c.Assert(u.checkStatements(1, 0), IsNil)
+	u.disconnect()
+	c.Assert(ur.accounts, HasLen, 1)
+	u, err = ur.connect(limits)
+	c.Assert(err, IsNil)
+	err = u.checkStatements(10, 0)
+	c.Assert(terror.ErrorEqual(err, errUserLimitReached), IsTrue)
+	u.disconnect()
+	u.hourStart = u.hourStart.Add(-time.Hour)
+	ur.lastPrune = ur.lastPrune.Add(-pruneInterval)
+	_, err = ur.connect(&variable.UserResources{Account: "u2@%"})
+	c.Assert(err, IsNil)
+	c.Assert(ur.accounts, HasLen, 1)
+	c.Assert(ur.accounts["u2@%"], NotNil)
+}
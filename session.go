@@ -21,8 +21,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
@@ -43,8 +45,12 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-binlog"
 )
@@ -68,6 +74,15 @@ type Session interface {
 	SetConnectionID(uint64)
 	Close() error
 	Auth(user string, auth []byte, salt []byte) bool
+	GetAuthPlugin(user string) (string, error)
+	AuthCleartext(user string, password []byte) bool
+	AuthCachingSha2(user string, auth []byte, salt []byte) bool
+	AuthTrusted(user string) bool
+	GetPGPassword(user string, method string) (string, error)
+	GetUserResources() (*variable.UserResources, error)
+	// SetStmtChecker sets the checker which is called with the parsed statements before they are executed.
+	SetStmtChecker(checker func(stmts []ast.StmtNode) error)
+	ShowProcess() util.ProcessInfo // The information of the current or the last statement for SHOW PROCESSLIST.
 }
 
 var (
@@ -103,11 +118,52 @@ type session struct {
 	// Used for test only.
 	unlimitedRetryCount bool
 
-	// For performance_schema only.
-	stmtState *perfschema.StatementState
-	parser    *parser.Parser
+	// committedTxn is the transaction committed by the last doCommit, it's recorded to the performance schema.
+	committedTxn kv.Transaction
+
+	parser *parser.Parser
 
 	sessionVars *variable.SessionVars
+
+	// stmtChecker refuses the statements before they are executed if it's not nil.
+	stmtChecker func(stmts []ast.StmtNode) error
+
+	// processInfo is the *processState of the current or the last statement, it's read by other sessions.
+	processInfo atomic.Value
+}
+
+// processState is the state of a statement shown by SHOW PROCESSLIST.
+type processState struct {
+	info       util.ProcessInfo
+	memTracker *memory.Tracker
+}
+
+// setProcessInfo records the statement which is executing for SHOW PROCESSLIST.
+func (s *session) setProcessInfo(sql string) {
+	s.processInfo.Store(&processState{
+		info: util.ProcessInfo{
+			ID:      s.sessionVars.ConnectionID,
+			User:    s.sessionVars.User,
+			DB:      s.sessionVars.CurrentDB,
+			Command: "Query",
+			Time:    time.Now(),
+			Info:    sql,
+		},
+		memTracker: s.sessionVars.StmtCtx.MemTracker,
+	})
+}
+
+// ShowProcess implements Session ShowProcess interface.
+func (s *session) ShowProcess() util.ProcessInfo {
+	ps, ok := s.processInfo.Load().(*processState)
+	if !ok {
+		return util.ProcessInfo{}
+	}
+	pi := ps.info
+	if ps.memTracker != nil {
+		pi.Mem = ps.memTracker.MaxConsumed()
+	}
+	return pi
 }
 
 func (s *session) cleanRetryInfo() {
@@ -183,6 +239,7 @@ func (s *session) doCommit() error {
 		return nil
 	}
 	defer func() {
+		s.committedTxn = s.txn
 		s.txn = nil
 		s.sessionVars.SetStatusFlag(mysql.ServerStatusInTrans, false)
 	}()
@@ -206,6 +263,10 @@ func (s *session) doCommit() error {
 		SchemaValidator: sessionctx.GetDomain(s).SchemaValidator,
 		schemaVer:       s.sessionVars.TxnCtx.SchemaVersion,
 	})
+	// Set this option for 2 phase commit to trace it in the statement that commits the transaction.
+	if span := s.sessionVars.StmtCtx.Span; span != nil {
+		s.txn.SetOption(kv.TraceSpan, span)
+	}
 	if err := s.txn.Commit(); err != nil {
 		return errors.Trace(err)
 	}
@@ -217,21 +278,34 @@ func (s *session) doCommitWithRetry() error {
 	if s.txn != nil && s.txn.Valid() {
 		txnSize = s.txn.Size()
 	}
+	autocommit := s.sessionVars.TxnCtx.Autocommit
+	if s.txn != nil && !s.txn.Valid() {
+		// The transaction is rolled back by the ROLLBACK statement.
+		s.recordTransaction(s.txn, false, autocommit, 0)
+		s.txn = nil
+		return nil
+	}
+	s.committedTxn = nil
 	err := s.doCommit()
+	var retryCnt int
 	if err != nil {
 		if s.isRetryableError(err) {
 			// Transactions will retry 2 ~ 10 times.
 			// We make larger transactions retry less times to prevent cluster resource outage.
 			txnSizeRate := float64(txnSize) / float64(kv.TxnTotalSizeLimit)
 			maxRetryCount := 10 - int(txnSizeRate*9.0)
-			err = s.retry(maxRetryCount)
+			retryCnt, err = s.retry(maxRetryCount)
 		}
 	}
 	s.cleanRetryInfo()
+	s.recordTransaction(s.committedTxn, err == nil, autocommit, retryCnt)
+	s.committedTxn = nil
 	if err != nil {
 		log.Warnf("[%d] finished txn:%v, %v", s.sessionVars.ConnectionID, s.txn, err)
 		return errors.Trace(err)
 	}
+	// The transaction context of the last retry holds the rows changed by the committed transaction.
+	tables.CountTxnRowsChanged(s.sessionVars.TxnCtx)
 	return nil
 }
 
@@ -243,6 +317,7 @@ func (s *session) RollbackTxn() error {
 	var err error
 	if s.txn != nil && s.txn.Valid() {
 		err = s.txn.Rollback()
+		s.recordTransaction(s.txn, false, s.sessionVars.TxnCtx.Autocommit, 0)
 	}
 	s.cleanRetryInfo()
 	s.txn = nil
@@ -251,6 +326,29 @@ func (s *session) RollbackTxn() error {
 	return errors.Trace(err)
 }
 
+// recordTransaction records the committed or rolled back transaction to the performance schema.
+func (s *session) recordTransaction(txn kv.Transaction, committed, autocommit bool, retryCnt int) {
+	if txn == nil || !perfschema.Enabled() {
+		return
+	}
+	startTS := txn.StartTS()
+	stats := &perfschema.TransactionStats{
+		ConnID:      s.sessionVars.ConnectionID,
+		StartTS:     startTS,
+		StartTime:   time.Unix(0, oracle.ExtractPhysical(startTS)*int64(time.Millisecond)),
+		EndTime:     time.Now(),
+		Committed:   committed,
+		ReadOnly:    txn.IsReadOnly(),
+		Autocommit:  autocommit,
+		RetryCount:  uint64(retryCnt),
+		KeysWritten: uint64(txn.Len()),
+	}
+	if committed {
+		stats.CommitTS = txn.CommitTS()
+	}
+	sessionctx.GetDomain(s).PerfSchema().RecordTransaction(stats)
+}
+
 func (s *session) GetClient() kv.Client {
 	return s.store.GetClient()
 }
@@ -288,10 +386,11 @@ func (s *session) isRetryableError(err error) bool {
 	return kv.IsRetryableError(err) || terror.ErrorEqual(err, domain.ErrInfoSchemaChanged)
 }
 
-func (s *session) retry(maxCnt int) error {
+// retry re-executes the statements of the transaction and commits it, it returns the number of the retries.
+func (s *session) retry(maxCnt int) (int, error) {
 	connID := s.sessionVars.ConnectionID
 	if s.sessionVars.TxnCtx.ForUpdate {
-		return errors.Errorf("[%d] can not retry select for update statement", connID)
+		return 0, errors.Errorf("[%d] can not retry select for update statement", connID)
 	}
 	s.sessionVars.RetryInfo.Retrying = true
 	retryCnt := 0
@@ -321,16 +420,16 @@ func (s *session) retry(maxCnt int) error {
 		}
 		if !s.isRetryableError(err) {
 			log.Warnf("[%d] session:%v, err:%v", connID, s, err)
-			return errors.Trace(err)
+			return retryCnt, errors.Trace(err)
 		}
 		retryCnt++
 		if !s.unlimitedRetryCount && (retryCnt >= maxCnt) {
 			log.Warnf("[%id] Retry reached max count %d", connID, retryCnt)
-			return errors.Trace(err)
+			return retryCnt, errors.Trace(err)
 		}
 		kv.BackOff(retryCnt)
 	}
-	return err
+	return retryCnt, err
 }
 
 func sqlForLog(sql string) string {
@@ -432,7 +531,7 @@ func (s *session) ParseSQL(sql, charset, collation string) ([]ast.StmtNode, erro
 
 func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 	s.prepareTxnCtx()
-	startTS := time.Now()
+	parseStartTS := time.Now()
 	charset, collation := s.sessionVars.GetCharsetInfo()
 	connID := s.sessionVars.ConnectionID
 	rawStmts, err := s.ParseSQL(sql, charset, collation)
@@ -440,29 +539,54 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 		log.Warnf("[%d] parse error:\n%v\n%s", connID, err, sql)
 		return nil, errors.Trace(err)
 	}
-	sessionExecuteParseDuration.Observe(time.Since(startTS).Seconds())
+	parseTime := time.Since(parseStartTS)
+	sessionExecuteParseDuration.Observe(parseTime.Seconds())
+	if s.stmtChecker != nil {
+		if err = s.stmtChecker(rawStmts); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 
 	var rs []ast.RecordSet
-	ph := sessionctx.GetDomain(s).PerfSchema()
 	for i, rst := range rawStmts {
 		s.prepareTxnCtx()
 		startTS := time.Now()
 		// Some execution is done in compile stage, so we reset it before compile.
 		resetStmtCtx(s, rawStmts[0])
+		if _, ok := rst.(*ast.TraceStmt); ok || tracing.Exporting() {
+			// The statements are parsed together, the parse span is recorded in the first one.
+			if i == 0 {
+				span := tracing.NewRootSpan("session.Execute", parseStartTS)
+				span.AddChild("session.ParseSQL", parseStartTS, parseTime)
+				s.sessionVars.StmtCtx.Span = span
+			} else {
+				s.sessionVars.StmtCtx.Span = tracing.NewRootSpan("session.Execute", startTS)
+			}
+		}
+		if i == 0 {
+			// The statements are parsed together, the parse time is recorded in the first one.
+			s.sessionVars.StmtCtx.ParseTime = parseTime
+		}
 		st, err1 := Compile(s, rst)
 		if err1 != nil {
 			log.Warnf("[%d] compile error:\n%v\n%s", connID, err1, sql)
+			s.sessionVars.StmtCtx.Span.Finish()
 			s.RollbackTxn()
 			return nil, errors.Trace(err1)
 		}
-		sessionExecuteCompileDuration.Observe(time.Since(startTS).Seconds())
+		compileTime := time.Since(startTS)
+		sessionExecuteCompileDuration.Observe(compileTime.Seconds())
+		s.sessionVars.StmtCtx.CompileTime = compileTime
 
-		s.stmtState = ph.StartStatement(sql, connID, perfschema.CallerNameSessionExecute, rawStmts[i])
 		s.SetValue(context.QueryString, st.OriginText())
+		s.setProcessInfo(st.OriginText())
 
 		startTS = time.Now()
 		r, err := runStmt(s, st)
-		ph.EndStatement(s.stmtState)
+		if r == nil || err != nil {
+			// The result set finishes the span when it's closed.
+			s.sessionVars.StmtCtx.Span.Finish()
+		}
 		if err != nil {
 			log.Warnf("[%d] session error:\n%v\n%s", connID, err, s)
 			return nil, errors.Trace(err)
@@ -492,7 +616,7 @@ func (s *session) PrepareStmt(sql string) (stmtID uint32, paramCount int, fields
 		SQLText: sql,
 	}
 	prepareExec.DoPrepare()
-	return prepareExec.ID, prepareExec.ParamCount, nil, prepareExec.Err
+	return prepareExec.ID, prepareExec.ParamCount, prepareExec.Fields, prepareExec.Err
 }
 
 // checkArgs makes sure all the arguments' types are known and can be handled.
@@ -548,8 +672,24 @@ func (s *session) ExecutePreparedStmt(stmtID uint32, args ...interface{}) (ast.R
 		return nil, errors.Trace(err)
 	}
 	s.prepareTxnCtx()
+	if prepared, ok := s.sessionVars.PreparedStmts[stmtID].(*executor.Prepared); ok {
+		if s.stmtChecker != nil {
+			if err = s.stmtChecker([]ast.StmtNode{prepared.Stmt}); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		resetStmtCtx(s, prepared.Stmt)
+		s.setProcessInfo(prepared.Stmt.Text())
+		if tracing.Exporting() {
+			s.sessionVars.StmtCtx.Span = tracing.NewRootSpan("session.ExecutePreparedStmt", time.Now())
+		}
+	}
 	st := executor.CompileExecutePreparedStmt(s, stmtID, args...)
 	r, err := runStmt(s, st)
+	if r == nil || err != nil {
+		// The result set finishes the span when it's closed.
+		s.sessionVars.StmtCtx.Span.Finish()
+	}
 	return r, errors.Trace(err)
 }
 
@@ -588,6 +728,7 @@ func (s *session) GetTxn(forceNew bool) (kv.Transaction, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	s.sessionVars.TxnCtx.Autocommit = !s.sessionVars.InTxn()
 	ac := s.sessionVars.IsAutocommit()
 	if !ac {
 		s.sessionVars.SetStatusFlag(mysql.ServerStatusInTrans, true)
@@ -638,37 +779,61 @@ func (s *session) GetSessionVars() *variable.SessionVars {
 	return s.sessionVars
 }
 
-func (s *session) getPassword(name, host string) (string, error) {
-	// Get password for name and host.
-	authSQL := fmt.Sprintf("SELECT Password FROM %s.%s WHERE User='%s' and Host='%s';", mysql.SystemDB, mysql.UserTable, name, host)
-	pwd, err := s.getExecRet(s, authSQL)
+// getUserTableValue gets the column value of the mysql.user row which matches name and host.
+func (s *session) getUserTableValue(column, name, host string) (string, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s.%s WHERE User='%s' and Host='%s';", column, mysql.SystemDB, mysql.UserTable, name, host)
+	value, err := s.getExecRet(s, sql)
 	if err == nil {
-		return pwd, nil
+		return value, nil
 	} else if !terror.ExecResultIsEmpty.Equal(err) {
 		return "", errors.Trace(err)
 	}
-	//Try to get user password for name with any host(%).
-	authSQL = fmt.Sprintf("SELECT Password FROM %s.%s WHERE User='%s' and Host='%%';", mysql.SystemDB, mysql.UserTable, name)
-	pwd, err = s.getExecRet(s, authSQL)
+	//Try to get the value for name with any host(%).
+	sql = fmt.Sprintf("SELECT %s FROM %s.%s WHERE User='%s' and Host='%%';", column, mysql.SystemDB, mysql.UserTable, name)
+	value, err = s.getExecRet(s, sql)
+	return value, errors.Trace(err)
+}
+
+func (s *session) getPassword(name, host string) (string, error) {
+	// Get password for name and host.
+	pwd, err := s.getUserTableValue("Password", name, host)
 	return pwd, errors.Trace(err)
 }
 
-func (s *session) Auth(user string, auth []byte, salt []byte) bool {
+// checkSSLType checks whether the connection meets the REQUIRE clause of the account.
+func (s *session) checkSSLType(name, host string) bool {
+	sslType, err := s.getUserTableValue("Ssl_type", name, host)
+	if err != nil {
+		log.Errorf("Get User [%s] ssl_type from SystemDB error %v", name, err)
+		return false
+	}
+	state := s.sessionVars.TLSConnectionState
+	switch sslType {
+	case "":
+		return true
+	case "ANY":
+		return state != nil
+	case "X509":
+		return state != nil && len(state.PeerCertificates) > 0
+	default:
+		log.Errorf("User [%s] ssl_type %s is not supported", name, sslType)
+		return false
+	}
+}
+
+// splitUser splits user in the format of "name@host" into name and host.
+func splitUser(user string) (name, host string, ok bool) {
 	strs := strings.Split(user, "@")
 	if len(strs) != 2 {
 		log.Warnf("Invalid format for user: %s", user)
-		return false
+		return "", "", false
 	}
-	// Get user password.
-	name := strs[0]
-	host := strs[1]
-
-	// TODO: Use the new privilege implementation.
-	domain := sessionctx.GetDomain(s)
-	checker := domain.Privilege()
-	succ := checker.ConnectionVerification(name, host)
-	log.Debug("RequestVerification result:", succ)
+	return strs[0], strs[1], true
+}
 
+// getStoredPassword gets the password of the user from mysql.user, it returns false if the user
+// doesn't exist or the password is malformed.
+func (s *session) getStoredPassword(name, host string) (string, bool) {
 	pwd, err := s.getPassword(name, host)
 	if err != nil {
 		if terror.ExecResultIsEmpty.Equal(err) {
@@ -676,10 +841,39 @@ func (s *session) Auth(user string, auth []byte, salt []byte) bool {
 		} else {
 			log.Errorf("Get User [%s] password from SystemDB error %v", name, err)
 		}
-		return false
+		return "", false
 	}
 	if len(pwd) != 0 && len(pwd) != 40 {
 		log.Errorf("User [%s] password from SystemDB not like a sha1sum", name)
+		return "", false
+	}
+	return pwd, true
+}
+
+// finishAuth checks the REQUIRE clause of the authenticated user and sets the session user.
+func (s *session) finishAuth(user, name, host string) bool {
+	if !s.checkSSLType(name, host) {
+		log.Errorf("User [%s] connection does not meet the REQUIRE clause", name)
+		return false
+	}
+	s.sessionVars.User = user
+	return true
+}
+
+func (s *session) Auth(user string, auth []byte, salt []byte) bool {
+	name, host, ok := splitUser(user)
+	if !ok {
+		return false
+	}
+
+	// TODO: Use the new privilege implementation.
+	domain := sessionctx.GetDomain(s)
+	checker := domain.Privilege()
+	succ := checker.ConnectionVerification(name, host)
+	log.Debug("RequestVerification result:", succ)
+
+	pwd, ok := s.getStoredPassword(name, host)
+	if !ok {
 		return false
 	}
 	hpwd, err := util.DecodePassword(pwd)
@@ -691,9 +885,144 @@ func (s *session) Auth(user string, auth []byte, salt []byte) bool {
 	if !bytes.Equal(auth, checkAuth) {
 		return false
 	}
-	s.sessionVars.User = user
+	return s.finishAuth(user, name, host)
+}
 
-	return true
+// GetAuthPlugin gets the authentication plugin of the user.
+func (s *session) GetAuthPlugin(user string) (string, error) {
+	name, host, ok := splitUser(user)
+	if !ok {
+		return "", errors.Errorf("invalid format for user: %s", user)
+	}
+	plugin, err := s.getUserTableValue("plugin", name, host)
+	return plugin, errors.Trace(err)
+}
+
+// sha2PasswordCache caches SHA256(SHA256(password)) of the users who passed the full authentication
+// of caching_sha2_password. The stored password is a part of the key, so the entry of a user is
+// not used any more after the password is changed.
+var sha2PasswordCache = struct {
+	sync.RWMutex
+	m map[string][]byte
+}{m: make(map[string][]byte)}
+
+func sha2PasswordCacheKey(user, pwd string) string {
+	return user + "\x00" + pwd
+}
+
+// AuthCleartext verifies the cleartext password of the user.
+func (s *session) AuthCleartext(user string, password []byte) bool {
+	name, host, ok := splitUser(user)
+	if !ok {
+		return false
+	}
+	pwd, ok := s.getStoredPassword(name, host)
+	if !ok {
+		return false
+	}
+	if util.EncodePassword(string(password)) != pwd {
+		return false
+	}
+	sha2PasswordCache.Lock()
+	sha2PasswordCache.m[sha2PasswordCacheKey(user, pwd)] = util.Sha256Hash(util.Sha256Hash(password))
+	sha2PasswordCache.Unlock()
+	return s.finishAuth(user, name, host)
+}
+
+// AuthCachingSha2 verifies the caching_sha2_password scramble of the user with the cached password hash,
+// it returns false if the user has not passed the full authentication since the password is set.
+func (s *session) AuthCachingSha2(user string, auth []byte, salt []byte) bool {
+	name, host, ok := splitUser(user)
+	if !ok {
+		return false
+	}
+	pwd, ok := s.getStoredPassword(name, host)
+	if !ok {
+		return false
+	}
+	sha2PasswordCache.RLock()
+	sha2pwd, ok := sha2PasswordCache.m[sha2PasswordCacheKey(user, pwd)]
+	sha2PasswordCache.RUnlock()
+	if !ok || !util.CheckSha2Scramble(auth, salt, sha2pwd) {
+		return false
+	}
+	return s.finishAuth(user, name, host)
+}
+
+// AuthTrusted logs in the user whose identity has been verified by the authentication plugin without
+// a password, such as auth_socket.
+func (s *session) AuthTrusted(user string) bool {
+	name, host, ok := splitUser(user)
+	if !ok {
+		return false
+	}
+	if _, ok = s.getStoredPassword(name, host); !ok {
+		return false
+	}
+	return s.finishAuth(user, name, host)
+}
+
+// GetPGPassword gets the password verifier of the user for the authentication method of the PostgreSQL protocol.
+func (s *session) GetPGPassword(user string, method string) (string, error) {
+	name, host, ok := splitUser(user)
+	if !ok {
+		return "", errors.Errorf("invalid format for user: %s", user)
+	}
+	var column string
+	switch method {
+	case util.PGAuthMD5:
+		column = "pg_md5_password"
+	case util.PGAuthScramSha256:
+		column = "pg_scram_password"
+	default:
+		return "", errors.Errorf("unknown authentication method %s", method)
+	}
+	pwd, err := s.getUserTableValue(column, name, host)
+	return pwd, errors.Trace(err)
+}
+
+// GetUserResources gets the resource limits of the account of the session user. The limits are
+// cached by the domain, so the connections don't query all the limits on every handshake.
+func (s *session) GetUserResources() (*variable.UserResources, error) {
+	name, host, ok := splitUser(s.sessionVars.User)
+	if !ok {
+		return nil, errors.Errorf("invalid format for user: %s", s.sessionVars.User)
+	}
+	// The user may be matched by the account of any host(%), the usage is shared by the account.
+	accountHost, err := s.getUserTableValue("Host", name, host)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	account := fmt.Sprintf("%s@%s", name, accountHost)
+	do := sessionctx.GetDomain(s)
+	if res, ok := do.GetUserResources(account); ok {
+		return res, nil
+	}
+	res := &variable.UserResources{Account: account}
+	limits := []struct {
+		column string
+		value  *uint64
+	}{
+		{"max_questions", &res.MaxQueriesPerHour},
+		{"max_updates", &res.MaxUpdatesPerHour},
+		{"max_user_connections", &res.MaxUserConnections},
+	}
+	for _, limit := range limits {
+		value, err := s.getUserTableValue(limit.column, name, host)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if *limit.value, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	do.SetUserResources(res)
+	return res, nil
+}
+
+// SetStmtChecker implements Session SetStmtChecker interface.
+func (s *session) SetStmtChecker(checker func(stmts []ast.StmtNode) error) {
+	s.stmtChecker = checker
 }
 
 // Some vars name for debug.
@@ -786,7 +1115,7 @@ func createSession(store kv.Storage) (*session, error) {
 
 const (
 	notBootstrapped         = 0
-	currentBootstrapVersion = 3
+	currentBootstrapVersion = 8
 )
 
 func getStoreBootstrapVersion(store kv.Storage) int64 {
@@ -835,6 +1164,7 @@ const loadCommonGlobalVarsSQL = "select * from mysql.global_variables where vari
 	variable.SQLModeVar + "', '" +
 	variable.DistSQLJoinConcurrencyVar + "', '" +
 	variable.MaxAllowedPacket + "', '" +
+	variable.MaxExecutionTime + "', '" +
 	variable.DistSQLScanConcurrencyVar + "')"
 
 // LoadCommonGlobalVariableIfNeeded loads and applies commonly used global variables for the session.
@@ -918,6 +1248,7 @@ func (s *session) ActivePendingTxn() error {
 		return errors.Trace(txnWithErr.err)
 	}
 	s.txn = txnWithErr.txn
+	s.sessionVars.TxnCtx.Autocommit = !s.sessionVars.InTxn()
 	err := s.loadCommonGlobalVariablesIfNeeded()
 	if err != nil {
 		return errors.Trace(err)
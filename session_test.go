@@ -20,7 +20,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/kv"
@@ -28,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/terror"
@@ -841,7 +844,7 @@ func (s *testSessionSuite) TestSelectForUpdate(c *C) {
 
 	_, err = exec(se1, "commit")
 	c.Assert(err, NotNil)
-	err = se1.(*session).retry(10)
+	_, err = se1.(*session).retry(10)
 	// retry should fail
 	c.Assert(err, NotNil)
 
@@ -2067,6 +2070,74 @@ func (s *testSessionSuite) TestSessionAuth(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *testSessionSuite) TestUserResourcesCache(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStoreWithBootstrap(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+	defer se.Close()
+	mustExecSQL(c, se, "CREATE USER 'res_cache'@'%' WITH MAX_USER_CONNECTIONS 1")
+	se.(*session).sessionVars.User = "res_cache@localhost"
+	res, err := se.GetUserResources()
+	c.Assert(err, IsNil)
+	c.Assert(*res, Equals, variable.UserResources{Account: "res_cache@%", MaxUserConnections: 1})
+
+	// The limits are cached by the account, whatever the host of the user is.
+	do := sessionctx.GetDomain(se)
+	cached, ok := do.GetUserResources("res_cache@%")
+	c.Assert(ok, IsTrue)
+	c.Assert(*cached, Equals, *res)
+	se.(*session).sessionVars.User = "res_cache@127.0.0.1"
+	res, err = se.GetUserResources()
+	c.Assert(err, IsNil)
+	c.Assert(res.Account, Equals, "res_cache@%")
+
+	// The cached limits are cleared when the account is altered.
+	mustExecSQL(c, se, "ALTER USER 'res_cache'@'%' WITH MAX_USER_CONNECTIONS 2")
+	_, ok = do.GetUserResources("res_cache@%")
+	c.Assert(ok, IsFalse)
+	res, err = se.GetUserResources()
+	c.Assert(err, IsNil)
+	c.Assert(res.MaxUserConnections, Equals, uint64(2))
+
+	mustExecSQL(c, se, "DROP USER 'res_cache'@'%'")
+	_, ok = do.GetUserResources("res_cache@%")
+	c.Assert(ok, IsFalse)
+
+	err = store.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *testSessionSuite) TestStmtChecker(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStoreWithBootstrap(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+	defer se.Close()
+	mustExecSQL(c, se, "drop table if exists t_checker")
+	mustExecSQL(c, se, "create table t_checker (a int)")
+	var checked []int
+	errRefused := errors.New("refused")
+	se.SetStmtChecker(func(stmts []ast.StmtNode) error {
+		checked = append(checked, len(stmts))
+		if _, ok := stmts[0].(*ast.InsertStmt); ok {
+			return errRefused
+		}
+		return nil
+	})
+	mustExecSQL(c, se, "select 1; select 2")
+	_, err := se.Execute("insert into t_checker values (1)")
+	c.Assert(errors.Cause(err), Equals, errRefused)
+	id, _, _, err := se.PrepareStmt("insert into t_checker values (?)")
+	c.Assert(err, IsNil)
+	_, err = se.ExecutePreparedStmt(id, 1)
+	c.Assert(errors.Cause(err), Equals, errRefused)
+	c.Assert(checked, DeepEquals, []int{2, 1, 1})
+	se.SetStmtChecker(nil)
+	mustExecMatch(c, se, "select count(*) from t_checker", [][]interface{}{{0}})
+
+	err = store.Close()
+	c.Assert(err, IsNil)
+}
+
 func (s *testSessionSuite) TestErrorRollback(c *C) {
 	defer testleak.AfterTest(c)()
 	store := newStoreWithBootstrap(c, s.dbName)
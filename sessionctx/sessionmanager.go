@@ -0,0 +1,43 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionctx
+
+import (
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util"
+)
+
+// A dummy type to avoid naming collision in context.
+type sessionManagerKeyType int
+
+// String defines a Stringer function for debugging and pretty printing.
+func (k sessionManagerKeyType) String() string {
+	return "session manager"
+}
+
+const sessionManagerKey sessionManagerKeyType = 0
+
+// BindSessionManager binds the session manager to context.
+func BindSessionManager(ctx context.Context, sm util.SessionManager) {
+	ctx.SetValue(sessionManagerKey, sm)
+}
+
+// GetSessionManager gets the session manager from context, it returns nil if the session isn't created by a server.
+func GetSessionManager(ctx context.Context) util.SessionManager {
+	sm, ok := ctx.Value(sessionManagerKey).(util.SessionManager)
+	if !ok {
+		return nil
+	}
+	return sm
+}
@@ -14,13 +14,17 @@
 package variable
 
 import (
+	"crypto/tls"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/tracing"
 )
 
 const (
@@ -82,6 +86,20 @@ type TransactionContext struct {
 	InfoSchema    interface{}
 	Histroy       interface{}
 	SchemaVersion int64
+	// Autocommit is true if the transaction is started by a statement in autocommit mode.
+	Autocommit bool
+	// TableDeltas are the rows changed by the transaction by the table IDs, they are added to the
+	// table statistics after the transaction is committed.
+	TableDeltas map[int64]*TableDelta
+}
+
+// TableDelta is the number of the rows changed by a transaction in a table.
+type TableDelta struct {
+	Inserted uint64
+	Updated  uint64
+	Deleted  uint64
+	// ChangedXIndexes is the number of the changed rows multiplied by the number of the indices.
+	ChangedXIndexes uint64
 }
 
 // SessionVars is to handle user-defined or global variables in current session.
@@ -108,6 +126,9 @@ type SessionVars struct {
 	// Client capability
 	ClientCapability uint32
 
+	// TLSConnectionState is the TLS state of the client connection, it is nil if the connection is not secured.
+	TLSConnectionState *tls.ConnectionState
+
 	// Connection ID
 	ConnectionID uint64
 
@@ -153,6 +174,32 @@ type SessionVars struct {
 	// Per-connection time zones. Each client that connects has its own time zone setting, given by the session time_zone variable.
 	// See https://dev.mysql.com/doc/refman/5.7/en/time-zone-support.html
 	TimeZone *time.Location
+
+	// MaxExecutionTime is the timeout of the SELECT statements in milliseconds, 0 means no timeout.
+	// See https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html#sysvar_max_execution_time
+	MaxExecutionTime uint64
+
+	// MemQuotaQuery is the memory quota of a statement in bytes, 0 means no quota.
+	MemQuotaQuery int64
+	// MemOOMAction is the action when a statement exceeds MemQuotaQuery.
+	MemOOMAction string
+
+	// SlowLogThreshold is the execution time in milliseconds above which a statement is logged as a slow query.
+	SlowLogThreshold uint64
+
+	// killed is set to 1 by Kill, it's accessed atomically.
+	killed uint32
+}
+
+// Kill aborts the running statement of the session and the statements after it, it's called by other
+// goroutines when the connection of the session is being closed.
+func (s *SessionVars) Kill() {
+	atomic.StoreUint32(&s.killed, 1)
+}
+
+// Killed returns whether the session has been killed.
+func (s *SessionVars) Killed() bool {
+	return atomic.LoadUint32(&s.killed) == 1
 }
 
 // NewSessionVars creates a session vars object.
@@ -167,6 +214,8 @@ func NewSessionVars() *SessionVars {
 		StrictSQLMode:        true,
 		Status:               mysql.ServerStatusAutocommit,
 		StmtCtx:              new(StatementContext),
+		MemOOMAction:         OOMActionLog,
+		SlowLogThreshold:     defaultSlowLogThreshold,
 	}
 }
 
@@ -235,6 +284,9 @@ const (
 	CharacterSetResults = "character_set_results"
 	MaxAllowedPacket    = "max_allowed_packet"
 	TimeZone            = "time_zone"
+	MaxExecutionTime    = "max_execution_time"
+	// GeneralLog toggles the general query log of the server, it's a global variable that takes effect immediately.
+	GeneralLog = "general_log"
 )
 
 // GetTiDBSystemVar gets variable value for name.
@@ -254,6 +306,15 @@ func (s *SessionVars) GetTiDBSystemVar(name string) (string, error) {
 	return SysVars[key].Value, nil
 }
 
+// UserResources is the resource limits of an account in mysql.user, 0 means no limit.
+type UserResources struct {
+	// Account is the matched account in the "user@host" format.
+	Account            string
+	MaxQueriesPerHour  uint64
+	MaxUpdatesPerHour  uint64
+	MaxUserConnections uint64
+}
+
 // StatementContext contains variables for a statement.
 // It should be reset before executing a statement.
 type StatementContext struct {
@@ -261,6 +322,15 @@ type StatementContext struct {
 	InUpdateOrDeleteStmt bool
 	IgnoreTruncate       bool
 	TruncateAsWarning    bool
+	// Deadline is the time when the statement is aborted by max_execution_time, it's zero if there is no timeout.
+	Deadline time.Time
+	// MemTracker tracks the memory usage of the statement, the trackers of the executors are attached to it.
+	MemTracker *memory.Tracker
+	// ParseTime and CompileTime are the time spent on parsing and compiling the statement.
+	ParseTime   time.Duration
+	CompileTime time.Duration
+	// Span is the root span of the statement if the statement is traced, the spans of the statement are its children.
+	Span *tracing.Span
 
 	/* Variables that changes during execution. */
 	mu struct {
@@ -269,6 +339,33 @@ type StatementContext struct {
 		foundRows    uint64
 		warnings     []error
 	}
+	copWaitTime   int64 // accessed atomically
+	processedKeys int64 // accessed atomically
+}
+
+// AddCopWaitTime adds the time spent on waiting for the coprocessor responses.
+func (sc *StatementContext) AddCopWaitTime(d time.Duration) {
+	atomic.AddInt64(&sc.copWaitTime, int64(d))
+}
+
+// CopWaitTime returns the time spent on waiting for the coprocessor responses.
+func (sc *StatementContext) CopWaitTime() time.Duration {
+	return time.Duration(atomic.LoadInt64(&sc.copWaitTime))
+}
+
+// AddProcessedKeys adds the number of keys read from the storage.
+func (sc *StatementContext) AddProcessedKeys(n int64) {
+	atomic.AddInt64(&sc.processedKeys, n)
+}
+
+// ProcessedKeys returns the number of keys read from the storage.
+func (sc *StatementContext) ProcessedKeys() int64 {
+	return atomic.LoadInt64(&sc.processedKeys)
+}
+
+// DeadlineExceeded returns whether the statement runs out of its execution time.
+func (sc *StatementContext) DeadlineExceeded() bool {
+	return !sc.Deadline.IsZero() && !time.Now().Before(sc.Deadline)
 }
 
 // AddAffectedRows adds affected rows.
@@ -14,10 +14,15 @@
 package variable
 
 import (
+	"sync"
+
 	"github.com/juju/errors"
 )
 
-var statisticsList []Statistics
+var statisticsList struct {
+	sync.RWMutex
+	list []Statistics
+}
 var globalStatusScopes = make(map[string]ScopeFlag)
 
 // DefaultScopeFlag is the status default scope.
@@ -33,21 +38,38 @@ type StatusVal struct {
 type Statistics interface {
 	// GetScope gets the status variables scope.
 	GetScope(status string) ScopeFlag
-	// Stats returns the statistics status variables.
-	Stats() (map[string]interface{}, error)
+	// Stats returns the statistics status variables, vars is the session variables of the querying session.
+	Stats(vars *SessionVars) (map[string]interface{}, error)
 }
 
 // RegisterStatistics registers statistics.
 func RegisterStatistics(s Statistics) {
-	statisticsList = append(statisticsList, s)
+	statisticsList.Lock()
+	defer statisticsList.Unlock()
+	statisticsList.list = append(statisticsList.list, s)
+}
+
+// UnregisterStatistics unregisters the statistics registered by RegisterStatistics.
+func UnregisterStatistics(s Statistics) {
+	statisticsList.Lock()
+	defer statisticsList.Unlock()
+	for i, registered := range statisticsList.list {
+		if registered == s {
+			statisticsList.list = append(statisticsList.list[:i:i], statisticsList.list[i+1:]...)
+			return
+		}
+	}
 }
 
 // GetStatusVars gets registered statistics status variables.
-func GetStatusVars() (map[string]*StatusVal, error) {
+func GetStatusVars(vars *SessionVars) (map[string]*StatusVal, error) {
 	statusVars := make(map[string]*StatusVal)
 
-	for _, statistics := range statisticsList {
-		vals, err := statistics.Stats()
+	statisticsList.RLock()
+	list := statisticsList.list
+	statisticsList.RUnlock()
+	for _, statistics := range list {
+		vals, err := statistics.Stats(vars)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
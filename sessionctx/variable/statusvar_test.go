@@ -30,7 +30,10 @@ func (s *testStatusVarSuite) SetUpSuite(c *C) {
 }
 
 // mockStatistics represents mocked statistics.
-type mockStatistics struct{}
+type mockStatistics struct {
+	// id makes the instances distinct, the pointers to zero-size values may be equal.
+	id int
+}
 
 const (
 	testStatus        = "test_status"
@@ -51,7 +54,7 @@ func (ms *mockStatistics) GetScope(status string) ScopeFlag {
 	return scope
 }
 
-func (ms *mockStatistics) Stats() (map[string]interface{}, error) {
+func (ms *mockStatistics) Stats(vars *SessionVars) (map[string]interface{}, error) {
 	m := make(map[string]interface{}, len(specificStatusScopes))
 	m[testStatus] = testStatusVal
 
@@ -65,8 +68,25 @@ func (s *testStatusVarSuite) TestStatusVar(c *C) {
 	scope = s.ms.GetScope(testSessionStatus)
 	c.Assert(scope, Equals, ScopeSession)
 
-	vars, err := GetStatusVars()
+	vars, err := GetStatusVars(nil)
 	c.Assert(err, IsNil)
 	v := &StatusVal{Scope: DefaultScopeFlag, Value: testStatusVal}
 	c.Assert(v, DeepEquals, vars[testStatus])
 }
+
+func (s *testStatusVarSuite) TestUnregisterStatistics(c *C) {
+	defer testleak.AfterTest(c)()
+	ms := &mockStatistics{id: 1}
+	RegisterStatistics(ms)
+	c.Assert(statisticsList.list, HasLen, 2)
+	UnregisterStatistics(ms)
+	c.Assert(statisticsList.list, HasLen, 1)
+	c.Assert(statisticsList.list[0], Equals, s.ms)
+	// Unregistering the unknown statistics does nothing.
+	UnregisterStatistics(ms)
+	c.Assert(statisticsList.list, HasLen, 1)
+
+	vars, err := GetStatusVars(nil)
+	c.Assert(err, IsNil)
+	c.Assert(vars[testStatus], NotNil)
+}
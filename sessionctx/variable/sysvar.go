@@ -14,6 +14,7 @@
 package variable
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/pingcap/tidb/mysql"
@@ -58,6 +59,7 @@ const (
 	CodeUnknownStatusVar terror.ErrCode = 1
 	CodeUnknownSystemVar terror.ErrCode = 1193
 	CodeIncorrectScope   terror.ErrCode = 1238
+	CodeWrongValueForVar terror.ErrCode = 1231
 )
 
 var tidbSysVars map[string]bool
@@ -67,6 +69,8 @@ var (
 	UnknownStatusVar  = terror.ClassVariable.New(CodeUnknownStatusVar, "unknown status variable")
 	UnknownSystemVar  = terror.ClassVariable.New(CodeUnknownSystemVar, "unknown system variable '%s'")
 	ErrIncorrectScope = terror.ClassVariable.New(CodeIncorrectScope, "Incorrect variable scope")
+	// ErrWrongValueForVar is returned when a variable is set to an unsupported value.
+	ErrWrongValueForVar = terror.ClassVariable.New(CodeWrongValueForVar, mysql.MySQLErrName[mysql.ErrWrongValueForVar])
 )
 
 func init() {
@@ -79,6 +83,7 @@ func init() {
 	mySQLErrCodes := map[terror.ErrCode]uint16{
 		CodeUnknownSystemVar: mysql.ErrUnknownSystemVariable,
 		CodeIncorrectScope:   mysql.ErrIncorrectGlobalLocalVar,
+		CodeWrongValueForVar: mysql.ErrWrongValueForVar,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassVariable] = mySQLErrCodes
 
@@ -88,6 +93,9 @@ func init() {
 	tidbSysVars[TiDBSnapshot] = true
 	tidbSysVars[TiDBSkipConstraintCheck] = true
 	tidbSysVars[TiDBSkipDDLWait] = true
+	tidbSysVars[TiDBMemQuotaQuery] = true
+	tidbSysVars[TiDBMemOOMAction] = true
+	tidbSysVars[TiDBSlowLogThreshold] = true
 }
 
 // we only support MySQL now
@@ -175,7 +183,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "innodb_log_write_ahead_size", ""},
 	{ScopeNone, "innodb_log_group_home_dir", "./"},
 	{ScopeNone, "performance_schema_events_statements_history_size", "10"},
-	{ScopeGlobal, "general_log", "OFF"},
+	{ScopeGlobal, GeneralLog, "OFF"},
 	{ScopeGlobal, "validate_password_dictionary_file", ""},
 	{ScopeGlobal, "binlog_order_commits", "ON"},
 	{ScopeGlobal, "master_verify_checksum", "OFF"},
@@ -239,6 +247,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "query_prealloc_size", "8192"},
 	{ScopeNone, "relay_log_space_limit", "0"},
 	{ScopeGlobal | ScopeSession, "max_user_connections", "0"},
+	{ScopeGlobal | ScopeSession, MaxExecutionTime, "0"},
 	{ScopeNone, "performance_schema_max_thread_classes", "50"},
 	{ScopeGlobal, "innodb_api_trx_level", "0"},
 	{ScopeNone, "disconnect_on_expired_password", "ON"},
@@ -596,6 +605,9 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, DistSQLJoinConcurrencyVar, "5"},
 	{ScopeSession, TiDBSkipConstraintCheck, "0"},
 	{ScopeSession, TiDBSkipDDLWait, "0"},
+	{ScopeSession, TiDBMemQuotaQuery, "0"},
+	{ScopeSession, TiDBMemOOMAction, OOMActionLog},
+	{ScopeSession, TiDBSlowLogThreshold, strconv.Itoa(DefSlowLogThreshold)},
 }
 
 // TiDB system variables
@@ -605,6 +617,35 @@ const (
 	DistSQLJoinConcurrencyVar = "tidb_distsql_join_concurrency"
 	TiDBSkipConstraintCheck   = "tidb_skip_constraint_check"
 	TiDBSkipDDLWait           = "tidb_skip_ddl_wait"
+	// TiDBMemQuotaQuery is the memory quota of a statement in bytes, 0 means no quota.
+	TiDBMemQuotaQuery = "tidb_mem_quota_query"
+	// TiDBMemOOMAction is the action when a statement exceeds its memory quota, it's one of the OOMAction values.
+	TiDBMemOOMAction = "tidb_mem_oom_action"
+	// TiDBSlowLogThreshold is the execution time in milliseconds above which a statement is logged as a slow query.
+	TiDBSlowLogThreshold = "tidb_slow_log_threshold"
+)
+
+// DefSlowLogThreshold is the default value of tidb_slow_log_threshold.
+const DefSlowLogThreshold = 300
+
+// defaultSlowLogThreshold is the value of tidb_slow_log_threshold of the new sessions.
+var defaultSlowLogThreshold uint64 = DefSlowLogThreshold
+
+// SetDefaultSlowLogThreshold sets the default value of tidb_slow_log_threshold in milliseconds,
+// it should be called before any session is created.
+func SetDefaultSlowLogThreshold(ms uint64) {
+	defaultSlowLogThreshold = ms
+	SysVars[TiDBSlowLogThreshold].Value = strconv.FormatUint(ms, 10)
+}
+
+// The actions when a statement exceeds tidb_mem_quota_query.
+const (
+	// OOMActionLog logs a warning and keeps running the statement.
+	OOMActionLog = "log"
+	// OOMActionCancel cancels the statement.
+	OOMActionCancel = "cancel"
+	// OOMActionSpill spills the data of the executors to disk if they support it, otherwise cancels the statement.
+	OOMActionSpill = "spill"
 )
 
 // SetNamesVariables is the system variable names related to set names statements.
@@ -14,6 +14,7 @@
 package varsutil
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
@@ -109,6 +110,32 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		vars.SkipConstraintCheck = (sVal == "1")
 	case variable.TiDBSkipDDLWait:
 		vars.SkipDDLWait = (sVal == "1")
+	case variable.MaxExecutionTime:
+		timeout, err := strconv.ParseUint(sVal, 10, 64)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		vars.MaxExecutionTime = timeout
+	case variable.TiDBMemQuotaQuery:
+		quota, err := strconv.ParseInt(sVal, 10, 64)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		vars.MemQuotaQuery = quota
+	case variable.TiDBMemOOMAction:
+		sVal = strings.ToLower(sVal)
+		switch sVal {
+		case variable.OOMActionLog, variable.OOMActionCancel, variable.OOMActionSpill:
+			vars.MemOOMAction = sVal
+		default:
+			return variable.ErrWrongValueForVar.GenByArgs(name, sVal)
+		}
+	case variable.TiDBSlowLogThreshold:
+		threshold, err := strconv.ParseUint(sVal, 10, 64)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		vars.SlowLogThreshold = threshold
 	}
 	vars.Systems[name] = sVal
 	return nil
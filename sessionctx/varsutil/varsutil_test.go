@@ -19,6 +19,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -103,4 +104,22 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	c.Assert(t2.Sub(t1), Equals, 10*time.Hour)
 	SetSessionSystemVar(v, variable.TimeZone, types.NewStringDatum("-6:00"))
 	c.Assert(v.TimeZone.String(), Equals, "UTC")
+
+	c.Assert(v.MaxExecutionTime, Equals, uint64(0))
+	SetSessionSystemVar(v, variable.MaxExecutionTime, types.NewStringDatum("100"))
+	c.Assert(v.MaxExecutionTime, Equals, uint64(100))
+
+	c.Assert(v.MemQuotaQuery, Equals, int64(0))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaQuery, types.NewStringDatum("1024"))
+	c.Assert(v.MemQuotaQuery, Equals, int64(1024))
+	c.Assert(v.MemOOMAction, Equals, variable.OOMActionLog)
+	SetSessionSystemVar(v, variable.TiDBMemOOMAction, types.NewStringDatum("Spill"))
+	c.Assert(v.MemOOMAction, Equals, variable.OOMActionSpill)
+	err = SetSessionSystemVar(v, variable.TiDBMemOOMAction, types.NewStringDatum("abort"))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	c.Assert(v.MemOOMAction, Equals, variable.OOMActionSpill)
+
+	c.Assert(v.SlowLogThreshold, Equals, uint64(variable.DefSlowLogThreshold))
+	SetSessionSystemVar(v, variable.TiDBSlowLogThreshold, types.NewStringDatum("100"))
+	c.Assert(v.SlowLogThreshold, Equals, uint64(100))
 }
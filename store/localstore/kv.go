@@ -29,7 +29,8 @@ import (
 )
 
 var (
-	_ kv.Storage = (*dbStore)(nil)
+	_ kv.Storage       = (*dbStore)(nil)
+	_ kv.MvccInspector = (*dbStore)(nil)
 )
 
 const (
@@ -16,6 +16,8 @@ package localstore
 import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/localstore/engine"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/codec"
 )
 
@@ -57,3 +59,35 @@ func MvccDecode(encodedKey kv.EncodedKey) (kv.Key, kv.Version, error) {
 	}
 	return key, kv.Version{Ver: ver}, nil
 }
+
+// MvccInfo implements the kv.MvccInspector interface. The transactions are committed atomically
+// in the local stores, so there is no lock.
+func (s *dbStore) MvccInfo(key kv.Key) (*kv.MvccInfo, error) {
+	info := &kv.MvccInfo{Key: key}
+	ver := kv.MaxVersion
+	for {
+		mvccK, v, err := s.Seek(MvccEncodeVersionKey(key, ver), kv.MaxVersion.Ver)
+		if terror.ErrorEqual(err, engine.ErrNotFound) {
+			return info, nil
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		k, commitVer, err := MvccDecode(mvccK)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if key.Cmp(k) != 0 {
+			return info, nil
+		}
+		version := &kv.MvccVersion{CommitTS: commitVer.Ver}
+		if !isTombstone(v) {
+			version.Value = v
+		}
+		info.Versions = append(info.Versions, version)
+		if commitVer.Ver == 0 {
+			return info, nil
+		}
+		ver = kv.Version{Ver: commitVer.Ver - 1}
+	}
+}
@@ -425,3 +425,30 @@ func decodeInt(s []byte) int {
 	fmt.Sscanf(string(s), "%010d", &n)
 	return n
 }
+
+func (t *testMvccSuite) TestMvccInfo(c *C) {
+	k := encodeInt(1)
+	txn, err := t.s.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(txn.Delete(k), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	txn, err = t.s.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(txn.Set(k, []byte("v")), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+
+	info, err := t.s.(kv.MvccInspector).MvccInfo(k)
+	c.Assert(err, IsNil)
+	c.Assert([]byte(info.Key), BytesEquals, []byte(k))
+	c.Assert(info.Lock, IsNil)
+	c.Assert(info.Versions, HasLen, 3)
+	c.Assert(info.Versions[0].Value, BytesEquals, []byte("v"))
+	c.Assert(info.Versions[1].Value, IsNil)
+	c.Assert(info.Versions[2].Value, BytesEquals, []byte(k))
+	c.Assert(info.Versions[0].CommitTS, Greater, info.Versions[1].CommitTS)
+	c.Assert(info.Versions[1].CommitTS, Greater, info.Versions[2].CommitTS)
+
+	info, err = t.s.(kv.MvccInspector).MvccInfo(encodeInt(1024))
+	c.Assert(err, IsNil)
+	c.Assert(info.Versions, HasLen, 0)
+}
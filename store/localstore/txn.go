@@ -159,6 +159,10 @@ func (txn *dbTxn) StartTS() uint64 {
 	return txn.tid
 }
 
+func (txn *dbTxn) CommitTS() uint64 {
+	return txn.version.Ver
+}
+
 func (txn *dbTxn) Valid() bool {
 	return txn.valid
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tipb/go-binlog"
 	"golang.org/x/net/context"
 )
@@ -292,6 +293,12 @@ func (c *twoPhaseCommitter) prewriteSingleBatch(bo *Backoffer, batch batchKeys)
 		},
 	}
 
+	waiting := false
+	defer func() {
+		if waiting {
+			c.store.lockResolver.EndLockWait(c.startTS)
+		}
+	}()
 	for {
 		resp, err := c.store.SendKVReq(bo, req, batch.region, readTimeoutShort)
 		if err != nil {
@@ -326,11 +333,12 @@ func (c *twoPhaseCommitter) prewriteSingleBatch(bo *Backoffer, batch batchKeys)
 			log.Debugf("2PC prewrite encounters lock: %v", lock)
 			locks = append(locks, lock)
 		}
-		ok, err := c.store.lockResolver.ResolveLocks(bo, locks)
+		ok, err := c.store.lockResolver.ResolveLocks(bo, c.startTS, locks)
 		if err != nil {
 			return errors.Trace(err)
 		}
 		if !ok {
+			waiting = true
 			err = bo.Backoff(boTxnLock, errors.Errorf("2PC prewrite lockedKeys: %d", len(locks)))
 			if err != nil {
 				return errors.Trace(err)
@@ -445,6 +453,9 @@ const maxTxnTimeUse = 590000
 
 // execute executes the two-phase commit protocol.
 func (c *twoPhaseCommitter) execute() error {
+	span, _ := c.txn.us.GetOption(kv.TraceSpan).(*tracing.Span)
+	span = span.StartChild("tikv.2pc")
+	defer span.Finish()
 	ctx := context.Background()
 	defer func() {
 		// Always clean up all written keys if the txn does not commit.
@@ -465,7 +476,9 @@ func (c *twoPhaseCommitter) execute() error {
 	}()
 
 	binlogChan := c.prewriteBinlog()
-	err := c.prewriteKeys(NewBackoffer(prewriteMaxBackoff, ctx), c.keys)
+	prewriteSpan := span.StartChild("tikv.prewrite")
+	err := c.prewriteKeys(NewBackoffer(prewriteMaxBackoff, tracing.ContextWithSpan(ctx, prewriteSpan)), c.keys)
+	prewriteSpan.Finish()
 	if binlogChan != nil {
 		binlogErr := <-binlogChan
 		if binlogErr != nil {
@@ -492,7 +505,9 @@ func (c *twoPhaseCommitter) execute() error {
 		return errors.Annotate(err, txnRetryableMark)
 	}
 
-	err = c.commitKeys(NewBackoffer(commitMaxBackoff, ctx), c.keys)
+	commitSpan := span.StartChild("tikv.commit")
+	err = c.commitKeys(NewBackoffer(commitMaxBackoff, tracing.ContextWithSpan(ctx, commitSpan)), c.keys)
+	commitSpan.Finish()
 	if err != nil {
 		if !c.mu.committed {
 			log.Debugf("2PC failed on commit: %v, tid: %d", err, c.startTS)
@@ -25,6 +25,7 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tipb/go-tipb"
 	"golang.org/x/net/context"
 )
@@ -76,15 +77,23 @@ func supportExpr(exprType tipb.ExprType) bool {
 func (c *CopClient) Send(req *kv.Request) kv.Response {
 	coprocessorCounter.WithLabelValues("send").Inc()
 
-	bo := NewBackoffer(copBuildTaskMaxBackoff, context.Background())
+	parent := tracing.ContextWithSpan(context.Background(), req.Span)
+	ctx, cancel := context.WithCancel(parent)
+	if !req.Deadline.IsZero() {
+		ctx, cancel = context.WithDeadline(parent, req.Deadline)
+	}
+	bo := NewBackoffer(copBuildTaskMaxBackoff, ctx)
 	tasks, err := buildCopTasks(bo, c.store.regionCache, &copRanges{mid: req.KeyRanges}, req.Desc)
 	if err != nil {
+		cancel()
 		return copErrorResponse{err}
 	}
 	it := &copIterator{
 		store:       c.store,
 		req:         req,
 		concurrency: req.Concurrency,
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 	it.mu.tasks = tasks
 	if it.concurrency > len(tasks) {
@@ -305,6 +314,10 @@ type copIterator struct {
 	}
 	respChan chan *coprocessor.Response
 	errChan  chan error
+	// ctx is done when the iterator is closed or the deadline of the request is exceeded,
+	// the workers stop sending requests and the pending RPCs are abandoned.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 const minLogCopTaskTime = 50 * time.Millisecond
@@ -331,9 +344,11 @@ func (it *copIterator) work() {
 		}
 		task.status = taskRunning
 		it.mu.Unlock()
-		bo := NewBackoffer(copNextMaxBackoff, context.Background())
+		span := it.req.Span.StartChild("tikv.copTask")
+		bo := NewBackoffer(copNextMaxBackoff, tracing.ContextWithSpan(it.ctx, span))
 		startTime := time.Now()
 		resp, err := it.handleTask(bo, task)
+		span.Finish()
 		costTime := time.Since(startTime)
 		if costTime > minLogCopTaskTime {
 			log.Infof("[TIME_COP_TASK] %s%s %s", costTime, bo, task)
@@ -346,10 +361,14 @@ func (it *copIterator) work() {
 			it.errChan <- err
 			break
 		}
+		respChan := task.respChan
 		if !it.req.KeepOrder {
-			it.respChan <- resp
-		} else {
-			task.respChan <- resp
+			respChan = it.respChan
+		}
+		select {
+		case respChan <- resp:
+		case <-it.ctx.Done():
+			return
 		}
 	}
 }
@@ -382,6 +401,8 @@ func (it *copIterator) Next() (io.ReadCloser, error) {
 		select {
 		case resp = <-it.respChan:
 		case err = <-it.errChan:
+		case <-it.ctx.Done():
+			err = it.ctx.Err()
 		}
 	} else {
 		var task *copTask
@@ -402,6 +423,8 @@ func (it *copIterator) Next() (io.ReadCloser, error) {
 		select {
 		case resp = <-task.respChan:
 		case err = <-it.errChan:
+		case <-it.ctx.Done():
+			err = it.ctx.Err()
 		}
 		it.mu.Lock()
 		task.status = taskDone
@@ -429,6 +452,12 @@ func (it *copIterator) Next() (io.ReadCloser, error) {
 func (it *copIterator) handleTask(bo *Backoffer, task *copTask) (*coprocessor.Response, error) {
 	coprocessorCounter.WithLabelValues("handle_task").Inc()
 	sender := NewRegionRequestSender(bo, it.store.regionCache, it.store.client)
+	waiting := false
+	defer func() {
+		if waiting {
+			it.store.lockResolver.EndLockWait(it.req.StartTs)
+		}
+	}()
 	for {
 		it.mu.RLock()
 		if it.mu.finished {
@@ -459,11 +488,12 @@ func (it *copIterator) handleTask(bo *Backoffer, task *copTask) (*coprocessor.Re
 		}
 		if e := resp.GetLocked(); e != nil {
 			log.Debugf("coprocessor encounters lock: %v", e)
-			ok, err1 := it.store.lockResolver.ResolveLocks(bo, []*Lock{newLock(e)})
+			ok, err1 := it.store.lockResolver.ResolveLocks(bo, it.req.StartTs, []*Lock{newLock(e)})
 			if err1 != nil {
 				return nil, errors.Trace(err1)
 			}
 			if !ok {
+				waiting = true
 				err = bo.Backoff(boTxnLockFast, errors.New(e.String()))
 				if err != nil {
 					return nil, errors.Trace(err)
@@ -514,6 +544,7 @@ func (it *copIterator) Close() error {
 	it.mu.Lock()
 	it.mu.finished = true
 	it.mu.Unlock()
+	it.cancel()
 	return nil
 }
 
@@ -137,6 +137,10 @@ func (w *GCWorker) start() {
 
 // Leader of GC worker checks if it should start a GC job every tick.
 func (w *GCWorker) leaderTick() error {
+	// The lag is only a metric, GC goes on if it fails to be updated.
+	if err := w.updateSafePointLag(); err != nil {
+		log.Warnf("[gc worker] update safe point lag err: %v", err)
+	}
 	if w.gcIsRunning {
 		return nil
 	}
@@ -157,6 +161,20 @@ func (w *GCWorker) leaderTick() error {
 	return nil
 }
 
+// updateSafePointLag reports the time since the last safe point, it keeps growing if GC is stuck.
+func (w *GCWorker) updateSafePointLag() error {
+	now, err := w.getOracleTime()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	safePoint, err := w.loadTime(gcSafePointKey)
+	if err != nil || safePoint == nil {
+		return errors.Trace(err)
+	}
+	gcSafePointLagGauge.Set(now.Sub(*safePoint).Seconds())
+	return nil
+}
+
 // prepare checks required conditions for starting a GC job. It returns a bool
 // that indicates whether the GC job should start and the new safePoint.
 func (w *GCWorker) prepare() (bool, uint64, error) {
@@ -292,7 +310,7 @@ func (w *GCWorker) resolveLocks(safePoint uint64) error {
 		for i := range locksInfo {
 			locks[i] = newLock(locksInfo[i])
 		}
-		ok, err1 := w.store.lockResolver.ResolveLocks(bo, locks)
+		ok, err1 := w.store.lockResolver.ResolveLocks(bo, 0, locks)
 		if err1 != nil {
 			return errors.Trace(err1)
 		}
@@ -305,6 +323,7 @@ func (w *GCWorker) resolveLocks(safePoint uint64) error {
 		}
 		regions++
 		totalResolvedLocks += len(locks)
+		gcResolvedLocksCounter.Add(float64(len(locks)))
 		key = loc.EndKey
 		if len(key) == 0 {
 			break
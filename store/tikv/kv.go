@@ -14,6 +14,7 @@
 package tikv
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
 	"net/url"
@@ -136,6 +137,83 @@ func ClearRegionCache(store kv.Storage) {
 	s.regionCache = NewRegionCache(s.regionCache.pdClient)
 }
 
+// IsTiKVStore checks if the store is a TiKV store.
+func IsTiKVStore(store kv.Storage) bool {
+	_, ok := store.(*tikvStore)
+	return ok
+}
+
+// RegionInfo is the information of a region and its leader.
+type RegionInfo struct {
+	ID            uint64
+	StartKey      []byte
+	EndKey        []byte
+	LeaderStoreID uint64
+	LeaderAddr    string
+}
+
+// LocateRegions returns the regions that cover the key range [startKey, endKey), an empty endKey means
+// there is no upper bound. It returns an error if the store is not a TiKV store.
+func LocateRegions(store kv.Storage, startKey, endKey []byte) ([]*RegionInfo, error) {
+	s, ok := store.(*tikvStore)
+	if !ok {
+		return nil, errors.Errorf("%s is not a TiKV store", store.UUID())
+	}
+	bo := NewBackoffer(locateRegionsMaxBackoff, context.Background())
+	var regions []*RegionInfo
+	key := startKey
+	for {
+		loc, err := s.regionCache.LocateKey(bo, key)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ctx, err := s.regionCache.GetRPCContext(bo, loc.Region)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if ctx == nil {
+			// The region is out of date, locate the key again.
+			err = bo.Backoff(boRegionMiss, errors.Errorf("region %d is dropped", loc.Region.id))
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			continue
+		}
+		regions = append(regions, &RegionInfo{
+			ID:            loc.Region.id,
+			StartKey:      loc.StartKey,
+			EndKey:        loc.EndKey,
+			LeaderStoreID: ctx.GetStoreID(),
+			LeaderAddr:    ctx.Addr,
+		})
+		if len(loc.EndKey) == 0 || (len(endKey) > 0 && bytes.Compare(loc.EndKey, endKey) >= 0) {
+			return regions, nil
+		}
+		key = loc.EndKey
+	}
+}
+
+// Regions implements the kv.RegionInspector interface.
+func (s *tikvStore) Regions(startKey, endKey kv.Key) ([]*kv.RegionStatus, error) {
+	regions, err := LocateRegions(s, startKey, endKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	status := make([]*kv.RegionStatus, 0, len(regions))
+	for _, r := range regions {
+		readCount, writeCount := s.regionCache.getFlow(r.ID)
+		status = append(status, &kv.RegionStatus{
+			ID:            r.ID,
+			StartKey:      r.StartKey,
+			EndKey:        r.EndKey,
+			LeaderStoreID: r.LeaderStoreID,
+			ReadCount:     readCount,
+			WriteCount:    writeCount,
+		})
+	}
+	return status, nil
+}
+
 func (s *tikvStore) Begin() (kv.Transaction, error) {
 	txn, err := newTiKVTxn(s)
 	if err != nil {
@@ -151,6 +229,11 @@ func (s *tikvStore) GetSnapshot(ver kv.Version) (kv.Snapshot, error) {
 	return snapshot, nil
 }
 
+// SetLockWaitObserver implements the kv.LockWaitObservable interface.
+func (s *tikvStore) SetLockWaitObserver(o kv.LockWaitObserver) {
+	s.lockResolver.setObserver(o)
+}
+
 func (s *tikvStore) Close() error {
 	mc.Lock()
 	defer mc.Unlock()
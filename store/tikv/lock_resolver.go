@@ -22,6 +22,7 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/pd/pd-client"
+	"github.com/pingcap/tidb/kv"
 	"golang.org/x/net/context"
 )
 
@@ -35,6 +36,8 @@ type LockResolver struct {
 		// Cache resolved txns (FIFO, txn id -> txnStatus).
 		resolved       map[uint64]TxnStatus
 		recentResolved *list.List
+		// observer is notified when a transaction is blocked by a lock.
+		observer kv.LockWaitObserver
 	}
 }
 
@@ -118,6 +121,18 @@ func (lr *LockResolver) saveResolved(txnID uint64, status TxnStatus) {
 	}
 }
 
+func (lr *LockResolver) setObserver(o kv.LockWaitObserver) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	lr.mu.observer = o
+}
+
+func (lr *LockResolver) getObserver() kv.LockWaitObserver {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	return lr.mu.observer
+}
+
 func (lr *LockResolver) getResolved(txnID uint64) (TxnStatus, bool) {
 	lr.mu.RLock()
 	defer lr.mu.RUnlock()
@@ -135,22 +150,37 @@ func (lr *LockResolver) getResolved(txnID uint64) (TxnStatus, bool) {
 //    commit status.
 // 3) Send `ResolveLock` cmd to the lock's region to resolve all locks belong to
 //    the same transaction.
-func (lr *LockResolver) ResolveLocks(bo *Backoffer, locks []*Lock) (ok bool, err error) {
+// The callerStartTS is the start timestamp of the transaction which encounters
+// the locks, the lock waits of the transaction are reported to the observer if
+// it's not 0. If it returns false without an error, the caller must call
+// EndLockWait when it stops waiting, whether it reads the keys or gives up.
+func (lr *LockResolver) ResolveLocks(bo *Backoffer, callerStartTS uint64, locks []*Lock) (ok bool, err error) {
 	if len(locks) == 0 {
 		return true, nil
 	}
 
 	lockResolverCounter.WithLabelValues("resolve").Inc()
 
+	var observer kv.LockWaitObserver
+	if callerStartTS != 0 {
+		observer = lr.getObserver()
+	}
 	var expiredLocks []*Lock
+	var waitLock *Lock
 	for _, l := range locks {
 		if lr.store.oracle.IsExpired(l.TxnID, l.TTL) {
 			lockResolverCounter.WithLabelValues("expired").Inc()
 			expiredLocks = append(expiredLocks, l)
 		} else {
 			lockResolverCounter.WithLabelValues("not_expired").Inc()
+			if waitLock == nil {
+				waitLock = l
+			}
 		}
 	}
+	if observer != nil && waitLock != nil {
+		observer.OnLockWait(callerStartTS, waitLock.TxnID, waitLock.Key, waitLock.Primary, waitLock.TTL)
+	}
 	if len(expiredLocks) == 0 {
 		return false, nil
 	}
@@ -161,6 +191,7 @@ func (lr *LockResolver) ResolveLocks(bo *Backoffer, locks []*Lock) (ok bool, err
 	for _, l := range expiredLocks {
 		status, err := lr.getTxnStatus(bo, l.TxnID, l.Primary)
 		if err != nil {
+			lr.EndLockWait(callerStartTS)
 			return false, errors.Trace(err)
 		}
 
@@ -172,10 +203,26 @@ func (lr *LockResolver) ResolveLocks(bo *Backoffer, locks []*Lock) (ok bool, err
 
 		err = lr.resolveLock(bo, l, status, cleanRegions)
 		if err != nil {
+			lr.EndLockWait(callerStartTS)
 			return false, errors.Trace(err)
 		}
 	}
-	return len(expiredLocks) == len(locks), nil
+	if waitLock != nil {
+		return false, nil
+	}
+	lr.EndLockWait(callerStartTS)
+	return true, nil
+}
+
+// EndLockWait notifies the observer that the transaction of callerStartTS
+// doesn't wait for the locks any longer.
+func (lr *LockResolver) EndLockWait(callerStartTS uint64) {
+	if callerStartTS == 0 {
+		return
+	}
+	if observer := lr.getObserver(); observer != nil {
+		observer.OnLockWaitDone(callerStartTS)
+	}
 }
 
 // GetTxnStatus queries tikv-server for a txn's status (commit/rollback).
@@ -224,6 +224,65 @@ func (s *testLockSuite) TestLockTTL(c *C) {
 	c.Assert(l.TTL, Equals, uint64(ttlFactor*2))
 }
 
+type mockLockWaitObserver struct {
+	waits map[uint64]uint64
+	done  []uint64
+}
+
+func (o *mockLockWaitObserver) OnLockWait(startTS, lockTS uint64, key, primary []byte, ttl uint64) {
+	o.waits[startTS] = lockTS
+}
+
+func (o *mockLockWaitObserver) OnLockWaitDone(startTS uint64) {
+	o.done = append(o.done, startTS)
+}
+
+func (s *testLockSuite) TestLockWaitObserver(c *C) {
+	startTS, _ := s.lockKey(c, []byte("k"), []byte("v"), []byte("k"), []byte("v"), false)
+	o := &mockLockWaitObserver{waits: make(map[uint64]uint64)}
+	s.store.SetLockWaitObserver(o)
+	l := s.mustGetLock(c, []byte("k"))
+	bo := NewBackoffer(getMaxBackoff, context.Background())
+
+	l.TTL = maxLockTTL * 1000
+	ok, err := s.store.lockResolver.ResolveLocks(bo, 100, []*Lock{l})
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(o.waits, DeepEquals, map[uint64]uint64{100: startTS})
+	// The lock waits are not reported if the caller isn't a transaction.
+	ok, err = s.store.lockResolver.ResolveLocks(bo, 0, []*Lock{l})
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(o.waits, HasLen, 1)
+
+	l.TTL = 0
+	ok, err = s.store.lockResolver.ResolveLocks(bo, 100, []*Lock{l})
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(o.done, DeepEquals, []uint64{100})
+}
+
+func (s *testLockSuite) TestLockWaitGiveUp(c *C) {
+	txn, err := newTiKVTxn(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(txn.Set([]byte("k"), []byte("v")), IsNil)
+	tpc, err := newTwoPhaseCommitter(txn)
+	c.Assert(err, IsNil)
+	tpc.lockTTL = maxLockTTL * 1000
+	err = tpc.prewriteKeys(NewBackoffer(prewriteMaxBackoff, context.Background()), tpc.keys)
+	c.Assert(err, IsNil)
+
+	o := &mockLockWaitObserver{waits: make(map[uint64]uint64)}
+	s.store.SetLockWaitObserver(o)
+	defer s.store.SetLockWaitObserver(nil)
+	// The lock wait ends if the reader gives up.
+	snapshot := newTiKVSnapshot(s.store, kv.Version{Ver: txn.StartTS() + 1})
+	_, err = snapshot.get(NewBackoffer(10, context.Background()), []byte("k"))
+	c.Assert(err, NotNil)
+	c.Assert(o.waits, DeepEquals, map[uint64]uint64{txn.StartTS() + 1: txn.StartTS()})
+	c.Assert(o.done, DeepEquals, []uint64{txn.StartTS() + 1})
+}
+
 func init() {
 	// Speed up tests.
 	defaultLockTTL = 3
@@ -121,6 +121,30 @@ var (
 		}, []string{"type"},
 	)
 
+	gcSafePointLagGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "gc_safe_point_lag_seconds",
+			Help:      "Gauge of the time since the GC safe point, it's reported by the GC leader.",
+		})
+
+	gcResolvedLocksCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "gc_resolved_locks_total",
+			Help:      "Counter of locks resolved by gc worker.",
+		})
+
+	regionCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "region_cache_operations_total",
+			Help:      "Counter of region cache lookups.",
+		}, []string{"type"})
+
 	lockResolverCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "tidb",
@@ -207,6 +231,9 @@ func init() {
 	prometheus.MustRegister(gcWorkerCounter)
 	prometheus.MustRegister(gcConfigGauge)
 	prometheus.MustRegister(gcHistogram)
+	prometheus.MustRegister(gcSafePointLagGauge)
+	prometheus.MustRegister(gcResolvedLocksCounter)
+	prometheus.MustRegister(regionCacheCounter)
 	prometheus.MustRegister(lockResolverCounter)
 	prometheus.MustRegister(regionErrorCounter)
 	prometheus.MustRegister(txnWriteKVCountHistogram)
@@ -70,7 +70,7 @@ func (h *rpcHandler) handleRequest(req *kvrpcpb.Request) *kvrpcpb.Response {
 	case kvrpcpb.MessageType_CmdBatchGet:
 		resp.CmdBatchGetResp = h.onBatchGet(req.CmdBatchGetReq)
 	case kvrpcpb.MessageType_CmdScanLock:
-		resp.CmdResolveLockResp = h.onResolveLock(req.CmdResolveLockReq)
+		resp.CmdScanLockResp = h.onScanLock(req.CmdScanLockReq)
 	case kvrpcpb.MessageType_CmdResolveLock:
 		resp.CmdResolveLockResp = h.onResolveLock(req.CmdResolveLockReq)
 	case kvrpcpb.MessageType_CmdBatchRollback:
@@ -16,6 +16,7 @@ package tikv
 import (
 	"bytes"
 	"sync"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
@@ -37,6 +38,47 @@ type RegionCache struct {
 		sync.RWMutex
 		stores map[uint64]*Store
 	}
+	// flowMu guards the read and write counters of the regions by the region IDs, the regions without
+	// requests in the window are evicted when the epoch changes.
+	flowMu struct {
+		sync.Mutex
+		epoch int64
+		flows map[uint64]*regionFlow
+	}
+}
+
+// The flows of the regions are counted in a sliding window of flowSlots slots of flowSlotDuration.
+const (
+	flowSlotDuration = 10 * time.Second
+	flowSlots        = 6
+)
+
+// flowEpoch returns the sequence number of the slot that the time is in.
+func flowEpoch(t time.Time) int64 {
+	return t.UnixNano() / int64(flowSlotDuration)
+}
+
+// regionFlow is the number of the read and write requests sent to a region in the window.
+type regionFlow struct {
+	slots [flowSlots]flowSlot
+}
+
+// flowSlot is the number of the requests sent in the slot of epoch.
+type flowSlot struct {
+	epoch      int64
+	readCount  uint64
+	writeCount uint64
+}
+
+// count returns the number of the requests in the window that ends in the slot of epoch.
+func (f *regionFlow) count(epoch int64) (readCount, writeCount uint64) {
+	for _, s := range f.slots {
+		if s.epoch > epoch-flowSlots && s.epoch <= epoch {
+			readCount += s.readCount
+			writeCount += s.writeCount
+		}
+	}
+	return
 }
 
 // NewRegionCache creates a RegionCache.
@@ -47,6 +89,7 @@ func NewRegionCache(pdClient pd.Client) *RegionCache {
 	c.mu.regions = make(map[RegionVerID]*Region)
 	c.mu.sorted = llrb.New()
 	c.storeMu.stores = make(map[uint64]*Store)
+	c.flowMu.flows = make(map[uint64]*regionFlow)
 	return c
 }
 
@@ -93,6 +136,46 @@ func (c *RegionCache) GetRPCContext(bo *Backoffer, id RegionVerID) (*RPCContext,
 	}, nil
 }
 
+// addFlow counts a read or a write request sent to the region.
+func (c *RegionCache) addFlow(regionID uint64, write bool) {
+	epoch := flowEpoch(time.Now())
+	c.flowMu.Lock()
+	defer c.flowMu.Unlock()
+	if epoch != c.flowMu.epoch {
+		c.flowMu.epoch = epoch
+		for id, f := range c.flowMu.flows {
+			if r, w := f.count(epoch); r == 0 && w == 0 {
+				delete(c.flowMu.flows, id)
+			}
+		}
+	}
+	f, ok := c.flowMu.flows[regionID]
+	if !ok {
+		f = &regionFlow{}
+		c.flowMu.flows[regionID] = f
+	}
+	s := &f.slots[epoch%flowSlots]
+	if s.epoch != epoch {
+		*s = flowSlot{epoch: epoch}
+	}
+	if write {
+		s.writeCount++
+	} else {
+		s.readCount++
+	}
+}
+
+// getFlow returns the number of the read and write requests sent to the region in the window.
+func (c *RegionCache) getFlow(regionID uint64) (readCount, writeCount uint64) {
+	epoch := flowEpoch(time.Now())
+	c.flowMu.Lock()
+	defer c.flowMu.Unlock()
+	if f, ok := c.flowMu.flows[regionID]; ok {
+		return f.count(epoch)
+	}
+	return 0, 0
+}
+
 // KeyLocation is the region and range that a key is located.
 type KeyLocation struct {
 	Region   RegionVerID
@@ -116,9 +199,11 @@ func (c *RegionCache) LocateKey(bo *Backoffer, key []byte) (*KeyLocation, error)
 			EndKey:   r.EndKey(),
 		}
 		c.mu.RUnlock()
+		regionCacheCounter.WithLabelValues("hit").Inc()
 		return loc, nil
 	}
 	c.mu.RUnlock()
+	regionCacheCounter.WithLabelValues("miss").Inc()
 
 	r, err := c.loadRegion(bo, key)
 	if err != nil {
@@ -217,6 +302,10 @@ func (c *RegionCache) dropRegionFromCache(verID RegionVerID) {
 	}
 	c.mu.sorted.Delete(newRBItem(r))
 	delete(c.mu.regions, r.VerID())
+	// The flow is counted again when the region is reloaded, its range may be changed.
+	c.flowMu.Lock()
+	delete(c.flowMu.flows, verID.id)
+	c.flowMu.Unlock()
 }
 
 // loadRegion loads region from pd client, and picks the first peer as leader.
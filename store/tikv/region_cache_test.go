@@ -274,3 +274,33 @@ func (s *testRegionCacheSuite) TestUpdateStoreAddr(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(getVal, BytesEquals, testValue)
 }
+
+func (s *testRegionCacheSuite) TestFlow(c *C) {
+	r := s.getRegion(c, []byte("a"))
+	s.cache.addFlow(r.GetID(), false)
+	s.cache.addFlow(r.GetID(), false)
+	s.cache.addFlow(r.GetID(), true)
+	readCount, writeCount := s.cache.getFlow(r.GetID())
+	c.Assert(readCount, Equals, uint64(2))
+	c.Assert(writeCount, Equals, uint64(1))
+
+	// The requests out of the window aren't counted.
+	f := s.cache.flowMu.flows[r.GetID()]
+	for i := range f.slots {
+		f.slots[i].epoch -= flowSlots
+	}
+	readCount, writeCount = s.cache.getFlow(r.GetID())
+	c.Assert(readCount, Equals, uint64(0))
+	c.Assert(writeCount, Equals, uint64(0))
+	// The regions without requests in the window are evicted when the epoch changes.
+	s.cache.flowMu.epoch--
+	s.cache.addFlow(r.GetID()+1, true)
+	c.Assert(s.cache.flowMu.flows, HasLen, 1)
+
+	// The flow is evicted when the region is dropped.
+	s.cache.addFlow(r.GetID(), true)
+	s.cache.DropRegion(r.VerID())
+	c.Assert(s.cache.flowMu.flows, HasLen, 1)
+	_, ok := s.cache.flowMu.flows[r.GetID()]
+	c.Assert(ok, IsFalse)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/kvproto/pkg/errorpb"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"golang.org/x/net/context"
 )
 
 // RegionRequestSender sends KV/Cop requests to tikv server. It handles network
@@ -99,6 +100,13 @@ func (s *RegionRequestSender) SendKVReq(req *kvrpcpb.Request, regionID RegionVer
 		if resp.GetType() != req.GetType() {
 			return nil, errors.Trace(errMismatch(resp, req))
 		}
+		switch req.GetType() {
+		case kvrpcpb.MessageType_CmdGet, kvrpcpb.MessageType_CmdScan, kvrpcpb.MessageType_CmdBatchGet:
+			s.regionCache.addFlow(regionID.id, false)
+		case kvrpcpb.MessageType_CmdPrewrite, kvrpcpb.MessageType_CmdCommit, kvrpcpb.MessageType_CmdBatchRollback,
+			kvrpcpb.MessageType_CmdCleanup, kvrpcpb.MessageType_CmdResolveLock:
+			s.regionCache.addFlow(regionID.id, true)
+		}
 		return resp, nil
 	}
 }
@@ -106,6 +114,22 @@ func (s *RegionRequestSender) SendKVReq(req *kvrpcpb.Request, regionID RegionVer
 // SendCopReq sends a coprocessor request to tikv server.
 func (s *RegionRequestSender) SendCopReq(req *coprocessor.Request, regionID RegionVerID, timeout time.Duration) (*coprocessor.Response, error) {
 	for {
+		select {
+		case <-s.bo.ctx.Done():
+			return nil, errors.Trace(s.bo.ctx.Err())
+		default:
+		}
+		// Don't wait for the response after the deadline of the request.
+		if deadline, ok := s.bo.ctx.Deadline(); ok {
+			remain := deadline.Sub(time.Now())
+			if remain <= 0 {
+				return nil, errors.Trace(context.DeadlineExceeded)
+			}
+			if remain < timeout {
+				timeout = remain
+			}
+		}
+
 		ctx, err := s.regionCache.GetRPCContext(s.bo, regionID)
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -136,7 +160,9 @@ func (s *RegionRequestSender) SendCopReq(req *coprocessor.Request, regionID Regi
 			if retry {
 				continue
 			}
+			return resp, nil
 		}
+		s.regionCache.addFlow(regionID.id, false)
 		return resp, nil
 	}
 }
@@ -110,6 +110,12 @@ func (s *tikvSnapshot) batchGetKeysByRegions(bo *Backoffer, keys [][]byte, colle
 
 func (s *tikvSnapshot) batchGetSingleRegion(bo *Backoffer, batch batchKeys, collectF func(k, v []byte)) error {
 	pending := batch.keys
+	waiting := false
+	defer func() {
+		if waiting {
+			s.store.lockResolver.EndLockWait(s.version.Ver)
+		}
+	}()
 	for {
 		req := &pb.Request{
 			Type: pb.MessageType_CmdBatchGet,
@@ -152,11 +158,12 @@ func (s *tikvSnapshot) batchGetSingleRegion(bo *Backoffer, batch batchKeys, coll
 			locks = append(locks, lock)
 		}
 		if len(lockedKeys) > 0 {
-			ok, err := s.store.lockResolver.ResolveLocks(bo, locks)
+			ok, err := s.store.lockResolver.ResolveLocks(bo, s.version.Ver, locks)
 			if err != nil {
 				return errors.Trace(err)
 			}
 			if !ok {
+				waiting = true
 				err = bo.Backoff(boTxnLock, errors.Errorf("batchGet lockedKeys: %d", len(lockedKeys)))
 				if err != nil {
 					return errors.Trace(err)
@@ -182,6 +189,12 @@ func (s *tikvSnapshot) Get(k kv.Key) ([]byte, error) {
 }
 
 func (s *tikvSnapshot) get(bo *Backoffer, k kv.Key) ([]byte, error) {
+	waiting := false
+	defer func() {
+		if waiting {
+			s.store.lockResolver.EndLockWait(s.version.Ver)
+		}
+	}()
 	req := &pb.Request{
 		Type: pb.MessageType_CmdGet,
 		CmdGetReq: &pb.CmdGetRequest{
@@ -215,11 +228,12 @@ func (s *tikvSnapshot) get(bo *Backoffer, k kv.Key) ([]byte, error) {
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
-			ok, err := s.store.lockResolver.ResolveLocks(bo, []*Lock{lock})
+			ok, err := s.store.lockResolver.ResolveLocks(bo, s.version.Ver, []*Lock{lock})
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
 			if !ok {
+				waiting = true
 				err = bo.Backoff(boTxnLockFast, errors.New(keyErr.String()))
 				if err != nil {
 					return nil, errors.Trace(err)
@@ -100,3 +100,54 @@ func (s *testSplitSuite) TestStaleEpoch(c *C) {
 	_, err = txn.Get([]byte("c"))
 	c.Assert(err, IsNil)
 }
+
+func (s *testSplitSuite) TestLocateRegions(c *C) {
+	loc, err := s.store.regionCache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+	s.split(c, loc.Region.id, []byte("b"))
+	s.store.regionCache.DropRegion(loc.Region)
+
+	regions, err := LocateRegions(s.store, []byte("a"), []byte("c"))
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 2)
+	c.Assert(regions[0].EndKey, BytesEquals, []byte("b"))
+	c.Assert(regions[1].StartKey, BytesEquals, []byte("b"))
+	for _, r := range regions {
+		c.Assert(r.LeaderStoreID, Not(Equals), uint64(0))
+		c.Assert(r.LeaderAddr, Not(Equals), "")
+	}
+	regions, err = LocateRegions(s.store, []byte("a"), []byte("b"))
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	regions, err = LocateRegions(s.store, []byte("b"), nil)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	c.Assert(regions[0].EndKey, HasLen, 0)
+}
+
+func (s *testSplitSuite) TestRegionFlows(c *C) {
+	loc, err := s.store.regionCache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+	s.split(c, loc.Region.id, []byte("b"))
+	s.store.regionCache.DropRegion(loc.Region)
+
+	txn := s.begin(c)
+	err = txn.Set([]byte("a"), []byte("a"))
+	c.Assert(err, IsNil)
+	err = txn.Commit()
+	c.Assert(err, IsNil)
+	txn = s.begin(c)
+	_, err = txn.Get([]byte("a"))
+	c.Assert(err, IsNil)
+	_, err = txn.Get([]byte("c"))
+	c.Assert(kv.IsErrNotFound(err), IsTrue)
+
+	regions, err := s.store.Regions(nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 2)
+	// The prewrite and the commit requests are sent to the first region.
+	c.Assert(regions[0].ReadCount, Equals, uint64(1))
+	c.Assert(regions[0].WriteCount, Equals, uint64(2))
+	c.Assert(regions[1].ReadCount, Equals, uint64(1))
+	c.Assert(regions[1].WriteCount, Equals, uint64(0))
+}
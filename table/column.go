@@ -173,7 +173,11 @@ func NewColDesc(col *Column) *ColDesc {
 	}
 
 	extra := ""
-	if mysql.HasAutoIncrementFlag(col.Flag) {
+	if col.IsVirtualGenerated() {
+		extra = "VIRTUAL GENERATED"
+	} else if col.IsGenerated() {
+		extra = "STORED GENERATED"
+	} else if mysql.HasAutoIncrementFlag(col.Flag) {
 		extra = "auto_increment"
 	} else if mysql.HasOnUpdateNowFlag(col.Flag) {
 		extra = "on update CURRENT_TIMESTAMP"
@@ -229,6 +233,16 @@ func (c *Column) IsPKHandleColumn(tbInfo *model.TableInfo) bool {
 	return mysql.HasPriKeyFlag(c.Flag) && tbInfo.PKIsHandle
 }
 
+// IsGenerated checks if the column is a generated column.
+func (c *Column) IsGenerated() bool {
+	return c.ToInfo().IsGenerated()
+}
+
+// IsVirtualGenerated checks if the column is a generated column which is not stored.
+func (c *Column) IsVirtualGenerated() bool {
+	return c.ToInfo().IsVirtualGenerated()
+}
+
 // CheckNotNull checks if row has nil value set to a column with NotNull flag set.
 func CheckNotNull(cols []*Column, row []types.Datum) error {
 	for _, c := range cols {
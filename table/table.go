@@ -53,6 +53,10 @@ var (
 	ErrIndexStateCantNone = terror.ClassTable.New(codeIndexStateCantNone, "index can not be in none state")
 	// ErrInvalidRecordKey returns for invalid record key.
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
+	// ErrCheckConstraintViolated returns for a row that violates a check constraint.
+	ErrCheckConstraintViolated = terror.ClassTable.New(codeCheckConstraintViolated, "Check constraint '%s' is violated.")
+	// ErrBadGeneratedColumn returns for a value specified for a generated column.
+	ErrBadGeneratedColumn = terror.ClassTable.New(codeBadGeneratedColumn, "The value specified for generated column '%s' in table '%s' is not allowed.")
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -141,6 +145,9 @@ const (
 	codeUnknownColumn   = 1054
 	codeDuplicateColumn = 1110
 	codeNoDefaultValue  = 1364
+
+	codeBadGeneratedColumn      = 3105
+	codeCheckConstraintViolated = 3819
 )
 
 // Slice is used for table sorting.
@@ -160,6 +167,9 @@ func init() {
 		codeUnknownColumn:   mysql.ErrBadField,
 		codeDuplicateColumn: mysql.ErrFieldSpecifiedTwice,
 		codeNoDefaultValue:  mysql.ErrNoDefaultForField,
+
+		codeBadGeneratedColumn:      mysql.ErrBadGeneratedColumn,
+		codeCheckConstraintViolated: mysql.ErrCheckConstraintViolated,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTable] = tableMySQLErrCodes
 }
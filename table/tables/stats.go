@@ -0,0 +1,177 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+// TableStats contains the read and write counters of a table since the server starts.
+type TableStats struct {
+	// RowsReadFullScan is the number of rows read by table scans.
+	RowsReadFullScan uint64
+	// RowsReadIndex is the number of rows read via the indices.
+	RowsReadIndex uint64
+	RowsInserted  uint64
+	RowsUpdated   uint64
+	RowsDeleted   uint64
+	// RowsChangedXIndexes is the number of the changed rows multiplied by the number of the indices.
+	RowsChangedXIndexes uint64
+	// LockWaits is the number of the times that transactions are blocked by the locks on the table.
+	LockWaits uint64
+}
+
+type indexStatsKey struct {
+	tableID int64
+	indexID int64
+}
+
+// usageStats holds the counters, the counters are updated atomically.
+var usageStats = struct {
+	sync.RWMutex
+	tables  map[int64]*TableStats
+	indices map[indexStatsKey]*uint64
+}{
+	tables:  make(map[int64]*TableStats),
+	indices: make(map[indexStatsKey]*uint64),
+}
+
+func getTableStats(tableID int64) *TableStats {
+	usageStats.RLock()
+	s, ok := usageStats.tables[tableID]
+	usageStats.RUnlock()
+	if ok {
+		return s
+	}
+	usageStats.Lock()
+	defer usageStats.Unlock()
+	if s, ok = usageStats.tables[tableID]; !ok {
+		s = &TableStats{}
+		usageStats.tables[tableID] = s
+	}
+	return s
+}
+
+func getIndexRowsRead(tableID, indexID int64) *uint64 {
+	key := indexStatsKey{tableID: tableID, indexID: indexID}
+	usageStats.RLock()
+	cnt, ok := usageStats.indices[key]
+	usageStats.RUnlock()
+	if ok {
+		return cnt
+	}
+	usageStats.Lock()
+	defer usageStats.Unlock()
+	if cnt, ok = usageStats.indices[key]; !ok {
+		cnt = new(uint64)
+		usageStats.indices[key] = cnt
+	}
+	return cnt
+}
+
+// CountRowsReadFullScan adds n rows read by a table scan of the table.
+func CountRowsReadFullScan(tableID int64, n uint64) {
+	atomic.AddUint64(&getTableStats(tableID).RowsReadFullScan, n)
+}
+
+// CountRowsReadIndex adds n rows read via the index of the table.
+func CountRowsReadIndex(tableID, indexID int64, n uint64) {
+	atomic.AddUint64(&getTableStats(tableID).RowsReadIndex, n)
+	atomic.AddUint64(getIndexRowsRead(tableID, indexID), n)
+}
+
+// CountLockWait adds a lock wait on the table.
+func CountLockWait(tableID int64) {
+	atomic.AddUint64(&getTableStats(tableID).LockWaits, 1)
+}
+
+// The kinds of the row changes.
+const (
+	rowInserted = iota
+	rowUpdated
+	rowDeleted
+)
+
+// countRowChanged adds a row change of the kind tp to the transaction, the changes are added to the counters
+// of the table by CountTxnRowsChanged after the transaction is committed.
+func (t *Table) countRowChanged(ctx context.Context, tp int) {
+	if ctx == nil {
+		return
+	}
+	txnCtx := ctx.GetSessionVars().TxnCtx
+	if txnCtx.TableDeltas == nil {
+		txnCtx.TableDeltas = make(map[int64]*variable.TableDelta)
+	}
+	d, ok := txnCtx.TableDeltas[t.ID]
+	if !ok {
+		d = &variable.TableDelta{}
+		txnCtx.TableDeltas[t.ID] = d
+	}
+	switch tp {
+	case rowInserted:
+		d.Inserted++
+	case rowUpdated:
+		d.Updated++
+	case rowDeleted:
+		d.Deleted++
+	}
+	d.ChangedXIndexes += uint64(len(t.indices))
+}
+
+// CountTxnRowsChanged adds the rows changed by the committed transaction to the counters of the tables,
+// the changes are removed from the transaction context so they are counted once.
+func CountTxnRowsChanged(txnCtx *variable.TransactionContext) {
+	for tableID, d := range txnCtx.TableDeltas {
+		s := getTableStats(tableID)
+		atomic.AddUint64(&s.RowsInserted, d.Inserted)
+		atomic.AddUint64(&s.RowsUpdated, d.Updated)
+		atomic.AddUint64(&s.RowsDeleted, d.Deleted)
+		atomic.AddUint64(&s.RowsChangedXIndexes, d.ChangedXIndexes)
+	}
+	txnCtx.TableDeltas = nil
+}
+
+// GetTableStats returns the counters of the table.
+func GetTableStats(tableID int64) TableStats {
+	usageStats.RLock()
+	s, ok := usageStats.tables[tableID]
+	usageStats.RUnlock()
+	if !ok {
+		return TableStats{}
+	}
+	return TableStats{
+		RowsReadFullScan:    atomic.LoadUint64(&s.RowsReadFullScan),
+		RowsReadIndex:       atomic.LoadUint64(&s.RowsReadIndex),
+		RowsInserted:        atomic.LoadUint64(&s.RowsInserted),
+		RowsUpdated:         atomic.LoadUint64(&s.RowsUpdated),
+		RowsDeleted:         atomic.LoadUint64(&s.RowsDeleted),
+		RowsChangedXIndexes: atomic.LoadUint64(&s.RowsChangedXIndexes),
+		LockWaits:           atomic.LoadUint64(&s.LockWaits),
+	}
+}
+
+// GetIndexRowsRead returns the number of rows read via the index of the table.
+func GetIndexRowsRead(tableID, indexID int64) uint64 {
+	usageStats.RLock()
+	cnt, ok := usageStats.indices[indexStatsKey{tableID: tableID, indexID: indexID}]
+	usageStats.RUnlock()
+	if !ok {
+		return 0
+	}
+	return atomic.LoadUint64(cnt)
+}
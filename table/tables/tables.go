@@ -186,7 +186,12 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 	// Compose new row
 	t.composeNewData(touched, currentData, oldData)
 	colIDs := make([]int64, 0, len(t.WritableCols()))
+	row := make([]types.Datum, 0, len(t.WritableCols()))
 	for i, col := range t.WritableCols() {
+		if col.IsVirtualGenerated() {
+			// Virtual generated column is not stored, it is evaluated when read.
+			continue
+		}
 		if col.State != model.StatePublic && currentData[i].IsNull() {
 			defaultVal, _, err1 := table.GetColDefaultValue(ctx, col.ToInfo())
 			if err1 != nil {
@@ -195,10 +200,11 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 			currentData[i] = defaultVal
 		}
 		colIDs = append(colIDs, col.ID)
+		row = append(row, currentData[i])
 	}
 	// Set new row data into KV.
 	key := t.RecordKey(h)
-	value, err := tablecodec.EncodeRow(currentData, colIDs)
+	value, err := tablecodec.EncodeRow(row, colIDs)
 	if err = txn.Set(key, value); err != nil {
 		return errors.Trace(err)
 	}
@@ -218,6 +224,7 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 	if shouldWriteBinlog(ctx) {
 		t.addUpdateBinlog(ctx, h, oldData, value, colIDs)
 	}
+	t.countRowChanged(ctx, rowUpdated)
 	return nil
 }
 
@@ -315,11 +322,14 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 	row := make([]types.Datum, 0, len(r))
 	// Set public and write only column value.
 	for _, col := range t.WritableCols() {
-		if col.IsPKHandleColumn(t.meta) {
+		if col.IsPKHandleColumn(t.meta) || col.IsVirtualGenerated() {
 			continue
 		}
 		var value types.Datum
-		if col.State == model.StateWriteOnly || col.State == model.StateWriteReorganization {
+		if col.IsGenerated() {
+			// The stored generated column is evaluated by the caller even if it is not public.
+			value = r[col.Offset]
+		} else if col.State == model.StateWriteOnly || col.State == model.StateWriteReorganization {
 			// if col is in write only or write reorganization state, we must add it with its default value.
 			value, _, err = table.GetColDefaultValue(ctx, col.ToInfo())
 			if err != nil {
@@ -355,6 +365,7 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 		mutation.Sequence = append(mutation.Sequence, binlog.MutationType_Insert)
 	}
 	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+	t.countRowChanged(ctx, rowInserted)
 	return recordID, nil
 }
 
@@ -462,7 +473,7 @@ func (t *Table) RowWithCols(ctx context.Context, h int64, cols []*table.Column)
 			continue
 		}
 		ri, ok := row[col.ID]
-		if !ok && mysql.HasNotNullFlag(col.Flag) {
+		if !ok && mysql.HasNotNullFlag(col.Flag) && !col.IsVirtualGenerated() {
 			return nil, errors.New("Miss column")
 		}
 		v[i] = ri
@@ -492,8 +503,12 @@ func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) erro
 	}
 	if shouldWriteBinlog(ctx) {
 		err = t.addDeleteBinlog(ctx, h, r)
+		if err != nil {
+			return errors.Trace(err)
+		}
 	}
-	return errors.Trace(err)
+	t.countRowChanged(ctx, rowDeleted)
+	return nil
 }
 
 func (t *Table) addUpdateBinlog(ctx context.Context, h int64, old []types.Datum, newValue []byte, colIDs []int64) error {
@@ -20,6 +20,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
@@ -27,14 +28,20 @@ import (
 	"github.com/ngaut/log"
 	"github.com/ngaut/systimemon"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/audit"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/server"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/store/localstore/boltdb"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/generallog"
 	"github.com/pingcap/tidb/util/printer"
+	"github.com/pingcap/tidb/util/slowlog"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tipb/go-binlog"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
@@ -51,6 +58,7 @@ var (
 	statusPort      = flag.String("status", "10080", "tidb server status port")
 	lease           = flag.String("lease", "1s", "schema lease duration, very dangerous to change only if you know what you do")
 	socket          = flag.String("socket", "", "The socket file to use for connection.")
+	socketSkipAuth  = flag.Bool("socket-skip-auth", false, "skip the authentication of the connections from the socket file, otherwise they authenticate by auth_socket or passwords.")
 	enablePS        = flag.Bool("perfschema", false, "If enable performance schema.")
 	reportStatus    = flag.Bool("report-status", true, "If enable status report HTTP service.")
 	logFile         = flag.String("log-file", "", "log file path")
@@ -59,6 +67,24 @@ var (
 	metricsAddr     = flag.String("metrics-addr", "", "prometheus pushgateway address, leaves it empty will disable prometheus push.")
 	metricsInterval = flag.Int("metrics-interval", 15, "prometheus client push interval in second, set \"0\" to disable prometheus push.")
 	binlogSocket    = flag.String("binlog-socket", "", "socket file to write binlog")
+	sslCA           = flag.String("ssl-ca", "", "path of the PEM file of the CA used to verify client certificates")
+	sslCert         = flag.String("ssl-cert", "", "path of the PEM file of the server certificate, enables TLS with ssl-key")
+	sslKey          = flag.String("ssl-key", "", "path of the PEM file of the server private key, enables TLS with ssl-cert")
+	pgPort          = flag.String("pg-port", "", "PostgreSQL protocol server port, leaves it empty will disable the PostgreSQL protocol server")
+	pgAuthMethod    = flag.String("pg-auth-method", "scram-sha-256", "PostgreSQL protocol authentication method: scram-sha-256, md5, password")
+	userTokenLimit  = flag.Int("user-token-limit", 0, "the max number of concurrently handled commands of each account, set \"0\" to disable the limit.")
+	drainTimeout    = flag.Int("drain-timeout", 30, "the seconds to wait for running statements and transactions on shutdown before rolling them back.")
+	slowLogFile     = flag.String("slow-log-file", "", "slow query log file path, leaves it empty will disable the slow query log file.")
+	slowThreshold   = flag.Uint64("slow-threshold", variable.DefSlowLogThreshold, "the default value of tidb_slow_log_threshold, the queries that take longer milliseconds are logged as slow queries.")
+	traceFile       = flag.String("trace-file", "", "trace file path, every statement is traced and the traces are written to it as JSON lines, leaves it empty will disable the tracing.")
+	generalLogFile  = flag.String("general-log-file", "", "general query log file path, leaves it empty will write the general query log to the log file, the general query log is enabled by \"SET GLOBAL general_log = 1\".")
+	auditLogFile    = flag.String("audit-log-file", "", "audit log file path, the connections and the statements are written to it as JSON lines, leaves it empty will disable the audit log.")
+	auditMaxSize    = flag.Int64("audit-log-max-size", 300, "the max size in MB of the audit log file before it's rotated, set \"0\" to disable the rotation.")
+	auditMaxBackups = flag.Int("audit-log-max-backups", 10, "the max number of the rotated audit log files to keep.")
+	auditUsers      = flag.String("audit-users", "", "comma separated users to audit, leaves it empty will audit all the users.")
+	auditClasses    = flag.String("audit-classes", "", "comma separated statement classes to audit, e.g. \"Insert,CreateTable,Grant\", leaves it empty will audit all the statements.")
+	auditDBs        = flag.String("audit-dbs", "", "comma separated databases to audit, leaves it empty will audit all the databases.")
+	auditTables     = flag.String("audit-tables", "", "comma separated tables in the form of db.table to audit, leaves it empty will audit all the tables.")
 )
 
 func main() {
@@ -77,11 +103,21 @@ func main() {
 	tidb.SetSchemaLease(leaseDuration)
 
 	cfg := &server.Config{
-		Addr:         fmt.Sprintf("%s:%s", *host, *port),
-		LogLevel:     *logLevel,
-		StatusAddr:   fmt.Sprintf(":%s", *statusPort),
-		Socket:       *socket,
-		ReportStatus: *reportStatus,
+		Addr:           fmt.Sprintf("%s:%s", *host, *port),
+		LogLevel:       *logLevel,
+		StatusAddr:     fmt.Sprintf(":%s", *statusPort),
+		Socket:         *socket,
+		SocketSkipAuth: *socketSkipAuth,
+		ReportStatus:   *reportStatus,
+		SSLCA:          *sslCA,
+		SSLCert:        *sslCert,
+		SSLKey:         *sslKey,
+		PGAuthMethod:   *pgAuthMethod,
+		UserTokenLimit: *userTokenLimit,
+		DrainTimeout:   *drainTimeout,
+	}
+	if *pgPort != "" {
+		cfg.PGAddr = fmt.Sprintf("%s:%s", *host, *pgPort)
 	}
 
 	// set log options
@@ -94,6 +130,15 @@ func main() {
 		log.SetHighlighting(false)
 	}
 
+	variable.SetDefaultSlowLogThreshold(*slowThreshold)
+	if err := slowlog.SetFile(*slowLogFile); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	if err := tracing.SetExportFile(*traceFile); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	setupAudit()
+
 	if joinCon != nil && *joinCon > 0 {
 		plan.JoinConcurrency = *joinCon
 	}
@@ -116,6 +161,7 @@ func main() {
 	if err != nil {
 		log.Fatal(errors.ErrorStack(err))
 	}
+	setupGeneralLog(store)
 
 	var driver server.IDriver
 	driver = server.NewTiDBDriver(store)
@@ -135,7 +181,12 @@ func main() {
 	go func() {
 		sig := <-sc
 		log.Infof("Got signal [%d] to exit.", sig)
-		svr.Close()
+		go func() {
+			sig := <-sc
+			log.Infof("Got signal [%d] again, exit without waiting for the connections.", sig)
+			os.Exit(0)
+		}()
+		svr.Drain()
 		os.Exit(0)
 	}()
 
@@ -198,6 +249,53 @@ func prometheusPushClient(addr string, interval time.Duration) {
 	}
 }
 
+// setupGeneralLog sets the general query log file, and enables the general query log if general_log is ON.
+func setupGeneralLog(store kv.Storage) {
+	if err := generallog.SetFile(*generalLogFile); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	se, err := tidb.CreateSession(store)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	defer se.Close()
+	value, err := varsutil.GetGlobalSystemVar(se.GetSessionVars(), variable.GeneralLog)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	generallog.SetEnabled(value == "ON" || value == "1")
+}
+
+// splitList splits the comma separated flag value, it returns nil for an empty value.
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func setupAudit() {
+	if *auditLogFile == "" {
+		return
+	}
+	w, err := audit.NewFileWriter(*auditLogFile, *auditMaxSize<<20, *auditMaxBackups)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	filter := &audit.Filter{
+		Users:   splitList(*auditUsers),
+		Classes: splitList(*auditClasses),
+		DBs:     splitList(*auditDBs),
+		Tables:  splitList(*auditTables),
+	}
+	if err = audit.Register(w, filter); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+}
+
 // parseLease parses lease argument string.
 func parseLease() time.Duration {
 	dur, err := time.ParseDuration(*lease)
@@ -26,6 +26,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/audit"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
@@ -35,7 +36,9 @@ import (
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/store/localstore/engine"
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -77,6 +80,12 @@ func (dm *domainMap) Get(store kv.Storage) (d *domain.Domain, err error) {
 	return
 }
 
+// GetDomain gets the domain of the store, the domain is created if it doesn't exist.
+func GetDomain(store kv.Storage) (*domain.Domain, error) {
+	do, err := domap.Get(store)
+	return do, errors.Trace(err)
+}
+
 func (dm *domainMap) Delete(store kv.Storage) {
 	dm.mu.Lock()
 	delete(dm.domains, store.UUID())
@@ -138,9 +147,33 @@ func resetStmtCtx(ctx context.Context, s ast.StmtNode) {
 			}
 		}
 	}
+	sc.MemTracker = memory.NewTracker("query", sessVars.MemQuotaQuery)
+	if ms := maxExecutionTime(sessVars, s); ms > 0 {
+		sc.Deadline = time.Now().Add(time.Duration(ms) * time.Millisecond)
+	}
 	sessVars.StmtCtx = sc
 }
 
+// maxExecutionTime returns the timeout of s in milliseconds. Like MySQL, only the SELECT statements
+// have timeouts, and the MAX_EXECUTION_TIME hint overrides the max_execution_time variable.
+func maxExecutionTime(sessVars *variable.SessionVars, s ast.StmtNode) uint64 {
+	if sessVars.InRestrictedSQL {
+		return 0
+	}
+	switch x := s.(type) {
+	case *ast.SelectStmt:
+		for _, hint := range x.TableHints {
+			if hint.HintName.L == "max_execution_time" {
+				return hint.MaxExecutionTime
+			}
+		}
+		return sessVars.MaxExecutionTime
+	case *ast.UnionStmt:
+		return sessVars.MaxExecutionTime
+	}
+	return 0
+}
+
 // Compile is safe for concurrent use by multiple goroutines.
 func Compile(ctx context.Context, rawStmt ast.StmtNode) (ast.Statement, error) {
 	compiler := executor.Compiler{}
@@ -166,6 +199,11 @@ func runStmt(ctx context.Context, s ast.Statement) (ast.RecordSet, error) {
 		} else {
 			err = se.CommitTxn()
 		}
+	} else if terror.ErrorEqual(err, audit.ErrAuditFailed) {
+		// The changes of the statement can't be committed without being audited, so the whole transaction
+		// is rolled back.
+		log.Warnf("[%d] RollbackTxn for audit error.", se.sessionVars.ConnectionID)
+		se.RollbackTxn()
 	}
 	return rs, errors.Trace(err)
 }
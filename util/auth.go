@@ -14,10 +14,20 @@
 package util
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/juju/errors"
+	"golang.org/x/crypto/pbkdf2"
 )
 
 // CalcPassword is the algorithm convert hashed password to auth string.
@@ -49,6 +59,46 @@ func Sha1Hash(bs []byte) []byte {
 	return crypt.Sum(nil)
 }
 
+// Sha256Hash is an util function to calculate sha256 hash.
+func Sha256Hash(bs []byte) []byte {
+	crypt := sha256.New()
+	crypt.Write(bs)
+	return crypt.Sum(nil)
+}
+
+// CalcSha2Password is the algorithm of caching_sha2_password to convert password to auth string.
+// See https://dev.mysql.com/doc/dev/mysql-server/latest/page_caching_sha2_authentication_exchanges.html
+// SHA256( password ) XOR SHA256( SHA256( SHA256( password ) ) <concat> "20-bytes random data from server" )
+func CalcSha2Password(salt, password []byte) []byte {
+	if len(password) == 0 {
+		return nil
+	}
+	stage1 := Sha256Hash(password)
+	return xorSha2Scramble(stage1, Sha256Hash(stage1), salt)
+}
+
+// CheckSha2Scramble checks the caching_sha2_password auth string sent by the client,
+// sha2pwd is SHA256( SHA256( password ) ).
+func CheckSha2Scramble(scramble, salt, sha2pwd []byte) bool {
+	if len(scramble) != sha256.Size || len(sha2pwd) != sha256.Size {
+		return false
+	}
+	// Recover SHA256( password ) from the scramble and check it against sha2pwd.
+	stage1 := xorSha2Scramble(scramble, sha2pwd, salt)
+	return bytes.Equal(Sha256Hash(stage1), sha2pwd)
+}
+
+func xorSha2Scramble(data, sha2pwd, salt []byte) []byte {
+	crypt := sha256.New()
+	crypt.Write(sha2pwd)
+	crypt.Write(salt)
+	result := crypt.Sum(nil)
+	for i := range result {
+		result[i] ^= data[i]
+	}
+	return result
+}
+
 // EncodePassword converts plaintext password to hashed hex string.
 func EncodePassword(pwd string) string {
 	if len(pwd) == 0 {
@@ -66,3 +116,88 @@ func DecodePassword(pwd string) ([]byte, error) {
 	}
 	return x, nil
 }
+
+// The authentication methods of the PostgreSQL protocol.
+const (
+	PGAuthPassword    = "password"
+	PGAuthMD5         = "md5"
+	PGAuthScramSha256 = "scram-sha-256"
+)
+
+// PGMD5Password encodes the password for the md5 authentication of the PostgreSQL protocol,
+// the result is "md5" followed by the hex string of MD5(password + user).
+func PGMD5Password(user, pwd string) string {
+	if len(pwd) == 0 {
+		return ""
+	}
+	sum := md5.Sum([]byte(pwd + user))
+	return "md5" + hex.EncodeToString(sum[:])
+}
+
+const (
+	// PGScramIterations is the iteration count of PBKDF2 used by SCRAM-SHA-256.
+	PGScramIterations = 4096
+	pgScramPrefix     = "SCRAM-SHA-256$"
+	pgScramSaltLen    = 16
+)
+
+// PGScramPassword encodes the password with a random salt for the SCRAM-SHA-256 authentication
+// of the PostgreSQL protocol.
+func PGScramPassword(pwd string) (string, error) {
+	if len(pwd) == 0 {
+		return "", nil
+	}
+	salt := make([]byte, pgScramSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Trace(err)
+	}
+	return EncodePGScramPassword(pwd, salt, PGScramIterations), nil
+}
+
+// EncodePGScramPassword encodes the password in the format PostgreSQL stores SCRAM-SHA-256 verifiers:
+// SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+// See https://tools.ietf.org/html/rfc5802#section-3
+func EncodePGScramPassword(pwd string, salt []byte, iterations int) string {
+	saltedPassword := pbkdf2.Key([]byte(pwd), salt, iterations, sha256.Size, sha256.New)
+	storedKey := Sha256Hash(HmacSha256(saltedPassword, []byte("Client Key")))
+	serverKey := HmacSha256(saltedPassword, []byte("Server Key"))
+	enc := base64.StdEncoding
+	return fmt.Sprintf("%s%d:%s$%s:%s", pgScramPrefix, iterations,
+		enc.EncodeToString(salt), enc.EncodeToString(storedKey), enc.EncodeToString(serverKey))
+}
+
+// DecodePGScramPassword decodes the SCRAM-SHA-256 verifier encoded by EncodePGScramPassword.
+func DecodePGScramPassword(verifier string) (iterations int, salt, storedKey, serverKey []byte, err error) {
+	if !strings.HasPrefix(verifier, pgScramPrefix) {
+		return 0, nil, nil, nil, errors.Errorf("invalid SCRAM-SHA-256 verifier")
+	}
+	parts := strings.Split(verifier[len(pgScramPrefix):], "$")
+	if len(parts) != 2 {
+		return 0, nil, nil, nil, errors.Errorf("invalid SCRAM-SHA-256 verifier")
+	}
+	params, keys := strings.Split(parts[0], ":"), strings.Split(parts[1], ":")
+	if len(params) != 2 || len(keys) != 2 {
+		return 0, nil, nil, nil, errors.Errorf("invalid SCRAM-SHA-256 verifier")
+	}
+	if iterations, err = strconv.Atoi(params[0]); err != nil {
+		return 0, nil, nil, nil, errors.Trace(err)
+	}
+	enc := base64.StdEncoding
+	if salt, err = enc.DecodeString(params[1]); err != nil {
+		return 0, nil, nil, nil, errors.Trace(err)
+	}
+	if storedKey, err = enc.DecodeString(keys[0]); err != nil {
+		return 0, nil, nil, nil, errors.Trace(err)
+	}
+	if serverKey, err = enc.DecodeString(keys[1]); err != nil {
+		return 0, nil, nil, nil, errors.Trace(err)
+	}
+	return iterations, salt, storedKey, serverKey, nil
+}
+
+// HmacSha256 is the HMAC-SHA-256 of data with the key.
+func HmacSha256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -43,3 +43,39 @@ func (s *testAuthSuite) TestCalcPassword(c *C) {
 	checkAuth := []byte{126, 168, 249, 64, 180, 223, 60, 240, 69, 249, 184, 57, 21, 34, 214, 219, 8, 193, 208, 55}
 	c.Assert(CalcPassword(salt, pwd), DeepEquals, checkAuth)
 }
+
+func (s *testAuthSuite) TestSha2Password(c *C) {
+	defer testleak.AfterTest(c)()
+	salt := []byte{116, 32, 122, 120, 2, 51, 33, 66, 47, 85, 34, 39, 84, 58, 108, 14, 62, 47, 120, 126}
+	sha2pwd := Sha256Hash(Sha256Hash([]byte("123")))
+	scramble := CalcSha2Password(salt, []byte("123"))
+	c.Assert(CheckSha2Scramble(scramble, salt, sha2pwd), IsTrue)
+	c.Assert(CheckSha2Scramble(CalcSha2Password(salt, []byte("456")), salt, sha2pwd), IsFalse)
+	c.Assert(CheckSha2Scramble(scramble[1:], salt, sha2pwd), IsFalse)
+	c.Assert(CalcSha2Password(salt, nil), IsNil)
+}
+
+func (s *testAuthSuite) TestPGPassword(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(PGMD5Password("postgres", "postgres"), Equals, "md53175bce1d3201d16594cebf9d7eb3f9d")
+	c.Assert(PGMD5Password("postgres", ""), Equals, "")
+	verifier, err := PGScramPassword("")
+	c.Assert(err, IsNil)
+	c.Assert(verifier, Equals, "")
+
+	salt := []byte("0123456789abcdef")
+	verifier = EncodePGScramPassword("123", salt, PGScramIterations)
+	iterations, decodedSalt, storedKey, serverKey, err := DecodePGScramPassword(verifier)
+	c.Assert(err, IsNil)
+	c.Assert(iterations, Equals, PGScramIterations)
+	c.Assert(decodedSalt, DeepEquals, salt)
+	c.Assert(storedKey, HasLen, 32)
+	c.Assert(serverKey, HasLen, 32)
+	verifier1, err := PGScramPassword("123")
+	c.Assert(err, IsNil)
+	verifier2, err := PGScramPassword("123")
+	c.Assert(err, IsNil)
+	c.Assert(verifier1, Not(Equals), verifier2)
+	_, _, _, _, err = DecodePGScramPassword("md53175bce1d3201d16594cebf9d7eb3f9d")
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,131 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package generallog writes the general query log, it records every command received from the clients.
+//
+// The log is toggled by the general_log system variable. An entry is a tab separated line of the time,
+// the connection ID, the user, the command, the start timestamp of the transaction and the argument
+// of the command, for example:
+//
+//	2017-06-01T10:00:00.123456+08:00	3	root@127.0.0.1	Query	0	select * from t
+//
+// The line breaks, the tabs and the backslashes in the argument are escaped as "\n", "\t" and "\\".
+//
+// The entries are written to the file set by SetFile, or to the server log if there isn't any.
+package generallog
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+)
+
+// Entry is an entry of the general query log.
+type Entry struct {
+	Time   time.Time
+	ConnID uint64
+	User   string
+	// Command is the name of the command, e.g. "Query" and "StmtExecute".
+	Command string
+	// TxnStartTS is the start timestamp of the transaction when the command is received, it's 0
+	// if there isn't any.
+	TxnStartTS uint64
+	// Arg is the argument of the command, e.g. the SQL of a query.
+	Arg string
+}
+
+// enabled is 1 if the general query log is enabled, it's checked on every command so it's accessed atomically.
+var enabled int32
+
+// SetEnabled enables or disables the general query log.
+func SetEnabled(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&enabled, v)
+}
+
+// Enabled returns true if the general query log is enabled, the callers should skip building the
+// entries if it's false.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+var output struct {
+	sync.Mutex
+	file *os.File
+}
+
+// SetFile sets the file that the entries are appended to, an empty path writes the entries to the server log.
+func SetFile(path string) error {
+	output.Lock()
+	defer output.Unlock()
+	if output.file != nil {
+		output.file.Close()
+		output.file = nil
+	}
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	output.file = file
+	return nil
+}
+
+// argEscaper escapes the argument, so an entry is always a line of the tab separated fields.
+var argEscaper = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\t", "\\t")
+
+// format formats the entry as a line without the line break.
+func (e *Entry) format() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(e.Time.Format(time.RFC3339Nano))
+	buf.WriteByte('\t')
+	buf.WriteString(strconv.FormatUint(e.ConnID, 10))
+	buf.WriteByte('\t')
+	buf.WriteString(e.User)
+	buf.WriteByte('\t')
+	buf.WriteString(e.Command)
+	buf.WriteByte('\t')
+	buf.WriteString(strconv.FormatUint(e.TxnStartTS, 10))
+	buf.WriteByte('\t')
+	buf.WriteString(argEscaper.Replace(e.Arg))
+	return buf.Bytes()
+}
+
+// Write writes the entry, the errors are only logged as the general query log is only for diagnosis.
+func Write(e *Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	line := e.format()
+	output.Lock()
+	defer output.Unlock()
+	if output.file == nil {
+		log.Infof("[GENERAL_LOG] %s", line)
+		return
+	}
+	if _, err := output.file.Write(append(line, '\n')); err != nil {
+		log.Warnf("[general log] write error: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generallog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testGeneralLogSuite{})
+
+type testGeneralLogSuite struct{}
+
+func (s *testGeneralLogSuite) TestEnabled(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(Enabled(), IsFalse)
+	SetEnabled(true)
+	c.Assert(Enabled(), IsTrue)
+	SetEnabled(false)
+	c.Assert(Enabled(), IsFalse)
+}
+
+func (s *testGeneralLogSuite) TestWrite(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "generallog")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "general.log")
+
+	c.Assert(SetFile(path), IsNil)
+	ts := time.Date(2017, 6, 1, 10, 0, 0, 123456000, time.UTC)
+	Write(&Entry{Time: ts, ConnID: 3, User: "root", Command: "Query", Arg: "select 1"})
+	Write(&Entry{Time: ts, ConnID: 3, User: "root", Command: "StmtExecute", TxnStartTS: 392, Arg: "1 [10 'abc' NULL]"})
+	Write(&Entry{Time: ts, ConnID: 3, User: "root", Command: "Query", Arg: "select 'a\\b'\n\tfrom t"})
+	c.Assert(SetFile(""), IsNil)
+	// The entries are written to the server log after the file is unset.
+	Write(&Entry{ConnID: 4, Command: "Ping"})
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "2017-06-01T10:00:00.123456Z\t3\troot\tQuery\t0\tselect 1\n"+
+		"2017-06-01T10:00:00.123456Z\t3\troot\tStmtExecute\t392\t1 [10 'abc' NULL]\n"+
+		"2017-06-01T10:00:00.123456Z\t3\troot\tQuery\t0\tselect 'a\\\\b'\\n\\tfrom t\n")
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Tracker tracks the memory usage of a statement or one of its executors.
+// The trackers form a tree, the memory consumed by a tracker is also consumed by its ancestors,
+// so the root tracker knows the memory usage of the whole statement.
+// It's safe to consume memory in multiple goroutines.
+type Tracker struct {
+	label string
+	// bytesLimit is the quota of the tracker, the tracker has no quota if it's not positive.
+	bytesLimit int64
+	parent     *Tracker
+
+	bytesConsumed int64 // accessed atomically
+	maxConsumed   int64 // accessed atomically
+	exceeded      int32 // accessed atomically, set to 1 by MarkExceeded
+}
+
+// NewTracker creates a memory tracker with a label and a quota in bytes.
+func NewTracker(label string, bytesLimit int64) *Tracker {
+	return &Tracker{
+		label:      label,
+		bytesLimit: bytesLimit,
+	}
+}
+
+// AttachTo attaches the tracker to parent, the memory consumed by the tracker is also consumed by parent.
+// It does nothing if parent is nil.
+func (t *Tracker) AttachTo(parent *Tracker) {
+	if parent == nil {
+		return
+	}
+	t.parent = parent
+	parent.Consume(t.BytesConsumed())
+}
+
+// Consume adds bytes to the memory usage of the tracker and its ancestors, bytes can be negative when memory is released.
+func (t *Tracker) Consume(bytes int64) {
+	for tracker := t; tracker != nil; tracker = tracker.parent {
+		consumed := atomic.AddInt64(&tracker.bytesConsumed, bytes)
+		for {
+			max := atomic.LoadInt64(&tracker.maxConsumed)
+			if consumed <= max || atomic.CompareAndSwapInt64(&tracker.maxConsumed, max, consumed) {
+				break
+			}
+		}
+	}
+}
+
+// BytesConsumed returns the current memory usage of the tracker.
+func (t *Tracker) BytesConsumed() int64 {
+	return atomic.LoadInt64(&t.bytesConsumed)
+}
+
+// MaxConsumed returns the peak memory usage of the tracker.
+func (t *Tracker) MaxConsumed() int64 {
+	return atomic.LoadInt64(&t.maxConsumed)
+}
+
+// BytesLimit returns the quota of the tracker.
+func (t *Tracker) BytesLimit() int64 {
+	return t.bytesLimit
+}
+
+// Exceeded returns the first tracker from t up to the root whose quota is exceeded, it returns nil if there is none.
+func (t *Tracker) Exceeded() *Tracker {
+	for tracker := t; tracker != nil; tracker = tracker.parent {
+		if tracker.bytesLimit > 0 && tracker.BytesConsumed() > tracker.bytesLimit {
+			return tracker
+		}
+	}
+	return nil
+}
+
+// MarkExceeded marks the quota of the tracker as exceeded, it returns true only for the first call,
+// so the action on the exceeded quota like logging is done once.
+func (t *Tracker) MarkExceeded() bool {
+	return atomic.CompareAndSwapInt32(&t.exceeded, 0, 1)
+}
+
+func (t *Tracker) String() string {
+	return fmt.Sprintf("%s consumed %d bytes, max %d bytes, quota %d bytes",
+		t.label, t.BytesConsumed(), t.MaxConsumed(), t.bytesLimit)
+}
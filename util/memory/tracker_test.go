@@ -0,0 +1,70 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testTrackerSuite{})
+
+type testTrackerSuite struct{}
+
+func (s *testTrackerSuite) TestConsume(c *C) {
+	defer testleak.AfterTest(c)()
+	root := NewTracker("query", 100)
+	child := NewTracker("sort", -1)
+	child.Consume(10)
+	child.AttachTo(root)
+	c.Assert(root.BytesConsumed(), Equals, int64(10))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			child.Consume(5)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	c.Assert(child.BytesConsumed(), Equals, int64(60))
+	c.Assert(root.BytesConsumed(), Equals, int64(60))
+	c.Assert(child.Exceeded(), IsNil)
+
+	child.Consume(50)
+	c.Assert(child.Exceeded(), Equals, root)
+	c.Assert(root.MarkExceeded(), IsTrue)
+	c.Assert(root.MarkExceeded(), IsFalse)
+
+	child.Consume(-80)
+	c.Assert(child.Exceeded(), IsNil)
+	c.Assert(root.BytesConsumed(), Equals, int64(30))
+	c.Assert(root.MaxConsumed(), Equals, int64(110))
+	c.Assert(child.MaxConsumed(), Equals, int64(110))
+
+	// A nil parent is ignored.
+	orphan := NewTracker("cache", -1)
+	orphan.AttachTo(nil)
+	orphan.Consume(1000)
+	c.Assert(orphan.Exceeded(), IsNil)
+}
@@ -0,0 +1,38 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+)
+
+// ProcessInfo is the information of a connection shown by the SHOW PROCESSLIST statement.
+type ProcessInfo struct {
+	ID      uint64
+	User    string
+	Host    string
+	DB      string
+	Command string
+	// Time is the time when the connection entered its current state.
+	Time  time.Time
+	State string
+	Info  string
+	// Mem is the peak memory usage of the current or the last statement in bytes.
+	Mem int64
+}
+
+// SessionManager is implemented by the server, SHOW PROCESSLIST gets the information of all the connections from it.
+type SessionManager interface {
+	ShowProcessList() []ProcessInfo
+}
@@ -0,0 +1,315 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slowlog writes and parses the slow query log.
+//
+// An entry of the slow query log is some header lines starting with "# ", followed by the query.
+// The "# Time:" line starts a new entry, for example:
+//
+//	# Time: 2017-06-01T10:00:00.123456+08:00
+//	# Conn_ID: 3
+//	# User: root@127.0.0.1
+//	# DB: test
+//	# Digest: 3f3a...
+//	# Query_time: 0.523000
+//	# Parse_time: 0.000120
+//	# Compile_time: 0.001305
+//	# Exec_time: 0.521575
+//	# Cop_wait_time: 0.413080
+//	# Process_keys: 10000
+//	# Mem_max: 1048576
+//	# Plan: Table(t)->Sort
+//	select * from t order by a;
+//
+// The query lines starting with "#" after any spaces, such as the comments, are written with one more
+// leading space, so they can't be taken as the header lines. The entries which can't be parsed are skipped.
+package slowlog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// Entry is an entry of the slow query log.
+type Entry struct {
+	Time          time.Time
+	ConnID        uint64
+	User          string
+	DB            string
+	Digest        string
+	QueryTime     time.Duration
+	ParseTime     time.Duration
+	CompileTime   time.Duration
+	ExecTime      time.Duration
+	CopWaitTime   time.Duration
+	ProcessedKeys int64
+	MemMax        int64
+	Plan          string
+	Query         string
+}
+
+// The names of the header fields.
+const (
+	fieldTime          = "Time"
+	fieldConnID        = "Conn_ID"
+	fieldUser          = "User"
+	fieldDB            = "DB"
+	fieldDigest        = "Digest"
+	fieldQueryTime     = "Query_time"
+	fieldParseTime     = "Parse_time"
+	fieldCompileTime   = "Compile_time"
+	fieldExecTime      = "Exec_time"
+	fieldCopWaitTime   = "Cop_wait_time"
+	fieldProcessedKeys = "Process_keys"
+	fieldMemMax        = "Mem_max"
+	fieldPlan          = "Plan"
+
+	headerPrefix = "# "
+	timeHeader   = headerPrefix + fieldTime + ": "
+)
+
+// maxParseSize is the maximum size of the slow query log that ParseFile reads, the older entries
+// beyond the size are ignored.
+const maxParseSize = 64 << 20
+
+// escapeQueryLine adds a leading space to the query line which starts with "#" after any spaces.
+func escapeQueryLine(line string) string {
+	if strings.HasPrefix(strings.TrimLeft(line, " "), "#") {
+		return " " + line
+	}
+	return line
+}
+
+// unescapeQueryLine removes the leading space added by escapeQueryLine.
+func unescapeQueryLine(line string) string {
+	if strings.HasPrefix(line, " ") && strings.HasPrefix(strings.TrimLeft(line, " "), "#") {
+		return line[1:]
+	}
+	return line
+}
+
+// Format formats the entry as it's written to the slow query log.
+func (e *Entry) Format() string {
+	var buf bytes.Buffer
+	writeField := func(name, value string) {
+		// The header fields can't span lines.
+		value = strings.Replace(value, "\n", " ", -1)
+		fmt.Fprintf(&buf, "%s%s: %s\n", headerPrefix, name, value)
+	}
+	seconds := func(d time.Duration) string {
+		return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+	}
+	writeField(fieldTime, e.Time.Format(time.RFC3339Nano))
+	writeField(fieldConnID, strconv.FormatUint(e.ConnID, 10))
+	writeField(fieldUser, e.User)
+	writeField(fieldDB, e.DB)
+	writeField(fieldDigest, e.Digest)
+	writeField(fieldQueryTime, seconds(e.QueryTime))
+	writeField(fieldParseTime, seconds(e.ParseTime))
+	writeField(fieldCompileTime, seconds(e.CompileTime))
+	writeField(fieldExecTime, seconds(e.ExecTime))
+	writeField(fieldCopWaitTime, seconds(e.CopWaitTime))
+	writeField(fieldProcessedKeys, strconv.FormatInt(e.ProcessedKeys, 10))
+	writeField(fieldMemMax, strconv.FormatInt(e.MemMax, 10))
+	writeField(fieldPlan, e.Plan)
+	lines := strings.Split(e.Query, "\n")
+	for i, line := range lines {
+		lines[i] = escapeQueryLine(line)
+	}
+	buf.WriteString(strings.Join(lines, "\n"))
+	buf.WriteString(";\n")
+	return buf.String()
+}
+
+var logger struct {
+	sync.Mutex
+	path string
+	file *os.File
+}
+
+// SetFile sets the file of the slow query log, the entries are appended to the file.
+// An empty path disables the slow query log.
+func SetFile(path string) error {
+	logger.Lock()
+	defer logger.Unlock()
+	if logger.file != nil {
+		logger.file.Close()
+		logger.file = nil
+	}
+	logger.path = ""
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	logger.path, logger.file = path, file
+	return nil
+}
+
+// File returns the path of the slow query log file, it's empty if the slow query log is disabled.
+func File() string {
+	logger.Lock()
+	defer logger.Unlock()
+	return logger.path
+}
+
+// Write appends an entry to the slow query log, it does nothing if the slow query log is disabled.
+func Write(e *Entry) error {
+	logger.Lock()
+	defer logger.Unlock()
+	if logger.file == nil {
+		return nil
+	}
+	_, err := logger.file.WriteString(e.Format())
+	return errors.Trace(err)
+}
+
+// ParseFile parses the entries in a slow query log file, only the last maxParseSize bytes of the file are read.
+func ParseFile(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	reader := bufio.NewReader(file)
+	if offset := info.Size() - maxParseSize; offset > 0 {
+		if _, err = file.Seek(offset, io.SeekStart); err != nil {
+			return nil, errors.Trace(err)
+		}
+		// Skips the partial line.
+		if _, err = reader.ReadString('\n'); err != nil && err != io.EOF {
+			return nil, errors.Trace(err)
+		}
+	}
+	entries, err := Parse(reader)
+	return entries, errors.Trace(err)
+}
+
+// Parse parses the entries of the slow query log in r. The lines before the first entry are ignored,
+// and so are the entries which have invalid header fields.
+func Parse(r io.Reader) ([]*Entry, error) {
+	var (
+		entries []*Entry
+		entry   *Entry
+		query   []string
+		inQuery bool
+		invalid bool
+	)
+	finishEntry := func() {
+		if entry != nil && !invalid {
+			entry.Query = strings.TrimSuffix(strings.Join(query, "\n"), ";")
+			entries = append(entries, entry)
+		}
+		entry, query, inQuery, invalid = nil, nil, false, false
+	}
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Trace(err)
+		}
+		if err == io.EOF && line == "" {
+			break
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, timeHeader):
+			finishEntry()
+			entry = new(Entry)
+			if entry.Time, err = time.Parse(time.RFC3339Nano, line[len(timeHeader):]); err != nil {
+				invalid = true
+			}
+		case entry == nil:
+		case !inQuery && strings.HasPrefix(line, headerPrefix):
+			if err = entry.parseField(line[len(headerPrefix):]); err != nil {
+				invalid = true
+			}
+		default:
+			inQuery = true
+			query = append(query, unescapeQueryLine(line))
+		}
+	}
+	finishEntry()
+	return entries, nil
+}
+
+func (e *Entry) parseField(field string) error {
+	pos := strings.Index(field, ": ")
+	if pos < 0 {
+		// The empty values are written without the trailing space.
+		if !strings.HasSuffix(field, ":") {
+			return errors.Errorf("invalid field %q", field)
+		}
+		pos = len(field) - 1
+	}
+	name, value := field[:pos], ""
+	if pos+2 <= len(field) {
+		value = field[pos+2:]
+	}
+	var err error
+	switch name {
+	case fieldConnID:
+		e.ConnID, err = strconv.ParseUint(value, 10, 64)
+	case fieldUser:
+		e.User = value
+	case fieldDB:
+		e.DB = value
+	case fieldDigest:
+		e.Digest = value
+	case fieldQueryTime:
+		e.QueryTime, err = parseSeconds(value)
+	case fieldParseTime:
+		e.ParseTime, err = parseSeconds(value)
+	case fieldCompileTime:
+		e.CompileTime, err = parseSeconds(value)
+	case fieldExecTime:
+		e.ExecTime, err = parseSeconds(value)
+	case fieldCopWaitTime:
+		e.CopWaitTime, err = parseSeconds(value)
+	case fieldProcessedKeys:
+		e.ProcessedKeys, err = strconv.ParseInt(value, 10, 64)
+	case fieldMemMax:
+		e.MemMax, err = strconv.ParseInt(value, 10, 64)
+	case fieldPlan:
+		e.Plan = value
+	}
+	// The unknown fields are ignored.
+	if err != nil {
+		return errors.Errorf("invalid %s: %v", name, err)
+	}
+	return nil
+}
+
+func parseSeconds(value string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	// Rounds to the nearest nanosecond, the durations are not negative.
+	return time.Duration(seconds*float64(time.Second) + 0.5), nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testSlowLogSuite{})
+
+type testSlowLogSuite struct{}
+
+func (s *testSlowLogSuite) TestWriteAndParse(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "slowlog")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slow.log")
+
+	entry := &Entry{
+		Time:          time.Date(2017, 6, 1, 10, 0, 0, 123456000, time.UTC),
+		ConnID:        3,
+		User:          "root@127.0.0.1",
+		DB:            "test",
+		Digest:        "3f3a",
+		QueryTime:     523 * time.Millisecond,
+		ParseTime:     120 * time.Microsecond,
+		CompileTime:   1305 * time.Microsecond,
+		ExecTime:      521575 * time.Microsecond,
+		CopWaitTime:   413080 * time.Microsecond,
+		ProcessedKeys: 10000,
+		MemMax:        1048576,
+		Plan:          "Table(t)->Sort",
+		Query:         "select *\n# not a header\n  # comment\nfrom t order by a",
+	}
+	// Nothing is written if the slow query log is disabled.
+	c.Assert(Write(entry), IsNil)
+	c.Assert(File(), Equals, "")
+
+	c.Assert(SetFile(path), IsNil)
+	defer SetFile("")
+	c.Assert(File(), Equals, path)
+	c.Assert(Write(entry), IsNil)
+	second := &Entry{Time: entry.Time.Add(time.Second), ConnID: 4, Query: "commit"}
+	c.Assert(Write(second), IsNil)
+
+	entries, err := ParseFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Assert(entries[0].Time.Equal(entry.Time), IsTrue)
+	entries[0].Time = entry.Time
+	c.Assert(entries[0], DeepEquals, entry)
+	c.Assert(entries[1].ConnID, Equals, uint64(4))
+	c.Assert(entries[1].DB, Equals, "")
+	c.Assert(entries[1].Query, Equals, "commit")
+
+	// The lines before the first entry are ignored.
+	entries, err = Parse(strings.NewReader("garbage\n" + entry.Format()))
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+
+	// The query lines which look like the header lines are escaped.
+	third := &Entry{Time: entry.Time, Query: "# comment\nselect 1\n# Time: 2017-06-01T10:00:00Z\n # Query_time: slow"}
+	entries, err = Parse(strings.NewReader(third.Format()))
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Query, Equals, third.Query)
+
+	// The invalid entries are skipped.
+	entries, err = Parse(strings.NewReader("# Time: yesterday\nselect 1;\n" +
+		"# Time: 2017-06-01T10:00:00Z\n# Query_time: slow\nselect 2;\n" +
+		"# Time: 2017-06-01T10:00:00Z\n# comment\nselect 3;\n" +
+		second.Format()))
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Query, Equals, "commit")
+}
+
+func (s *testSlowLogSuite) TestParseFileTail(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "slowlog")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slow.log")
+
+	// Only the entries in the last maxParseSize bytes are parsed.
+	first := &Entry{Time: time.Now(), Query: "select 1"}
+	last := &Entry{Time: time.Now(), Query: "select 2"}
+	data := first.Format() + strings.Repeat("x", maxParseSize) + "\n" + last.Format()
+	c.Assert(ioutil.WriteFile(path, []byte(data), 0644), IsNil)
+	entries, err := ParseFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Query, Equals, "select 2")
+}
@@ -0,0 +1,235 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing records the timed operations of a request as a tree of spans.
+//
+// A trace starts with a root span, the child spans are started from their parent spans. All the methods
+// of a nil *Span are no-ops, so the code paths can be instrumented unconditionally and the spans are only
+// recorded when the request is traced. The spans are passed to the goroutines with a context.Context.
+//
+// The finished traces are exported as JSON lines to a file if it's set by SetExportFile, for example:
+//
+//	{"operation":"session.Execute","start_time":"2017-06-01T10:00:00.123456+08:00","duration":1.523,"children":[...]}
+//
+// The duration is in milliseconds.
+package tracing
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"golang.org/x/net/context"
+)
+
+// Span is a timed operation of a trace.
+type Span struct {
+	operation string
+	startTime time.Time
+	root      bool
+
+	mu struct {
+		sync.Mutex
+		duration time.Duration
+		finished bool
+		children []*Span
+	}
+}
+
+// NewRootSpan starts a new trace at startTime, the trace is exported when the root span is finished.
+func NewRootSpan(operation string, startTime time.Time) *Span {
+	return &Span{operation: operation, startTime: startTime, root: true}
+}
+
+// StartChild starts a child span, it returns nil if s is nil.
+func (s *Span) StartChild(operation string) *Span {
+	if s == nil {
+		return nil
+	}
+	child := &Span{operation: operation, startTime: time.Now()}
+	s.mu.Lock()
+	s.mu.children = append(s.mu.children, child)
+	s.mu.Unlock()
+	return child
+}
+
+// AddChild adds a finished child span, it's used by the operations that are done before the trace starts.
+func (s *Span) AddChild(operation string, startTime time.Time, d time.Duration) {
+	if s == nil {
+		return
+	}
+	child := &Span{operation: operation, startTime: startTime}
+	child.mu.finished = true
+	child.mu.duration = d
+	s.mu.Lock()
+	s.mu.children = append(s.mu.children, child)
+	s.mu.Unlock()
+}
+
+// Finish finishes the span, the duration is the time since the span is started.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.FinishWithDuration(time.Since(s.startTime))
+}
+
+// FinishWithDuration finishes the span with the duration, it's used by the operations that are not continuous,
+// for example, an executor is only running in its Next calls. Finishing a finished span is a no-op.
+func (s *Span) FinishWithDuration(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.mu.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.finished = true
+	s.mu.duration = d
+	s.mu.Unlock()
+	if s.root {
+		export(s)
+	}
+}
+
+// Operation returns the operation name of the span.
+func (s *Span) Operation() string {
+	return s.operation
+}
+
+// StartTime returns the time when the span is started.
+func (s *Span) StartTime() time.Time {
+	return s.startTime
+}
+
+// Duration returns the duration of the span, it's the time since the span is started if it's not finished.
+func (s *Span) Duration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.mu.finished {
+		return time.Since(s.startTime)
+	}
+	return s.mu.duration
+}
+
+// Children returns the child spans in the order they are started.
+func (s *Span) Children() []*Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	children := make([]*Span, len(s.mu.children))
+	copy(children, s.mu.children)
+	return children
+}
+
+// Walk visits the span tree in depth-first order, depth is 0 for s.
+func (s *Span) Walk(fn func(span *Span, depth int)) {
+	s.walk(fn, 0)
+}
+
+func (s *Span) walk(fn func(span *Span, depth int), depth int) {
+	fn(s, depth)
+	for _, child := range s.Children() {
+		child.walk(fn, depth+1)
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying the span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span in ctx, it returns nil if there isn't any.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// spanJSON is the exported format of a span.
+type spanJSON struct {
+	Operation string      `json:"operation"`
+	StartTime time.Time   `json:"start_time"`
+	Duration  float64     `json:"duration"`
+	Children  []*spanJSON `json:"children,omitempty"`
+}
+
+func (s *Span) toJSON() *spanJSON {
+	js := &spanJSON{
+		Operation: s.operation,
+		StartTime: s.startTime,
+		Duration:  float64(s.Duration()) / float64(time.Millisecond),
+	}
+	for _, child := range s.Children() {
+		js.Children = append(js.Children, child.toJSON())
+	}
+	return js
+}
+
+var exporter struct {
+	sync.Mutex
+	file *os.File
+}
+
+// SetExportFile sets the file that the finished traces are appended to, and every statement is traced
+// when it's set. An empty path disables the exporting.
+func SetExportFile(path string) error {
+	exporter.Lock()
+	defer exporter.Unlock()
+	if exporter.file != nil {
+		exporter.file.Close()
+		exporter.file = nil
+	}
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	exporter.file = file
+	return nil
+}
+
+// Exporting returns true if the traces are exported to a file.
+func Exporting() bool {
+	exporter.Lock()
+	defer exporter.Unlock()
+	return exporter.file != nil
+}
+
+// export writes the trace to the export file, the errors are only logged as the trace is only for diagnosis.
+func export(root *Span) {
+	exporter.Lock()
+	defer exporter.Unlock()
+	if exporter.file == nil {
+		return
+	}
+	js, err := json.Marshal(root.toJSON())
+	if err == nil {
+		_, err = exporter.file.Write(append(js, '\n'))
+	}
+	if err != nil {
+		log.Warnf("[tracing] export trace error: %v", err)
+	}
+}
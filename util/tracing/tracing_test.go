@@ -0,0 +1,106 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+	"golang.org/x/net/context"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testTracingSuite{})
+
+type testTracingSuite struct{}
+
+func (s *testTracingSuite) TestSpanTree(c *C) {
+	defer testleak.AfterTest(c)()
+	startTime := time.Now()
+	root := NewRootSpan("root", startTime)
+	c.Assert(root.StartTime(), Equals, startTime)
+	root.AddChild("added", startTime, time.Millisecond)
+	child := root.StartChild("child")
+	grandChild := child.StartChild("grand child")
+	grandChild.Finish()
+	child.FinishWithDuration(time.Second)
+	// Finishing a finished span is a no-op.
+	child.Finish()
+	c.Assert(child.Duration(), Equals, time.Second)
+	root.StartChild("unfinished")
+	root.Finish()
+
+	var ops []string
+	root.Walk(func(span *Span, depth int) {
+		ops = append(ops, strings.Repeat("-", depth)+span.Operation())
+	})
+	c.Assert(ops, DeepEquals, []string{"root", "-added", "-child", "--grand child", "-unfinished"})
+
+	// The methods of a nil span are no-ops.
+	var nilSpan *Span
+	c.Assert(nilSpan.StartChild("child"), IsNil)
+	nilSpan.AddChild("child", startTime, time.Millisecond)
+	nilSpan.Finish()
+
+	ctx := ContextWithSpan(context.Background(), nilSpan)
+	c.Assert(SpanFromContext(ctx), IsNil)
+	ctx = ContextWithSpan(ctx, child)
+	c.Assert(SpanFromContext(ctx), Equals, child)
+	c.Assert(SpanFromContext(nil), IsNil)
+}
+
+func (s *testTracingSuite) TestExport(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "tracing")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trace.log")
+
+	// Nothing is exported if the export file isn't set.
+	NewRootSpan("not exported", time.Now()).Finish()
+	c.Assert(Exporting(), IsFalse)
+
+	c.Assert(SetExportFile(path), IsNil)
+	c.Assert(Exporting(), IsTrue)
+	root := NewRootSpan("root", time.Now())
+	root.StartChild("child").FinishWithDuration(2 * time.Millisecond)
+	// Only the root span triggers the exporting, and it's exported once.
+	root.Finish()
+	root.Finish()
+	c.Assert(SetExportFile(""), IsNil)
+	c.Assert(Exporting(), IsFalse)
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	c.Assert(lines, HasLen, 1)
+	var js spanJSON
+	c.Assert(json.Unmarshal([]byte(lines[0]), &js), IsNil)
+	c.Assert(js.Operation, Equals, "root")
+	c.Assert(js.StartTime.Equal(root.StartTime()), IsTrue)
+	c.Assert(js.Children, HasLen, 1)
+	c.Assert(js.Children[0].Operation, Equals, "child")
+	c.Assert(js.Children[0].Duration, Equals, float64(2))
+}